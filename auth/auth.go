@@ -0,0 +1,103 @@
+// Package auth authenticates callers of the customers service and carries the
+// resulting principal through the request context.
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ErrUnauthenticated is returned when a caller's credentials are missing or invalid.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Kind distinguishes the type of identity that authenticated.
+type Kind string
+
+const (
+	// KindUser is a person presenting a JWT issued by the identity provider.
+	KindUser Kind = "user"
+	// KindService is another backend presenting a static service token.
+	KindService Kind = "service"
+)
+
+// RoleService is the global role held by every principal authenticated with
+// a service token.
+const RoleService = "service"
+
+// Principal is the authenticated identity behind a request.
+type Principal struct {
+	Subject string
+	Kind    Kind
+	Issuer  string
+	// Roles are global roles, such as "admin" or "support", that apply
+	// regardless of the account being accessed.
+	Roles []string
+	// Accounts maps the ids of the accounts the principal belongs to onto the
+	// role held within each of them.
+	Accounts map[string]string
+}
+
+// HasRole reports whether the principal holds any of the given global roles.
+func (p Principal) HasRole(roles ...string) bool {
+	for _, held := range p.Roles {
+		for _, role := range roles {
+			if held == role {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Authenticator resolves a bearer token into a Principal.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (Principal, error)
+}
+
+// Chain returns an Authenticator that tries each authenticator in turn,
+// returning the first principal that authenticates.
+func Chain(authenticators ...Authenticator) Authenticator {
+	return chain(authenticators)
+}
+
+type chain []Authenticator
+
+func (c chain) Authenticate(ctx context.Context, token string) (Principal, error) {
+	err := ErrUnauthenticated
+	for _, a := range c {
+		var p Principal
+		p, err = a.Authenticate(ctx, token)
+		if err == nil {
+			return p, nil
+		}
+	}
+
+	return Principal{}, err
+}
+
+type principalKey struct{}
+
+// NewContext returns a copy of ctx carrying the principal.
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal carried by ctx, if any.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// bearerToken extracts the token from an "authorization: Bearer <token>" value.
+func bearerToken(header string) (string, bool) {
+	const prefix = "bearer "
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", false
+	}
+
+	token := strings.TrimSpace(header[len(prefix):])
+	return token, token != ""
+}
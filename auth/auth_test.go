@@ -0,0 +1,283 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+
+	segment := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	signed := segment(map[string]string{"alg": "RS256", "kid": kid}) + "." + segment(claims)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	a := NewJWTAuthenticator(staticKeySet{"k1": &key.PublicKey},
+		WithIssuer("https://id.symptomatic.io"),
+		WithAudience("customers"),
+	)
+
+	valid := map[string]interface{}{
+		"sub": "user-1",
+		"iss": "https://id.symptomatic.io",
+		"aud": []string{"customers", "billing"},
+		"exp": now.Add(time.Hour).Unix(),
+	}
+
+	with := func(key string, value interface{}) map[string]interface{} {
+		claims := map[string]interface{}{}
+		for k, v := range valid {
+			claims[k] = v
+		}
+		claims[key] = value
+		return claims
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		ok    bool
+	}{
+		{"valid", signRS256(t, key, "k1", valid), true},
+		{"single audience", signRS256(t, key, "k1", with("aud", "customers")), true},
+		{"expired", signRS256(t, key, "k1", with("exp", now.Add(-time.Hour).Unix())), false},
+		{"not yet valid", signRS256(t, key, "k1", with("nbf", now.Add(time.Hour).Unix())), false},
+		{"wrong issuer", signRS256(t, key, "k1", with("iss", "https://evil.example.com")), false},
+		{"wrong audience", signRS256(t, key, "k1", with("aud", "billing")), false},
+		{"unknown key", signRS256(t, key, "k2", valid), false},
+		{"bad signature", signRS256(t, other, "k1", valid), false},
+		{"malformed", "not-a-jwt", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := a.Authenticate(context.Background(), tt.token)
+			if !tt.ok {
+				if errors.Cause(err) != ErrUnauthenticated {
+					t.Fatalf("expected ErrUnauthenticated, got %v", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.Subject != "user-1" || p.Kind != KindUser {
+				t.Errorf("unexpected principal %+v", p)
+			}
+		})
+	}
+}
+
+func TestJWTAuthenticatorECDSACurves(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewJWTAuthenticator(staticKeySet{"k1": &key.PublicKey})
+	claims := map[string]interface{}{
+		"sub": "user-1",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+
+	sign := func(alg string, digest func([]byte) []byte) string {
+		header, _ := json.Marshal(map[string]string{"alg": alg, "kid": "k1"})
+		payload, _ := json.Marshal(claims)
+		signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest([]byte(signed)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig := make([]byte, 64)
+		rb, sb := r.Bytes(), s.Bytes()
+		copy(sig[32-len(rb):32], rb)
+		copy(sig[64-len(sb):], sb)
+
+		return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+	}
+
+	es256 := sign("ES256", func(b []byte) []byte { d := sha256.Sum256(b); return d[:] })
+	if _, err := a.Authenticate(context.Background(), es256); err != nil {
+		t.Fatalf("unexpected error for ES256 on P-256: %v", err)
+	}
+
+	es384 := sign("ES384", func(b []byte) []byte { d := sha512.Sum384(b); return d[:] })
+	if _, err := a.Authenticate(context.Background(), es384); errors.Cause(err) != ErrUnauthenticated {
+		t.Fatalf("expected ES384 on a P-256 key to be rejected, got %v", err)
+	}
+}
+
+func TestInterceptor(t *testing.T) {
+	authenticator := Chain(NewServiceTokenAuthenticator(map[string]string{"billing": "s3cret"}))
+	interceptor := NewInterceptor(authenticator, log.NewNopLogger(), DefaultPublicMethods...).Unary()
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		p, _ := FromContext(ctx)
+		return p, nil
+	}
+
+	call := func(method, authorization string) (interface{}, error) {
+		ctx := context.Background()
+		if authorization != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", authorization))
+		}
+		return interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+	}
+
+	resp, err := call("/customers.Customers/GetUser", "Bearer s3cret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p := resp.(Principal); p.Subject != "billing" || p.Kind != KindService {
+		t.Errorf("unexpected principal %+v", p)
+	}
+
+	for _, authorization := range []string{"", "Bearer wrong", "s3cret"} {
+		if _, err := call("/customers.Customers/GetUser", authorization); status.Code(err) != codes.Unauthenticated {
+			t.Errorf("authorization %q: expected Unauthenticated, got %v", authorization, err)
+		}
+	}
+
+	if _, err := call("/grpc.health.v1.Health/Check", ""); err != nil {
+		t.Errorf("health checks should not require credentials: %v", err)
+	}
+}
+
+func encodeRSAKey(kid string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func TestParseKeySet(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	okp := map[string]string{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}
+	data, _ := json.Marshal(map[string]interface{}{"keys": []interface{}{okp, encodeRSAKey("k1", &key.PublicKey)}})
+	keys, err := parseKeySet(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := keys["k1"]; !ok || len(keys) != 1 {
+		t.Errorf("expected only the RSA key, got %v", keys)
+	}
+
+	data, _ = json.Marshal(map[string]interface{}{"keys": []interface{}{okp}})
+	if _, err := parseKeySet(data); err == nil {
+		t.Error("expected a set without usable keys to be rejected")
+	}
+}
+
+func TestRemoteKeySetRefreshesWithoutBlocking(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fetches int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&fetches, 1) > 1 {
+			<-release
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []interface{}{encodeRSAKey("k1", &key.PublicKey)}})
+	}))
+	defer server.Close()
+
+	ks := &remoteKeySet{url: server.URL, client: server.Client(), ttl: time.Hour, minRefresh: time.Minute}
+	if err := ks.refresh(); err != nil {
+		t.Fatal(err)
+	}
+	ks.fetchedAt = time.Now().Add(-2 * time.Minute)
+
+	// lookups of unknown keys refetch the set, sharing a single request
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ks.Key("k2")
+		}()
+	}
+
+	// wait for the refetch to reach the endpoint
+	for atomic.LoadInt32(&fetches) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := ks.Key("k1")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("cached key lookup waited on the refetch")
+	}
+
+	close(release)
+	wg.Wait()
+	if n := atomic.LoadInt32(&fetches); n != 2 {
+		t.Errorf("expected concurrent lookups to share a fetch, got %d fetches", n)
+	}
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultPublicMethods are the method prefixes that may be called without
+// credentials: health checks and server reflection.
+var DefaultPublicMethods = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.v1alpha.ServerReflection/",
+}
+
+// Interceptor authenticates incoming gRPC calls, placing the principal in the
+// request context.
+type Interceptor struct {
+	authenticator Authenticator
+	logger        log.Logger
+	public        []string
+}
+
+// NewInterceptor creates an Interceptor. Calls to methods matching one of the
+// public prefixes are let through without credentials.
+func NewInterceptor(authenticator Authenticator, logger log.Logger, public ...string) *Interceptor {
+	return &Interceptor{
+		authenticator: authenticator,
+		logger:        log.With(logger, "component", "auth"),
+		public:        public,
+	}
+}
+
+// Unary returns a grpc.UnaryServerInterceptor.
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := i.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// Stream returns a grpc.StreamServerInterceptor.
+func (i *Interceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := i.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (i *Interceptor) authenticate(ctx context.Context, method string) (context.Context, error) {
+	for _, prefix := range i.public {
+		if strings.HasPrefix(method, prefix) {
+			return ctx, nil
+		}
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return ctx, status.Error(codes.Unauthenticated, "missing credentials")
+	}
+
+	token, ok := bearerToken(values[0])
+	if !ok {
+		return ctx, status.Error(codes.Unauthenticated, "malformed authorization header")
+	}
+
+	principal, err := i.authenticator.Authenticate(ctx, token)
+	if err != nil {
+		i.logger.Log("level", "info", "message", "rejected credentials", "method", method, "error", err.Error())
+		return ctx, status.Error(codes.Unauthenticated, "invalid credentials")
+	}
+
+	return NewContext(ctx, principal), nil
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,258 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrUnknownKey is returned when a token is signed by a key missing from the key set.
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// KeySet resolves the public key used to verify a token signature.
+type KeySet interface {
+	Key(kid string) (crypto.PublicKey, error)
+}
+
+// LoadKeySet loads a JSON Web Key Set from a local file or, when source is an
+// http(s) URL, from a remote endpoint that is refreshed periodically.
+func LoadKeySet(source string) (KeySet, error) {
+	if strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://") {
+		ks := &remoteKeySet{
+			url:        source,
+			client:     &http.Client{Timeout: 10 * time.Second},
+			ttl:        time.Hour,
+			minRefresh: time.Minute,
+		}
+		if err := ks.refresh(); err != nil {
+			return nil, err
+		}
+
+		return ks, nil
+	}
+
+	data, err := ioutil.ReadFile(source)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading jwks file %s", source)
+	}
+
+	keys, err := parseKeySet(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return staticKeySet(keys), nil
+}
+
+type staticKeySet map[string]crypto.PublicKey
+
+func (ks staticKeySet) Key(kid string) (crypto.PublicKey, error) {
+	return lookupKey(ks, kid)
+}
+
+// remoteKeySet caches keys fetched from a JWKS endpoint, refetching them when
+// the cache expires or a token names a key that has not been seen yet.
+// Fetches are made without holding the lock, so lookups of cached keys never
+// wait on the endpoint, and concurrent lookups share a single fetch.
+type remoteKeySet struct {
+	url        string
+	client     *http.Client
+	ttl        time.Duration
+	minRefresh time.Duration
+
+	group singleflight.Group
+
+	mu        sync.RWMutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+func (ks *remoteKeySet) Key(kid string) (crypto.PublicKey, error) {
+	ks.mu.RLock()
+	key, err := lookupKey(ks.keys, kid)
+	fetchedAt := ks.fetchedAt
+	ks.mu.RUnlock()
+
+	stale := time.Since(fetchedAt) > ks.ttl
+	if err == nil && !stale {
+		return key, nil
+	}
+
+	if time.Since(fetchedAt) < ks.minRefresh {
+		return key, err
+	}
+
+	if _, refreshErr, _ := ks.group.Do("refresh", func() (interface{}, error) {
+		return nil, ks.refresh()
+	}); refreshErr != nil {
+		// keep serving the cached keys while the endpoint is unavailable
+		return key, err
+	}
+
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	return lookupKey(ks.keys, kid)
+}
+
+func (ks *remoteKeySet) refresh() error {
+	ks.mu.Lock()
+	ks.fetchedAt = time.Now()
+	ks.mu.Unlock()
+
+	keys, err := ks.fetch()
+	if err != nil {
+		return err
+	}
+
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.mu.Unlock()
+
+	return nil
+}
+
+func (ks *remoteKeySet) fetch() (map[string]crypto.PublicKey, error) {
+	resp, err := ks.client.Get(ks.url)
+	if err != nil {
+		return nil, errors.Wrapf(err, "fetching jwks from %s", ks.url)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("fetching jwks from %s: unexpected status %d", ks.url, resp.StatusCode)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading jwks from %s", ks.url)
+	}
+
+	return parseKeySet(data)
+}
+
+func lookupKey(keys map[string]crypto.PublicKey, kid string) (crypto.PublicKey, error) {
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+
+	// a set containing a single key may be used by tokens that omit the kid
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key, nil
+		}
+	}
+
+	return nil, ErrUnknownKey
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func parseKeySet(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, errors.Wrap(err, "decoding jwks")
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	var skipped error
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		// sets may hold keys of types we cannot verify, such as OKP, for
+		// other consumers; skip them rather than rejecting the whole set
+		key, err := jwk.publicKey()
+		if err != nil {
+			skipped = errors.Wrapf(err, "decoding jwk %q", jwk.Kid)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		if skipped != nil {
+			return nil, errors.Wrap(skipped, "jwks contains no usable signing keys")
+		}
+		return nil, errors.New("jwks contains no signing keys")
+	}
+
+	return keys, nil
+}
+
+func (jwk jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Errorf("unsupported curve %q", jwk.Crv)
+		}
+
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on curve")
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+
+	return nil, errors.Errorf("unsupported key type %q", jwk.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding key parameter")
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
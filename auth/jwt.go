@@ -0,0 +1,243 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// JWTOption configures a JWT authenticator.
+type JWTOption func(*jwtAuthenticator)
+
+// WithIssuer requires tokens to carry the given "iss" claim.
+func WithIssuer(issuer string) JWTOption {
+	return func(a *jwtAuthenticator) {
+		a.issuer = issuer
+	}
+}
+
+// WithAudience requires tokens to list the given value in their "aud" claim.
+func WithAudience(audience string) JWTOption {
+	return func(a *jwtAuthenticator) {
+		a.audience = audience
+	}
+}
+
+// WithLeeway allows for clock skew when validating "exp" and "nbf".
+func WithLeeway(leeway time.Duration) JWTOption {
+	return func(a *jwtAuthenticator) {
+		a.leeway = leeway
+	}
+}
+
+// NewJWTAuthenticator verifies signed JWTs against the keys in the key set.
+// Only asymmetric RS* and ES* algorithms are accepted.
+func NewJWTAuthenticator(keys KeySet, opts ...JWTOption) Authenticator {
+	a := &jwtAuthenticator{
+		keys:   keys,
+		leeway: time.Minute,
+		now:    time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+type jwtAuthenticator struct {
+	keys     KeySet
+	issuer   string
+	audience string
+	leeway   time.Duration
+	now      func() time.Time
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+
+	Roles    []string          `json:"roles"`
+	Accounts map[string]string `json:"accounts"`
+}
+
+// audience accepts both the single string and array forms of the "aud" claim.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var multi []string
+	if err := json.Unmarshal(data, &multi); err != nil {
+		return err
+	}
+	*a = multi
+
+	return nil
+}
+
+func (a audience) contains(value string) bool {
+	for _, v := range a {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a *jwtAuthenticator) Authenticate(ctx context.Context, token string) (Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Principal{}, errors.Wrap(ErrUnauthenticated, "malformed jwt")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Principal{}, errors.Wrap(ErrUnauthenticated, "malformed jwt header")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Principal{}, errors.Wrap(ErrUnauthenticated, "malformed jwt signature")
+	}
+
+	key, err := a.keys.Key(header.Kid)
+	if err != nil {
+		return Principal{}, errors.Wrap(ErrUnauthenticated, err.Error())
+	}
+
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return Principal{}, errors.Wrap(ErrUnauthenticated, err.Error())
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Principal{}, errors.Wrap(ErrUnauthenticated, "malformed jwt claims")
+	}
+
+	if err := a.validate(claims); err != nil {
+		return Principal{}, errors.Wrap(ErrUnauthenticated, err.Error())
+	}
+
+	return Principal{
+		Subject:  claims.Subject,
+		Kind:     KindUser,
+		Issuer:   claims.Issuer,
+		Roles:    claims.Roles,
+		Accounts: claims.Accounts,
+	}, nil
+}
+
+func (a *jwtAuthenticator) validate(claims jwtClaims) error {
+	now := a.now()
+
+	if claims.Subject == "" {
+		return errors.New("token has no subject")
+	}
+
+	if claims.ExpiresAt == 0 || now.After(time.Unix(claims.ExpiresAt, 0).Add(a.leeway)) {
+		return errors.New("token is expired")
+	}
+
+	if claims.NotBefore != 0 && now.Add(a.leeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return errors.New("token is not yet valid")
+	}
+
+	if a.issuer != "" && claims.Issuer != a.issuer {
+		return errors.Errorf("unexpected issuer %q", claims.Issuer)
+	}
+
+	if a.audience != "" && !claims.Audience.contains(a.audience) {
+		return errors.New("token audience does not match")
+	}
+
+	return nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// ecdsaCurves pins each ES* algorithm to the curve it is defined for, so a
+// token cannot pick a weaker hash than the key was issued with.
+var ecdsaCurves = map[string]string{
+	"ES256": "P-256",
+	"ES384": "P-384",
+	"ES512": "P-521",
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signed string, signature []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		return errors.Errorf("unsupported signing algorithm %q", alg)
+	}
+
+	h := hash.New()
+	h.Write([]byte(signed))
+	digest := h.Sum(nil)
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if alg[0] != 'R' {
+			return errors.Errorf("algorithm %s does not match rsa key", alg)
+		}
+
+		return rsa.VerifyPKCS1v15(k, hash, digest, signature)
+	case *ecdsa.PublicKey:
+		if alg[0] != 'E' {
+			return errors.Errorf("algorithm %s does not match ecdsa key", alg)
+		}
+		if curve := k.Curve.Params().Name; ecdsaCurves[alg] != curve {
+			return errors.Errorf("algorithm %s does not match ecdsa curve %s", alg, curve)
+		}
+
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("invalid ecdsa signature length")
+		}
+
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return errors.New("invalid ecdsa signature")
+		}
+
+		return nil
+	}
+
+	return errors.New("unsupported key type")
+}
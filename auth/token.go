@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// NewServiceTokenAuthenticator authenticates backends presenting one of a set
+// of static tokens, keyed by the name of the calling service. Only digests of
+// the tokens are retained.
+func NewServiceTokenAuthenticator(tokens map[string]string) Authenticator {
+	a := serviceTokenAuthenticator{}
+	for name, token := range tokens {
+		a[sha256.Sum256([]byte(token))] = name
+	}
+
+	return a
+}
+
+// LoadServiceTokens reads service tokens from a file containing one
+// "<service-name> <token>" pair per line. Blank lines and lines starting with
+// "#" are ignored.
+func LoadServiceTokens(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "opening service tokens file %s", path)
+	}
+	defer f.Close()
+
+	tokens := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, errors.Errorf("%s:%d: expected \"<service-name> <token>\"", path, line)
+		}
+		tokens[fields[0]] = fields[1]
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "reading service tokens file %s", path)
+	}
+
+	return tokens, nil
+}
+
+type serviceTokenAuthenticator map[[sha256.Size]byte]string
+
+func (a serviceTokenAuthenticator) Authenticate(ctx context.Context, token string) (Principal, error) {
+	digest := sha256.Sum256([]byte(token))
+
+	var name string
+	for known, service := range a {
+		if subtle.ConstantTimeCompare(known[:], digest[:]) == 1 {
+			name = service
+		}
+	}
+
+	if name == "" {
+		return Principal{}, errors.Wrap(ErrUnauthenticated, "unknown service token")
+	}
+
+	return Principal{Subject: name, Kind: KindService, Roles: []string{RoleService}}, nil
+}
@@ -0,0 +1,51 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+func (s *authorizingService) CreateAPIKey(ctx context.Context, req service.CreateAPIKeyRequest) (service.IssuedAPIKey, error) {
+	principal, err := s.authorize(ctx, "CreateAPIKey", req.AccountID)
+	if err != nil {
+		return service.IssuedAPIKey{}, err
+	}
+
+	req.CreatedBy = principal.Subject
+	return s.next.CreateAPIKey(ctx, req)
+}
+
+func (s *authorizingService) ListAPIKeys(ctx context.Context, req service.ListAPIKeysRequest) ([]service.APIKey, error) {
+	if _, err := s.authorize(ctx, "ListAPIKeys", req.AccountID); err != nil {
+		return nil, err
+	}
+
+	return s.next.ListAPIKeys(ctx, req)
+}
+
+func (s *authorizingService) RotateAPIKey(ctx context.Context, req service.RotateAPIKeyRequest) (service.IssuedAPIKey, error) {
+	principal, err := s.authorize(ctx, "RotateAPIKey", req.AccountID)
+	if err != nil {
+		return service.IssuedAPIKey{}, err
+	}
+
+	req.RotatedBy = principal.Subject
+	return s.next.RotateAPIKey(ctx, req)
+}
+
+func (s *authorizingService) RevokeAPIKey(ctx context.Context, req service.RevokeAPIKeyRequest) (service.APIKey, error) {
+	if _, err := s.authorize(ctx, "RevokeAPIKey", req.AccountID); err != nil {
+		return service.APIKey{}, err
+	}
+
+	return s.next.RevokeAPIKey(ctx, req)
+}
+
+func (s *authorizingService) VerifyAPIKey(ctx context.Context, req service.VerifyAPIKeyRequest) (service.APIKeyVerification, error) {
+	if _, err := s.authorize(ctx, "VerifyAPIKey", ""); err != nil {
+		return service.APIKeyVerification{}, err
+	}
+
+	return s.next.VerifyAPIKey(ctx, req)
+}
@@ -0,0 +1,47 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+func (s *authorizingService) DefineCustomField(ctx context.Context, req service.DefineCustomFieldRequest) (service.CustomField, error) {
+	if _, err := s.authorize(ctx, "DefineCustomField", req.AccountID); err != nil {
+		return service.CustomField{}, err
+	}
+
+	return s.next.DefineCustomField(ctx, req)
+}
+
+func (s *authorizingService) ListCustomFields(ctx context.Context, req service.ListCustomFieldsRequest) ([]service.CustomField, error) {
+	if _, err := s.authorize(ctx, "ListCustomFields", req.AccountID); err != nil {
+		return nil, err
+	}
+
+	return s.next.ListCustomFields(ctx, req)
+}
+
+func (s *authorizingService) UpdateCustomField(ctx context.Context, req service.UpdateCustomFieldRequest) (service.CustomField, error) {
+	if _, err := s.authorize(ctx, "UpdateCustomField", req.AccountID); err != nil {
+		return service.CustomField{}, err
+	}
+
+	return s.next.UpdateCustomField(ctx, req)
+}
+
+func (s *authorizingService) DeleteCustomField(ctx context.Context, req service.DeleteCustomFieldRequest) error {
+	if _, err := s.authorize(ctx, "DeleteCustomField", req.AccountID); err != nil {
+		return err
+	}
+
+	return s.next.DeleteCustomField(ctx, req)
+}
+
+func (s *authorizingService) SetCustomFieldValues(ctx context.Context, req service.SetCustomFieldValuesRequest) (service.Membership, error) {
+	if _, err := s.authorize(ctx, "SetCustomFieldValues", req.AccountID); err != nil {
+		return service.Membership{}, err
+	}
+
+	return s.next.SetCustomFieldValues(ctx, req)
+}
@@ -0,0 +1,25 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+func (s *authorizingService) ListDuplicateAccounts(ctx context.Context, req service.ListDuplicateAccountsRequest) ([]service.DuplicateCandidate, error) {
+	if _, err := s.authorize(ctx, "ListDuplicateAccounts", req.AccountID); err != nil {
+		return nil, err
+	}
+
+	return s.next.ListDuplicateAccounts(ctx, req)
+}
+
+func (s *authorizingService) MergeAccounts(ctx context.Context, req service.MergeAccountsRequest) (service.AccountMerge, error) {
+	principal, err := s.authorize(ctx, "MergeAccounts", "")
+	if err != nil {
+		return service.AccountMerge{}, err
+	}
+
+	req.MergedBy = principal.Subject
+	return s.next.MergeAccounts(ctx, req)
+}
@@ -0,0 +1,106 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/symptomatichq/customers/auth"
+	"github.com/symptomatichq/customers/service"
+)
+
+func (s *authorizingService) CreateFeatureFlag(ctx context.Context, req service.CreateFeatureFlagRequest) (service.FeatureFlag, error) {
+	if _, err := s.authorize(ctx, "CreateFeatureFlag", ""); err != nil {
+		return service.FeatureFlag{}, err
+	}
+
+	return s.next.CreateFeatureFlag(ctx, req)
+}
+
+func (s *authorizingService) ListFeatureFlags(ctx context.Context, req service.ListFeatureFlagsRequest) ([]service.FeatureFlag, error) {
+	if _, err := s.authorize(ctx, "ListFeatureFlags", ""); err != nil {
+		return nil, err
+	}
+
+	return s.next.ListFeatureFlags(ctx, req)
+}
+
+func (s *authorizingService) UpdateFeatureFlag(ctx context.Context, req service.UpdateFeatureFlagRequest) (service.FeatureFlag, error) {
+	if _, err := s.authorize(ctx, "UpdateFeatureFlag", ""); err != nil {
+		return service.FeatureFlag{}, err
+	}
+
+	return s.next.UpdateFeatureFlag(ctx, req)
+}
+
+func (s *authorizingService) DeleteFeatureFlag(ctx context.Context, req service.DeleteFeatureFlagRequest) error {
+	if _, err := s.authorize(ctx, "DeleteFeatureFlag", ""); err != nil {
+		return err
+	}
+
+	return s.next.DeleteFeatureFlag(ctx, req)
+}
+
+func (s *authorizingService) SetFlagOverride(ctx context.Context, req service.SetFlagOverrideRequest) (service.FlagOverride, error) {
+	principal, err := s.authorize(ctx, "SetFlagOverride", req.AccountID)
+	if err != nil {
+		return service.FlagOverride{}, err
+	}
+
+	req.CreatedBy = principal.Subject
+	return s.next.SetFlagOverride(ctx, req)
+}
+
+func (s *authorizingService) RemoveFlagOverride(ctx context.Context, req service.RemoveFlagOverrideRequest) error {
+	if _, err := s.authorize(ctx, "RemoveFlagOverride", req.AccountID); err != nil {
+		return err
+	}
+
+	return s.next.RemoveFlagOverride(ctx, req)
+}
+
+func (s *authorizingService) ListFlagOverrides(ctx context.Context, req service.ListFlagOverridesRequest) ([]service.FlagOverride, error) {
+	if _, err := s.authorize(ctx, "ListFlagOverrides", req.AccountID); err != nil {
+		return nil, err
+	}
+
+	return s.next.ListFlagOverrides(ctx, req)
+}
+
+func (s *authorizingService) EvaluateFlags(ctx context.Context, req service.EvaluateFlagsRequest) ([]service.FlagEvaluation, error) {
+	userID, err := s.flagSubject(ctx, "EvaluateFlags", req.AccountID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	req.UserID = userID
+	return s.next.EvaluateFlags(ctx, req)
+}
+
+func (s *authorizingService) WatchFlags(ctx context.Context, req service.WatchFlagsRequest) error {
+	userID, err := s.flagSubject(ctx, "WatchFlags", req.AccountID, req.UserID)
+	if err != nil {
+		return err
+	}
+
+	req.UserID = userID
+	return s.next.WatchFlags(ctx, req)
+}
+
+// flagSubject returns the user flags are evaluated for. Members evaluate
+// flags as themselves unless they name another user, which only callers with
+// a global role may do.
+func (s *authorizingService) flagSubject(ctx context.Context, rpc, accountID, userID string) (string, error) {
+	principal, err := s.authorize(ctx, rpc, accountID)
+	if err != nil {
+		return "", err
+	}
+
+	if _, member := principal.Accounts[accountID]; userID == "" && member && principal.Kind == auth.KindUser {
+		return principal.Subject, nil
+	}
+
+	if userID != "" && userID != principal.Subject && !s.policy.Global(principal, rpc) {
+		return "", s.deny(principal, rpc, accountID)
+	}
+
+	return userID, nil
+}
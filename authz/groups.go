@@ -0,0 +1,71 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+func (s *authorizingService) CreateGroup(ctx context.Context, req service.CreateGroupRequest) (service.Group, error) {
+	if _, err := s.authorize(ctx, "CreateGroup", req.AccountID); err != nil {
+		return service.Group{}, err
+	}
+
+	return s.next.CreateGroup(ctx, req)
+}
+
+func (s *authorizingService) GetGroup(ctx context.Context, req service.GetGroupRequest) (service.Group, error) {
+	if _, err := s.authorize(ctx, "GetGroup", req.AccountID); err != nil {
+		return service.Group{}, err
+	}
+
+	return s.next.GetGroup(ctx, req)
+}
+
+func (s *authorizingService) ListGroups(ctx context.Context, req service.ListGroupsRequest) ([]service.Group, error) {
+	if _, err := s.authorize(ctx, "ListGroups", req.AccountID); err != nil {
+		return nil, err
+	}
+
+	return s.next.ListGroups(ctx, req)
+}
+
+func (s *authorizingService) UpdateGroup(ctx context.Context, req service.UpdateGroupRequest) (service.Group, error) {
+	if _, err := s.authorize(ctx, "UpdateGroup", req.AccountID); err != nil {
+		return service.Group{}, err
+	}
+
+	return s.next.UpdateGroup(ctx, req)
+}
+
+func (s *authorizingService) DeleteGroup(ctx context.Context, req service.DeleteGroupRequest) error {
+	if _, err := s.authorize(ctx, "DeleteGroup", req.AccountID); err != nil {
+		return err
+	}
+
+	return s.next.DeleteGroup(ctx, req)
+}
+
+func (s *authorizingService) AddGroupMember(ctx context.Context, req service.AddGroupMemberRequest) (service.GroupMember, error) {
+	if _, err := s.authorize(ctx, "AddGroupMember", req.AccountID); err != nil {
+		return service.GroupMember{}, err
+	}
+
+	return s.next.AddGroupMember(ctx, req)
+}
+
+func (s *authorizingService) RemoveGroupMember(ctx context.Context, req service.RemoveGroupMemberRequest) error {
+	if _, err := s.authorize(ctx, "RemoveGroupMember", req.AccountID); err != nil {
+		return err
+	}
+
+	return s.next.RemoveGroupMember(ctx, req)
+}
+
+func (s *authorizingService) ListGroupMembers(ctx context.Context, req service.ListGroupMembersRequest) ([]service.GroupMember, error) {
+	if _, err := s.authorize(ctx, "ListGroupMembers", req.AccountID); err != nil {
+		return nil, err
+	}
+
+	return s.next.ListGroupMembers(ctx, req)
+}
@@ -0,0 +1,39 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+// SetAccountParent requires the caller to own both the account and its new
+// parent, so accounts cannot be attached to hierarchies they do not control.
+func (s *authorizingService) SetAccountParent(ctx context.Context, req service.SetAccountParentRequest) (service.Account, error) {
+	if _, err := s.authorize(ctx, "SetAccountParent", req.AccountID); err != nil {
+		return service.Account{}, err
+	}
+
+	if req.ParentID != "" {
+		if _, err := s.authorize(ctx, "SetAccountParent", req.ParentID); err != nil {
+			return service.Account{}, err
+		}
+	}
+
+	return s.next.SetAccountParent(ctx, req)
+}
+
+func (s *authorizingService) ListChildAccounts(ctx context.Context, req service.ListChildAccountsRequest) ([]service.Account, error) {
+	if _, err := s.authorize(ctx, "ListChildAccounts", req.AccountID); err != nil {
+		return nil, err
+	}
+
+	return s.next.ListChildAccounts(ctx, req)
+}
+
+func (s *authorizingService) ListAncestorAccounts(ctx context.Context, req service.ListAncestorAccountsRequest) ([]service.Account, error) {
+	if _, err := s.authorize(ctx, "ListAncestorAccounts", req.AccountID); err != nil {
+		return nil, err
+	}
+
+	return s.next.ListAncestorAccounts(ctx, req)
+}
@@ -0,0 +1,47 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+func (s *authorizingService) InviteUser(ctx context.Context, req service.InviteUserRequest) (service.IssuedInvitation, error) {
+	principal, err := s.authorize(ctx, "InviteUser", req.AccountID)
+	if err != nil {
+		return service.IssuedInvitation{}, err
+	}
+
+	if req.Role == service.RoleOwner {
+		if _, err := s.authorize(ctx, "ManageOwners", req.AccountID); err != nil {
+			return service.IssuedInvitation{}, err
+		}
+	}
+
+	req.InvitedBy = principal.Subject
+	return s.next.InviteUser(ctx, req)
+}
+
+func (s *authorizingService) ListInvitations(ctx context.Context, req service.ListInvitationsRequest) ([]service.Invitation, error) {
+	if _, err := s.authorize(ctx, "ListInvitations", req.AccountID); err != nil {
+		return nil, err
+	}
+
+	return s.next.ListInvitations(ctx, req)
+}
+
+func (s *authorizingService) RevokeInvitation(ctx context.Context, req service.RevokeInvitationRequest) (service.Invitation, error) {
+	if _, err := s.authorize(ctx, "RevokeInvitation", req.AccountID); err != nil {
+		return service.Invitation{}, err
+	}
+
+	return s.next.RevokeInvitation(ctx, req)
+}
+
+func (s *authorizingService) AcceptInvitation(ctx context.Context, req service.AcceptInvitationRequest) (service.Membership, error) {
+	if _, err := s.authorize(ctx, "AcceptInvitation", ""); err != nil {
+		return service.Membership{}, err
+	}
+
+	return s.next.AcceptInvitation(ctx, req)
+}
@@ -0,0 +1,167 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/symptomatichq/customers/auth"
+	"github.com/symptomatichq/customers/service"
+)
+
+func (s *authorizingService) GrantRole(ctx context.Context, req service.GrantRoleRequest) (service.Membership, error) {
+	if _, err := s.authorize(ctx, "GrantRole", req.AccountID); err != nil {
+		return service.Membership{}, err
+	}
+
+	if req.Role == service.RoleOwner {
+		if _, err := s.authorize(ctx, "ManageOwners", req.AccountID); err != nil {
+			return service.Membership{}, err
+		}
+	}
+
+	return s.next.GrantRole(ctx, req)
+}
+
+func (s *authorizingService) ChangeRole(ctx context.Context, req service.ChangeRoleRequest) (service.Membership, error) {
+	if _, err := s.authorize(ctx, "ChangeRole", req.AccountID); err != nil {
+		return service.Membership{}, err
+	}
+
+	owner, err := s.isOwner(ctx, req.AccountID, req.UserID)
+	if err != nil {
+		return service.Membership{}, err
+	}
+
+	if owner || req.Role == service.RoleOwner {
+		if _, err := s.authorize(ctx, "ManageOwners", req.AccountID); err != nil {
+			return service.Membership{}, err
+		}
+	}
+
+	return s.next.ChangeRole(ctx, req)
+}
+
+func (s *authorizingService) RevokeRole(ctx context.Context, req service.RevokeRoleRequest) error {
+	principal, _ := auth.FromContext(ctx)
+	if !s.policy.AllowedSelf(principal, "RevokeRole", req.UserID) {
+		if _, err := s.authorize(ctx, "RevokeRole", req.AccountID); err != nil {
+			return err
+		}
+
+		owner, err := s.isOwner(ctx, req.AccountID, req.UserID)
+		if err != nil {
+			return err
+		}
+
+		if owner {
+			if _, err := s.authorize(ctx, "ManageOwners", req.AccountID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.next.RevokeRole(ctx, req)
+}
+
+func (s *authorizingService) FetchMemberships(ctx context.Context, req service.FetchMembershipsRequest) ([]service.Membership, error) {
+	principal, _ := auth.FromContext(ctx)
+	if req.AccountID == "" && s.policy.AllowedSelf(principal, "FetchMemberships", req.UserID) {
+		return s.next.FetchMemberships(ctx, req)
+	}
+
+	if _, err := s.authorize(ctx, "FetchMemberships", req.AccountID); err != nil {
+		return nil, err
+	}
+
+	return s.next.FetchMemberships(ctx, req)
+}
+
+func (s *authorizingService) ChangeMembershipStatus(ctx context.Context, req service.ChangeMembershipStatusRequest) (service.Membership, error) {
+	if _, err := s.authorize(ctx, "ChangeMembershipStatus", req.AccountID); err != nil {
+		return service.Membership{}, err
+	}
+
+	return s.next.ChangeMembershipStatus(ctx, req)
+}
+
+func (s *authorizingService) FetchUserAccounts(ctx context.Context, req service.FetchUserAccountsRequest) ([]service.UserAccount, error) {
+	if err := s.authorizeUser(ctx, "FetchUserAccounts", req.UserID); err != nil {
+		return nil, err
+	}
+
+	accounts, err := s.next.FetchUserAccounts(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	principal, _ := auth.FromContext(ctx)
+	if s.policy.Global(principal, "FetchUserAccounts") || s.policy.AllowedSelf(principal, "FetchUserAccounts", req.UserID) {
+		return accounts, nil
+	}
+
+	// other callers only see the accounts they share with the user
+	visible := []service.UserAccount{}
+	for _, account := range accounts {
+		if s.policy.Allowed(principal, "FetchUserAccounts", account.Account.ID) {
+			visible = append(visible, account)
+		}
+	}
+
+	if len(visible) == 0 {
+		return nil, s.deny(principal, "FetchUserAccounts", "")
+	}
+
+	return visible, nil
+}
+
+// isOwner reports whether the user currently owns the account.
+func (s *authorizingService) isOwner(ctx context.Context, accountID, userID string) (bool, error) {
+	memberships, err := s.next.FetchMemberships(ctx, service.FetchMembershipsRequest{
+		AccountID: accountID,
+		UserID:    userID,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	for _, m := range memberships {
+		if m.Role == service.RoleOwner {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// TransferUser requires the caller to manage memberships of both accounts, and
+// to manage owners of each account the transfer removes an owner from or
+// makes the user an owner of.
+func (s *authorizingService) TransferUser(ctx context.Context, req service.TransferUserRequest) (service.UserTransfer, error) {
+	principal, err := s.authorize(ctx, "TransferUser", req.FromAccountID)
+	if err != nil {
+		return service.UserTransfer{}, err
+	}
+
+	if _, err := s.authorize(ctx, "TransferUser", req.ToAccountID); err != nil {
+		return service.UserTransfer{}, err
+	}
+
+	owner, err := s.isOwner(ctx, req.FromAccountID, req.UserID)
+	if err != nil {
+		return service.UserTransfer{}, err
+	}
+
+	if owner {
+		if _, err := s.authorize(ctx, "ManageOwners", req.FromAccountID); err != nil {
+			return service.UserTransfer{}, err
+		}
+	}
+
+	if req.Role == service.RoleOwner || (req.Role == "" && owner) {
+		if _, err := s.authorize(ctx, "ManageOwners", req.ToAccountID); err != nil {
+			return service.UserTransfer{}, err
+		}
+	}
+
+	req.TransferredBy = principal.Subject
+	return s.next.TransferUser(ctx, req)
+}
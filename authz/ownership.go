@@ -0,0 +1,58 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+// InitiateOwnershipTransfer lets owners hand over their own ownership, while
+// callers with a global role and admins of the account may transfer any
+// owner's as an override.
+func (s *authorizingService) InitiateOwnershipTransfer(ctx context.Context, req service.InitiateOwnershipTransferRequest) (service.OwnershipTransfer, error) {
+	principal, err := s.authorize(ctx, "InitiateOwnershipTransfer", req.AccountID)
+	if err != nil {
+		return service.OwnershipTransfer{}, err
+	}
+
+	if req.FromUserID == "" {
+		req.FromUserID = principal.Subject
+	}
+
+	override := s.policy.Global(principal, "InitiateOwnershipTransfer") || principal.Accounts[req.AccountID] == RoleAdmin
+	if req.FromUserID != principal.Subject && !override {
+		return service.OwnershipTransfer{}, s.deny(principal, "InitiateOwnershipTransfer", req.AccountID)
+	}
+
+	req.InitiatedBy = principal.Subject
+	req.Override = req.FromUserID != principal.Subject
+	return s.next.InitiateOwnershipTransfer(ctx, req)
+}
+
+func (s *authorizingService) AcceptOwnershipTransfer(ctx context.Context, req service.AcceptOwnershipTransferRequest) (service.OwnershipTransfer, error) {
+	principal, err := s.authorize(ctx, "AcceptOwnershipTransfer", "")
+	if err != nil {
+		return service.OwnershipTransfer{}, err
+	}
+
+	req.UserID = principal.Subject
+	return s.next.AcceptOwnershipTransfer(ctx, req)
+}
+
+func (s *authorizingService) CancelOwnershipTransfer(ctx context.Context, req service.CancelOwnershipTransferRequest) (service.OwnershipTransfer, error) {
+	principal, err := s.authorize(ctx, "CancelOwnershipTransfer", req.AccountID)
+	if err != nil {
+		return service.OwnershipTransfer{}, err
+	}
+
+	req.CancelledBy = principal.Subject
+	return s.next.CancelOwnershipTransfer(ctx, req)
+}
+
+func (s *authorizingService) ListOwnershipTransfers(ctx context.Context, req service.ListOwnershipTransfersRequest) ([]service.OwnershipTransfer, error) {
+	if _, err := s.authorize(ctx, "ListOwnershipTransfers", req.AccountID); err != nil {
+		return nil, err
+	}
+
+	return s.next.ListOwnershipTransfers(ctx, req)
+}
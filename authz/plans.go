@@ -0,0 +1,83 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+func (s *authorizingService) CreatePlan(ctx context.Context, req service.CreatePlanRequest) (service.Plan, error) {
+	if _, err := s.authorize(ctx, "CreatePlan", ""); err != nil {
+		return service.Plan{}, err
+	}
+
+	return s.next.CreatePlan(ctx, req)
+}
+
+func (s *authorizingService) ListPlans(ctx context.Context, req service.ListPlansRequest) ([]service.Plan, error) {
+	if _, err := s.authorize(ctx, "ListPlans", ""); err != nil {
+		return nil, err
+	}
+
+	return s.next.ListPlans(ctx, req)
+}
+
+func (s *authorizingService) UpdatePlan(ctx context.Context, req service.UpdatePlanRequest) (service.Plan, error) {
+	if _, err := s.authorize(ctx, "UpdatePlan", ""); err != nil {
+		return service.Plan{}, err
+	}
+
+	return s.next.UpdatePlan(ctx, req)
+}
+
+func (s *authorizingService) AssignPlan(ctx context.Context, req service.AssignPlanRequest) (service.AccountPlan, error) {
+	principal, err := s.authorize(ctx, "AssignPlan", req.AccountID)
+	if err != nil {
+		return service.AccountPlan{}, err
+	}
+
+	req.AssignedBy = principal.Subject
+	return s.next.AssignPlan(ctx, req)
+}
+
+func (s *authorizingService) ListAccountPlans(ctx context.Context, req service.ListAccountPlansRequest) ([]service.AccountPlan, error) {
+	if _, err := s.authorize(ctx, "ListAccountPlans", req.AccountID); err != nil {
+		return nil, err
+	}
+
+	return s.next.ListAccountPlans(ctx, req)
+}
+
+func (s *authorizingService) SetEntitlementOverride(ctx context.Context, req service.SetEntitlementOverrideRequest) (service.EntitlementOverride, error) {
+	principal, err := s.authorize(ctx, "SetEntitlementOverride", req.AccountID)
+	if err != nil {
+		return service.EntitlementOverride{}, err
+	}
+
+	req.CreatedBy = principal.Subject
+	return s.next.SetEntitlementOverride(ctx, req)
+}
+
+func (s *authorizingService) RemoveEntitlementOverride(ctx context.Context, req service.RemoveEntitlementOverrideRequest) error {
+	if _, err := s.authorize(ctx, "RemoveEntitlementOverride", req.AccountID); err != nil {
+		return err
+	}
+
+	return s.next.RemoveEntitlementOverride(ctx, req)
+}
+
+func (s *authorizingService) ListAccountEntitlements(ctx context.Context, req service.ListAccountEntitlementsRequest) ([]service.EntitlementCheck, error) {
+	if _, err := s.authorize(ctx, "ListAccountEntitlements", req.AccountID); err != nil {
+		return nil, err
+	}
+
+	return s.next.ListAccountEntitlements(ctx, req)
+}
+
+func (s *authorizingService) CheckEntitlement(ctx context.Context, req service.CheckEntitlementRequest) (service.EntitlementCheck, error) {
+	if _, err := s.authorize(ctx, "CheckEntitlement", req.AccountID); err != nil {
+		return service.EntitlementCheck{}, err
+	}
+
+	return s.next.CheckEntitlement(ctx, req)
+}
@@ -0,0 +1,447 @@
+// Package authz decides which customers RPCs a principal may call and for
+// which accounts.
+package authz
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+
+	"github.com/symptomatichq/customers/auth"
+	"github.com/symptomatichq/customers/service"
+)
+
+// Account roles, as carried in a principal's account claims.
+const (
+	RoleOwner    = string(service.RoleOwner)
+	RoleAdmin    = string(service.RoleAdmin)
+	RoleMember   = string(service.RoleMember)
+	RoleBilling  = string(service.RoleBilling)
+	RoleReadOnly = string(service.RoleReadOnly)
+)
+
+// Global roles granted to staff.
+const (
+	RoleStaffAdmin = "admin"
+	RoleSupport    = "support"
+)
+
+// Rule describes who may call a single RPC.
+type Rule struct {
+	// Roles lists the global roles allowed to call the RPC for any account.
+	Roles []string `json:"roles"`
+	// AccountRoles lists the roles which, when held within the account the
+	// call targets, allow the call.
+	AccountRoles []string `json:"account_roles"`
+	// Self allows users to call the RPC on their own user record.
+	Self bool `json:"self"`
+	// Authenticated allows any authenticated principal to call the RPC, for
+	// calls that carry their own proof of access such as invitation tokens.
+	Authenticated bool `json:"authenticated"`
+}
+
+// Policy maps RPC names onto the rule governing them. RPCs missing from the
+// policy are denied.
+type Policy map[string]Rule
+
+// DefaultPolicy is used when no policy file is configured.
+var DefaultPolicy = Policy{
+	"CreateAccount": {
+		Roles: []string{RoleStaffAdmin, auth.RoleService},
+	},
+	"GetAccount": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin, RoleMember, RoleBilling, RoleReadOnly},
+	},
+	"FetchAccounts": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin, RoleMember, RoleBilling, RoleReadOnly},
+	},
+	"UpdateAccount": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"ChangeAccountStatus": {
+		Roles: []string{RoleStaffAdmin, auth.RoleService},
+	},
+	"FindAccountsByContactEmail": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin, RoleMember, RoleBilling, RoleReadOnly},
+	},
+	"SearchCustomers": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin, RoleMember, RoleBilling, RoleReadOnly},
+	},
+	"ListDuplicateAccounts": {
+		Roles: []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+	},
+	"MergeAccounts": {
+		Roles: []string{RoleStaffAdmin, auth.RoleService},
+	},
+	"SetAccountParent": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner},
+	},
+	"ListChildAccounts": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin, RoleMember, RoleBilling, RoleReadOnly},
+	},
+	"ListAncestorAccounts": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin, RoleMember, RoleBilling, RoleReadOnly},
+	},
+	"CreateAPIKey": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"ListAPIKeys": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"RotateAPIKey": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"RevokeAPIKey": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	// VerifyAPIKey is called by other services resolving keys presented to them
+	"VerifyAPIKey": {
+		Roles: []string{auth.RoleService},
+	},
+	"CreateServiceAccount": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"ListServiceAccounts": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"UpdateServiceAccount": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"DeleteServiceAccount": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"DefineCustomField": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"ListCustomFields": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin, RoleMember, RoleBilling, RoleReadOnly},
+	},
+	"UpdateCustomField": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"DeleteCustomField": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"SetCustomFieldValues": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"CreatePlan": {
+		Roles: []string{RoleStaffAdmin},
+	},
+	"ListPlans": {
+		Roles: []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+	},
+	"UpdatePlan": {
+		Roles: []string{RoleStaffAdmin},
+	},
+	// plans are assigned by staff and by the billing system
+	"AssignPlan": {
+		Roles: []string{RoleStaffAdmin, auth.RoleService},
+	},
+	"ListAccountPlans": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin, RoleBilling},
+	},
+	"SetEntitlementOverride": {
+		Roles: []string{RoleStaffAdmin},
+	},
+	"RemoveEntitlementOverride": {
+		Roles: []string{RoleStaffAdmin},
+	},
+	"ListAccountEntitlements": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin, RoleMember, RoleBilling, RoleReadOnly},
+	},
+	"CheckEntitlement": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin, RoleMember, RoleBilling, RoleReadOnly},
+	},
+	"GetAccountUsage": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin, RoleBilling},
+	},
+	// trials are managed by sales staff and converted by the billing system
+	"ExtendTrial": {
+		Roles: []string{RoleStaffAdmin, auth.RoleService},
+	},
+	"ConvertTrial": {
+		Roles: []string{RoleStaffAdmin, auth.RoleService},
+	},
+	"ScheduleStatusChange": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"ListScheduledActions": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"CancelScheduledAction": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"CreateFeatureFlag": {
+		Roles: []string{RoleStaffAdmin, auth.RoleService},
+	},
+	"ListFeatureFlags": {
+		Roles: []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+	},
+	"UpdateFeatureFlag": {
+		Roles: []string{RoleStaffAdmin, auth.RoleService},
+	},
+	"DeleteFeatureFlag": {
+		Roles: []string{RoleStaffAdmin, auth.RoleService},
+	},
+	"SetFlagOverride": {
+		Roles: []string{RoleStaffAdmin, auth.RoleService},
+	},
+	"RemoveFlagOverride": {
+		Roles: []string{RoleStaffAdmin, auth.RoleService},
+	},
+	"ListFlagOverrides": {
+		Roles: []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+	},
+	"EvaluateFlags": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin, RoleMember, RoleBilling, RoleReadOnly},
+	},
+	"WatchFlags": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin, RoleMember, RoleBilling, RoleReadOnly},
+	},
+	"GetUserPreferences": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin, RoleMember, RoleBilling, RoleReadOnly},
+		Self:         true,
+	},
+	"UpdateUserPreferences": {
+		Roles: []string{RoleStaffAdmin, auth.RoleService},
+		Self:  true,
+	},
+	"GetAccountPreferences": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin, RoleMember, RoleBilling, RoleReadOnly},
+	},
+	"UpdateAccountPreferences": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"CreateUser": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"GetUser": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin, RoleMember, RoleBilling, RoleReadOnly},
+		Self:         true,
+	},
+	"GetUserByEmail": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin, RoleMember, RoleBilling, RoleReadOnly},
+		Self:         true,
+	},
+	"UpdateUser": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+		Self:         true,
+	},
+	"FetchUsers": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin, RoleMember, RoleBilling, RoleReadOnly},
+	},
+	"CreateGroup": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"GetGroup": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin, RoleMember, RoleBilling, RoleReadOnly},
+	},
+	"ListGroups": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin, RoleMember, RoleBilling, RoleReadOnly},
+	},
+	"UpdateGroup": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"DeleteGroup": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"AddGroupMember": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"RemoveGroupMember": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"ListGroupMembers": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin, RoleMember, RoleBilling, RoleReadOnly},
+	},
+	"GrantRole": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"ChangeRole": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"RevokeRole": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+		Self:         true,
+	},
+	"TransferUser": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"FetchMemberships": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin, RoleMember, RoleBilling, RoleReadOnly},
+		Self:         true,
+	},
+	"ChangeMembershipStatus": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"FetchUserAccounts": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+		Self:         true,
+	},
+	// staff and account admins may initiate a transfer on behalf of an owner
+	// who has left
+	"InitiateOwnershipTransfer": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"AcceptOwnershipTransfer": {
+		Authenticated: true,
+	},
+	"CancelOwnershipTransfer": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner},
+	},
+	"ListOwnershipTransfers": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"InviteUser": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"ListInvitations": {
+		Roles:        []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"RevokeInvitation": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner, RoleAdmin},
+	},
+	"AcceptInvitation": {
+		Authenticated: true,
+	},
+	"RequestEmailVerification": {
+		Roles: []string{RoleStaffAdmin, RoleSupport, auth.RoleService},
+		Self:  true,
+	},
+	"ConfirmEmailVerification": {
+		Authenticated: true,
+	},
+	// ManageOwners is checked in addition to the membership RPCs whenever
+	// the owner role is granted or taken away.
+	"ManageOwners": {
+		Roles:        []string{RoleStaffAdmin, auth.RoleService},
+		AccountRoles: []string{RoleOwner},
+	},
+}
+
+// LoadPolicy reads a policy from a JSON file of the form
+// {"GetUser": {"roles": ["admin"], "account_roles": ["owner"], "self": true}}.
+func LoadPolicy(path string) (Policy, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading policy file %s", path)
+	}
+
+	var policy Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, errors.Wrapf(err, "decoding policy file %s", path)
+	}
+
+	return policy, nil
+}
+
+// Global reports whether the principal may call the RPC for every account.
+func (p Policy) Global(principal auth.Principal, rpc string) bool {
+	rule, ok := p[rpc]
+	return ok && (rule.Authenticated || principal.HasRole(rule.Roles...))
+}
+
+// Allowed reports whether the principal may call the RPC against the given
+// account. An empty account id only matches global roles.
+func (p Policy) Allowed(principal auth.Principal, rpc, accountID string) bool {
+	if p.Global(principal, rpc) {
+		return true
+	}
+
+	if accountID == "" {
+		return false
+	}
+
+	role, ok := principal.Accounts[accountID]
+	return ok && contains(p[rpc].AccountRoles, role)
+}
+
+// AllowedSelf reports whether the principal may call the RPC on the user
+// record identified by userID because it is their own.
+func (p Policy) AllowedSelf(principal auth.Principal, rpc, userID string) bool {
+	return p[rpc].Self && principal.Kind == auth.KindUser && principal.Subject == userID
+}
+
+// Accounts returns the ids of the accounts the principal may call the RPC
+// against through their account roles.
+func (p Policy) Accounts(principal auth.Principal, rpc string) []string {
+	ids := []string{}
+	for id, role := range principal.Accounts {
+		if contains(p[rpc].AccountRoles, role) {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
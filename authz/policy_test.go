@@ -0,0 +1,57 @@
+package authz
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/symptomatichq/customers/auth"
+)
+
+func TestPolicy(t *testing.T) {
+	member := auth.Principal{
+		Subject:  "user-1",
+		Kind:     auth.KindUser,
+		Accounts: map[string]string{"acct-a": RoleMember, "acct-b": RoleBilling},
+	}
+	admin := auth.Principal{Subject: "user-2", Kind: auth.KindUser, Accounts: map[string]string{"acct-a": RoleAdmin}}
+	support := auth.Principal{Subject: "agent-1", Kind: auth.KindUser, Roles: []string{RoleSupport}}
+
+	tests := []struct {
+		name      string
+		principal auth.Principal
+		rpc       string
+		accountID string
+		want      bool
+	}{
+		{"member reads own account", member, "GetAccount", "acct-a", true},
+		{"member reads other account", member, "GetAccount", "acct-c", false},
+		{"member cannot create users", member, "CreateUser", "acct-a", false},
+		{"member cannot create accounts", member, "CreateAccount", "", false},
+		{"support reads any account", support, "GetAccount", "acct-c", true},
+		{"support cannot create users", support, "CreateUser", "acct-c", false},
+		{"unknown rpc is denied", support, "DropAccounts", "acct-c", false},
+		{"admin initiates ownership transfer", admin, "InitiateOwnershipTransfer", "acct-a", true},
+		{"member cannot initiate ownership transfer", member, "InitiateOwnershipTransfer", "acct-a", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultPolicy.Allowed(tt.principal, tt.rpc, tt.accountID); got != tt.want {
+				t.Errorf("Allowed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !DefaultPolicy.AllowedSelf(member, "GetUser", "user-1") {
+		t.Error("users should be able to read their own record")
+	}
+	if DefaultPolicy.AllowedSelf(member, "GetUser", "user-2") {
+		t.Error("users should not be able to read other records as themselves")
+	}
+
+	accounts := DefaultPolicy.Accounts(member, "FetchUsers")
+	sort.Strings(accounts)
+	if len(accounts) != 2 || accounts[0] != "acct-a" || accounts[1] != "acct-b" {
+		t.Errorf("unexpected scope %v", accounts)
+	}
+}
@@ -0,0 +1,41 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+func (s *authorizingService) GetUserPreferences(ctx context.Context, req service.GetUserPreferencesRequest) (service.UserPreferences, error) {
+	if err := s.authorizeUser(ctx, "GetUserPreferences", req.UserID); err != nil {
+		return service.UserPreferences{}, err
+	}
+
+	return s.next.GetUserPreferences(ctx, req)
+}
+
+// UpdateUserPreferences lets users change their own preferences, which
+// account admins may read but not change.
+func (s *authorizingService) UpdateUserPreferences(ctx context.Context, req service.UpdateUserPreferencesRequest) (service.UserPreferences, error) {
+	if err := s.authorizeUser(ctx, "UpdateUserPreferences", req.UserID); err != nil {
+		return service.UserPreferences{}, err
+	}
+
+	return s.next.UpdateUserPreferences(ctx, req)
+}
+
+func (s *authorizingService) GetAccountPreferences(ctx context.Context, req service.GetAccountPreferencesRequest) (service.AccountPreferences, error) {
+	if _, err := s.authorize(ctx, "GetAccountPreferences", req.AccountID); err != nil {
+		return service.AccountPreferences{}, err
+	}
+
+	return s.next.GetAccountPreferences(ctx, req)
+}
+
+func (s *authorizingService) UpdateAccountPreferences(ctx context.Context, req service.UpdateAccountPreferencesRequest) (service.AccountPreferences, error) {
+	if _, err := s.authorize(ctx, "UpdateAccountPreferences", req.AccountID); err != nil {
+		return service.AccountPreferences{}, err
+	}
+
+	return s.next.UpdateAccountPreferences(ctx, req)
+}
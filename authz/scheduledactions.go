@@ -0,0 +1,43 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+// ScheduleStatusChange lets account admins schedule changes to memberships,
+// while changes to the account's own status may only be scheduled by callers
+// who could make them immediately.
+func (s *authorizingService) ScheduleStatusChange(ctx context.Context, req service.ScheduleStatusChangeRequest) (service.ScheduledAction, error) {
+	principal, err := s.authorize(ctx, "ScheduleStatusChange", req.AccountID)
+	if err != nil {
+		return service.ScheduledAction{}, err
+	}
+
+	if req.UserID == "" && !s.policy.Allowed(principal, "ChangeAccountStatus", req.AccountID) {
+		return service.ScheduledAction{}, s.deny(principal, "ChangeAccountStatus", req.AccountID)
+	}
+
+	req.ScheduledBy = principal.Subject
+	return s.next.ScheduleStatusChange(ctx, req)
+}
+
+func (s *authorizingService) ListScheduledActions(ctx context.Context, req service.ListScheduledActionsRequest) ([]service.ScheduledAction, error) {
+	if _, err := s.authorize(ctx, "ListScheduledActions", req.AccountID); err != nil {
+		return nil, err
+	}
+
+	return s.next.ListScheduledActions(ctx, req)
+}
+
+func (s *authorizingService) CancelScheduledAction(ctx context.Context, req service.CancelScheduledActionRequest) (service.ScheduledAction, error) {
+	principal, err := s.authorize(ctx, "CancelScheduledAction", req.AccountID)
+	if err != nil {
+		return service.ScheduledAction{}, err
+	}
+
+	req.CancelledBy = principal.Subject
+	req.AccountChanges = s.policy.Allowed(principal, "ChangeAccountStatus", req.AccountID)
+	return s.next.CancelScheduledAction(ctx, req)
+}
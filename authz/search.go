@@ -0,0 +1,17 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+func (s *authorizingService) SearchCustomers(ctx context.Context, req service.SearchCustomersRequest) ([]service.SearchResult, error) {
+	accountIDs, err := s.scope(ctx, "SearchCustomers", req.AccountIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	req.AccountIDs = accountIDs
+	return s.next.SearchCustomers(ctx, req)
+}
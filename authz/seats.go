@@ -0,0 +1,15 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+func (s *authorizingService) GetAccountUsage(ctx context.Context, req service.GetAccountUsageRequest) (service.AccountUsage, error) {
+	if _, err := s.authorize(ctx, "GetAccountUsage", req.AccountID); err != nil {
+		return service.AccountUsage{}, err
+	}
+
+	return s.next.GetAccountUsage(ctx, req)
+}
@@ -0,0 +1,249 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+
+	"github.com/symptomatichq/customers/auth"
+	"github.com/symptomatichq/customers/service"
+)
+
+// NewService wraps a service.Service, checking every call against the policy
+// using the principal placed in the context by the auth package.
+func NewService(next service.Service, policy Policy, logger log.Logger) service.Service {
+	return &authorizingService{
+		next:   next,
+		policy: policy,
+		logger: log.With(logger, "component", "authz"),
+	}
+}
+
+type authorizingService struct {
+	next   service.Service
+	policy Policy
+	logger log.Logger
+}
+
+// authorize fails unless the caller may invoke rpc against accountID.
+func (s *authorizingService) authorize(ctx context.Context, rpc, accountID string) (auth.Principal, error) {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return principal, s.deny(principal, rpc, accountID)
+	}
+
+	if !s.policy.Allowed(principal, rpc, accountID) {
+		return principal, s.deny(principal, rpc, accountID)
+	}
+
+	return principal, nil
+}
+
+// scope returns the accounts a list RPC must be restricted to. A nil result
+// means the caller may see every account.
+func (s *authorizingService) scope(ctx context.Context, rpc string, requested []string) ([]string, error) {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, s.deny(principal, rpc, "")
+	}
+
+	if s.policy.Global(principal, rpc) {
+		return requested, nil
+	}
+
+	visible := s.policy.Accounts(principal, rpc)
+	if len(visible) == 0 {
+		return nil, s.deny(principal, rpc, "")
+	}
+
+	if len(requested) == 0 {
+		return visible, nil
+	}
+
+	for _, id := range requested {
+		if !contains(visible, id) {
+			return nil, s.deny(principal, rpc, id)
+		}
+	}
+
+	return requested, nil
+}
+
+func (s *authorizingService) deny(principal auth.Principal, rpc, accountID string) error {
+	s.logger.Log("level", "info", "message", "permission denied", "rpc", rpc, "subject", principal.Subject, "account_id", accountID)
+	return errors.Wrapf(service.ErrPermissionDenied, "%s", rpc)
+}
+
+func (s *authorizingService) CreateAccount(ctx context.Context, req service.CreateAccountRequest) (service.Account, error) {
+	if _, err := s.authorize(ctx, "CreateAccount", ""); err != nil {
+		return service.Account{}, err
+	}
+
+	if req.ParentID != "" {
+		if _, err := s.authorize(ctx, "SetAccountParent", req.ParentID); err != nil {
+			return service.Account{}, err
+		}
+	}
+
+	return s.next.CreateAccount(ctx, req)
+}
+
+func (s *authorizingService) GetAccount(ctx context.Context, req service.GetAccountRequest) (service.Account, error) {
+	if _, err := s.authorize(ctx, "GetAccount", req.ID); err != nil {
+		return service.Account{}, err
+	}
+
+	return s.next.GetAccount(ctx, req)
+}
+
+func (s *authorizingService) FetchAccounts(ctx context.Context, req service.FetchAccountsRequest) ([]service.Account, error) {
+	accountIDs, err := s.scope(ctx, "FetchAccounts", req.AccountIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	req.AccountIDs = accountIDs
+	return s.next.FetchAccounts(ctx, req)
+}
+
+func (s *authorizingService) UpdateAccount(ctx context.Context, req service.UpdateAccountRequest) (service.Account, error) {
+	if _, err := s.authorize(ctx, "UpdateAccount", req.ID); err != nil {
+		return service.Account{}, err
+	}
+
+	return s.next.UpdateAccount(ctx, req)
+}
+
+func (s *authorizingService) ChangeAccountStatus(ctx context.Context, req service.ChangeAccountStatusRequest) (service.Account, error) {
+	if _, err := s.authorize(ctx, "ChangeAccountStatus", req.ID); err != nil {
+		return service.Account{}, err
+	}
+
+	return s.next.ChangeAccountStatus(ctx, req)
+}
+
+func (s *authorizingService) FindAccountsByContactEmail(ctx context.Context, req service.FindAccountsByContactEmailRequest) ([]service.Account, error) {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, s.deny(principal, "FindAccountsByContactEmail", "")
+	}
+
+	accounts, err := s.next.FindAccountsByContactEmail(ctx, req)
+	if err != nil || s.policy.Global(principal, "FindAccountsByContactEmail") {
+		return accounts, err
+	}
+
+	visible := []service.Account{}
+	for _, account := range accounts {
+		if s.policy.Allowed(principal, "FindAccountsByContactEmail", account.ID) {
+			visible = append(visible, account)
+		}
+	}
+
+	return visible, nil
+}
+
+func (s *authorizingService) CreateUser(ctx context.Context, req service.CreateUserRequest) (service.User, error) {
+	if _, err := s.authorize(ctx, "CreateUser", req.AccountID); err != nil {
+		return service.User{}, err
+	}
+
+	return s.next.CreateUser(ctx, req)
+}
+
+func (s *authorizingService) GetUser(ctx context.Context, req service.GetUserRequest) (service.User, error) {
+	if err := s.authorizeUser(ctx, "GetUser", req.ID); err != nil {
+		return service.User{}, err
+	}
+
+	return s.next.GetUser(ctx, req)
+}
+
+func (s *authorizingService) GetUserByEmail(ctx context.Context, req service.GetUserByEmailRequest) (service.User, error) {
+	// the user id is only known once the user is loaded
+	user, err := s.next.GetUserByEmail(ctx, req)
+	if err != nil {
+		principal, _ := auth.FromContext(ctx)
+		if !s.policy.Global(principal, "GetUserByEmail") {
+			// do not reveal to account members whether an address is registered
+			return service.User{}, s.deny(principal, "GetUserByEmail", "")
+		}
+
+		return service.User{}, err
+	}
+
+	if err := s.authorizeUser(ctx, "GetUserByEmail", user.ID); err != nil {
+		return service.User{}, err
+	}
+
+	return user, nil
+}
+
+func (s *authorizingService) UpdateUser(ctx context.Context, req service.UpdateUserRequest) (service.User, error) {
+	authorize := s.authorizeUser
+	if req.Email != nil {
+		// the email address is how the user signs in, so an admin of one
+		// account must not be able to take over a user shared with others
+		authorize = s.authorizeUserEverywhere
+	}
+
+	if err := authorize(ctx, "UpdateUser", req.ID); err != nil {
+		return service.User{}, err
+	}
+
+	return s.next.UpdateUser(ctx, req)
+}
+
+// authorizeUser fails unless the caller may invoke rpc on the user, either
+// as the user themselves or through a role in any account the user belongs to.
+func (s *authorizingService) authorizeUser(ctx context.Context, rpc, userID string) error {
+	return s.authorizeUserAccounts(ctx, rpc, userID, false)
+}
+
+// authorizeUserEverywhere is like authorizeUser, but requires the role in
+// every account the user belongs to.
+func (s *authorizingService) authorizeUserEverywhere(ctx context.Context, rpc, userID string) error {
+	return s.authorizeUserAccounts(ctx, rpc, userID, true)
+}
+
+func (s *authorizingService) authorizeUserAccounts(ctx context.Context, rpc, userID string, all bool) error {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return s.deny(principal, rpc, "")
+	}
+
+	if s.policy.Global(principal, rpc) || s.policy.AllowedSelf(principal, rpc, userID) {
+		return nil
+	}
+
+	memberships, err := s.next.FetchMemberships(ctx, service.FetchMembershipsRequest{UserID: userID})
+	if err != nil {
+		return err
+	}
+
+	allowed := 0
+	for _, m := range memberships {
+		if s.policy.Allowed(principal, rpc, m.AccountID) {
+			allowed++
+		} else if all {
+			return s.deny(principal, rpc, m.AccountID)
+		}
+	}
+
+	if allowed == 0 {
+		return s.deny(principal, rpc, "")
+	}
+
+	return nil
+}
+
+func (s *authorizingService) FetchUsers(ctx context.Context, req service.FetchUsersRequest) ([]service.User, error) {
+	accountIDs, err := s.scope(ctx, "FetchUsers", req.AccountIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	req.AccountIDs = accountIDs
+	return s.next.FetchUsers(ctx, req)
+}
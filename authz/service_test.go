@@ -0,0 +1,119 @@
+package authz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+
+	"github.com/symptomatichq/customers/auth"
+	"github.com/symptomatichq/customers/service"
+)
+
+// fakeService answers the lookups the authorizing service makes and records
+// which calls made it through. Calling any other method panics.
+type fakeService struct {
+	service.Service
+	memberships []service.Membership
+	calls       []string
+}
+
+func (f *fakeService) FetchMemberships(ctx context.Context, req service.FetchMembershipsRequest) ([]service.Membership, error) {
+	var memberships []service.Membership
+	for _, m := range f.memberships {
+		if m.UserID == req.UserID {
+			memberships = append(memberships, m)
+		}
+	}
+	return memberships, nil
+}
+
+func (f *fakeService) UpdateUser(ctx context.Context, req service.UpdateUserRequest) (service.User, error) {
+	f.calls = append(f.calls, "UpdateUser")
+	return service.User{ID: req.ID}, nil
+}
+
+func (f *fakeService) FetchUserAccounts(ctx context.Context, req service.FetchUserAccountsRequest) ([]service.UserAccount, error) {
+	f.calls = append(f.calls, "FetchUserAccounts")
+	var accounts []service.UserAccount
+	for _, m := range f.memberships {
+		if m.UserID == req.UserID {
+			accounts = append(accounts, service.UserAccount{Account: service.Account{ID: m.AccountID}, Role: m.Role})
+		}
+	}
+	return accounts, nil
+}
+
+func TestUpdateUserEmail(t *testing.T) {
+	next := &fakeService{memberships: []service.Membership{
+		{AccountID: "acct-a", UserID: "user-1", Role: service.RoleMember},
+		{AccountID: "acct-b", UserID: "user-1", Role: service.RoleMember},
+	}}
+	svc := NewService(next, DefaultPolicy, log.NewNopLogger())
+
+	self := auth.Principal{Subject: "user-1", Kind: auth.KindUser}
+	adminOfOne := auth.Principal{Subject: "user-2", Kind: auth.KindUser, Accounts: map[string]string{"acct-a": RoleAdmin}}
+	adminOfBoth := auth.Principal{Subject: "user-3", Kind: auth.KindUser, Accounts: map[string]string{"acct-a": RoleOwner, "acct-b": RoleAdmin}}
+	support := auth.Principal{Subject: "agent-1", Kind: auth.KindUser, Roles: []string{RoleSupport}}
+	staff := auth.Principal{Subject: "agent-2", Kind: auth.KindUser, Roles: []string{RoleStaffAdmin}}
+
+	name, email := "Jane", "jane@example.com"
+	tests := []struct {
+		name      string
+		principal auth.Principal
+		req       service.UpdateUserRequest
+		want      bool
+	}{
+		{"admin of one account renames", adminOfOne, service.UpdateUserRequest{ID: "user-1", Name: &name}, true},
+		{"admin of one account changes email", adminOfOne, service.UpdateUserRequest{ID: "user-1", Email: &email}, false},
+		{"admin of every account changes email", adminOfBoth, service.UpdateUserRequest{ID: "user-1", Email: &email}, true},
+		{"user changes own email", self, service.UpdateUserRequest{ID: "user-1", Email: &email}, true},
+		{"staff admin changes email", staff, service.UpdateUserRequest{ID: "user-1", Email: &email}, true},
+		{"support cannot rename", support, service.UpdateUserRequest{ID: "user-1", Name: &name}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next.calls = nil
+			_, err := svc.UpdateUser(auth.NewContext(context.Background(), tt.principal), tt.req)
+			if tt.want && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.want {
+				if errors.Cause(err) != service.ErrPermissionDenied {
+					t.Fatalf("expected ErrPermissionDenied, got %v", err)
+				}
+				if len(next.calls) != 0 {
+					t.Errorf("denied update reached the service: %v", next.calls)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchUserAccountsAuthorizesFirst(t *testing.T) {
+	next := &fakeService{memberships: []service.Membership{
+		{AccountID: "acct-a", UserID: "user-1", Role: service.RoleMember},
+		{AccountID: "acct-b", UserID: "user-1", Role: service.RoleMember},
+	}}
+	svc := NewService(next, DefaultPolicy, log.NewNopLogger())
+
+	stranger := auth.Principal{Subject: "user-2", Kind: auth.KindUser, Accounts: map[string]string{"acct-c": RoleAdmin}}
+	_, err := svc.FetchUserAccounts(auth.NewContext(context.Background(), stranger), service.FetchUserAccountsRequest{UserID: "user-1"})
+	if errors.Cause(err) != service.ErrPermissionDenied {
+		t.Fatalf("expected ErrPermissionDenied, got %v", err)
+	}
+	if len(next.calls) != 0 {
+		t.Errorf("denied call reached the service: %v", next.calls)
+	}
+
+	admin := auth.Principal{Subject: "user-3", Kind: auth.KindUser, Accounts: map[string]string{"acct-a": RoleAdmin}}
+	accounts, err := svc.FetchUserAccounts(auth.NewContext(context.Background(), admin), service.FetchUserAccountsRequest{UserID: "user-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 1 || accounts[0].Account.ID != "acct-a" {
+		t.Errorf("expected only the shared account, got %+v", accounts)
+	}
+}
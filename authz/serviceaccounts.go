@@ -0,0 +1,39 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+func (s *authorizingService) CreateServiceAccount(ctx context.Context, req service.CreateServiceAccountRequest) (service.User, error) {
+	if _, err := s.authorize(ctx, "CreateServiceAccount", req.AccountID); err != nil {
+		return service.User{}, err
+	}
+
+	return s.next.CreateServiceAccount(ctx, req)
+}
+
+func (s *authorizingService) ListServiceAccounts(ctx context.Context, req service.ListServiceAccountsRequest) ([]service.User, error) {
+	if _, err := s.authorize(ctx, "ListServiceAccounts", req.AccountID); err != nil {
+		return nil, err
+	}
+
+	return s.next.ListServiceAccounts(ctx, req)
+}
+
+func (s *authorizingService) UpdateServiceAccount(ctx context.Context, req service.UpdateServiceAccountRequest) (service.User, error) {
+	if _, err := s.authorize(ctx, "UpdateServiceAccount", req.AccountID); err != nil {
+		return service.User{}, err
+	}
+
+	return s.next.UpdateServiceAccount(ctx, req)
+}
+
+func (s *authorizingService) DeleteServiceAccount(ctx context.Context, req service.DeleteServiceAccountRequest) error {
+	if _, err := s.authorize(ctx, "DeleteServiceAccount", req.AccountID); err != nil {
+		return err
+	}
+
+	return s.next.DeleteServiceAccount(ctx, req)
+}
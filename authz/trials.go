@@ -0,0 +1,25 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+func (s *authorizingService) ExtendTrial(ctx context.Context, req service.ExtendTrialRequest) (service.Account, error) {
+	if _, err := s.authorize(ctx, "ExtendTrial", req.AccountID); err != nil {
+		return service.Account{}, err
+	}
+
+	return s.next.ExtendTrial(ctx, req)
+}
+
+func (s *authorizingService) ConvertTrial(ctx context.Context, req service.ConvertTrialRequest) (service.Account, error) {
+	principal, err := s.authorize(ctx, "ConvertTrial", req.AccountID)
+	if err != nil {
+		return service.Account{}, err
+	}
+
+	req.ConvertedBy = principal.Subject
+	return s.next.ConvertTrial(ctx, req)
+}
@@ -0,0 +1,27 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/symptomatichq/customers/auth"
+	"github.com/symptomatichq/customers/service"
+)
+
+func (s *authorizingService) RequestEmailVerification(ctx context.Context, req service.RequestEmailVerificationRequest) (service.EmailVerification, error) {
+	principal, _ := auth.FromContext(ctx)
+	if !s.policy.AllowedSelf(principal, "RequestEmailVerification", req.UserID) {
+		if _, err := s.authorize(ctx, "RequestEmailVerification", ""); err != nil {
+			return service.EmailVerification{}, err
+		}
+	}
+
+	return s.next.RequestEmailVerification(ctx, req)
+}
+
+func (s *authorizingService) ConfirmEmailVerification(ctx context.Context, req service.ConfirmEmailVerificationRequest) (service.User, error) {
+	if _, err := s.authorize(ctx, "ConfirmEmailVerification", ""); err != nil {
+		return service.User{}, err
+	}
+
+	return s.next.ConfirmEmailVerification(ctx, req)
+}
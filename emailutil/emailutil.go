@@ -0,0 +1,113 @@
+// Package emailutil canonicalizes email addresses so that equivalent
+// addresses are stored and looked up in a single form.
+package emailutil
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/idna"
+)
+
+// ErrInvalid is returned for strings that are not email addresses.
+var ErrInvalid = errors.New("invalid email address")
+
+// Normalize returns the form in which an address is stored: surrounding
+// whitespace is removed, the address is lowercased and internationalized
+// domain names are converted to their ASCII (punycode) form.
+func Normalize(address string) (string, error) {
+	address = strings.TrimSpace(address)
+
+	at := strings.LastIndex(address, "@")
+	if at <= 0 || at == len(address)-1 {
+		return "", errors.Wrapf(ErrInvalid, "%q", address)
+	}
+
+	local, domain := address[:at], address[at+1:]
+	if strings.ContainsAny(local, " \t\r\n") {
+		return "", errors.Wrapf(ErrInvalid, "%q", address)
+	}
+
+	domain, err := idna.Lookup.ToASCII(strings.TrimSuffix(domain, "."))
+	if err != nil || !strings.Contains(domain, ".") {
+		return "", errors.Wrapf(ErrInvalid, "%q: invalid domain", address)
+	}
+
+	return strings.ToLower(local) + "@" + strings.ToLower(domain), nil
+}
+
+// provider describes how a mail provider treats variations of an address.
+type provider struct {
+	// domain is the primary domain addresses are rewritten to.
+	domain string
+	// ignoreDots is set for providers that deliver "a.lice" to "alice".
+	ignoreDots bool
+}
+
+// providers maps domains onto providers known to ignore "+tag" suffixes.
+var providers = map[string]provider{
+	"gmail.com":      {domain: "gmail.com", ignoreDots: true},
+	"googlemail.com": {domain: "gmail.com", ignoreDots: true},
+	"outlook.com":    {domain: "outlook.com"},
+	"hotmail.com":    {domain: "hotmail.com"},
+	"live.com":       {domain: "live.com"},
+	"icloud.com":     {domain: "icloud.com"},
+	"me.com":         {domain: "icloud.com"},
+	"fastmail.com":   {domain: "fastmail.com"},
+	"protonmail.com": {domain: "protonmail.com"},
+}
+
+// Canonical normalizes an address and then applies provider-specific rules,
+// stripping "+tag" suffixes and, for Gmail, dots from the local part. Distinct
+// mailboxes never share a canonical form, but the canonical form is not
+// necessarily deliverable, so it is only suitable for lookups.
+func Canonical(address string) (string, error) {
+	normalized, err := Normalize(address)
+	if err != nil {
+		return "", err
+	}
+
+	at := strings.LastIndex(normalized, "@")
+	local, domain := normalized[:at], normalized[at+1:]
+
+	p, ok := providers[domain]
+	if !ok {
+		return normalized, nil
+	}
+
+	if plus := strings.Index(local, "+"); plus > 0 {
+		local = local[:plus]
+	}
+
+	if p.ignoreDots {
+		local = strings.Replace(local, ".", "", -1)
+	}
+
+	return local + "@" + p.domain, nil
+}
+
+// freemail lists domains offering mailboxes to the public, so sharing one
+// says nothing about two addresses belonging to the same organization.
+var freemail = map[string]bool{
+	"gmail.com":      true,
+	"googlemail.com": true,
+	"outlook.com":    true,
+	"hotmail.com":    true,
+	"live.com":       true,
+	"icloud.com":     true,
+	"me.com":         true,
+	"fastmail.com":   true,
+	"protonmail.com": true,
+	"yahoo.com":      true,
+	"aol.com":        true,
+}
+
+// Domain returns the domain of a normalized address.
+func Domain(address string) string {
+	return address[strings.LastIndex(address, "@")+1:]
+}
+
+// IsFreemail reports whether domain is a public email provider.
+func IsFreemail(domain string) bool {
+	return freemail[strings.ToLower(domain)]
+}
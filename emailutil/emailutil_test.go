@@ -0,0 +1,59 @@
+package emailutil
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Alice@Example.com", "alice@example.com"},
+		{"  bob@EXAMPLE.com ", "bob@example.com"},
+		{"carol@bücher.example", "carol@xn--bcher-kva.example"},
+		{"dave@example.com.", "dave@example.com"},
+		{"a.lice+news@gmail.com", "a.lice+news@gmail.com"},
+	}
+
+	for _, tt := range tests {
+		got, err := Normalize(tt.in)
+		if err != nil {
+			t.Errorf("Normalize(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "alice", "@example.com", "alice@", "al ice@example.com", "alice@localhost"} {
+		if _, err := Normalize(in); errors.Cause(err) != ErrInvalid {
+			t.Errorf("Normalize(%q): expected ErrInvalid, got %v", in, err)
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"A.Lice+News@Gmail.com", "alice@gmail.com"},
+		{"alice@googlemail.com", "alice@gmail.com"},
+		{"bob+work@outlook.com", "bob@outlook.com"},
+		{"b.ob@outlook.com", "b.ob@outlook.com"},
+		{"carol+tag@example.com", "carol+tag@example.com"},
+	}
+
+	for _, tt := range tests {
+		got, err := Canonical(tt.in)
+		if err != nil {
+			t.Errorf("Canonical(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Canonical(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
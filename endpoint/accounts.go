@@ -0,0 +1,35 @@
+package endpoint
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeUpdateAccountEndpoint creates UpdateAccount Endpoint
+func MakeUpdateAccountEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.UpdateAccountRequest)
+		account, err := svc.UpdateAccount(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return account, nil
+	}
+}
+
+// MakeChangeAccountStatusEndpoint creates ChangeAccountStatus Endpoint
+func MakeChangeAccountStatusEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ChangeAccountStatusRequest)
+		account, err := svc.ChangeAccountStatus(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return account, nil
+	}
+}
@@ -0,0 +1,74 @@
+package endpoint
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeCreateAPIKeyEndpoint creates CreateAPIKey Endpoint
+func MakeCreateAPIKeyEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.CreateAPIKeyRequest)
+		issued, err := svc.CreateAPIKey(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return issued, nil
+	}
+}
+
+// MakeListAPIKeysEndpoint creates ListAPIKeys Endpoint
+func MakeListAPIKeysEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListAPIKeysRequest)
+		keys, err := svc.ListAPIKeys(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return keys, nil
+	}
+}
+
+// MakeRotateAPIKeyEndpoint creates RotateAPIKey Endpoint
+func MakeRotateAPIKeyEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.RotateAPIKeyRequest)
+		issued, err := svc.RotateAPIKey(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return issued, nil
+	}
+}
+
+// MakeRevokeAPIKeyEndpoint creates RevokeAPIKey Endpoint
+func MakeRevokeAPIKeyEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.RevokeAPIKeyRequest)
+		key, err := svc.RevokeAPIKey(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return key, nil
+	}
+}
+
+// MakeVerifyAPIKeyEndpoint creates VerifyAPIKey Endpoint
+func MakeVerifyAPIKeyEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.VerifyAPIKeyRequest)
+		verification, err := svc.VerifyAPIKey(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return verification, nil
+	}
+}
@@ -0,0 +1,73 @@
+package endpoint
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeDefineCustomFieldEndpoint creates DefineCustomField Endpoint
+func MakeDefineCustomFieldEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.DefineCustomFieldRequest)
+		field, err := svc.DefineCustomField(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return field, nil
+	}
+}
+
+// MakeListCustomFieldsEndpoint creates ListCustomFields Endpoint
+func MakeListCustomFieldsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListCustomFieldsRequest)
+		fields, err := svc.ListCustomFields(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return fields, nil
+	}
+}
+
+// MakeUpdateCustomFieldEndpoint creates UpdateCustomField Endpoint
+func MakeUpdateCustomFieldEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.UpdateCustomFieldRequest)
+		field, err := svc.UpdateCustomField(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return field, nil
+	}
+}
+
+// MakeDeleteCustomFieldEndpoint creates DeleteCustomField Endpoint
+func MakeDeleteCustomFieldEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.DeleteCustomFieldRequest)
+		if err := svc.DeleteCustomField(ctx, req); err != nil {
+			return nil, err
+		}
+
+		return req, nil
+	}
+}
+
+// MakeSetCustomFieldValuesEndpoint creates SetCustomFieldValues Endpoint
+func MakeSetCustomFieldValuesEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.SetCustomFieldValuesRequest)
+		membership, err := svc.SetCustomFieldValues(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return membership, nil
+	}
+}
@@ -0,0 +1,35 @@
+package endpoint
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeListDuplicateAccountsEndpoint creates ListDuplicateAccounts Endpoint
+func MakeListDuplicateAccountsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListDuplicateAccountsRequest)
+		candidates, err := svc.ListDuplicateAccounts(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return candidates, nil
+	}
+}
+
+// MakeMergeAccountsEndpoint creates MergeAccounts Endpoint
+func MakeMergeAccountsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.MergeAccountsRequest)
+		merge, err := svc.MergeAccounts(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return merge, nil
+	}
+}
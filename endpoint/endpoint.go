@@ -17,8 +17,107 @@ type Endpoints struct {
 	GetAccountEndpoint    endpoint.Endpoint
 	FetchAccountsEndpoint endpoint.Endpoint
 	CreateUserEndpoint    endpoint.Endpoint
+	UpdateUserEndpoint    endpoint.Endpoint
 	GetUserEndpoint       endpoint.Endpoint
 	FetchUsersEndpoint    endpoint.Endpoint
+
+	GetUserByEmailEndpoint             endpoint.Endpoint
+	FindAccountsByContactEmailEndpoint endpoint.Endpoint
+
+	GrantRoleEndpoint        endpoint.Endpoint
+	ChangeRoleEndpoint       endpoint.Endpoint
+	RevokeRoleEndpoint       endpoint.Endpoint
+	FetchMembershipsEndpoint endpoint.Endpoint
+
+	ChangeMembershipStatusEndpoint endpoint.Endpoint
+	FetchUserAccountsEndpoint      endpoint.Endpoint
+
+	InviteUserEndpoint       endpoint.Endpoint
+	ListInvitationsEndpoint  endpoint.Endpoint
+	RevokeInvitationEndpoint endpoint.Endpoint
+	AcceptInvitationEndpoint endpoint.Endpoint
+
+	RequestEmailVerificationEndpoint endpoint.Endpoint
+	ConfirmEmailVerificationEndpoint endpoint.Endpoint
+
+	SearchCustomersEndpoint endpoint.Endpoint
+
+	ListDuplicateAccountsEndpoint endpoint.Endpoint
+	MergeAccountsEndpoint         endpoint.Endpoint
+
+	TransferUserEndpoint endpoint.Endpoint
+
+	InitiateOwnershipTransferEndpoint endpoint.Endpoint
+	AcceptOwnershipTransferEndpoint   endpoint.Endpoint
+	CancelOwnershipTransferEndpoint   endpoint.Endpoint
+	ListOwnershipTransfersEndpoint    endpoint.Endpoint
+
+	SetAccountParentEndpoint     endpoint.Endpoint
+	ListChildAccountsEndpoint    endpoint.Endpoint
+	ListAncestorAccountsEndpoint endpoint.Endpoint
+
+	CreateGroupEndpoint       endpoint.Endpoint
+	GetGroupEndpoint          endpoint.Endpoint
+	ListGroupsEndpoint        endpoint.Endpoint
+	UpdateGroupEndpoint       endpoint.Endpoint
+	DeleteGroupEndpoint       endpoint.Endpoint
+	AddGroupMemberEndpoint    endpoint.Endpoint
+	RemoveGroupMemberEndpoint endpoint.Endpoint
+	ListGroupMembersEndpoint  endpoint.Endpoint
+
+	CreateAPIKeyEndpoint endpoint.Endpoint
+	ListAPIKeysEndpoint  endpoint.Endpoint
+	RotateAPIKeyEndpoint endpoint.Endpoint
+	RevokeAPIKeyEndpoint endpoint.Endpoint
+	VerifyAPIKeyEndpoint endpoint.Endpoint
+
+	CreateServiceAccountEndpoint endpoint.Endpoint
+	ListServiceAccountsEndpoint  endpoint.Endpoint
+	UpdateServiceAccountEndpoint endpoint.Endpoint
+	DeleteServiceAccountEndpoint endpoint.Endpoint
+
+	UpdateAccountEndpoint       endpoint.Endpoint
+	ChangeAccountStatusEndpoint endpoint.Endpoint
+
+	DefineCustomFieldEndpoint    endpoint.Endpoint
+	ListCustomFieldsEndpoint     endpoint.Endpoint
+	UpdateCustomFieldEndpoint    endpoint.Endpoint
+	DeleteCustomFieldEndpoint    endpoint.Endpoint
+	SetCustomFieldValuesEndpoint endpoint.Endpoint
+
+	CreatePlanEndpoint                endpoint.Endpoint
+	ListPlansEndpoint                 endpoint.Endpoint
+	UpdatePlanEndpoint                endpoint.Endpoint
+	AssignPlanEndpoint                endpoint.Endpoint
+	ListAccountPlansEndpoint          endpoint.Endpoint
+	SetEntitlementOverrideEndpoint    endpoint.Endpoint
+	RemoveEntitlementOverrideEndpoint endpoint.Endpoint
+	ListAccountEntitlementsEndpoint   endpoint.Endpoint
+	CheckEntitlementEndpoint          endpoint.Endpoint
+
+	GetAccountUsageEndpoint endpoint.Endpoint
+
+	ExtendTrialEndpoint  endpoint.Endpoint
+	ConvertTrialEndpoint endpoint.Endpoint
+
+	ScheduleStatusChangeEndpoint  endpoint.Endpoint
+	ListScheduledActionsEndpoint  endpoint.Endpoint
+	CancelScheduledActionEndpoint endpoint.Endpoint
+
+	CreateFeatureFlagEndpoint  endpoint.Endpoint
+	ListFeatureFlagsEndpoint   endpoint.Endpoint
+	UpdateFeatureFlagEndpoint  endpoint.Endpoint
+	DeleteFeatureFlagEndpoint  endpoint.Endpoint
+	SetFlagOverrideEndpoint    endpoint.Endpoint
+	RemoveFlagOverrideEndpoint endpoint.Endpoint
+	ListFlagOverridesEndpoint  endpoint.Endpoint
+	EvaluateFlagsEndpoint      endpoint.Endpoint
+	WatchFlagsEndpoint         endpoint.Endpoint
+
+	GetUserPreferencesEndpoint       endpoint.Endpoint
+	UpdateUserPreferencesEndpoint    endpoint.Endpoint
+	GetAccountPreferencesEndpoint    endpoint.Endpoint
+	UpdateAccountPreferencesEndpoint endpoint.Endpoint
 }
 
 // CreateAccount ...
@@ -99,6 +198,20 @@ func MakeFetchAccountsEndpoint(svc service.Service) endpoint.Endpoint {
 
 }
 
+// MakeFindAccountsByContactEmailEndpoint creates FindAccountsByContactEmail Endpoint
+func MakeFindAccountsByContactEmailEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.FindAccountsByContactEmailRequest)
+		accounts, err := svc.FindAccountsByContactEmail(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return accounts, nil
+	}
+
+}
+
 // MakeCreateUserEndpoint creates CreateUser Endpoint
 func MakeCreateUserEndpoint(svc service.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
@@ -113,6 +226,20 @@ func MakeCreateUserEndpoint(svc service.Service) endpoint.Endpoint {
 
 }
 
+// MakeUpdateUserEndpoint creates UpdateUser Endpoint
+func MakeUpdateUserEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.UpdateUserRequest)
+		user, err := svc.UpdateUser(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return user, nil
+	}
+
+}
+
 // MakeGetUserEndpoint creates GetUser Endpoint
 func MakeGetUserEndpoint(svc service.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
@@ -127,6 +254,20 @@ func MakeGetUserEndpoint(svc service.Service) endpoint.Endpoint {
 
 }
 
+// MakeGetUserByEmailEndpoint creates GetUserByEmail Endpoint
+func MakeGetUserByEmailEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.GetUserByEmailRequest)
+		user, err := svc.GetUserByEmail(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return user, nil
+	}
+
+}
+
 // MakeFetchUsersEndpoint creates FetchUsers Endpoint
 func MakeFetchUsersEndpoint(svc service.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
@@ -155,24 +296,435 @@ func New(svc service.Service, logger log.Logger) Endpoints {
 		log.With(logger, "method", "FetchAccounts"),
 	)(MakeFetchAccountsEndpoint(svc))
 
+	findAccountsByContactEmailEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "FindAccountsByContactEmail"),
+	)(MakeFindAccountsByContactEmailEndpoint(svc))
+
 	createUserEndpoint := middleware.LoggingMiddleware(
 		log.With(logger, "method", "CreateUser"),
 	)(MakeCreateUserEndpoint(svc))
 
+	updateUserEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "UpdateUser"),
+	)(MakeUpdateUserEndpoint(svc))
+
 	getUserEndpoint := middleware.LoggingMiddleware(
 		log.With(logger, "method", "GetUser"),
 	)(MakeGetUserEndpoint(svc))
 
+	getUserByEmailEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "GetUserByEmail"),
+	)(MakeGetUserByEmailEndpoint(svc))
+
 	fetchUsersEndpoint := middleware.LoggingMiddleware(
 		log.With(logger, "method", "FetchUsers"),
 	)(MakeFetchUsersEndpoint(svc))
 
+	grantRoleEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "GrantRole"),
+	)(MakeGrantRoleEndpoint(svc))
+
+	changeRoleEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "ChangeRole"),
+	)(MakeChangeRoleEndpoint(svc))
+
+	revokeRoleEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "RevokeRole"),
+	)(MakeRevokeRoleEndpoint(svc))
+
+	fetchMembershipsEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "FetchMemberships"),
+	)(MakeFetchMembershipsEndpoint(svc))
+
+	changeMembershipStatusEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "ChangeMembershipStatus"),
+	)(MakeChangeMembershipStatusEndpoint(svc))
+
+	fetchUserAccountsEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "FetchUserAccounts"),
+	)(MakeFetchUserAccountsEndpoint(svc))
+
+	inviteUserEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "InviteUser"),
+	)(MakeInviteUserEndpoint(svc))
+
+	listInvitationsEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "ListInvitations"),
+	)(MakeListInvitationsEndpoint(svc))
+
+	revokeInvitationEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "RevokeInvitation"),
+	)(MakeRevokeInvitationEndpoint(svc))
+
+	acceptInvitationEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "AcceptInvitation"),
+	)(MakeAcceptInvitationEndpoint(svc))
+
+	requestEmailVerificationEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "RequestEmailVerification"),
+	)(MakeRequestEmailVerificationEndpoint(svc))
+
+	confirmEmailVerificationEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "ConfirmEmailVerification"),
+	)(MakeConfirmEmailVerificationEndpoint(svc))
+
+	searchCustomersEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "SearchCustomers"),
+	)(MakeSearchCustomersEndpoint(svc))
+
+	listDuplicateAccountsEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "ListDuplicateAccounts"),
+	)(MakeListDuplicateAccountsEndpoint(svc))
+
+	mergeAccountsEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "MergeAccounts"),
+	)(MakeMergeAccountsEndpoint(svc))
+
+	transferUserEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "TransferUser"),
+	)(MakeTransferUserEndpoint(svc))
+
+	initiateOwnershipTransferEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "InitiateOwnershipTransfer"),
+	)(MakeInitiateOwnershipTransferEndpoint(svc))
+
+	acceptOwnershipTransferEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "AcceptOwnershipTransfer"),
+	)(MakeAcceptOwnershipTransferEndpoint(svc))
+
+	cancelOwnershipTransferEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "CancelOwnershipTransfer"),
+	)(MakeCancelOwnershipTransferEndpoint(svc))
+
+	listOwnershipTransfersEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "ListOwnershipTransfers"),
+	)(MakeListOwnershipTransfersEndpoint(svc))
+
+	setAccountParentEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "SetAccountParent"),
+	)(MakeSetAccountParentEndpoint(svc))
+
+	listChildAccountsEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "ListChildAccounts"),
+	)(MakeListChildAccountsEndpoint(svc))
+
+	listAncestorAccountsEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "ListAncestorAccounts"),
+	)(MakeListAncestorAccountsEndpoint(svc))
+
+	createGroupEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "CreateGroup"),
+	)(MakeCreateGroupEndpoint(svc))
+
+	getGroupEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "GetGroup"),
+	)(MakeGetGroupEndpoint(svc))
+
+	listGroupsEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "ListGroups"),
+	)(MakeListGroupsEndpoint(svc))
+
+	updateGroupEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "UpdateGroup"),
+	)(MakeUpdateGroupEndpoint(svc))
+
+	deleteGroupEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "DeleteGroup"),
+	)(MakeDeleteGroupEndpoint(svc))
+
+	addGroupMemberEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "AddGroupMember"),
+	)(MakeAddGroupMemberEndpoint(svc))
+
+	removeGroupMemberEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "RemoveGroupMember"),
+	)(MakeRemoveGroupMemberEndpoint(svc))
+
+	listGroupMembersEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "ListGroupMembers"),
+	)(MakeListGroupMembersEndpoint(svc))
+
+	createAPIKeyEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "CreateAPIKey"),
+	)(MakeCreateAPIKeyEndpoint(svc))
+
+	listAPIKeysEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "ListAPIKeys"),
+	)(MakeListAPIKeysEndpoint(svc))
+
+	rotateAPIKeyEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "RotateAPIKey"),
+	)(MakeRotateAPIKeyEndpoint(svc))
+
+	revokeAPIKeyEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "RevokeAPIKey"),
+	)(MakeRevokeAPIKeyEndpoint(svc))
+
+	verifyAPIKeyEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "VerifyAPIKey"),
+	)(MakeVerifyAPIKeyEndpoint(svc))
+
+	createServiceAccountEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "CreateServiceAccount"),
+	)(MakeCreateServiceAccountEndpoint(svc))
+
+	listServiceAccountsEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "ListServiceAccounts"),
+	)(MakeListServiceAccountsEndpoint(svc))
+
+	updateServiceAccountEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "UpdateServiceAccount"),
+	)(MakeUpdateServiceAccountEndpoint(svc))
+
+	deleteServiceAccountEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "DeleteServiceAccount"),
+	)(MakeDeleteServiceAccountEndpoint(svc))
+
+	updateAccountEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "UpdateAccount"),
+	)(MakeUpdateAccountEndpoint(svc))
+
+	changeAccountStatusEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "ChangeAccountStatus"),
+	)(MakeChangeAccountStatusEndpoint(svc))
+
+	defineCustomFieldEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "DefineCustomField"),
+	)(MakeDefineCustomFieldEndpoint(svc))
+
+	listCustomFieldsEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "ListCustomFields"),
+	)(MakeListCustomFieldsEndpoint(svc))
+
+	updateCustomFieldEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "UpdateCustomField"),
+	)(MakeUpdateCustomFieldEndpoint(svc))
+
+	deleteCustomFieldEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "DeleteCustomField"),
+	)(MakeDeleteCustomFieldEndpoint(svc))
+
+	setCustomFieldValuesEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "SetCustomFieldValues"),
+	)(MakeSetCustomFieldValuesEndpoint(svc))
+
+	createPlanEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "CreatePlan"),
+	)(MakeCreatePlanEndpoint(svc))
+
+	listPlansEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "ListPlans"),
+	)(MakeListPlansEndpoint(svc))
+
+	updatePlanEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "UpdatePlan"),
+	)(MakeUpdatePlanEndpoint(svc))
+
+	assignPlanEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "AssignPlan"),
+	)(MakeAssignPlanEndpoint(svc))
+
+	listAccountPlansEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "ListAccountPlans"),
+	)(MakeListAccountPlansEndpoint(svc))
+
+	setEntitlementOverrideEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "SetEntitlementOverride"),
+	)(MakeSetEntitlementOverrideEndpoint(svc))
+
+	removeEntitlementOverrideEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "RemoveEntitlementOverride"),
+	)(MakeRemoveEntitlementOverrideEndpoint(svc))
+
+	listAccountEntitlementsEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "ListAccountEntitlements"),
+	)(MakeListAccountEntitlementsEndpoint(svc))
+
+	checkEntitlementEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "CheckEntitlement"),
+	)(MakeCheckEntitlementEndpoint(svc))
+
+	getAccountUsageEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "GetAccountUsage"),
+	)(MakeGetAccountUsageEndpoint(svc))
+
+	extendTrialEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "ExtendTrial"),
+	)(MakeExtendTrialEndpoint(svc))
+
+	convertTrialEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "ConvertTrial"),
+	)(MakeConvertTrialEndpoint(svc))
+
+	scheduleStatusChangeEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "ScheduleStatusChange"),
+	)(MakeScheduleStatusChangeEndpoint(svc))
+
+	listScheduledActionsEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "ListScheduledActions"),
+	)(MakeListScheduledActionsEndpoint(svc))
+
+	cancelScheduledActionEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "CancelScheduledAction"),
+	)(MakeCancelScheduledActionEndpoint(svc))
+
+	createFeatureFlagEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "CreateFeatureFlag"),
+	)(MakeCreateFeatureFlagEndpoint(svc))
+
+	listFeatureFlagsEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "ListFeatureFlags"),
+	)(MakeListFeatureFlagsEndpoint(svc))
+
+	updateFeatureFlagEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "UpdateFeatureFlag"),
+	)(MakeUpdateFeatureFlagEndpoint(svc))
+
+	deleteFeatureFlagEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "DeleteFeatureFlag"),
+	)(MakeDeleteFeatureFlagEndpoint(svc))
+
+	setFlagOverrideEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "SetFlagOverride"),
+	)(MakeSetFlagOverrideEndpoint(svc))
+
+	removeFlagOverrideEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "RemoveFlagOverride"),
+	)(MakeRemoveFlagOverrideEndpoint(svc))
+
+	listFlagOverridesEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "ListFlagOverrides"),
+	)(MakeListFlagOverridesEndpoint(svc))
+
+	evaluateFlagsEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "EvaluateFlags"),
+	)(MakeEvaluateFlagsEndpoint(svc))
+
+	watchFlagsEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "WatchFlags"),
+	)(MakeWatchFlagsEndpoint(svc))
+
+	getUserPreferencesEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "GetUserPreferences"),
+	)(MakeGetUserPreferencesEndpoint(svc))
+
+	updateUserPreferencesEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "UpdateUserPreferences"),
+	)(MakeUpdateUserPreferencesEndpoint(svc))
+
+	getAccountPreferencesEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "GetAccountPreferences"),
+	)(MakeGetAccountPreferencesEndpoint(svc))
+
+	updateAccountPreferencesEndpoint := middleware.LoggingMiddleware(
+		log.With(logger, "method", "UpdateAccountPreferences"),
+	)(MakeUpdateAccountPreferencesEndpoint(svc))
+
 	return Endpoints{
 		CreateAccountEndpoint: createAccountEndpoint,
 		GetAccountEndpoint:    getAccountEndpoint,
 		FetchAccountsEndpoint: fetchAccountsEndpoint,
 		CreateUserEndpoint:    createUserEndpoint,
+		UpdateUserEndpoint:    updateUserEndpoint,
 		GetUserEndpoint:       getUserEndpoint,
 		FetchUsersEndpoint:    fetchUsersEndpoint,
+
+		GetUserByEmailEndpoint:             getUserByEmailEndpoint,
+		FindAccountsByContactEmailEndpoint: findAccountsByContactEmailEndpoint,
+
+		GrantRoleEndpoint:        grantRoleEndpoint,
+		ChangeRoleEndpoint:       changeRoleEndpoint,
+		RevokeRoleEndpoint:       revokeRoleEndpoint,
+		FetchMembershipsEndpoint: fetchMembershipsEndpoint,
+
+		ChangeMembershipStatusEndpoint: changeMembershipStatusEndpoint,
+		FetchUserAccountsEndpoint:      fetchUserAccountsEndpoint,
+
+		InviteUserEndpoint:       inviteUserEndpoint,
+		ListInvitationsEndpoint:  listInvitationsEndpoint,
+		RevokeInvitationEndpoint: revokeInvitationEndpoint,
+		AcceptInvitationEndpoint: acceptInvitationEndpoint,
+
+		RequestEmailVerificationEndpoint: requestEmailVerificationEndpoint,
+		ConfirmEmailVerificationEndpoint: confirmEmailVerificationEndpoint,
+
+		SearchCustomersEndpoint: searchCustomersEndpoint,
+
+		ListDuplicateAccountsEndpoint: listDuplicateAccountsEndpoint,
+		MergeAccountsEndpoint:         mergeAccountsEndpoint,
+
+		TransferUserEndpoint: transferUserEndpoint,
+
+		InitiateOwnershipTransferEndpoint: initiateOwnershipTransferEndpoint,
+		AcceptOwnershipTransferEndpoint:   acceptOwnershipTransferEndpoint,
+		CancelOwnershipTransferEndpoint:   cancelOwnershipTransferEndpoint,
+		ListOwnershipTransfersEndpoint:    listOwnershipTransfersEndpoint,
+
+		SetAccountParentEndpoint:     setAccountParentEndpoint,
+		ListChildAccountsEndpoint:    listChildAccountsEndpoint,
+		ListAncestorAccountsEndpoint: listAncestorAccountsEndpoint,
+
+		CreateGroupEndpoint:       createGroupEndpoint,
+		GetGroupEndpoint:          getGroupEndpoint,
+		ListGroupsEndpoint:        listGroupsEndpoint,
+		UpdateGroupEndpoint:       updateGroupEndpoint,
+		DeleteGroupEndpoint:       deleteGroupEndpoint,
+		AddGroupMemberEndpoint:    addGroupMemberEndpoint,
+		RemoveGroupMemberEndpoint: removeGroupMemberEndpoint,
+		ListGroupMembersEndpoint:  listGroupMembersEndpoint,
+
+		CreateAPIKeyEndpoint: createAPIKeyEndpoint,
+		ListAPIKeysEndpoint:  listAPIKeysEndpoint,
+		RotateAPIKeyEndpoint: rotateAPIKeyEndpoint,
+		RevokeAPIKeyEndpoint: revokeAPIKeyEndpoint,
+		VerifyAPIKeyEndpoint: verifyAPIKeyEndpoint,
+
+		CreateServiceAccountEndpoint: createServiceAccountEndpoint,
+		ListServiceAccountsEndpoint:  listServiceAccountsEndpoint,
+		UpdateServiceAccountEndpoint: updateServiceAccountEndpoint,
+		DeleteServiceAccountEndpoint: deleteServiceAccountEndpoint,
+
+		UpdateAccountEndpoint:       updateAccountEndpoint,
+		ChangeAccountStatusEndpoint: changeAccountStatusEndpoint,
+
+		DefineCustomFieldEndpoint:    defineCustomFieldEndpoint,
+		ListCustomFieldsEndpoint:     listCustomFieldsEndpoint,
+		UpdateCustomFieldEndpoint:    updateCustomFieldEndpoint,
+		DeleteCustomFieldEndpoint:    deleteCustomFieldEndpoint,
+		SetCustomFieldValuesEndpoint: setCustomFieldValuesEndpoint,
+
+		CreatePlanEndpoint:                createPlanEndpoint,
+		ListPlansEndpoint:                 listPlansEndpoint,
+		UpdatePlanEndpoint:                updatePlanEndpoint,
+		AssignPlanEndpoint:                assignPlanEndpoint,
+		ListAccountPlansEndpoint:          listAccountPlansEndpoint,
+		SetEntitlementOverrideEndpoint:    setEntitlementOverrideEndpoint,
+		RemoveEntitlementOverrideEndpoint: removeEntitlementOverrideEndpoint,
+		ListAccountEntitlementsEndpoint:   listAccountEntitlementsEndpoint,
+		CheckEntitlementEndpoint:          checkEntitlementEndpoint,
+
+		GetAccountUsageEndpoint: getAccountUsageEndpoint,
+
+		ExtendTrialEndpoint:  extendTrialEndpoint,
+		ConvertTrialEndpoint: convertTrialEndpoint,
+
+		ScheduleStatusChangeEndpoint:  scheduleStatusChangeEndpoint,
+		ListScheduledActionsEndpoint:  listScheduledActionsEndpoint,
+		CancelScheduledActionEndpoint: cancelScheduledActionEndpoint,
+
+		CreateFeatureFlagEndpoint:  createFeatureFlagEndpoint,
+		ListFeatureFlagsEndpoint:   listFeatureFlagsEndpoint,
+		UpdateFeatureFlagEndpoint:  updateFeatureFlagEndpoint,
+		DeleteFeatureFlagEndpoint:  deleteFeatureFlagEndpoint,
+		SetFlagOverrideEndpoint:    setFlagOverrideEndpoint,
+		RemoveFlagOverrideEndpoint: removeFlagOverrideEndpoint,
+		ListFlagOverridesEndpoint:  listFlagOverridesEndpoint,
+		EvaluateFlagsEndpoint:      evaluateFlagsEndpoint,
+		WatchFlagsEndpoint:         watchFlagsEndpoint,
+
+		GetUserPreferencesEndpoint:       getUserPreferencesEndpoint,
+		UpdateUserPreferencesEndpoint:    updateUserPreferencesEndpoint,
+		GetAccountPreferencesEndpoint:    getAccountPreferencesEndpoint,
+		UpdateAccountPreferencesEndpoint: updateAccountPreferencesEndpoint,
 	}
 }
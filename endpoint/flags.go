@@ -0,0 +1,123 @@
+package endpoint
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeCreateFeatureFlagEndpoint creates CreateFeatureFlag Endpoint
+func MakeCreateFeatureFlagEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.CreateFeatureFlagRequest)
+		flag, err := svc.CreateFeatureFlag(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return flag, nil
+	}
+}
+
+// MakeListFeatureFlagsEndpoint creates ListFeatureFlags Endpoint
+func MakeListFeatureFlagsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListFeatureFlagsRequest)
+		flags, err := svc.ListFeatureFlags(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return flags, nil
+	}
+}
+
+// MakeUpdateFeatureFlagEndpoint creates UpdateFeatureFlag Endpoint
+func MakeUpdateFeatureFlagEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.UpdateFeatureFlagRequest)
+		flag, err := svc.UpdateFeatureFlag(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return flag, nil
+	}
+}
+
+// MakeDeleteFeatureFlagEndpoint creates DeleteFeatureFlag Endpoint
+func MakeDeleteFeatureFlagEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.DeleteFeatureFlagRequest)
+		if err := svc.DeleteFeatureFlag(ctx, req); err != nil {
+			return nil, err
+		}
+
+		return req, nil
+	}
+}
+
+// MakeSetFlagOverrideEndpoint creates SetFlagOverride Endpoint
+func MakeSetFlagOverrideEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.SetFlagOverrideRequest)
+		override, err := svc.SetFlagOverride(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return override, nil
+	}
+}
+
+// MakeRemoveFlagOverrideEndpoint creates RemoveFlagOverride Endpoint
+func MakeRemoveFlagOverrideEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.RemoveFlagOverrideRequest)
+		if err := svc.RemoveFlagOverride(ctx, req); err != nil {
+			return nil, err
+		}
+
+		return req, nil
+	}
+}
+
+// MakeListFlagOverridesEndpoint creates ListFlagOverrides Endpoint
+func MakeListFlagOverridesEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListFlagOverridesRequest)
+		overrides, err := svc.ListFlagOverrides(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return overrides, nil
+	}
+}
+
+// MakeEvaluateFlagsEndpoint creates EvaluateFlags Endpoint
+func MakeEvaluateFlagsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.EvaluateFlagsRequest)
+		evaluations, err := svc.EvaluateFlags(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return evaluations, nil
+	}
+}
+
+// MakeWatchFlagsEndpoint creates WatchFlags Endpoint
+func MakeWatchFlagsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.WatchFlagsRequest)
+		if err := svc.WatchFlags(ctx, req); err != nil {
+			return nil, err
+		}
+
+		return req, nil
+	}
+}
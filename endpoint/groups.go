@@ -0,0 +1,111 @@
+package endpoint
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeCreateGroupEndpoint creates CreateGroup Endpoint
+func MakeCreateGroupEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.CreateGroupRequest)
+		group, err := svc.CreateGroup(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return group, nil
+	}
+}
+
+// MakeGetGroupEndpoint creates GetGroup Endpoint
+func MakeGetGroupEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.GetGroupRequest)
+		group, err := svc.GetGroup(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return group, nil
+	}
+}
+
+// MakeListGroupsEndpoint creates ListGroups Endpoint
+func MakeListGroupsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListGroupsRequest)
+		groups, err := svc.ListGroups(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return groups, nil
+	}
+}
+
+// MakeUpdateGroupEndpoint creates UpdateGroup Endpoint
+func MakeUpdateGroupEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.UpdateGroupRequest)
+		group, err := svc.UpdateGroup(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return group, nil
+	}
+}
+
+// MakeDeleteGroupEndpoint creates DeleteGroup Endpoint
+func MakeDeleteGroupEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.DeleteGroupRequest)
+		if err := svc.DeleteGroup(ctx, req); err != nil {
+			return nil, err
+		}
+
+		return req, nil
+	}
+}
+
+// MakeAddGroupMemberEndpoint creates AddGroupMember Endpoint
+func MakeAddGroupMemberEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.AddGroupMemberRequest)
+		member, err := svc.AddGroupMember(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return member, nil
+	}
+}
+
+// MakeRemoveGroupMemberEndpoint creates RemoveGroupMember Endpoint
+func MakeRemoveGroupMemberEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.RemoveGroupMemberRequest)
+		if err := svc.RemoveGroupMember(ctx, req); err != nil {
+			return nil, err
+		}
+
+		return req, nil
+	}
+}
+
+// MakeListGroupMembersEndpoint creates ListGroupMembers Endpoint
+func MakeListGroupMembersEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListGroupMembersRequest)
+		members, err := svc.ListGroupMembers(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return members, nil
+	}
+}
@@ -0,0 +1,48 @@
+package endpoint
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeSetAccountParentEndpoint creates SetAccountParent Endpoint
+func MakeSetAccountParentEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.SetAccountParentRequest)
+		account, err := svc.SetAccountParent(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return account, nil
+	}
+}
+
+// MakeListChildAccountsEndpoint creates ListChildAccounts Endpoint
+func MakeListChildAccountsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListChildAccountsRequest)
+		children, err := svc.ListChildAccounts(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return children, nil
+	}
+}
+
+// MakeListAncestorAccountsEndpoint creates ListAncestorAccounts Endpoint
+func MakeListAncestorAccountsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListAncestorAccountsRequest)
+		ancestors, err := svc.ListAncestorAccounts(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return ancestors, nil
+	}
+}
@@ -0,0 +1,61 @@
+package endpoint
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeInviteUserEndpoint creates InviteUser Endpoint
+func MakeInviteUserEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.InviteUserRequest)
+		invitation, err := svc.InviteUser(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return invitation, nil
+	}
+}
+
+// MakeListInvitationsEndpoint creates ListInvitations Endpoint
+func MakeListInvitationsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListInvitationsRequest)
+		invitations, err := svc.ListInvitations(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return invitations, nil
+	}
+}
+
+// MakeRevokeInvitationEndpoint creates RevokeInvitation Endpoint
+func MakeRevokeInvitationEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.RevokeInvitationRequest)
+		invitation, err := svc.RevokeInvitation(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return invitation, nil
+	}
+}
+
+// MakeAcceptInvitationEndpoint creates AcceptInvitation Endpoint
+func MakeAcceptInvitationEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.AcceptInvitationRequest)
+		membership, err := svc.AcceptInvitation(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return membership, nil
+	}
+}
@@ -0,0 +1,99 @@
+package endpoint
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeGrantRoleEndpoint creates GrantRole Endpoint
+func MakeGrantRoleEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.GrantRoleRequest)
+		membership, err := svc.GrantRole(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return membership, nil
+	}
+}
+
+// MakeChangeRoleEndpoint creates ChangeRole Endpoint
+func MakeChangeRoleEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ChangeRoleRequest)
+		membership, err := svc.ChangeRole(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return membership, nil
+	}
+}
+
+// MakeRevokeRoleEndpoint creates RevokeRole Endpoint
+func MakeRevokeRoleEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.RevokeRoleRequest)
+		if err := svc.RevokeRole(ctx, req); err != nil {
+			return nil, err
+		}
+
+		return req, nil
+	}
+}
+
+// MakeFetchMembershipsEndpoint creates FetchMemberships Endpoint
+func MakeFetchMembershipsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.FetchMembershipsRequest)
+		memberships, err := svc.FetchMemberships(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return memberships, nil
+	}
+}
+
+// MakeChangeMembershipStatusEndpoint creates ChangeMembershipStatus Endpoint
+func MakeChangeMembershipStatusEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ChangeMembershipStatusRequest)
+		membership, err := svc.ChangeMembershipStatus(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return membership, nil
+	}
+}
+
+// MakeFetchUserAccountsEndpoint creates FetchUserAccounts Endpoint
+func MakeFetchUserAccountsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.FetchUserAccountsRequest)
+		accounts, err := svc.FetchUserAccounts(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return accounts, nil
+	}
+}
+
+// MakeTransferUserEndpoint creates TransferUser Endpoint
+func MakeTransferUserEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.TransferUserRequest)
+		transfer, err := svc.TransferUser(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return transfer, nil
+	}
+}
@@ -0,0 +1,61 @@
+package endpoint
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeInitiateOwnershipTransferEndpoint creates InitiateOwnershipTransfer Endpoint
+func MakeInitiateOwnershipTransferEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.InitiateOwnershipTransferRequest)
+		transfer, err := svc.InitiateOwnershipTransfer(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return transfer, nil
+	}
+}
+
+// MakeAcceptOwnershipTransferEndpoint creates AcceptOwnershipTransfer Endpoint
+func MakeAcceptOwnershipTransferEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.AcceptOwnershipTransferRequest)
+		transfer, err := svc.AcceptOwnershipTransfer(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return transfer, nil
+	}
+}
+
+// MakeCancelOwnershipTransferEndpoint creates CancelOwnershipTransfer Endpoint
+func MakeCancelOwnershipTransferEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.CancelOwnershipTransferRequest)
+		transfer, err := svc.CancelOwnershipTransfer(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return transfer, nil
+	}
+}
+
+// MakeListOwnershipTransfersEndpoint creates ListOwnershipTransfers Endpoint
+func MakeListOwnershipTransfersEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListOwnershipTransfersRequest)
+		transfers, err := svc.ListOwnershipTransfers(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return transfers, nil
+	}
+}
@@ -0,0 +1,125 @@
+package endpoint
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeCreatePlanEndpoint creates CreatePlan Endpoint
+func MakeCreatePlanEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.CreatePlanRequest)
+		plan, err := svc.CreatePlan(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return plan, nil
+	}
+}
+
+// MakeListPlansEndpoint creates ListPlans Endpoint
+func MakeListPlansEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListPlansRequest)
+		plans, err := svc.ListPlans(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return plans, nil
+	}
+}
+
+// MakeUpdatePlanEndpoint creates UpdatePlan Endpoint
+func MakeUpdatePlanEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.UpdatePlanRequest)
+		plan, err := svc.UpdatePlan(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return plan, nil
+	}
+}
+
+// MakeAssignPlanEndpoint creates AssignPlan Endpoint
+func MakeAssignPlanEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.AssignPlanRequest)
+		assignment, err := svc.AssignPlan(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return assignment, nil
+	}
+}
+
+// MakeListAccountPlansEndpoint creates ListAccountPlans Endpoint
+func MakeListAccountPlansEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListAccountPlansRequest)
+		assignments, err := svc.ListAccountPlans(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return assignments, nil
+	}
+}
+
+// MakeSetEntitlementOverrideEndpoint creates SetEntitlementOverride Endpoint
+func MakeSetEntitlementOverrideEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.SetEntitlementOverrideRequest)
+		override, err := svc.SetEntitlementOverride(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return override, nil
+	}
+}
+
+// MakeRemoveEntitlementOverrideEndpoint creates RemoveEntitlementOverride Endpoint
+func MakeRemoveEntitlementOverrideEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.RemoveEntitlementOverrideRequest)
+		if err := svc.RemoveEntitlementOverride(ctx, req); err != nil {
+			return nil, err
+		}
+
+		return req, nil
+	}
+}
+
+// MakeListAccountEntitlementsEndpoint creates ListAccountEntitlements Endpoint
+func MakeListAccountEntitlementsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListAccountEntitlementsRequest)
+		entitlements, err := svc.ListAccountEntitlements(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return entitlements, nil
+	}
+}
+
+// MakeCheckEntitlementEndpoint creates CheckEntitlement Endpoint
+func MakeCheckEntitlementEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.CheckEntitlementRequest)
+		check, err := svc.CheckEntitlement(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return check, nil
+	}
+}
@@ -0,0 +1,61 @@
+package endpoint
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeGetUserPreferencesEndpoint creates GetUserPreferences Endpoint
+func MakeGetUserPreferencesEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.GetUserPreferencesRequest)
+		preferences, err := svc.GetUserPreferences(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return preferences, nil
+	}
+}
+
+// MakeUpdateUserPreferencesEndpoint creates UpdateUserPreferences Endpoint
+func MakeUpdateUserPreferencesEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.UpdateUserPreferencesRequest)
+		preferences, err := svc.UpdateUserPreferences(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return preferences, nil
+	}
+}
+
+// MakeGetAccountPreferencesEndpoint creates GetAccountPreferences Endpoint
+func MakeGetAccountPreferencesEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.GetAccountPreferencesRequest)
+		preferences, err := svc.GetAccountPreferences(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return preferences, nil
+	}
+}
+
+// MakeUpdateAccountPreferencesEndpoint creates UpdateAccountPreferences Endpoint
+func MakeUpdateAccountPreferencesEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.UpdateAccountPreferencesRequest)
+		preferences, err := svc.UpdateAccountPreferences(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return preferences, nil
+	}
+}
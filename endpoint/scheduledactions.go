@@ -0,0 +1,48 @@
+package endpoint
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeScheduleStatusChangeEndpoint creates ScheduleStatusChange Endpoint
+func MakeScheduleStatusChangeEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ScheduleStatusChangeRequest)
+		action, err := svc.ScheduleStatusChange(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return action, nil
+	}
+}
+
+// MakeListScheduledActionsEndpoint creates ListScheduledActions Endpoint
+func MakeListScheduledActionsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListScheduledActionsRequest)
+		actions, err := svc.ListScheduledActions(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return actions, nil
+	}
+}
+
+// MakeCancelScheduledActionEndpoint creates CancelScheduledAction Endpoint
+func MakeCancelScheduledActionEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.CancelScheduledActionRequest)
+		action, err := svc.CancelScheduledAction(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return action, nil
+	}
+}
@@ -0,0 +1,22 @@
+package endpoint
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeSearchCustomersEndpoint creates SearchCustomers Endpoint
+func MakeSearchCustomersEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.SearchCustomersRequest)
+		results, err := svc.SearchCustomers(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return results, nil
+	}
+}
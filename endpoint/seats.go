@@ -0,0 +1,22 @@
+package endpoint
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeGetAccountUsageEndpoint creates GetAccountUsage Endpoint
+func MakeGetAccountUsageEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.GetAccountUsageRequest)
+		usage, err := svc.GetAccountUsage(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return usage, nil
+	}
+}
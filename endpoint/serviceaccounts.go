@@ -0,0 +1,60 @@
+package endpoint
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeCreateServiceAccountEndpoint creates CreateServiceAccount Endpoint
+func MakeCreateServiceAccountEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.CreateServiceAccountRequest)
+		user, err := svc.CreateServiceAccount(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return user, nil
+	}
+}
+
+// MakeListServiceAccountsEndpoint creates ListServiceAccounts Endpoint
+func MakeListServiceAccountsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListServiceAccountsRequest)
+		users, err := svc.ListServiceAccounts(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return users, nil
+	}
+}
+
+// MakeUpdateServiceAccountEndpoint creates UpdateServiceAccount Endpoint
+func MakeUpdateServiceAccountEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.UpdateServiceAccountRequest)
+		user, err := svc.UpdateServiceAccount(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return user, nil
+	}
+}
+
+// MakeDeleteServiceAccountEndpoint creates DeleteServiceAccount Endpoint
+func MakeDeleteServiceAccountEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.DeleteServiceAccountRequest)
+		if err := svc.DeleteServiceAccount(ctx, req); err != nil {
+			return nil, err
+		}
+
+		return req, nil
+	}
+}
@@ -0,0 +1,35 @@
+package endpoint
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeExtendTrialEndpoint creates ExtendTrial Endpoint
+func MakeExtendTrialEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ExtendTrialRequest)
+		account, err := svc.ExtendTrial(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return account, nil
+	}
+}
+
+// MakeConvertTrialEndpoint creates ConvertTrial Endpoint
+func MakeConvertTrialEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ConvertTrialRequest)
+		account, err := svc.ConvertTrial(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return account, nil
+	}
+}
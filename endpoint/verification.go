@@ -0,0 +1,35 @@
+package endpoint
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeRequestEmailVerificationEndpoint creates RequestEmailVerification Endpoint
+func MakeRequestEmailVerificationEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.RequestEmailVerificationRequest)
+		verification, err := svc.RequestEmailVerification(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return verification, nil
+	}
+}
+
+// MakeConfirmEmailVerificationEndpoint creates ConfirmEmailVerification Endpoint
+func MakeConfirmEmailVerificationEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ConfirmEmailVerificationRequest)
+		user, err := svc.ConfirmEmailVerification(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		return user, nil
+	}
+}
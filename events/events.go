@@ -0,0 +1,44 @@
+// Package events publishes changes to customer records for other services to
+// react to.
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+// Event describes a change to a customer record.
+type Event struct {
+	// Type identifies the change, e.g. "user.transferred".
+	Type string `json:"type"`
+	// Subject is the id of the record which changed.
+	Subject    string            `json:"subject"`
+	Data       map[string]string `json:"data,omitempty"`
+	OccurredAt time.Time         `json:"occurred_at"`
+}
+
+// Publisher delivers events.
+type Publisher interface {
+	Publish(context.Context, Event) error
+}
+
+// NewLogPublisher returns a Publisher which writes events to the logger. It
+// is intended for local development, where no message broker is available.
+func NewLogPublisher(logger log.Logger) Publisher {
+	return &logPublisher{logger: log.With(logger, "component", "events")}
+}
+
+type logPublisher struct {
+	logger log.Logger
+}
+
+func (p *logPublisher) Publish(ctx context.Context, event Event) error {
+	keyvals := []interface{}{"level", "info", "message", "event", "type", event.Type, "subject", event.Subject, "occurred_at", event.OccurredAt}
+	for k, v := range event.Data {
+		keyvals = append(keyvals, k, v)
+	}
+
+	return p.logger.Log(keyvals...)
+}
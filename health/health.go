@@ -1 +1,135 @@
+// Package health exposes the standard grpc.health.v1.Health service, driven by
+// the same checkers that back the HTTP readiness probe.
 package health
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/symptomatichq/kit/health"
+)
+
+// Option configures optional behaviour of a Server.
+type Option func(*Server)
+
+// WithInterval sets how often the checkers are evaluated.
+func WithInterval(interval time.Duration) Option {
+	return func(s *Server) {
+		s.interval = interval
+	}
+}
+
+// WithService reports a status for the named gRPC service. The service is
+// SERVING only while every listed checker passes; when no checkers are listed
+// the service depends on all of them.
+func WithService(name string, checkers ...string) Option {
+	return func(s *Server) {
+		s.services[name] = checkers
+	}
+}
+
+// Server periodically evaluates a set of checkers and publishes the result
+// through the gRPC health service.
+type Server struct {
+	health   *grpchealth.Server
+	logger   log.Logger
+	interval time.Duration
+	checkers map[string]health.Checker
+	services map[string][]string
+
+	once sync.Once
+	stop chan struct{}
+}
+
+// NewServer creates a gRPC health server for the given checkers. The overall
+// server status (the empty service name) depends on every checker.
+func NewServer(logger log.Logger, checkers map[string]health.Checker, opts ...Option) *Server {
+	s := &Server{
+		health:   grpchealth.NewServer(),
+		logger:   log.With(logger, "component", "grpc-health"),
+		interval: 10 * time.Second,
+		checkers: checkers,
+		services: map[string][]string{},
+		stop:     make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Register attaches the health service to a gRPC server.
+func (s *Server) Register(srv *grpc.Server) {
+	healthpb.RegisterHealthServer(srv, s.health)
+}
+
+// Start evaluates the checkers once and then keeps them up to date in the
+// background until Stop is called.
+func (s *Server) Start() {
+	s.Check()
+
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.Check()
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the background checks and marks every service NOT_SERVING so
+// that clients drain before the server shuts down.
+func (s *Server) Stop() {
+	s.once.Do(func() {
+		close(s.stop)
+		s.health.Shutdown()
+	})
+}
+
+// Check evaluates every checker and updates the serving status of each service.
+func (s *Server) Check() {
+	failed := map[string]bool{}
+	for name, checker := range s.checkers {
+		if err := checker.HealthCheck(); err != nil {
+			s.logger.Log("level", "error", "message", "health check failed", "health-checker", name, "error", err.Error())
+			failed[name] = true
+		}
+	}
+
+	s.health.SetServingStatus("", servingStatus(len(failed) == 0))
+
+	for service, deps := range s.services {
+		healthy := len(failed) == 0
+		if len(deps) > 0 {
+			healthy = true
+			for _, dep := range deps {
+				if _, ok := s.checkers[dep]; !ok || failed[dep] {
+					healthy = false
+				}
+			}
+		}
+
+		s.health.SetServingStatus(service, servingStatus(healthy))
+	}
+}
+
+func servingStatus(healthy bool) healthpb.HealthCheckResponse_ServingStatus {
+	if healthy {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
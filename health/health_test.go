@@ -0,0 +1,55 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/symptomatichq/kit/health"
+)
+
+type checkerFunc func() error
+
+func (fn checkerFunc) HealthCheck() error {
+	return fn()
+}
+
+func TestCheck(t *testing.T) {
+	var dbErr error
+	checkers := map[string]health.Checker{
+		"noop": health.Nop(),
+		"db":   checkerFunc(func() error { return dbErr }),
+	}
+
+	s := NewServer(log.NewNopLogger(), checkers,
+		WithService("customers.Customers", "db"),
+		WithService("customers.Static", "noop"),
+	)
+
+	assertStatus := func(service string, want healthpb.HealthCheckResponse_ServingStatus) {
+		t.Helper()
+		resp, err := s.health.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatalf("check %q: %v", service, err)
+		}
+		if resp.Status != want {
+			t.Errorf("service %q: got %v, want %v", service, resp.Status, want)
+		}
+	}
+
+	s.Check()
+	assertStatus("", healthpb.HealthCheckResponse_SERVING)
+	assertStatus("customers.Customers", healthpb.HealthCheckResponse_SERVING)
+
+	dbErr = errors.New("connection refused")
+	s.Check()
+	assertStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	assertStatus("customers.Customers", healthpb.HealthCheckResponse_NOT_SERVING)
+	assertStatus("customers.Static", healthpb.HealthCheckResponse_SERVING)
+
+	s.Stop()
+	assertStatus("customers.Static", healthpb.HealthCheckResponse_NOT_SERVING)
+}
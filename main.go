@@ -5,10 +5,19 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"time"
 
+	"github.com/pkg/errors"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
 
+	"github.com/symptomatichq/customers/auth"
+	"github.com/symptomatichq/customers/authz"
 	"github.com/symptomatichq/customers/endpoint"
+	"github.com/symptomatichq/customers/events"
+	customerHealth "github.com/symptomatichq/customers/health"
+	"github.com/symptomatichq/customers/notify"
+	pb "github.com/symptomatichq/customers/protos/customers"
 	"github.com/symptomatichq/customers/service"
 	_ "github.com/symptomatichq/customers/telemetry" // load telemetry
 	"github.com/symptomatichq/customers/transport"
@@ -17,21 +26,76 @@ import (
 	"github.com/symptomatichq/kit/health"
 	"github.com/symptomatichq/kit/logutil"
 	"github.com/symptomatichq/kit/pgutil"
-	pb "github.com/symptomatichq/protos/customers"
 )
 
+// serviceName is the fully qualified name of the Customers gRPC service.
+const serviceName = "customers.Customers"
+
 var (
-	debug *bool
-	port  *int
+	debug      *bool
+	port       *int
+	reflective *bool
+
+	jwks          *string
+	issuer        *string
+	audience      *string
+	serviceTokens *string
+	policyFile    *string
+
+	notificationsFile    *string
+	requireVerifiedEmail *bool
+	searchBackend        *string
+	duplicatesInterval   *time.Duration
+	entitlementCacheTTL  *time.Duration
+	trialsInterval       *time.Duration
+	actionsInterval      *time.Duration
 )
 
 func main() {
 	port = flag.Int("port", env.Int("PORT", 8080), "GRPC server port")
 	debug = flag.Bool("debug", env.Bool("DEBUG", false), "run the server in debug mode")
+	reflective = flag.Bool("grpc.reflection", env.Bool("GRPC_REFLECTION", false), "enable gRPC server reflection")
+	jwks = flag.String("auth.jwks", env.String("AUTH_JWKS", ""), "path or URL of the JSON Web Key Set used to verify JWTs")
+	issuer = flag.String("auth.issuer", env.String("AUTH_ISSUER", ""), "required issuer of JWTs")
+	audience = flag.String("auth.audience", env.String("AUTH_AUDIENCE", ""), "required audience of JWTs")
+	serviceTokens = flag.String("auth.service-tokens", env.String("AUTH_SERVICE_TOKENS", ""), "path to a file of static service tokens")
+	policyFile = flag.String("authz.policy", env.String("AUTHZ_POLICY", ""), "path to a JSON authorization policy, defaults to the built-in policy")
+	notificationsFile = flag.String("notify.file", env.String("NOTIFY_FILE", ""), "append notifications to this file, required unless in debug mode")
+	requireVerifiedEmail = flag.Bool("users.require-verified-email", env.Bool("REQUIRE_VERIFIED_EMAIL", false), "keep users pending until they verify their email")
 
-	logger := logutil.NewServerLogger(*debug, "customers")
+	searchBackend = flag.String("search.backend", env.String("SEARCH_BACKEND", "postgres"), "customer search backend, either postgres or memory")
+
+	duplicatesInterval = flag.Duration("duplicates.interval", env.Duration("DUPLICATES_INTERVAL", 24*time.Hour), "how often to scan for duplicate accounts, 0 disables scanning")
 
+	trialsInterval = flag.Duration("trials.interval", env.Duration("TRIALS_INTERVAL", time.Hour), "how often to suspend expired trials, 0 disables expiry")
+
+	actionsInterval = flag.Duration("scheduled-actions.interval", env.Duration("SCHEDULED_ACTIONS_INTERVAL", time.Minute), "how often to run due scheduled status changes, 0 disables running them")
+
+	entitlementCacheTTL = flag.Duration("entitlements.cache-ttl", env.Duration("ENTITLEMENTS_CACHE_TTL", time.Minute), "how long resolved entitlements are cached, 0 disables caching")
+
+	// the database flags must be defined before parsing, although the
+	// configuration is read from their environment defaults
 	dbCfg := pgutil.ConfigFromEnv()
+
+	flag.Parse()
+
+	logger := logutil.NewServerLogger(*debug, "customers")
+
+	authenticator, err := newAuthenticator()
+	if err != nil {
+		logger.Log("level", "error", "message", "failed to configure authentication", "error", err.Error())
+		os.Exit(1)
+	}
+
+	policy := authz.DefaultPolicy
+	if *policyFile != "" {
+		policy, err = authz.LoadPolicy(*policyFile)
+		if err != nil {
+			logger.Log("level", "error", "message", "failed to load authorization policy", "error", err.Error())
+			os.Exit(1)
+		}
+	}
+
 	// err := dbutil.Migrate(dbCfg, "./migrations")
 	// if err != nil {
 	// 	logger.Log("level", "error", "message", "unable to execute database migrations", "error", err.Error())
@@ -40,16 +104,149 @@ func main() {
 	// 	logger.Log("level", "info", "message", "database migated to latest revision")
 	// }
 
-	repo := service.NewRepository(dbCfg)
-	svc := service.NewService(repo)
+	repo, err := service.NewRepository(dbCfg)
+	if err != nil {
+		logger.Log("level", "error", "message", "unable to open the database", "error", err.Error())
+		os.Exit(1)
+	}
+
+	var notifier notify.Notifier
+	switch {
+	case *notificationsFile != "":
+		notifier = notify.NewFileNotifier(*notificationsFile)
+	case *debug:
+		logger.Log("level", "warn", "message", "no notifier is configured, notifications will only be logged and cannot be delivered")
+		notifier = notify.NewLogNotifier(logger)
+	default:
+		logger.Log("level", "error", "message", "no notifier is configured, set notify.file or run in debug mode")
+		os.Exit(1)
+	}
+
+	var searcher service.Searcher = repo
+	switch *searchBackend {
+	case "postgres":
+	case "memory":
+		searcher = service.NewMemorySearcher(repo)
+	default:
+		logger.Log("level", "error", "message", "unknown search backend", "search-backend", *searchBackend)
+		os.Exit(1)
+	}
+
+	core := service.NewService(repo,
+		service.WithNotifier(notifier),
+		service.WithPublisher(events.NewLogPublisher(logger)),
+		service.WithVerifiedEmailRequired(*requireVerifiedEmail),
+		service.WithSearcher(searcher),
+		service.WithEntitlementCacheTTL(*entitlementCacheTTL),
+	)
+	svc := authz.NewService(core, policy, logger)
 
 	endpoints := endpoint.Endpoints{
 		CreateAccountEndpoint: transport.MakeGRPCCreateAccountEndpoint(svc),
 		GetAccountEndpoint:    transport.MakeGRPCGetAccountEndpoint(svc),
 		FetchAccountsEndpoint: transport.MakeGRPCFetchAccountsEndpoint(svc),
 		CreateUserEndpoint:    transport.MakeGRPCCreateUserEndpoint(svc),
+		UpdateUserEndpoint:    transport.MakeGRPCUpdateUserEndpoint(svc),
 		GetUserEndpoint:       transport.MakeGRPCGetUserEndpoint(svc),
 		FetchUsersEndpoint:    transport.MakeGRPCFetchUsersEndpoint(svc),
+
+		GetUserByEmailEndpoint:             transport.MakeGRPCGetUserByEmailEndpoint(svc),
+		FindAccountsByContactEmailEndpoint: transport.MakeGRPCFindAccountsByContactEmailEndpoint(svc),
+
+		GrantRoleEndpoint:        transport.MakeGRPCGrantRoleEndpoint(svc),
+		ChangeRoleEndpoint:       transport.MakeGRPCChangeRoleEndpoint(svc),
+		RevokeRoleEndpoint:       transport.MakeGRPCRevokeRoleEndpoint(svc),
+		FetchMembershipsEndpoint: transport.MakeGRPCFetchMembershipsEndpoint(svc),
+
+		ChangeMembershipStatusEndpoint: transport.MakeGRPCChangeMembershipStatusEndpoint(svc),
+		FetchUserAccountsEndpoint:      transport.MakeGRPCFetchUserAccountsEndpoint(svc),
+
+		InviteUserEndpoint:       transport.MakeGRPCInviteUserEndpoint(svc),
+		ListInvitationsEndpoint:  transport.MakeGRPCListInvitationsEndpoint(svc),
+		RevokeInvitationEndpoint: transport.MakeGRPCRevokeInvitationEndpoint(svc),
+		AcceptInvitationEndpoint: transport.MakeGRPCAcceptInvitationEndpoint(svc),
+
+		RequestEmailVerificationEndpoint: transport.MakeGRPCRequestEmailVerificationEndpoint(svc),
+		ConfirmEmailVerificationEndpoint: transport.MakeGRPCConfirmEmailVerificationEndpoint(svc),
+
+		SearchCustomersEndpoint: transport.MakeGRPCSearchCustomersEndpoint(svc),
+
+		ListDuplicateAccountsEndpoint: transport.MakeGRPCListDuplicateAccountsEndpoint(svc),
+		MergeAccountsEndpoint:         transport.MakeGRPCMergeAccountsEndpoint(svc),
+
+		TransferUserEndpoint: transport.MakeGRPCTransferUserEndpoint(svc),
+
+		InitiateOwnershipTransferEndpoint: transport.MakeGRPCInitiateOwnershipTransferEndpoint(svc),
+		AcceptOwnershipTransferEndpoint:   transport.MakeGRPCAcceptOwnershipTransferEndpoint(svc),
+		CancelOwnershipTransferEndpoint:   transport.MakeGRPCCancelOwnershipTransferEndpoint(svc),
+		ListOwnershipTransfersEndpoint:    transport.MakeGRPCListOwnershipTransfersEndpoint(svc),
+
+		SetAccountParentEndpoint:     transport.MakeGRPCSetAccountParentEndpoint(svc),
+		ListChildAccountsEndpoint:    transport.MakeGRPCListChildAccountsEndpoint(svc),
+		ListAncestorAccountsEndpoint: transport.MakeGRPCListAncestorAccountsEndpoint(svc),
+
+		CreateGroupEndpoint:       transport.MakeGRPCCreateGroupEndpoint(svc),
+		GetGroupEndpoint:          transport.MakeGRPCGetGroupEndpoint(svc),
+		ListGroupsEndpoint:        transport.MakeGRPCListGroupsEndpoint(svc),
+		UpdateGroupEndpoint:       transport.MakeGRPCUpdateGroupEndpoint(svc),
+		DeleteGroupEndpoint:       transport.MakeGRPCDeleteGroupEndpoint(svc),
+		AddGroupMemberEndpoint:    transport.MakeGRPCAddGroupMemberEndpoint(svc),
+		RemoveGroupMemberEndpoint: transport.MakeGRPCRemoveGroupMemberEndpoint(svc),
+		ListGroupMembersEndpoint:  transport.MakeGRPCListGroupMembersEndpoint(svc),
+
+		CreateAPIKeyEndpoint: transport.MakeGRPCCreateAPIKeyEndpoint(svc),
+		ListAPIKeysEndpoint:  transport.MakeGRPCListAPIKeysEndpoint(svc),
+		RotateAPIKeyEndpoint: transport.MakeGRPCRotateAPIKeyEndpoint(svc),
+		RevokeAPIKeyEndpoint: transport.MakeGRPCRevokeAPIKeyEndpoint(svc),
+		VerifyAPIKeyEndpoint: transport.MakeGRPCVerifyAPIKeyEndpoint(svc),
+
+		CreateServiceAccountEndpoint: transport.MakeGRPCCreateServiceAccountEndpoint(svc),
+		ListServiceAccountsEndpoint:  transport.MakeGRPCListServiceAccountsEndpoint(svc),
+		UpdateServiceAccountEndpoint: transport.MakeGRPCUpdateServiceAccountEndpoint(svc),
+		DeleteServiceAccountEndpoint: transport.MakeGRPCDeleteServiceAccountEndpoint(svc),
+
+		UpdateAccountEndpoint:       transport.MakeGRPCUpdateAccountEndpoint(svc),
+		ChangeAccountStatusEndpoint: transport.MakeGRPCChangeAccountStatusEndpoint(svc),
+
+		DefineCustomFieldEndpoint:    transport.MakeGRPCDefineCustomFieldEndpoint(svc),
+		ListCustomFieldsEndpoint:     transport.MakeGRPCListCustomFieldsEndpoint(svc),
+		UpdateCustomFieldEndpoint:    transport.MakeGRPCUpdateCustomFieldEndpoint(svc),
+		DeleteCustomFieldEndpoint:    transport.MakeGRPCDeleteCustomFieldEndpoint(svc),
+		SetCustomFieldValuesEndpoint: transport.MakeGRPCSetCustomFieldValuesEndpoint(svc),
+
+		CreatePlanEndpoint:                transport.MakeGRPCCreatePlanEndpoint(svc),
+		ListPlansEndpoint:                 transport.MakeGRPCListPlansEndpoint(svc),
+		UpdatePlanEndpoint:                transport.MakeGRPCUpdatePlanEndpoint(svc),
+		AssignPlanEndpoint:                transport.MakeGRPCAssignPlanEndpoint(svc),
+		ListAccountPlansEndpoint:          transport.MakeGRPCListAccountPlansEndpoint(svc),
+		SetEntitlementOverrideEndpoint:    transport.MakeGRPCSetEntitlementOverrideEndpoint(svc),
+		RemoveEntitlementOverrideEndpoint: transport.MakeGRPCRemoveEntitlementOverrideEndpoint(svc),
+		ListAccountEntitlementsEndpoint:   transport.MakeGRPCListAccountEntitlementsEndpoint(svc),
+		CheckEntitlementEndpoint:          transport.MakeGRPCCheckEntitlementEndpoint(svc),
+
+		GetAccountUsageEndpoint: transport.MakeGRPCGetAccountUsageEndpoint(svc),
+
+		ExtendTrialEndpoint:  transport.MakeGRPCExtendTrialEndpoint(svc),
+		ConvertTrialEndpoint: transport.MakeGRPCConvertTrialEndpoint(svc),
+
+		ScheduleStatusChangeEndpoint:  transport.MakeGRPCScheduleStatusChangeEndpoint(svc),
+		ListScheduledActionsEndpoint:  transport.MakeGRPCListScheduledActionsEndpoint(svc),
+		CancelScheduledActionEndpoint: transport.MakeGRPCCancelScheduledActionEndpoint(svc),
+
+		CreateFeatureFlagEndpoint:  transport.MakeGRPCCreateFeatureFlagEndpoint(svc),
+		ListFeatureFlagsEndpoint:   transport.MakeGRPCListFeatureFlagsEndpoint(svc),
+		UpdateFeatureFlagEndpoint:  transport.MakeGRPCUpdateFeatureFlagEndpoint(svc),
+		DeleteFeatureFlagEndpoint:  transport.MakeGRPCDeleteFeatureFlagEndpoint(svc),
+		SetFlagOverrideEndpoint:    transport.MakeGRPCSetFlagOverrideEndpoint(svc),
+		RemoveFlagOverrideEndpoint: transport.MakeGRPCRemoveFlagOverrideEndpoint(svc),
+		ListFlagOverridesEndpoint:  transport.MakeGRPCListFlagOverridesEndpoint(svc),
+		EvaluateFlagsEndpoint:      transport.MakeGRPCEvaluateFlagsEndpoint(svc),
+		WatchFlagsEndpoint:         transport.MakeGRPCWatchFlagsEndpoint(svc),
+
+		GetUserPreferencesEndpoint:       transport.MakeGRPCGetUserPreferencesEndpoint(svc),
+		UpdateUserPreferencesEndpoint:    transport.MakeGRPCUpdateUserPreferencesEndpoint(svc),
+		GetAccountPreferencesEndpoint:    transport.MakeGRPCGetAccountPreferencesEndpoint(svc),
+		UpdateAccountPreferencesEndpoint: transport.MakeGRPCUpdateAccountPreferencesEndpoint(svc),
 	}
 
 	addr, err := net.Listen("tcp", fmt.Sprintf(":%d", *port))
@@ -64,19 +261,89 @@ func main() {
 	}
 
 	handler := transport.NewGRPCServer(endpoints, logger)
-	gRPCServer := grpc.NewServer()
+	interceptor := auth.NewInterceptor(authenticator, logger, auth.DefaultPublicMethods...)
+	gRPCServer := grpc.NewServer(
+		grpc.UnaryInterceptor(interceptor.Unary()),
+		grpc.StreamInterceptor(interceptor.Stream()),
+	)
 
-	probe := health.NewServer(9090, logger, map[string]health.Checker{"noop": health.Nop()})
+	checkers := map[string]health.Checker{"noop": health.Nop()}
+
+	probe := health.NewServer(9090, logger, checkers)
 	probe.Start()
 
+	healthServer := customerHealth.NewServer(logger, checkers, customerHealth.WithService(serviceName))
+	healthServer.Start()
+
+	detector := service.NewDuplicateDetector(repo, logger, *duplicatesInterval)
+	if *duplicatesInterval > 0 {
+		detector.Start()
+	}
+
+	trials := service.NewTrialScheduler(repo, notifier, logger, *trialsInterval)
+	if *trialsInterval > 0 {
+		trials.Start()
+	}
+
+	// scheduled actions run as the service itself, bypassing authorization
+	actions := service.NewScheduledActionRunner(core, repo, logger, *actionsInterval)
+	if *actionsInterval > 0 {
+		actions.Start()
+	}
+
 	graceful.Handle(func(signal os.Signal) {
 		logger.Log("message", "shutting down server", "signal", signal.String())
+		detector.Stop()
+		trials.Stop()
+		actions.Stop()
+		healthServer.Stop()
 		probe.Stop()
 		gRPCServer.GracefulStop()
 	})
 
 	pb.RegisterCustomersServer(gRPCServer, handler)
+	healthServer.Register(gRPCServer)
+	if *reflective {
+		reflection.Register(gRPCServer)
+	}
 
 	logger.Log("message", "server started")
 	gRPCServer.Serve(addr)
 }
+
+// newAuthenticator builds the authenticator from the configured JWKS and
+// service tokens. At least one source of credentials must be configured.
+func newAuthenticator() (auth.Authenticator, error) {
+	var authenticators []auth.Authenticator
+
+	if *serviceTokens != "" {
+		tokens, err := auth.LoadServiceTokens(*serviceTokens)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, auth.NewServiceTokenAuthenticator(tokens))
+	}
+
+	if *jwks != "" {
+		// without both claims pinned any token signed by the identity
+		// provider, even one minted for another service, would be accepted
+		if *issuer == "" || *audience == "" {
+			return nil, errors.New("auth.issuer and auth.audience are required with a jwks")
+		}
+
+		keys, err := auth.LoadKeySet(*jwks)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, auth.NewJWTAuthenticator(keys,
+			auth.WithIssuer(*issuer),
+			auth.WithAudience(*audience),
+		))
+	}
+
+	if len(authenticators) == 0 {
+		return nil, errors.New("neither a jwks nor service tokens are configured")
+	}
+
+	return auth.Chain(authenticators...), nil
+}
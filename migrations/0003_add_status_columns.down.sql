@@ -0,0 +1,10 @@
+BEGIN;
+
+ALTER TABLE "users" DROP COLUMN "status";
+
+ALTER TABLE "accounts"
+    DROP COLUMN "created_at",
+    DROP COLUMN "updated_at",
+    DROP COLUMN "status";
+
+COMMIT;
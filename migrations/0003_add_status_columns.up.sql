@@ -0,0 +1,11 @@
+BEGIN;
+
+ALTER TABLE "accounts"
+    ADD COLUMN "status" VARCHAR(16) NOT NULL DEFAULT 'active',
+    ADD COLUMN "updated_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    ADD COLUMN "created_at" TIMESTAMP NOT NULL DEFAULT NOW();
+
+ALTER TABLE "users"
+    ADD COLUMN "status" VARCHAR(16) NOT NULL DEFAULT 'active';
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TRIGGER "trg_memberships_retain_owner" ON "memberships";
+DROP FUNCTION "memberships_retain_owner"();
+DROP TABLE "memberships";
+
+COMMIT;
@@ -0,0 +1,35 @@
+BEGIN;
+
+CREATE TABLE "memberships" (
+    "account_id" CHAR(26) NOT NULL REFERENCES "accounts" ON DELETE CASCADE,
+    "user_id" CHAR(26) NOT NULL REFERENCES "users" ON DELETE CASCADE,
+    "role" VARCHAR(16) NOT NULL CHECK ("role" IN ('owner', 'admin', 'member', 'billing', 'read_only')),
+    "updated_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY ("account_id", "user_id")
+);
+
+CREATE INDEX "idx_memberships_user_id" ON "memberships" ("user_id");
+CREATE INDEX "idx_memberships_owners" ON "memberships" ("account_id") WHERE "role" = 'owner';
+
+-- An active account must always retain at least one owner. The check runs at
+-- commit so that ownership can be handed over within a single transaction.
+CREATE FUNCTION "memberships_retain_owner"() RETURNS TRIGGER AS $$
+BEGIN
+    IF OLD."role" = 'owner'
+        AND EXISTS (SELECT 1 FROM "accounts" WHERE "id" = OLD."account_id" AND "status" = 'active')
+        AND NOT EXISTS (SELECT 1 FROM "memberships" WHERE "account_id" = OLD."account_id" AND "role" = 'owner')
+    THEN
+        RAISE EXCEPTION 'account % must retain at least one owner', OLD."account_id"
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER "trg_memberships_retain_owner"
+    AFTER UPDATE OR DELETE ON "memberships"
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE PROCEDURE "memberships_retain_owner"();
+
+COMMIT;
@@ -0,0 +1,33 @@
+BEGIN;
+
+ALTER TABLE "users"
+    ADD COLUMN "account_id" CHAR(26) REFERENCES "accounts";
+
+-- Users who belong to several accounts keep the one they joined first.
+UPDATE "users" SET "account_id" = "first"."account_id"
+FROM (
+    SELECT DISTINCT ON ("user_id") "user_id", "account_id"
+    FROM "memberships"
+    ORDER BY "user_id", "created_at", "account_id"
+) AS "first"
+WHERE "users"."id" = "first"."user_id";
+
+CREATE INDEX "idx_users_account_id" ON "users" ("account_id");
+
+CREATE OR REPLACE FUNCTION "memberships_retain_owner"() RETURNS TRIGGER AS $$
+BEGIN
+    IF OLD."role" = 'owner'
+        AND EXISTS (SELECT 1 FROM "accounts" WHERE "id" = OLD."account_id" AND "status" = 'active')
+        AND NOT EXISTS (SELECT 1 FROM "memberships" WHERE "account_id" = OLD."account_id" AND "role" = 'owner')
+    THEN
+        RAISE EXCEPTION 'account % must retain at least one owner', OLD."account_id"
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX "idx_memberships_account_id_status";
+ALTER TABLE "memberships" DROP COLUMN "status";
+
+COMMIT;
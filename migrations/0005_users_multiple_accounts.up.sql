@@ -0,0 +1,49 @@
+BEGIN;
+
+ALTER TABLE "memberships"
+    ADD COLUMN "status" VARCHAR(16) NOT NULL DEFAULT 'active';
+
+CREATE INDEX "idx_memberships_account_id_status" ON "memberships" ("account_id", "status");
+
+-- Suspended and inactive owners cannot manage the account, so an active
+-- account must retain an active owner.
+CREATE OR REPLACE FUNCTION "memberships_retain_owner"() RETURNS TRIGGER AS $$
+BEGIN
+    IF OLD."role" = 'owner' AND OLD."status" = 'active'
+        AND EXISTS (SELECT 1 FROM "accounts" WHERE "id" = OLD."account_id" AND "status" = 'active')
+        AND NOT EXISTS (
+            SELECT 1 FROM "memberships"
+            WHERE "account_id" = OLD."account_id" AND "role" = 'owner' AND "status" = 'active'
+        )
+    THEN
+        RAISE EXCEPTION 'account % must retain at least one active owner', OLD."account_id"
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Every user joins the account they were created in as a member.
+INSERT INTO "memberships" ("account_id", "user_id", "role", "status", "updated_at", "created_at")
+SELECT "account_id", "id", 'member', "status", "updated_at", "created_at"
+FROM "users"
+WHERE "account_id" IS NOT NULL
+ON CONFLICT ("account_id", "user_id") DO NOTHING;
+
+-- Every account must retain an owner, so the earliest created active user of
+-- each account without one is promoted.
+UPDATE "memberships" SET "role" = 'owner', "updated_at" = NOW()
+FROM (
+    SELECT DISTINCT ON ("account_id") "account_id", "user_id"
+    FROM "memberships"
+    WHERE "status" = 'active'
+        AND "account_id" NOT IN (SELECT "account_id" FROM "memberships" WHERE "role" = 'owner' AND "status" = 'active')
+    ORDER BY "account_id", "created_at", "user_id"
+) AS "earliest"
+WHERE "memberships"."account_id" = "earliest"."account_id"
+    AND "memberships"."user_id" = "earliest"."user_id";
+
+DROP INDEX "idx_users_account_id";
+ALTER TABLE "users" DROP COLUMN "account_id";
+
+COMMIT;
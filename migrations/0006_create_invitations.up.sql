@@ -0,0 +1,25 @@
+BEGIN;
+
+CREATE TABLE "invitations" (
+    "id" CHAR(26) PRIMARY KEY,
+    "account_id" CHAR(26) NOT NULL REFERENCES "accounts" ON DELETE CASCADE,
+    "email" VARCHAR(255) NOT NULL,
+    "name" VARCHAR(255) NOT NULL DEFAULT '',
+    "role" VARCHAR(16) NOT NULL CHECK ("role" IN ('owner', 'admin', 'member', 'billing', 'read_only')),
+    "status" VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK ("status" IN ('pending', 'accepted', 'revoked')),
+    "token_hash" CHAR(64) NOT NULL,
+    "invited_by" VARCHAR(255) NOT NULL DEFAULT '',
+    "user_id" CHAR(26) NULL REFERENCES "users" ON DELETE SET NULL,
+    "expires_at" TIMESTAMP NOT NULL,
+    "accepted_at" TIMESTAMP NULL,
+    "updated_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX "uidx_invitations_token_hash" ON "invitations" ("token_hash");
+CREATE INDEX "idx_invitations_account_id_status" ON "invitations" ("account_id", "status");
+
+-- re-inviting an address reuses its pending invitation
+CREATE UNIQUE INDEX "uidx_invitations_pending" ON "invitations" ("account_id", "email") WHERE "status" = 'pending';
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE "email_verifications";
+ALTER TABLE "users" DROP COLUMN "verified_at";
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+
+ALTER TABLE "users" ADD COLUMN "verified_at" TIMESTAMP NULL;
+
+CREATE TABLE "email_verifications" (
+    "id" CHAR(26) PRIMARY KEY,
+    "user_id" CHAR(26) NOT NULL REFERENCES "users" ON DELETE CASCADE,
+    "email" VARCHAR(255) NOT NULL,
+    "token_hash" CHAR(64) NOT NULL,
+    "expires_at" TIMESTAMP NOT NULL,
+    "consumed_at" TIMESTAMP NULL,
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX "uidx_email_verifications_token_hash" ON "email_verifications" ("token_hash");
+CREATE INDEX "idx_email_verifications_user_id_created_at" ON "email_verifications" ("user_id", "created_at");
+
+COMMIT;
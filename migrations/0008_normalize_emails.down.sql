@@ -0,0 +1,12 @@
+BEGIN;
+
+ALTER TABLE "invitations" ALTER COLUMN "email" TYPE VARCHAR(255);
+
+DROP INDEX "idx_accounts_contact_email";
+ALTER TABLE "accounts" ALTER COLUMN "contact_email" TYPE VARCHAR(255);
+
+DROP INDEX "idx_users_email_canonical";
+ALTER TABLE "users" DROP COLUMN "email_canonical";
+ALTER TABLE "users" ALTER COLUMN "email" TYPE VARCHAR(255);
+
+COMMIT;
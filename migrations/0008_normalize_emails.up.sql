@@ -0,0 +1,83 @@
+BEGIN;
+
+CREATE EXTENSION IF NOT EXISTS "citext";
+
+-- Addresses differing only in case or surrounding whitespace belong to the
+-- same person. Refuse to migrate while such duplicates exist, listing them so
+-- they can be merged or corrected first.
+DO $$
+DECLARE
+    collisions TEXT;
+BEGIN
+    SELECT string_agg(format('%s (users %s)', "normalized", "ids"), '; ')
+    INTO collisions
+    FROM (
+        SELECT lower(trim("email")) AS "normalized", string_agg(trim("id"), ', ' ORDER BY "created_at") AS "ids"
+        FROM "users"
+        GROUP BY 1
+        HAVING count(*) > 1
+    ) AS "duplicates";
+
+    IF collisions IS NOT NULL THEN
+        RAISE EXCEPTION 'users share an email address when case is ignored: %', collisions
+            USING HINT = 'merge or rename the listed users and run the migration again';
+    END IF;
+END $$;
+
+-- emailutil.Normalize also converts internationalized domains to punycode
+-- and drops a trailing dot, which SQL cannot reproduce. Refuse to migrate
+-- while any address needs either, listing them so they can be rewritten in
+-- their normalized form first; the check compares byte and character
+-- lengths to find non-ASCII domains.
+DO $$
+DECLARE
+    unnormalizable TEXT;
+BEGIN
+    SELECT string_agg(format('%s %s (%s)', "kind", trim("id"), "address"), '; ')
+    INTO unnormalizable
+    FROM (
+        SELECT 'user' AS "kind", "id", "email" AS "address" FROM "users"
+        UNION ALL
+        SELECT 'account', "id", "contact_email" FROM "accounts"
+        UNION ALL
+        SELECT 'invitation', "id", "email" FROM "invitations"
+    ) AS "addresses"
+    CROSS JOIN LATERAL (
+        SELECT substring(trim("address") FROM '@([^@]*)$') AS "domain"
+    ) AS "parts"
+    WHERE octet_length("domain") <> char_length("domain") OR "domain" LIKE '%.';
+
+    IF unnormalizable IS NOT NULL THEN
+        RAISE EXCEPTION 'email addresses need normalizing outside of SQL: %', unnormalizable
+            USING HINT = 'rewrite the listed addresses with emailutil.Normalize and run the migration again';
+    END IF;
+END $$;
+
+UPDATE "users" SET "email" = lower(trim("email"));
+ALTER TABLE "users" ALTER COLUMN "email" TYPE CITEXT;
+
+-- The canonical form applies provider rules (see emailutil.Canonical) and is
+-- only used for lookups, so it is indexed but not unique.
+ALTER TABLE "users" ADD COLUMN "email_canonical" CITEXT;
+
+UPDATE "users" SET "email_canonical" = CASE
+    WHEN split_part("email", '@', 2) IN ('gmail.com', 'googlemail.com') THEN
+        replace(split_part(split_part("email", '@', 1), '+', 1), '.', '') || '@gmail.com'
+    WHEN split_part("email", '@', 2) IN ('outlook.com', 'hotmail.com', 'live.com', 'icloud.com', 'fastmail.com', 'protonmail.com') THEN
+        split_part(split_part("email", '@', 1), '+', 1) || '@' || split_part("email", '@', 2)
+    WHEN split_part("email", '@', 2) = 'me.com' THEN
+        split_part(split_part("email", '@', 1), '+', 1) || '@icloud.com'
+    ELSE "email"
+END;
+
+ALTER TABLE "users" ALTER COLUMN "email_canonical" SET NOT NULL;
+CREATE INDEX "idx_users_email_canonical" ON "users" ("email_canonical");
+
+UPDATE "accounts" SET "contact_email" = lower(trim("contact_email"));
+ALTER TABLE "accounts" ALTER COLUMN "contact_email" TYPE CITEXT;
+CREATE INDEX "idx_accounts_contact_email" ON "accounts" ("contact_email");
+
+UPDATE "invitations" SET "email" = lower(trim("email"));
+ALTER TABLE "invitations" ALTER COLUMN "email" TYPE CITEXT;
+
+COMMIT;
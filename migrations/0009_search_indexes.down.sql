@@ -0,0 +1,11 @@
+BEGIN;
+
+DROP INDEX "idx_users_search";
+DROP INDEX "idx_accounts_search";
+
+DROP INDEX "idx_users_email_trgm";
+DROP INDEX "idx_users_name_trgm";
+DROP INDEX "idx_accounts_contact_email_trgm";
+DROP INDEX "idx_accounts_name_trgm";
+
+COMMIT;
@@ -0,0 +1,22 @@
+BEGIN;
+
+CREATE EXTENSION IF NOT EXISTS "pg_trgm";
+
+-- Trigram indexes serve partial and misspelled matches through the % and
+-- similarity operators. Email columns are citext, so they are indexed as text
+-- and must be queried with the same cast.
+CREATE INDEX "idx_accounts_name_trgm" ON "accounts" USING GIN ("name" gin_trgm_ops);
+CREATE INDEX "idx_accounts_contact_email_trgm" ON "accounts" USING GIN (("contact_email"::TEXT) gin_trgm_ops);
+CREATE INDEX "idx_users_name_trgm" ON "users" USING GIN ("name" gin_trgm_ops);
+CREATE INDEX "idx_users_email_trgm" ON "users" USING GIN (("email"::TEXT) gin_trgm_ops);
+
+-- Full-text indexes serve whole-word matches and ts_rank ordering. The simple
+-- configuration is used because names should not be stemmed.
+CREATE INDEX "idx_accounts_search" ON "accounts" USING GIN (
+    to_tsvector('simple', coalesce("name", '') || ' ' || coalesce("contact_email"::TEXT, ''))
+);
+CREATE INDEX "idx_users_search" ON "users" USING GIN (
+    to_tsvector('simple', coalesce("name", '') || ' ' || coalesce("email"::TEXT, ''))
+);
+
+COMMIT;
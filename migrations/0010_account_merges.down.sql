@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP TABLE "account_merges";
+DROP TABLE "account_duplicates";
+
+ALTER TABLE "accounts" DROP CONSTRAINT "chk_accounts_merged_into";
+ALTER TABLE "accounts" DROP COLUMN "merged_into";
+
+COMMIT;
@@ -0,0 +1,35 @@
+BEGIN;
+
+-- merged accounts are kept so that their ids redirect to the survivor
+ALTER TABLE "accounts" ADD COLUMN "merged_into" CHAR(26) NULL REFERENCES "accounts";
+ALTER TABLE "accounts" ADD CONSTRAINT "chk_accounts_merged_into"
+    CHECK (("status" = 'merged') = ("merged_into" IS NOT NULL));
+
+CREATE TABLE "account_duplicates" (
+    "account_id" CHAR(26) NOT NULL REFERENCES "accounts" ON DELETE CASCADE,
+    "duplicate_id" CHAR(26) NOT NULL REFERENCES "accounts" ON DELETE CASCADE,
+    "score" REAL NOT NULL,
+    "reasons" TEXT[] NOT NULL DEFAULT '{}',
+    "status" VARCHAR(16) NOT NULL DEFAULT 'open' CHECK ("status" IN ('open', 'dismissed', 'merged')),
+    "updated_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY ("account_id", "duplicate_id"),
+    CHECK ("account_id" < "duplicate_id")
+);
+
+CREATE INDEX "idx_account_duplicates_duplicate_id" ON "account_duplicates" ("duplicate_id");
+CREATE INDEX "idx_account_duplicates_status_score" ON "account_duplicates" ("status", "score" DESC);
+
+CREATE TABLE "account_merges" (
+    "id" CHAR(26) PRIMARY KEY,
+    "source_id" CHAR(26) NOT NULL REFERENCES "accounts",
+    "target_id" CHAR(26) NOT NULL REFERENCES "accounts",
+    "merged_by" VARCHAR(255) NOT NULL DEFAULT '',
+    "moved_users" INTEGER NOT NULL DEFAULT 0,
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX "idx_account_merges_source_id" ON "account_merges" ("source_id");
+CREATE INDEX "idx_account_merges_target_id" ON "account_merges" ("target_id");
+
+COMMIT;
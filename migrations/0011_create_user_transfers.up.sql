@@ -0,0 +1,18 @@
+BEGIN;
+
+-- user_transfers keeps the history of users moved between accounts, so rows
+-- outlive the accounts and users they refer to.
+CREATE TABLE "user_transfers" (
+    "id" CHAR(26) PRIMARY KEY,
+    "user_id" CHAR(26) NOT NULL,
+    "from_account_id" CHAR(26) NOT NULL,
+    "to_account_id" CHAR(26) NOT NULL,
+    "previous_role" VARCHAR(16) NOT NULL,
+    "role" VARCHAR(16) NOT NULL,
+    "transferred_by" VARCHAR(255) NOT NULL DEFAULT '',
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX "idx_user_transfers_user_id" ON "user_transfers" ("user_id", "created_at");
+
+COMMIT;
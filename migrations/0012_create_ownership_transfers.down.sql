@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE "ownership_transfers";
+
+COMMIT;
@@ -0,0 +1,25 @@
+BEGIN;
+
+CREATE TABLE "ownership_transfers" (
+    "id" CHAR(26) PRIMARY KEY,
+    "account_id" CHAR(26) NOT NULL REFERENCES "accounts" ON DELETE CASCADE,
+    "from_user_id" CHAR(26) NOT NULL,
+    "to_user_id" CHAR(26) NOT NULL,
+    "status" VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK ("status" IN ('pending', 'accepted', 'cancelled', 'expired')),
+    "initiated_by" VARCHAR(255) NOT NULL DEFAULT '',
+    "override" BOOLEAN NOT NULL DEFAULT FALSE,
+    "cancelled_by" VARCHAR(255) NOT NULL DEFAULT '',
+    "expires_at" TIMESTAMP NOT NULL,
+    "accepted_at" TIMESTAMP NULL,
+    "cancelled_at" TIMESTAMP NULL,
+    "updated_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK ("from_user_id" <> "to_user_id")
+);
+
+CREATE INDEX "idx_ownership_transfers_account_id" ON "ownership_transfers" ("account_id", "created_at");
+
+-- an account may only have one transfer awaiting acceptance
+CREATE UNIQUE INDEX "uidx_ownership_transfers_pending" ON "ownership_transfers" ("account_id") WHERE "status" = 'pending';
+
+COMMIT;
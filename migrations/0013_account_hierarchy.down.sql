@@ -0,0 +1,10 @@
+BEGIN;
+
+DROP TRIGGER "trg_accounts_prevent_cycles" ON "accounts";
+DROP FUNCTION "accounts_prevent_cycles"();
+
+DROP INDEX "idx_accounts_parent_id";
+ALTER TABLE "accounts" DROP CONSTRAINT "chk_accounts_parent_id";
+ALTER TABLE "accounts" DROP COLUMN "parent_id";
+
+COMMIT;
@@ -0,0 +1,31 @@
+BEGIN;
+
+ALTER TABLE "accounts" ADD COLUMN "parent_id" CHAR(26) NULL REFERENCES "accounts" ON DELETE SET NULL;
+ALTER TABLE "accounts" ADD CONSTRAINT "chk_accounts_parent_id" CHECK ("parent_id" <> "id");
+CREATE INDEX "idx_accounts_parent_id" ON "accounts" ("parent_id");
+
+-- Refuse parents which would make an account its own ancestor. The service
+-- checks the same, this also guards changes made directly in the database.
+CREATE FUNCTION "accounts_prevent_cycles"() RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW."parent_id" IS NOT NULL AND EXISTS (
+        WITH RECURSIVE "ancestors" ("id") AS (
+            SELECT NEW."parent_id"
+            UNION
+            SELECT "accounts"."parent_id" FROM "accounts" JOIN "ancestors" ON "accounts"."id" = "ancestors"."id"
+            WHERE "accounts"."parent_id" IS NOT NULL
+        )
+        SELECT 1 FROM "ancestors" WHERE "id" = NEW."id"
+    ) THEN
+        RAISE EXCEPTION 'account % cannot be a descendant of itself', NEW."id"
+            USING ERRCODE = 'check_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "trg_accounts_prevent_cycles"
+    BEFORE INSERT OR UPDATE OF "parent_id" ON "accounts"
+    FOR EACH ROW EXECUTE PROCEDURE "accounts_prevent_cycles"();
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE "group_members";
+DROP TABLE "groups";
+
+COMMIT;
@@ -0,0 +1,36 @@
+BEGIN;
+
+CREATE TABLE "groups" (
+    "id" CHAR(26) PRIMARY KEY,
+    "account_id" CHAR(26) NOT NULL REFERENCES "accounts" ON DELETE CASCADE,
+    "parent_id" CHAR(26) NULL,
+    "name" CITEXT NOT NULL,
+    "description" TEXT NOT NULL DEFAULT '',
+    "updated_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE ("id", "account_id"),
+    CHECK ("parent_id" <> "id")
+);
+
+-- nested groups must belong to the same account as their parent
+ALTER TABLE "groups" ADD CONSTRAINT "fk_groups_parent"
+    FOREIGN KEY ("parent_id", "account_id") REFERENCES "groups" ("id", "account_id");
+
+CREATE UNIQUE INDEX "uidx_groups_account_id_name" ON "groups" ("account_id", "name");
+CREATE INDEX "idx_groups_parent_id" ON "groups" ("parent_id");
+
+-- Group members must be members of the group's account, and leave its groups
+-- when their membership ends.
+CREATE TABLE "group_members" (
+    "group_id" CHAR(26) NOT NULL,
+    "account_id" CHAR(26) NOT NULL,
+    "user_id" CHAR(26) NOT NULL,
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY ("group_id", "user_id"),
+    FOREIGN KEY ("group_id", "account_id") REFERENCES "groups" ("id", "account_id") ON DELETE CASCADE,
+    FOREIGN KEY ("account_id", "user_id") REFERENCES "memberships" ("account_id", "user_id") ON DELETE CASCADE
+);
+
+CREATE INDEX "idx_group_members_user_id" ON "group_members" ("user_id");
+
+COMMIT;
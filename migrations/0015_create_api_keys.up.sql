@@ -0,0 +1,25 @@
+BEGIN;
+
+CREATE TABLE "api_keys" (
+    "id" CHAR(26) PRIMARY KEY,
+    "account_id" CHAR(26) NOT NULL REFERENCES "accounts" ON DELETE CASCADE,
+    "name" VARCHAR(255) NOT NULL,
+    "prefix" CHAR(12) NOT NULL,
+    "salt" VARCHAR(32) NOT NULL,
+    "hash" CHAR(64) NOT NULL,
+    "scopes" TEXT[] NOT NULL DEFAULT '{}',
+    "status" VARCHAR(16) NOT NULL DEFAULT 'active' CHECK ("status" IN ('active', 'revoked')),
+    "created_by" VARCHAR(255) NOT NULL DEFAULT '',
+    "replaced_by" CHAR(26) NULL REFERENCES "api_keys" ON DELETE SET NULL,
+    "expires_at" TIMESTAMP NULL,
+    "last_used_at" TIMESTAMP NULL,
+    "revoked_at" TIMESTAMP NULL,
+    "updated_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- keys are verified by looking up their public prefix
+CREATE UNIQUE INDEX "uidx_api_keys_prefix" ON "api_keys" ("prefix");
+CREATE INDEX "idx_api_keys_account_id" ON "api_keys" ("account_id", "status");
+
+COMMIT;
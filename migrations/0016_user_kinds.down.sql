@@ -0,0 +1,12 @@
+BEGIN;
+
+DELETE FROM "users" WHERE "kind" = 'service';
+
+DROP INDEX "uidx_users_email";
+CREATE UNIQUE INDEX "uidx_users_name" ON "users" ("email");
+ALTER TABLE "users" DROP CONSTRAINT "chk_users_email";
+
+DROP INDEX "idx_users_kind";
+ALTER TABLE "users" DROP COLUMN "kind";
+
+COMMIT;
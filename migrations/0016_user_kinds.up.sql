@@ -0,0 +1,12 @@
+BEGIN;
+
+ALTER TABLE "users" ADD COLUMN "kind" VARCHAR(16) NOT NULL DEFAULT 'human' CHECK ("kind" IN ('human', 'service'));
+CREATE INDEX "idx_users_kind" ON "users" ("kind");
+
+-- Service accounts need no email address. Those without one store an empty
+-- string, which must not collide with other service accounts.
+ALTER TABLE "users" ADD CONSTRAINT "chk_users_email" CHECK ("kind" = 'service' OR "email" <> '');
+DROP INDEX "uidx_users_name";
+CREATE UNIQUE INDEX "uidx_users_email" ON "users" ("email") WHERE "email" <> '';
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+DROP INDEX "idx_users_metadata";
+DROP INDEX "idx_accounts_metadata";
+DROP INDEX "idx_users_labels";
+DROP INDEX "idx_accounts_labels";
+
+ALTER TABLE "users" DROP COLUMN "metadata", DROP COLUMN "labels";
+ALTER TABLE "accounts" DROP COLUMN "metadata", DROP COLUMN "labels";
+
+COMMIT;
@@ -0,0 +1,20 @@
+BEGIN;
+
+ALTER TABLE "accounts"
+    ADD COLUMN "labels" JSONB NOT NULL DEFAULT '{}' CHECK (jsonb_typeof("labels") = 'object'),
+    ADD COLUMN "metadata" JSONB NOT NULL DEFAULT '{}' CHECK (jsonb_typeof("metadata") = 'object' AND octet_length("metadata"::TEXT) <= 32768);
+
+ALTER TABLE "users"
+    ADD COLUMN "labels" JSONB NOT NULL DEFAULT '{}' CHECK (jsonb_typeof("labels") = 'object'),
+    ADD COLUMN "metadata" JSONB NOT NULL DEFAULT '{}' CHECK (jsonb_typeof("metadata") = 'object' AND octet_length("metadata"::TEXT) <= 32768);
+
+-- label selectors use both containment and key existence, which only the
+-- default operator class supports
+CREATE INDEX "idx_accounts_labels" ON "accounts" USING GIN ("labels");
+CREATE INDEX "idx_users_labels" ON "users" USING GIN ("labels");
+
+-- metadata is only ever queried by containment
+CREATE INDEX "idx_accounts_metadata" ON "accounts" USING GIN ("metadata" jsonb_path_ops);
+CREATE INDEX "idx_users_metadata" ON "users" USING GIN ("metadata" jsonb_path_ops);
+
+COMMIT;
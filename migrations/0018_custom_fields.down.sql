@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX "idx_memberships_custom_fields";
+ALTER TABLE "memberships" DROP COLUMN "custom_fields";
+
+DROP TABLE "custom_fields";
+
+COMMIT;
@@ -0,0 +1,22 @@
+BEGIN;
+
+CREATE TABLE "custom_fields" (
+    "id" CHAR(26) PRIMARY KEY,
+    "account_id" CHAR(26) NOT NULL REFERENCES "accounts" ON DELETE CASCADE,
+    "key" VARCHAR(63) NOT NULL CHECK ("key" ~ '^[a-z][a-z0-9_]*$'),
+    "label" VARCHAR(255) NOT NULL,
+    "type" VARCHAR(16) NOT NULL CHECK ("type" IN ('string', 'number', 'boolean', 'date', 'enum')),
+    "required" BOOLEAN NOT NULL DEFAULT FALSE,
+    "enum_values" TEXT[] NOT NULL DEFAULT '{}',
+    "pattern" TEXT NOT NULL DEFAULT '',
+    "updated_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX "uidx_custom_fields_account_id_key" ON "custom_fields" ("account_id", "key");
+
+-- values are kept with the membership, as fields belong to the account
+ALTER TABLE "memberships" ADD COLUMN "custom_fields" JSONB NOT NULL DEFAULT '{}' CHECK (jsonb_typeof("custom_fields") = 'object');
+CREATE INDEX "idx_memberships_custom_fields" ON "memberships" USING GIN ("custom_fields" jsonb_path_ops);
+
+COMMIT;
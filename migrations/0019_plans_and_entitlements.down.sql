@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE "entitlement_overrides";
+DROP TABLE "account_plans";
+DROP TABLE "plans";
+
+COMMIT;
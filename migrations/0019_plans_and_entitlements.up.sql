@@ -0,0 +1,42 @@
+BEGIN;
+
+CREATE TABLE "plans" (
+    "id" CHAR(26) PRIMARY KEY,
+    "key" VARCHAR(63) NOT NULL,
+    "name" VARCHAR(255) NOT NULL,
+    "entitlements" JSONB NOT NULL DEFAULT '[]' CHECK (jsonb_typeof("entitlements") = 'array'),
+    "archived" BOOLEAN NOT NULL DEFAULT FALSE,
+    "updated_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX "uidx_plans_key" ON "plans" ("key");
+
+-- assignments are never updated or deleted and so record the account's
+-- billing history
+CREATE TABLE "account_plans" (
+    "id" CHAR(26) PRIMARY KEY,
+    "account_id" CHAR(26) NOT NULL REFERENCES "accounts" ON DELETE CASCADE,
+    "plan_id" CHAR(26) NOT NULL REFERENCES "plans",
+    "starts_at" TIMESTAMP NOT NULL,
+    "ends_at" TIMESTAMP NULL CHECK ("ends_at" > "starts_at"),
+    "assigned_by" VARCHAR(255) NOT NULL DEFAULT '',
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX "idx_account_plans_account_id" ON "account_plans" ("account_id", "starts_at");
+
+CREATE TABLE "entitlement_overrides" (
+    "account_id" CHAR(26) NOT NULL REFERENCES "accounts" ON DELETE CASCADE,
+    "key" VARCHAR(63) NOT NULL,
+    "enabled" BOOLEAN NOT NULL,
+    "limit" BIGINT NULL CHECK ("limit" >= 0),
+    "reason" TEXT NOT NULL DEFAULT '',
+    "expires_at" TIMESTAMP NULL,
+    "created_by" VARCHAR(255) NOT NULL DEFAULT '',
+    "updated_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY ("account_id", "key")
+);
+
+COMMIT;
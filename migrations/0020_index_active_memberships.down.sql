@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP INDEX "idx_memberships_active";
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+-- seats are counted whenever a member is added to an account with a seat limit
+CREATE INDEX "idx_memberships_active" ON "memberships" ("account_id") WHERE "status" = 'active';
+
+COMMIT;
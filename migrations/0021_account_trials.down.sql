@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP INDEX "idx_accounts_trial_ends_at";
+ALTER TABLE "accounts"
+    DROP COLUMN "trial_converted_at",
+    DROP COLUMN "trial_ends_at",
+    DROP COLUMN "trial_started_at";
+
+COMMIT;
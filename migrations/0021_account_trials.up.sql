@@ -0,0 +1,11 @@
+BEGIN;
+
+ALTER TABLE "accounts"
+    ADD COLUMN "trial_started_at" TIMESTAMP NULL,
+    ADD COLUMN "trial_ends_at" TIMESTAMP NULL,
+    ADD COLUMN "trial_converted_at" TIMESTAMP NULL;
+
+-- the trial scheduler looks for active trials which have ended
+CREATE INDEX "idx_accounts_trial_ends_at" ON "accounts" ("trial_ends_at") WHERE "trial_ends_at" IS NOT NULL AND "status" = 'active';
+
+COMMIT;
@@ -0,0 +1,26 @@
+BEGIN;
+
+CREATE TABLE "scheduled_actions" (
+    "id" CHAR(26) PRIMARY KEY,
+    "account_id" CHAR(26) NOT NULL REFERENCES "accounts" ON DELETE CASCADE,
+    "user_id" VARCHAR(26) NOT NULL DEFAULT '',
+    "to_status" VARCHAR(16) NOT NULL,
+    "status" VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK ("status" IN ('pending', 'completed', 'failed', 'cancelled')),
+    "run_at" TIMESTAMP NOT NULL,
+    "attempts" INTEGER NOT NULL DEFAULT 0,
+    "last_error" TEXT NOT NULL DEFAULT '',
+    "locked_until" TIMESTAMP NULL,
+    "scheduled_by" VARCHAR(255) NOT NULL DEFAULT '',
+    "cancelled_by" VARCHAR(255) NOT NULL DEFAULT '',
+    "completed_at" TIMESTAMP NULL,
+    "cancelled_at" TIMESTAMP NULL,
+    "updated_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX "idx_scheduled_actions_account_id" ON "scheduled_actions" ("account_id", "run_at");
+
+-- runners look for pending actions which are due
+CREATE INDEX "idx_scheduled_actions_due" ON "scheduled_actions" ("run_at") WHERE "status" = 'pending';
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE "feature_flag_overrides";
+DROP TABLE "feature_flags";
+
+COMMIT;
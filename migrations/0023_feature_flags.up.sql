@@ -0,0 +1,28 @@
+BEGIN;
+
+CREATE TABLE "feature_flags" (
+    "id" CHAR(26) PRIMARY KEY,
+    "key" VARCHAR(63) NOT NULL,
+    "description" TEXT NOT NULL DEFAULT '',
+    "enabled" BOOLEAN NOT NULL DEFAULT FALSE,
+    "percentage" SMALLINT NOT NULL DEFAULT 0 CHECK ("percentage" BETWEEN 0 AND 100),
+    "updated_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX "uidx_feature_flags_key" ON "feature_flags" ("key");
+
+-- overrides with an empty user id apply to the whole account
+CREATE TABLE "feature_flag_overrides" (
+    "flag_key" VARCHAR(63) NOT NULL REFERENCES "feature_flags" ("key") ON DELETE CASCADE,
+    "account_id" CHAR(26) NOT NULL REFERENCES "accounts" ON DELETE CASCADE,
+    "user_id" VARCHAR(26) NOT NULL DEFAULT '',
+    "enabled" BOOLEAN NOT NULL,
+    "percentage" SMALLINT NOT NULL DEFAULT 0 CHECK ("percentage" BETWEEN 0 AND 100),
+    "created_by" VARCHAR(255) NOT NULL DEFAULT '',
+    "updated_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    "created_at" TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY ("account_id", "user_id", "flag_key")
+);
+
+COMMIT;
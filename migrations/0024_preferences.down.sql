@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE "users" DROP COLUMN "preferences";
+ALTER TABLE "accounts" DROP COLUMN "default_preferences";
+
+COMMIT;
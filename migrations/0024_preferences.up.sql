@@ -0,0 +1,7 @@
+BEGIN;
+
+-- preferences left out of the documents are inherited
+ALTER TABLE "accounts" ADD COLUMN "default_preferences" JSONB NOT NULL DEFAULT '{}' CHECK (jsonb_typeof("default_preferences") = 'object');
+ALTER TABLE "users" ADD COLUMN "preferences" JSONB NOT NULL DEFAULT '{}' CHECK (jsonb_typeof("preferences") = 'object');
+
+COMMIT;
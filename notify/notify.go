@@ -0,0 +1,80 @@
+// Package notify delivers messages, such as verification emails, to customers.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+)
+
+// Message is a notification addressed to a single recipient.
+type Message struct {
+	// Kind identifies the template the message is rendered with, e.g.
+	// "email_verification".
+	Kind    string            `json:"kind"`
+	To      string            `json:"to"`
+	Subject string            `json:"subject"`
+	Data    map[string]string `json:"data,omitempty"`
+}
+
+// Notifier delivers messages.
+type Notifier interface {
+	Notify(context.Context, Message) error
+}
+
+// NewLogNotifier returns a Notifier which writes messages to the logger. It
+// is intended for local development, where no mail service is available.
+// Message data is not logged, as it may hold secrets such as verification
+// tokens, so the messages cannot actually be acted on.
+func NewLogNotifier(logger log.Logger) Notifier {
+	return &logNotifier{logger: log.With(logger, "component", "notify")}
+}
+
+type logNotifier struct {
+	logger log.Logger
+}
+
+func (n *logNotifier) Notify(ctx context.Context, msg Message) error {
+	return n.logger.Log("level", "info", "message", "notification", "kind", msg.Kind, "to", msg.To, "subject", msg.Subject)
+}
+
+// NewFileNotifier returns a Notifier which appends each message as a line of
+// JSON to the file at path, creating it if necessary.
+func NewFileNotifier(path string) Notifier {
+	return &fileNotifier{path: path}
+}
+
+type fileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+func (n *fileNotifier) Notify(ctx context.Context, msg Message) error {
+	line, err := json.Marshal(struct {
+		Message
+		SentAt time.Time `json:"sent_at"`
+	}{msg, time.Now().UTC()})
+	if err != nil {
+		return errors.Wrap(err, "encoding notification")
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "opening notification file %s", n.path)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return errors.Wrapf(err, "writing notification file %s", n.path)
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+)
+
+func TestLogNotifierRedactsData(t *testing.T) {
+	var buf bytes.Buffer
+	notifier := NewLogNotifier(log.NewLogfmtLogger(&buf))
+
+	err := notifier.Notify(context.Background(), Message{
+		Kind:    "email_verification",
+		To:      "alice@example.com",
+		Subject: "Verify your email address",
+		Data:    map[string]string{"token": "s3cret"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(buf.String(), "s3cret") {
+		t.Errorf("logged %q, want the token redacted", buf.String())
+	}
+	if !strings.Contains(buf.String(), "alice@example.com") {
+		t.Errorf("logged %q, want the recipient", buf.String())
+	}
+}
@@ -0,0 +1,6 @@
+// Package customers holds the generated gRPC definition of the Customers
+// service. Only this service's protos are generated here; the rest of the
+// symptomatichq protos are consumed from their own module.
+package customers
+
+//go:generate protoc -I=. -I=$GOPATH/src -I=$GOPATH/src/github.com/gogo/protobuf/protobuf --gogofaster_out=plugins=grpc,Mgoogle/protobuf/wrappers.proto=github.com/gogo/protobuf/types:. customers.proto
@@ -0,0 +1,357 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// apiKeyPrefix marks secrets as API keys of this service, so they are
+	// easy to recognise in logs and by secret scanners.
+	apiKeyPrefix = "sk"
+	// apiKeyUsageInterval limits how often the last use of a key is recorded.
+	apiKeyUsageInterval = time.Minute
+)
+
+var scopePattern = regexp.MustCompile(`^[a-z][a-z0-9_.:-]*$`)
+
+type APIKeyStatus string
+
+const (
+	APIKeyActive  APIKeyStatus = "active"
+	APIKeyRevoked APIKeyStatus = "revoked"
+)
+
+// APIKey lets a machine client act on behalf of an account. Keys are looked up
+// by their public prefix and only a salted hash of the secret is stored.
+type APIKey struct {
+	ID        string       `db:"id"`
+	AccountID string       `db:"account_id"`
+	Name      string       `db:"name"`
+	Prefix    string       `db:"prefix"`
+	Salt      string       `db:"salt"`
+	Hash      string       `db:"hash"`
+	Scopes    []string     `db:"scopes"`
+	Status    APIKeyStatus `db:"status"`
+	CreatedBy string       `db:"created_by"`
+	// ReplacedBy is the id of the key which replaced this one when it was rotated.
+	ReplacedBy *string    `db:"replaced_by"`
+	ExpiresAt  *time.Time `db:"expires_at"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+	UpdatedAt  time.Time  `db:"updated_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+}
+
+// Expired reports whether the key can no longer be used.
+func (k APIKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
+
+// HasScope reports whether the key grants the scope.
+func (k APIKey) HasScope(scope string) bool {
+	return containsString(k.Scopes, scope)
+}
+
+// matches reports whether secret is the key's secret, in constant time.
+func (k APIKey) matches(secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashAPIKeySecret(k.Salt, secret)), []byte(k.Hash)) == 1
+}
+
+// IssuedAPIKey is returned when a key is created or rotated and carries the
+// plaintext secret, which is never available again.
+type IssuedAPIKey struct {
+	APIKey
+	Secret string
+}
+
+// APIKeyVerification is the account and scopes a verified key acts with.
+type APIKeyVerification struct {
+	KeyID     string
+	AccountID string
+	Scopes    []string
+}
+
+type CreateAPIKeyRequest struct {
+	AccountID string
+	Name      string
+	Scopes    []string
+	// ExpiresAt is when the key stops working, or nil for a key that does not expire.
+	ExpiresAt *time.Time
+	CreatedBy string
+}
+
+type ListAPIKeysRequest struct {
+	AccountID string
+	Status    APIKeyStatus
+}
+
+type RotateAPIKeyRequest struct {
+	AccountID string
+	ID        string
+	// GracePeriod keeps the old key working for a while so that clients can
+	// switch over. The old key is revoked immediately when it is zero.
+	GracePeriod time.Duration
+	RotatedBy   string
+}
+
+type RevokeAPIKeyRequest struct {
+	AccountID string
+	ID        string
+}
+
+type VerifyAPIKeyRequest struct {
+	Key string
+	// Scope is required of the key when set.
+	Scope string
+}
+
+// CreateAPIKey issues a new key for an active account.
+func (svc *customersService) CreateAPIKey(ctx context.Context, req CreateAPIKeyRequest) (issued IssuedAPIKey, err error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return issued, errors.Wrap(ErrInvalidArgument, "api key name is required")
+	}
+
+	for _, scope := range req.Scopes {
+		if !scopePattern.MatchString(scope) {
+			return issued, errors.Wrapf(ErrInvalidArgument, "invalid scope %q", scope)
+		}
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return issued, errors.Wrap(ErrInvalidArgument, "api key must expire in the future")
+	}
+
+	account, err := svc.repo.GetAccountByID(ctx, req.AccountID)
+	if err != nil {
+		return
+	}
+
+	if account.Status != AccountActive {
+		return issued, errors.Wrapf(ErrFailedPrecondition, "account %s is not active", account.ID)
+	}
+
+	return svc.issueAPIKey(ctx, APIKey{
+		AccountID: account.ID,
+		Name:      name,
+		Scopes:    req.Scopes,
+		Status:    APIKeyActive,
+		CreatedBy: req.CreatedBy,
+		ExpiresAt: req.ExpiresAt,
+	})
+}
+
+func (svc *customersService) ListAPIKeys(ctx context.Context, req ListAPIKeysRequest) (keys []APIKey, err error) {
+	filters := map[string]interface{}{"account_id": req.AccountID}
+	if req.Status != "" {
+		filters["status"] = req.Status
+	}
+
+	keys, err = svc.repo.SelectAPIKeys(ctx, filters)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to retrieve api keys", "error", err.Error())
+	}
+
+	return
+}
+
+// RotateAPIKey replaces a key with a new one holding the same name, scopes and
+// expiry, and retires the old key once the grace period has passed.
+func (svc *customersService) RotateAPIKey(ctx context.Context, req RotateAPIKeyRequest) (issued IssuedAPIKey, err error) {
+	if req.GracePeriod < 0 {
+		return issued, errors.Wrap(ErrInvalidArgument, "grace period cannot be negative")
+	}
+
+	old, err := svc.apiKey(ctx, req.AccountID, req.ID)
+	if err != nil {
+		return
+	}
+
+	if old.Status != APIKeyActive || old.Expired(time.Now()) {
+		return issued, errors.Wrapf(ErrFailedPrecondition, "api key %s is no longer active", old.ID)
+	}
+
+	issued, err = svc.issueAPIKey(ctx, APIKey{
+		AccountID: old.AccountID,
+		Name:      old.Name,
+		Scopes:    old.Scopes,
+		Status:    APIKeyActive,
+		CreatedBy: req.RotatedBy,
+		ExpiresAt: old.ExpiresAt,
+	})
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	old.ReplacedBy = &issued.ID
+	if req.GracePeriod == 0 {
+		old.Status = APIKeyRevoked
+		old.RevokedAt = &now
+	} else if retireAt := now.Add(req.GracePeriod); old.ExpiresAt == nil || retireAt.Before(*old.ExpiresAt) {
+		old.ExpiresAt = &retireAt
+	}
+
+	if _, err = svc.repo.UpdateAPIKey(ctx, old); err != nil {
+		svc.logger.Log("level", "error", "message", "failed to retire rotated api key", "error", err.Error())
+	}
+
+	return
+}
+
+func (svc *customersService) RevokeAPIKey(ctx context.Context, req RevokeAPIKeyRequest) (key APIKey, err error) {
+	key, err = svc.apiKey(ctx, req.AccountID, req.ID)
+	if err != nil || key.Status == APIKeyRevoked {
+		return
+	}
+
+	now := time.Now()
+	key.Status = APIKeyRevoked
+	key.RevokedAt = &now
+	key, err = svc.repo.UpdateAPIKey(ctx, key)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to revoke api key", "error", err.Error())
+	}
+
+	return
+}
+
+// VerifyAPIKey resolves a key to the account it acts for. Unknown, revoked
+// and expired keys, and keys of inactive accounts, are indistinguishable to
+// the caller. A key lacking the requested scope is denied.
+func (svc *customersService) VerifyAPIKey(ctx context.Context, req VerifyAPIKeyRequest) (verification APIKeyVerification, err error) {
+	invalid := errors.Wrap(ErrNotFound, "api key is invalid or has expired")
+
+	prefix, secret, ok := parseAPIKey(req.Key)
+	if !ok {
+		return verification, invalid
+	}
+
+	keys, err := svc.repo.SelectAPIKeys(ctx, map[string]interface{}{
+		"prefix": prefix,
+		"status": APIKeyActive,
+	})
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	if len(keys) == 0 || keys[0].Expired(now) || !keys[0].matches(secret) {
+		return verification, invalid
+	}
+	key := keys[0]
+
+	account, err := svc.repo.GetAccountByID(ctx, key.AccountID)
+	if err != nil {
+		return
+	}
+
+	if account.Status != AccountActive {
+		return verification, invalid
+	}
+
+	if req.Scope != "" && !key.HasScope(req.Scope) {
+		return verification, errors.Wrapf(ErrPermissionDenied, "api key lacks scope %q", req.Scope)
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyUsageInterval {
+		key.LastUsedAt = &now
+		if _, err := svc.repo.UpdateAPIKey(ctx, key); err != nil {
+			svc.logger.Log("level", "error", "message", "failed to record api key use", "error", err.Error())
+		}
+	}
+
+	return APIKeyVerification{KeyID: key.ID, AccountID: key.AccountID, Scopes: key.Scopes}, nil
+}
+
+// issueAPIKey generates the secret for a key and stores it.
+func (svc *customersService) issueAPIKey(ctx context.Context, key APIKey) (issued IssuedAPIKey, err error) {
+	prefix, err := randomString(9)
+	if err != nil {
+		return
+	}
+
+	secret, err := randomString(32)
+	if err != nil {
+		return
+	}
+
+	salt, err := randomString(16)
+	if err != nil {
+		return
+	}
+
+	key.Prefix = prefix
+	key.Salt = salt
+	key.Hash = hashAPIKeySecret(salt, secret)
+
+	issued.APIKey, err = svc.repo.InsertAPIKey(ctx, key)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to insert api key", "error", err.Error())
+		return
+	}
+	issued.Secret = apiKeyPrefix + "_" + prefix + "_" + secret
+
+	return
+}
+
+func (svc *customersService) apiKey(ctx context.Context, accountID, id string) (APIKey, error) {
+	keys, err := svc.repo.SelectAPIKeys(ctx, map[string]interface{}{
+		"id":         id,
+		"account_id": accountID,
+	})
+	if err != nil {
+		return APIKey{}, err
+	}
+
+	if len(keys) == 0 {
+		return APIKey{}, errors.Wrapf(ErrNotFound, "api key %s", id)
+	}
+
+	return keys[0], nil
+}
+
+// parseAPIKey splits a key of the form "sk_<prefix>_<secret>". The prefix is
+// base64url encoded and so may itself contain underscores, but has a fixed
+// length.
+func parseAPIKey(key string) (prefix, secret string, ok bool) {
+	key = strings.TrimSpace(key)
+	if !strings.HasPrefix(key, apiKeyPrefix+"_") {
+		return "", "", false
+	}
+
+	rest := key[len(apiKeyPrefix)+1:]
+	const prefixLength = 12
+	if len(rest) < prefixLength+2 || rest[prefixLength] != '_' {
+		return "", "", false
+	}
+
+	return rest[:prefixLength], rest[prefixLength+1:], true
+}
+
+// hashAPIKeySecret hashes a secret with its salt. Secrets carry 256 bits of
+// entropy, so a fast hash suffices and keeps verification cheap.
+func hashAPIKeySecret(salt, secret string) string {
+	sum := sha256.Sum256([]byte(salt + secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomString returns n random bytes encoded as base64url.
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "generating random bytes")
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestAPIKeys(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	repo.accounts["acct"] = Account{ID: "acct", Status: AccountActive}
+	svc := newTestService(repo)
+
+	issued, err := svc.CreateAPIKey(ctx, CreateAPIKeyRequest{AccountID: "acct", Name: "CI", Scopes: []string{"users:read"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(repo.apiKeys[0].Hash, issued.Secret) || repo.apiKeys[0].Salt == "" {
+		t.Error("the secret should only be stored salted and hashed")
+	}
+
+	verification, err := svc.VerifyAPIKey(ctx, VerifyAPIKeyRequest{Key: issued.Secret, Scope: "users:read"})
+	if err != nil || verification.AccountID != "acct" || verification.KeyID != issued.ID {
+		t.Fatalf("unexpected verification %+v, %v", verification, err)
+	}
+
+	if _, err := svc.VerifyAPIKey(ctx, VerifyAPIKeyRequest{Key: issued.Secret, Scope: "users:write"}); errors.Cause(err) != ErrPermissionDenied {
+		t.Errorf("missing scope: expected ErrPermissionDenied, got %v", err)
+	}
+
+	for _, key := range []string{"", "sk_nope", issued.Secret + "x", strings.Replace(issued.Secret, "sk_", "pk_", 1)} {
+		if _, err := svc.VerifyAPIKey(ctx, VerifyAPIKeyRequest{Key: key}); errors.Cause(err) != ErrNotFound {
+			t.Errorf("key %q: expected ErrNotFound, got %v", key, err)
+		}
+	}
+
+	rotated, err := svc.RotateAPIKey(ctx, RotateAPIKeyRequest{AccountID: "acct", ID: issued.ID, GracePeriod: time.Hour})
+	if err != nil || rotated.Name != "CI" || rotated.Secret == issued.Secret {
+		t.Fatalf("unexpected rotated key %+v, %v", rotated, err)
+	}
+
+	for _, secret := range []string{issued.Secret, rotated.Secret} {
+		if _, err := svc.VerifyAPIKey(ctx, VerifyAPIKeyRequest{Key: secret}); err != nil {
+			t.Errorf("both keys should work during the grace period: %v", err)
+		}
+	}
+
+	if _, err := svc.RevokeAPIKey(ctx, RevokeAPIKeyRequest{AccountID: "acct", ID: issued.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.VerifyAPIKey(ctx, VerifyAPIKeyRequest{Key: issued.Secret}); errors.Cause(err) != ErrNotFound {
+		t.Errorf("revoked key: expected ErrNotFound, got %v", err)
+	}
+
+	expired := time.Now().Add(-time.Second)
+	repo.apiKeys[1].ExpiresAt = &expired
+	if _, err := svc.VerifyAPIKey(ctx, VerifyAPIKeyRequest{Key: rotated.Secret}); errors.Cause(err) != ErrNotFound {
+		t.Errorf("expired key: expected ErrNotFound, got %v", err)
+	}
+
+	if _, err := svc.CreateAPIKey(ctx, CreateAPIKeyRequest{AccountID: "acct", Name: "bad", Scopes: []string{"Users Read"}}); errors.Cause(err) != ErrInvalidArgument {
+		t.Errorf("invalid scope: expected ErrInvalidArgument, got %v", err)
+	}
+}
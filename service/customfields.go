@@ -0,0 +1,471 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxCustomFields is the number of custom fields an account may define.
+const maxCustomFields = 50
+
+var customFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+
+type CustomFieldType string
+
+const (
+	CustomFieldString  CustomFieldType = "string"
+	CustomFieldNumber  CustomFieldType = "number"
+	CustomFieldBoolean CustomFieldType = "boolean"
+	// CustomFieldDate values are formatted as "2006-01-02".
+	CustomFieldDate CustomFieldType = "date"
+	// CustomFieldEnum values must be one of the field's EnumValues.
+	CustomFieldEnum CustomFieldType = "enum"
+)
+
+// Valid reports whether t is one of the known field types.
+func (t CustomFieldType) Valid() bool {
+	switch t {
+	case CustomFieldString, CustomFieldNumber, CustomFieldBoolean, CustomFieldDate, CustomFieldEnum:
+		return true
+	}
+
+	return false
+}
+
+// CustomField is a typed field an account defines for its users, such as
+// "NPI number". Values are kept on the users' memberships of the account.
+type CustomField struct {
+	ID        string          `db:"id"`
+	AccountID string          `db:"account_id"`
+	Key       string          `db:"key"`
+	Label     string          `db:"label"`
+	Type      CustomFieldType `db:"type"`
+	// Required fields must be given a value when users are created or their
+	// fields are updated.
+	Required   bool     `db:"required"`
+	EnumValues []string `db:"enum_values"`
+	// Pattern is a regular expression string values must match.
+	Pattern   string    `db:"pattern"`
+	UpdatedAt time.Time `db:"updated_at"`
+	CreatedAt time.Time `db:"created_at"`
+
+	// pattern is Pattern compiled when the field is loaded, so values are
+	// matched without compiling it each time.
+	pattern *regexp.Regexp
+}
+
+// compile compiles the field's pattern, failing with ErrInvalidArgument if
+// it is not a valid regular expression.
+func (f *CustomField) compile() error {
+	f.pattern = nil
+	if f.Pattern == "" {
+		return nil
+	}
+
+	pattern, err := regexp.Compile(f.Pattern)
+	if err != nil {
+		return errors.Wrapf(ErrInvalidArgument, "invalid pattern for field %s: %s", f.Key, err)
+	}
+
+	f.pattern = pattern
+	return nil
+}
+
+// validate checks a value against the field's type and constraints, returning
+// it in canonical form.
+func (f CustomField) validate(value string) (string, error) {
+	invalid := func(reason string) error {
+		return errors.Wrapf(ErrInvalidArgument, "invalid value %q for field %s: %s", value, f.Key, reason)
+	}
+
+	switch f.Type {
+	case CustomFieldNumber:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", invalid("expected a number")
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	case CustomFieldBoolean:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", invalid("expected true or false")
+		}
+		return strconv.FormatBool(b), nil
+	case CustomFieldDate:
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return "", invalid("expected a date formatted as YYYY-MM-DD")
+		}
+	case CustomFieldEnum:
+		if !containsString(f.EnumValues, value) {
+			return "", invalid("expected one of " + strings.Join(f.EnumValues, ", "))
+		}
+	case CustomFieldString:
+		if f.Pattern == "" {
+			break
+		}
+
+		if f.pattern == nil {
+			if err := f.compile(); err != nil {
+				return "", err
+			}
+		}
+
+		if !f.pattern.MatchString(value) {
+			return "", invalid("does not match " + f.Pattern)
+		}
+	}
+
+	return value, nil
+}
+
+type DefineCustomFieldRequest struct {
+	AccountID  string
+	Key        string
+	Label      string
+	Type       CustomFieldType
+	Required   bool
+	EnumValues []string
+	Pattern    string
+}
+
+type ListCustomFieldsRequest struct {
+	AccountID string
+}
+
+// UpdateCustomFieldRequest changes a field's constraints. Fields left nil are
+// unchanged, and a field's key and type cannot be changed.
+type UpdateCustomFieldRequest struct {
+	AccountID  string
+	Key        string
+	Label      *string
+	Required   *bool
+	EnumValues []string
+	Pattern    *string
+}
+
+type DeleteCustomFieldRequest struct {
+	AccountID string
+	Key       string
+}
+
+type SetCustomFieldValuesRequest struct {
+	AccountID string
+	UserID    string
+	// Values are validated against the account's fields. An empty value
+	// clears the field.
+	Values map[string]string
+}
+
+func (svc *customersService) DefineCustomField(ctx context.Context, req DefineCustomFieldRequest) (field CustomField, err error) {
+	if !customFieldKeyPattern.MatchString(req.Key) {
+		return field, errors.Wrapf(ErrInvalidArgument, "invalid field key %q", req.Key)
+	}
+
+	field = CustomField{
+		AccountID:  req.AccountID,
+		Key:        req.Key,
+		Label:      strings.TrimSpace(req.Label),
+		Type:       req.Type,
+		Required:   req.Required,
+		EnumValues: req.EnumValues,
+		Pattern:    req.Pattern,
+	}
+	if field.Label == "" {
+		field.Label = field.Key
+	}
+
+	if err = validateCustomField(&field); err != nil {
+		return
+	}
+
+	if _, err = svc.repo.GetAccountByID(ctx, req.AccountID); err != nil {
+		return
+	}
+
+	fields, err := svc.customFields(ctx, req.AccountID)
+	if err != nil {
+		return
+	}
+
+	if _, ok := fields[req.Key]; ok {
+		return field, errors.Wrapf(ErrAlreadyExists, "account %s already has a field %s", req.AccountID, req.Key)
+	}
+
+	if len(fields) >= maxCustomFields {
+		return field, errors.Wrapf(ErrResourceExhausted, "accounts may define at most %d custom fields", maxCustomFields)
+	}
+
+	field, err = svc.repo.InsertCustomField(ctx, field)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to insert custom field", "error", err.Error())
+	}
+
+	return
+}
+
+func (svc *customersService) ListCustomFields(ctx context.Context, req ListCustomFieldsRequest) (fields []CustomField, err error) {
+	fields, err = svc.repo.SelectCustomFields(ctx, map[string]interface{}{"account_id": req.AccountID})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to retrieve custom fields", "error", err.Error())
+	}
+
+	return
+}
+
+// UpdateCustomField changes a field, failing with ErrFailedPrecondition when
+// its new constraints would reject the values users already hold, or leave a
+// newly required field without a value.
+func (svc *customersService) UpdateCustomField(ctx context.Context, req UpdateCustomFieldRequest) (field CustomField, err error) {
+	fields, err := svc.customFields(ctx, req.AccountID)
+	if err != nil {
+		return
+	}
+
+	field, ok := fields[req.Key]
+	if !ok {
+		return field, errors.Wrapf(ErrNotFound, "custom field %s", req.Key)
+	}
+
+	if req.Label != nil {
+		if field.Label = strings.TrimSpace(*req.Label); field.Label == "" {
+			field.Label = field.Key
+		}
+	}
+	if req.Required != nil {
+		field.Required = *req.Required
+	}
+	if req.EnumValues != nil {
+		field.EnumValues = req.EnumValues
+	}
+	if req.Pattern != nil {
+		field.Pattern = *req.Pattern
+	}
+
+	if err = validateCustomField(&field); err != nil {
+		return
+	}
+
+	if req.Required != nil || req.EnumValues != nil || req.Pattern != nil {
+		if err = svc.revalidateCustomField(ctx, field); err != nil {
+			return
+		}
+	}
+
+	field, err = svc.repo.UpdateCustomField(ctx, field)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to update custom field", "error", err.Error())
+	}
+
+	return
+}
+
+// DeleteCustomField deletes a field along with its values.
+func (svc *customersService) DeleteCustomField(ctx context.Context, req DeleteCustomFieldRequest) (err error) {
+	fields, err := svc.customFields(ctx, req.AccountID)
+	if err != nil {
+		return
+	}
+
+	field, ok := fields[req.Key]
+	if !ok {
+		return errors.Wrapf(ErrNotFound, "custom field %s", req.Key)
+	}
+
+	err = svc.repo.DeleteCustomField(ctx, field)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to delete custom field", "error", err.Error())
+	}
+
+	return
+}
+
+// SetCustomFieldValues sets a user's values for the custom fields of one of
+// their accounts.
+func (svc *customersService) SetCustomFieldValues(ctx context.Context, req SetCustomFieldValuesRequest) (membership Membership, err error) {
+	membership, err = svc.membership(ctx, req.AccountID, req.UserID)
+	if err != nil {
+		return
+	}
+
+	values, err := svc.validateCustomFieldValues(ctx, req.AccountID, membership.CustomFields, req.Values)
+	if err != nil {
+		return
+	}
+
+	membership.CustomFields = values
+	membership, err = svc.repo.UpdateMembership(ctx, membership)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to update custom field values", "error", err.Error())
+	}
+
+	return
+}
+
+// revalidateCustomField checks the values the users of the field's account
+// hold against its constraints.
+func (svc *customersService) revalidateCustomField(ctx context.Context, field CustomField) error {
+	memberships, err := svc.repo.SelectMemberships(ctx, map[string]interface{}{"account_id": field.AccountID})
+	if err != nil {
+		return err
+	}
+
+	for _, membership := range memberships {
+		value, ok := membership.CustomFields[field.Key]
+		if !ok {
+			if field.Required {
+				return errors.Wrapf(ErrFailedPrecondition, "user %s has no value for field %s", membership.UserID, field.Key)
+			}
+			continue
+		}
+
+		if _, err := field.validate(value); err != nil {
+			return errors.Wrapf(ErrFailedPrecondition, "user %s holds a value field %s would reject: %s", membership.UserID, field.Key, err)
+		}
+	}
+
+	return nil
+}
+
+// validateCustomFieldValues applies changes to a membership's current values,
+// failing if any value is invalid or a required field is left without one.
+func (svc *customersService) validateCustomFieldValues(ctx context.Context, accountID string, current, changes map[string]string) (map[string]string, error) {
+	fields, err := svc.customFields(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]string{}
+	for key, value := range current {
+		// values of deleted fields are dropped
+		if _, ok := fields[key]; ok {
+			values[key] = value
+		}
+	}
+
+	for key, value := range changes {
+		field, ok := fields[key]
+		if !ok {
+			return nil, errors.Wrapf(ErrInvalidArgument, "account %s has no field %s", accountID, key)
+		}
+
+		if value == "" {
+			delete(values, key)
+			continue
+		}
+
+		if values[key], err = field.validate(value); err != nil {
+			return nil, err
+		}
+	}
+
+	for key, field := range fields {
+		if _, ok := values[key]; field.Required && !ok {
+			return nil, errors.Wrapf(ErrInvalidArgument, "field %s is required", key)
+		}
+	}
+
+	return values, nil
+}
+
+// carryCustomFieldValues carries the values a user holds in another account
+// over to their membership of an account with the given fields, keeping any
+// values the membership already holds. Values of fields the account does not
+// define are dropped. Carried values must be valid for the account's fields,
+// and a user joining the account must end up with every required field.
+func carryCustomFieldValues(fields map[string]CustomField, userID string, current, carried map[string]string, joining bool) (map[string]string, error) {
+	values := make(map[string]string, len(current)+len(carried))
+	for key, value := range current {
+		values[key] = value
+	}
+
+	for key, value := range carried {
+		field, ok := fields[key]
+		if _, held := values[key]; !ok || held {
+			continue
+		}
+
+		canonical, err := field.validate(value)
+		if err != nil {
+			return nil, errors.Wrapf(ErrFailedPrecondition, "value of field %s held by user %s cannot be carried over: %s", key, userID, err)
+		}
+		values[key] = canonical
+	}
+
+	if joining {
+		for key, field := range fields {
+			if _, ok := values[key]; field.Required && !ok {
+				return nil, errors.Wrapf(ErrFailedPrecondition, "user %s has no value for required field %s", userID, key)
+			}
+		}
+	}
+
+	return values, nil
+}
+
+// customFieldFilter validates the values FetchUsers filters on, converting
+// them to the canonical form they are stored in.
+func (svc *customersService) customFieldFilter(ctx context.Context, accountID string, filter map[string]string) (map[string]string, error) {
+	fields, err := svc.customFields(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	canonical := make(map[string]string, len(filter))
+	for key, value := range filter {
+		field, ok := fields[key]
+		if !ok {
+			return nil, errors.Wrapf(ErrInvalidArgument, "account %s has no field %s", accountID, key)
+		}
+
+		if canonical[key], err = field.validate(value); err != nil {
+			return nil, err
+		}
+	}
+
+	return canonical, nil
+}
+
+// customFields returns the fields of an account by key, with their patterns
+// compiled.
+func (svc *customersService) customFields(ctx context.Context, accountID string) (map[string]CustomField, error) {
+	fields, err := svc.repo.SelectCustomFields(ctx, map[string]interface{}{"account_id": accountID})
+	if err != nil {
+		return nil, err
+	}
+
+	byKey := make(map[string]CustomField, len(fields))
+	for _, field := range fields {
+		if err := field.compile(); err != nil {
+			return nil, err
+		}
+		byKey[field.Key] = field
+	}
+
+	return byKey, nil
+}
+
+// validateCustomField checks a field's definition, compiling its pattern.
+func validateCustomField(field *CustomField) error {
+	if !field.Type.Valid() {
+		return errors.Wrapf(ErrInvalidArgument, "unknown field type %q", field.Type)
+	}
+
+	if field.Type == CustomFieldEnum {
+		if len(field.EnumValues) == 0 {
+			return errors.Wrapf(ErrInvalidArgument, "enum field %s needs at least one value", field.Key)
+		}
+	} else if len(field.EnumValues) > 0 {
+		return errors.Wrapf(ErrInvalidArgument, "only enum fields may list values, field %s is a %s", field.Key, field.Type)
+	}
+
+	if field.Pattern != "" && field.Type != CustomFieldString {
+		return errors.Wrapf(ErrInvalidArgument, "only string fields may have a pattern, field %s is a %s", field.Key, field.Type)
+	}
+
+	return field.compile()
+}
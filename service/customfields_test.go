@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestCustomFields(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	repo.accounts["clinic"] = Account{ID: "clinic", Status: AccountActive}
+	svc := newTestService(repo)
+
+	define := func(req DefineCustomFieldRequest) {
+		req.AccountID = "clinic"
+		if _, err := svc.DefineCustomField(ctx, req); err != nil {
+			t.Fatalf("defining %s: %v", req.Key, err)
+		}
+	}
+	define(DefineCustomFieldRequest{Key: "npi", Label: "NPI number", Type: CustomFieldString, Required: true, Pattern: `^\d{10}$`})
+	define(DefineCustomFieldRequest{Key: "department", Type: CustomFieldEnum, EnumValues: []string{"cardiology", "oncology"}})
+	define(DefineCustomFieldRequest{Key: "fte", Type: CustomFieldNumber})
+
+	for _, req := range []DefineCustomFieldRequest{
+		{AccountID: "clinic", Key: "npi", Type: CustomFieldString},
+		{AccountID: "clinic", Key: "Bad Key", Type: CustomFieldString},
+		{AccountID: "clinic", Key: "shift", Type: CustomFieldEnum},
+		{AccountID: "clinic", Key: "level", Type: CustomFieldNumber, Pattern: "^1$"},
+	} {
+		if _, err := svc.DefineCustomField(ctx, req); err == nil {
+			t.Errorf("defining %+v should fail", req)
+		}
+	}
+
+	if _, err := svc.CreateUser(ctx, CreateUserRequest{AccountID: "clinic", Email: "a@example.com"}); errors.Cause(err) != ErrInvalidArgument {
+		t.Errorf("missing required field: expected ErrInvalidArgument, got %v", err)
+	}
+	if _, err := svc.CreateUser(ctx, CreateUserRequest{AccountID: "clinic", Email: "a@example.com", CustomFields: map[string]string{"npi": "12345"}}); errors.Cause(err) != ErrInvalidArgument {
+		t.Errorf("pattern mismatch: expected ErrInvalidArgument, got %v", err)
+	}
+
+	alice, err := svc.CreateUser(ctx, CreateUserRequest{AccountID: "clinic", Email: "alice@example.com", CustomFields: map[string]string{"npi": "1234567890", "fte": "0.50"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m, _ := svc.membership(ctx, "clinic", alice.ID); m.CustomFields["fte"] != "0.5" {
+		t.Errorf("numbers should be stored in canonical form, got %v", m.CustomFields)
+	}
+
+	if _, err := svc.SetCustomFieldValues(ctx, SetCustomFieldValuesRequest{AccountID: "clinic", UserID: alice.ID, Values: map[string]string{"department": "surgery"}}); errors.Cause(err) != ErrInvalidArgument {
+		t.Errorf("unknown enum value: expected ErrInvalidArgument, got %v", err)
+	}
+	if _, err := svc.SetCustomFieldValues(ctx, SetCustomFieldValuesRequest{AccountID: "clinic", UserID: alice.ID, Values: map[string]string{"npi": ""}}); errors.Cause(err) != ErrInvalidArgument {
+		t.Errorf("clearing a required field: expected ErrInvalidArgument, got %v", err)
+	}
+	if _, err := svc.SetCustomFieldValues(ctx, SetCustomFieldValuesRequest{AccountID: "clinic", UserID: alice.ID, Values: map[string]string{"department": "oncology"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	required, pattern := true, `^\d{11}$`
+	if _, err := svc.UpdateCustomField(ctx, UpdateCustomFieldRequest{AccountID: "clinic", Key: "npi", Pattern: &pattern}); errors.Cause(err) != ErrFailedPrecondition {
+		t.Errorf("pattern rejecting existing values: expected ErrFailedPrecondition, got %v", err)
+	}
+	if _, err := svc.UpdateCustomField(ctx, UpdateCustomFieldRequest{AccountID: "clinic", Key: "department", EnumValues: []string{"cardiology"}}); errors.Cause(err) != ErrFailedPrecondition {
+		t.Errorf("enum values rejecting existing values: expected ErrFailedPrecondition, got %v", err)
+	}
+	if _, err := svc.UpdateCustomField(ctx, UpdateCustomFieldRequest{AccountID: "clinic", Key: "fte", Required: &required}); err != nil {
+		t.Errorf("requiring a field every user holds: %v", err)
+	}
+
+	users, err := svc.FetchUsers(ctx, FetchUsersRequest{AccountIDs: []string{"clinic"}, CustomFields: map[string]string{"department": "oncology", "fte": ".5"}})
+	if err != nil || len(users) != 1 || users[0].ID != alice.ID {
+		t.Errorf("unexpected users %+v, %v", users, err)
+	}
+	if _, err := svc.FetchUsers(ctx, FetchUsersRequest{CustomFields: map[string]string{"department": "oncology"}}); errors.Cause(err) != ErrInvalidArgument {
+		t.Errorf("filtering without an account: expected ErrInvalidArgument, got %v", err)
+	}
+
+	if err := svc.DeleteCustomField(ctx, DeleteCustomFieldRequest{AccountID: "clinic", Key: "department"}); err != nil {
+		t.Fatal(err)
+	}
+	if m, _ := svc.membership(ctx, "clinic", alice.ID); m.CustomFields["department"] != "" {
+		t.Errorf("values of deleted fields should be removed, got %v", m.CustomFields)
+	}
+	// a stored pattern which no longer compiles is reported rather than panicking
+	repo.customFields = append(repo.customFields, CustomField{ID: "broken", AccountID: "clinic", Key: "badge", Type: CustomFieldString, Pattern: "("})
+	if _, err := svc.SetCustomFieldValues(ctx, SetCustomFieldValuesRequest{AccountID: "clinic", UserID: alice.ID, Values: map[string]string{"fte": "1"}}); errors.Cause(err) != ErrInvalidArgument {
+		t.Errorf("invalid stored pattern: expected ErrInvalidArgument, got %v", err)
+	}
+}
@@ -0,0 +1,708 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+
+	"github.com/symptomatichq/customers/emailutil"
+)
+
+const (
+	// duplicateThreshold is the minimum score for a pair of accounts to be
+	// reported as suspected duplicates.
+	duplicateThreshold = 0.6
+	// maxBlockSize skips name trigrams shared by so many accounts that
+	// comparing them all would be slow and rarely find a duplicate.
+	maxBlockSize = 200
+	// maxRedirects bounds how many merges GetAccount follows.
+	maxRedirects = 5
+)
+
+type DuplicateStatus string
+
+const (
+	DuplicateOpen      DuplicateStatus = "open"
+	DuplicateDismissed DuplicateStatus = "dismissed"
+	DuplicateMerged    DuplicateStatus = "merged"
+)
+
+// DuplicateCandidate is a pair of accounts suspected to belong to the same
+// customer. AccountID always sorts before DuplicateID so each pair is stored
+// once.
+type DuplicateCandidate struct {
+	AccountID   string          `db:"account_id"`
+	DuplicateID string          `db:"duplicate_id"`
+	Score       float64         `db:"score"`
+	Reasons     []string        `db:"reasons"`
+	Status      DuplicateStatus `db:"status"`
+	UpdatedAt   time.Time       `db:"updated_at"`
+	CreatedAt   time.Time       `db:"created_at"`
+}
+
+// AccountMerge records that the source account was merged into the target.
+type AccountMerge struct {
+	ID         string    `db:"id"`
+	SourceID   string    `db:"source_id"`
+	TargetID   string    `db:"target_id"`
+	MergedBy   string    `db:"merged_by"`
+	MovedUsers int       `db:"moved_users"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+type ListDuplicateAccountsRequest struct {
+	AccountID string
+	Status    DuplicateStatus
+	MinScore  float64
+}
+
+type MergeAccountsRequest struct {
+	// SourceID is the duplicate account, which is retired by the merge.
+	SourceID string
+	// TargetID is the account which survives the merge.
+	TargetID string
+	MergedBy string
+}
+
+// ListDuplicateAccounts lists the suspected duplicates found by the detector,
+// highest scoring first. Open candidates are listed unless a status is given.
+func (svc *customersService) ListDuplicateAccounts(ctx context.Context, req ListDuplicateAccountsRequest) (candidates []DuplicateCandidate, err error) {
+	status := req.Status
+	if status == "" {
+		status = DuplicateOpen
+	}
+
+	filters := map[string]interface{}{"status": status}
+	if req.AccountID != "" {
+		filters["involves"] = req.AccountID
+	}
+
+	selected, err := svc.repo.SelectDuplicateCandidates(ctx, filters)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to retrieve duplicate accounts", "error", err.Error())
+		return
+	}
+
+	candidates = []DuplicateCandidate{}
+	for _, candidate := range selected {
+		if candidate.Score >= req.MinScore {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Score > candidates[j].Score
+	})
+
+	return
+}
+
+// MergeAccounts moves every user, group and child account of the source
+// account into the target and retires the source, which from then on redirects to the target. Users
+// belonging to both accounts keep the stronger of their roles, but owners of
+// the source join the target as admins so a merge never hands over ownership.
+// The source's API keys are revoked, and its flag overrides and pending
+// scheduled actions dropped, as they were granted to the source alone.
+// The merge is made in a single transaction, so it fails without changing
+// either account.
+func (svc *customersService) MergeAccounts(ctx context.Context, req MergeAccountsRequest) (merge AccountMerge, err error) {
+	if req.SourceID == "" || req.TargetID == "" {
+		return merge, errors.Wrap(ErrInvalidArgument, "source and target accounts are required")
+	}
+
+	if req.SourceID == req.TargetID {
+		return merge, errors.Wrap(ErrInvalidArgument, "an account cannot be merged into itself")
+	}
+
+	err = svc.repo.Transaction(ctx, func(ctx context.Context) error {
+		merge, err = svc.mergeAccounts(ctx, req)
+		return err
+	})
+	if err != nil {
+		return
+	}
+
+	svc.resolveDuplicates(ctx, merge.SourceID, merge.TargetID)
+	svc.flagWatchers.notify()
+
+	return
+}
+
+// mergeAccounts makes the changes of a merge within MergeAccounts' transaction.
+func (svc *customersService) mergeAccounts(ctx context.Context, req MergeAccountsRequest) (merge AccountMerge, err error) {
+	source, err := svc.repo.GetAccountByID(ctx, req.SourceID)
+	if err != nil {
+		return
+	}
+
+	target, err := svc.repo.GetAccountByID(ctx, req.TargetID)
+	if err != nil {
+		return
+	}
+
+	if target.Status != AccountActive {
+		return merge, errors.Wrapf(ErrFailedPrecondition, "account %s is not active", target.ID)
+	}
+
+	if source.Status == AccountMerged && (source.MergedInto == nil || *source.MergedInto != target.ID) {
+		return merge, errors.Wrapf(ErrFailedPrecondition, "account %s has already been merged", source.ID)
+	}
+
+	// retiring the source first makes it redirect immediately and lifts the
+	// requirement for it to retain an owner while its members are moved
+	source.Status = AccountMerged
+	source.MergedInto = &target.ID
+	if _, err = svc.repo.UpdateAccount(ctx, source); err != nil {
+		svc.logger.Log("level", "error", "message", "failed to retire merged account", "error", err.Error())
+		return
+	}
+
+	if err = svc.adoptChildren(ctx, source, target); err != nil {
+		svc.logger.Log("level", "error", "message", "failed to move child accounts of merged account", "error", err.Error())
+		return
+	}
+
+	// group members leave the source's groups along with their memberships,
+	// so they are looked up before the members are moved
+	groups, err := svc.repo.SelectGroups(ctx, map[string]interface{}{"account_id": source.ID})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to retrieve groups of merged account", "error", err.Error())
+		return
+	}
+
+	var groupMembers []GroupMember
+	if len(groups) > 0 {
+		groupIDs := make([]string, len(groups))
+		for i, group := range groups {
+			groupIDs[i] = group.ID
+		}
+
+		groupMembers, err = svc.repo.SelectGroupMembers(ctx, map[string]interface{}{"group_id": groupIDs})
+		if err != nil {
+			svc.logger.Log("level", "error", "message", "failed to retrieve group members of merged account", "error", err.Error())
+			return
+		}
+	}
+
+	moved, err := svc.moveMembers(ctx, source.ID, target.ID)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to move members of merged account", "error", err.Error())
+		return
+	}
+
+	if err = svc.moveGroups(ctx, source, target, groups, groupMembers); err != nil {
+		svc.logger.Log("level", "error", "message", "failed to move groups of merged account", "error", err.Error())
+		return
+	}
+
+	if err = svc.revokeAccountGrants(ctx, source.ID, req.MergedBy); err != nil {
+		svc.logger.Log("level", "error", "message", "failed to revoke grants of merged account", "error", err.Error())
+		return
+	}
+
+	pending, err := svc.repo.SelectInvitations(ctx, map[string]interface{}{
+		"account_id": source.ID,
+		"status":     InvitationPending,
+	})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to retrieve invitations of merged account", "error", err.Error())
+		return
+	}
+
+	for _, invitation := range pending {
+		invitation.Status = InvitationRevoked
+		if _, err = svc.repo.UpdateInvitation(ctx, invitation); err != nil {
+			svc.logger.Log("level", "error", "message", "failed to revoke invitation", "error", err.Error())
+			return
+		}
+	}
+
+	merge, err = svc.repo.InsertAccountMerge(ctx, AccountMerge{
+		SourceID:   source.ID,
+		TargetID:   target.ID,
+		MergedBy:   req.MergedBy,
+		MovedUsers: moved,
+	})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to record account merge", "error", err.Error())
+	}
+
+	return
+}
+
+// moveMembers moves every membership of the source account into the target,
+// returning how many users were moved. Custom field values are carried over
+// for the fields the target defines. The merge is refused with a
+// SeatLimitError when the target has too few seats for the active human
+// members joining it.
+func (svc *customersService) moveMembers(ctx context.Context, sourceID, targetID string) (int, error) {
+	memberships, err := svc.repo.SelectMemberships(ctx, map[string]interface{}{"account_id": sourceID})
+	if err != nil {
+		return 0, err
+	}
+
+	humans, err := svc.repo.SelectUsers(ctx, map[string]interface{}{"account_id": []string{sourceID}, "kind": UserHuman})
+	if err != nil {
+		return 0, err
+	}
+
+	human := make(map[string]bool, len(humans))
+	for _, user := range humans {
+		human[user.ID] = true
+	}
+
+	// users who already belong to the target keep their membership of it, so
+	// only those joining it take up seats
+	existing := make(map[string]*Membership, len(memberships))
+	var seats int64
+	for _, membership := range memberships {
+		m, err := svc.membership(ctx, targetID, membership.UserID)
+		switch {
+		case err == nil:
+			existing[membership.UserID] = &m
+		case errors.Cause(err) != ErrNotFound:
+			return 0, err
+		case membership.Status == UserActive && human[membership.UserID]:
+			seats++
+		}
+	}
+
+	fields, err := svc.customFields(ctx, targetID)
+	if err != nil {
+		return 0, err
+	}
+
+	err = svc.withSeats(ctx, targetID, seats, func(ctx context.Context) error {
+		for _, membership := range memberships {
+			role := membership.Role
+			if role == RoleOwner {
+				role = RoleAdmin
+			}
+
+			current := existing[membership.UserID]
+			if current == nil {
+				values, err := carryCustomFieldValues(fields, membership.UserID, nil, membership.CustomFields, true)
+				if err != nil {
+					return err
+				}
+
+				_, err = svc.repo.InsertMembership(ctx, Membership{
+					AccountID:    targetID,
+					UserID:       membership.UserID,
+					Role:         role,
+					Status:       membership.Status,
+					CustomFields: values,
+				})
+				if err != nil {
+					return err
+				}
+			} else {
+				values, err := carryCustomFieldValues(fields, membership.UserID, current.CustomFields, membership.CustomFields, false)
+				if err != nil {
+					return err
+				}
+
+				changed := len(values) != len(current.CustomFields)
+				current.CustomFields = values
+				if roleRank[role] > roleRank[current.Role] {
+					current.Role = role
+					changed = true
+				}
+
+				if changed {
+					if _, err := svc.repo.UpdateMembership(ctx, *current); err != nil {
+						return err
+					}
+				}
+			}
+
+			if err := svc.repo.DeleteMembership(ctx, membership); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(memberships), nil
+}
+
+// moveGroups moves the source's groups into the target, along with their
+// members, who must already have joined the target. A group whose name the
+// target already uses is suffixed with the source's name.
+func (svc *customersService) moveGroups(ctx context.Context, source, target Account, groups []Group, members []GroupMember) error {
+	for _, member := range members {
+		if err := svc.repo.DeleteGroupMember(ctx, member); err != nil {
+			return err
+		}
+	}
+
+	// nested groups must belong to their parent's account, so they are
+	// detached while the groups move and reattached afterwards
+	parents := make(map[string]*string, len(groups))
+	for i := range groups {
+		if groups[i].ParentID == nil {
+			continue
+		}
+
+		parents[groups[i].ID] = groups[i].ParentID
+		groups[i].ParentID = nil
+		if _, err := svc.repo.UpdateGroup(ctx, groups[i]); err != nil {
+			return err
+		}
+	}
+
+	for i := range groups {
+		name := groups[i].Name
+		if err := svc.ensureGroupNameAvailable(ctx, target.ID, "", name); errors.Cause(err) == ErrAlreadyExists {
+			name = fmt.Sprintf("%s (%s)", groups[i].Name, source.Name)
+			if err = svc.ensureGroupNameAvailable(ctx, target.ID, "", name); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+
+		groups[i].AccountID = target.ID
+		groups[i].Name = name
+		if _, err := svc.repo.UpdateGroup(ctx, groups[i]); err != nil {
+			return err
+		}
+	}
+
+	for i := range groups {
+		if parents[groups[i].ID] == nil {
+			continue
+		}
+
+		groups[i].ParentID = parents[groups[i].ID]
+		if _, err := svc.repo.UpdateGroup(ctx, groups[i]); err != nil {
+			return err
+		}
+	}
+
+	for _, member := range members {
+		member.AccountID = target.ID
+		if _, err := svc.repo.InsertGroupMember(ctx, member); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// revokeAccountGrants revokes the API keys of a merged account, removes its
+// flag overrides and cancels its pending scheduled actions.
+func (svc *customersService) revokeAccountGrants(ctx context.Context, accountID, mergedBy string) error {
+	now := time.Now()
+
+	keys, err := svc.repo.SelectAPIKeys(ctx, map[string]interface{}{
+		"account_id": accountID,
+		"status":     APIKeyActive,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		key.Status = APIKeyRevoked
+		key.RevokedAt = &now
+		if _, err = svc.repo.UpdateAPIKey(ctx, key); err != nil {
+			return err
+		}
+	}
+
+	overrides, err := svc.repo.SelectFlagOverrides(ctx, map[string]interface{}{"account_id": accountID})
+	if err != nil {
+		return err
+	}
+
+	for _, override := range overrides {
+		if err = svc.repo.DeleteFlagOverride(ctx, override); err != nil {
+			return err
+		}
+	}
+
+	actions, err := svc.repo.SelectScheduledActions(ctx, map[string]interface{}{
+		"account_id": accountID,
+		"status":     ScheduledActionPending,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, action := range actions {
+		action.Status = ScheduledActionCancelled
+		action.CancelledBy = mergedBy
+		action.CancelledAt = &now
+		if _, err = svc.repo.UpdateScheduledAction(ctx, action); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// adoptChildren moves the child accounts of the source under the target. When
+// the target is itself a descendant of the source it first takes the source's
+// place in the hierarchy, so that the source's child on the target's branch
+// can be moved under the target without forming a cycle.
+func (svc *customersService) adoptChildren(ctx context.Context, source, target Account) error {
+	ancestors, err := svc.repo.SelectAccountAncestors(ctx, target.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, ancestor := range ancestors {
+		if ancestor.ID != source.ID {
+			continue
+		}
+
+		target.ParentID = source.ParentID
+		if _, err := svc.repo.UpdateAccount(ctx, target); err != nil {
+			return err
+		}
+		break
+	}
+
+	children, err := svc.repo.SelectAccounts(ctx, map[string]interface{}{"parent_id": source.ID})
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		if child.ID == target.ID {
+			continue
+		}
+
+		child.ParentID = &target.ID
+		if _, err := svc.repo.UpdateAccount(ctx, child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// roleRank orders roles by the access they grant, for choosing between the
+// roles of a user who belongs to both merged accounts.
+var roleRank = map[Role]int{
+	RoleReadOnly: 1,
+	RoleBilling:  2,
+	RoleMember:   3,
+	RoleAdmin:    4,
+	RoleOwner:    5,
+}
+
+// resolveDuplicates marks the candidate pair for the merged accounts as
+// merged. Failures are logged since the merge itself has succeeded.
+func (svc *customersService) resolveDuplicates(ctx context.Context, sourceID, targetID string) {
+	a, b := orderedPair(sourceID, targetID)
+	candidates, err := svc.repo.SelectDuplicateCandidates(ctx, map[string]interface{}{
+		"account_id":   a,
+		"duplicate_id": b,
+	})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to retrieve duplicate accounts", "error", err.Error())
+		return
+	}
+
+	for _, candidate := range candidates {
+		candidate.Status = DuplicateMerged
+		if _, err := svc.repo.UpdateDuplicateCandidate(ctx, candidate); err != nil {
+			svc.logger.Log("level", "error", "message", "failed to resolve duplicate accounts", "error", err.Error())
+		}
+	}
+}
+
+// resolveAccount follows the redirects left by merges from account to the
+// account it was merged into.
+func (svc *customersService) resolveAccount(ctx context.Context, account Account) (Account, error) {
+	for i := 0; account.Status == AccountMerged && account.MergedInto != nil; i++ {
+		if i == maxRedirects {
+			return account, errors.Errorf("account %s redirects too many times", account.ID)
+		}
+
+		next, err := svc.repo.GetAccountByID(ctx, *account.MergedInto)
+		if err != nil {
+			return account, err
+		}
+		account = next
+	}
+
+	return account, nil
+}
+
+func orderedPair(a, b string) (string, string) {
+	if b < a {
+		return b, a
+	}
+
+	return a, b
+}
+
+// scoreDuplicate scores how likely two accounts are to belong to the same
+// customer between 0 and 1, along with the reasons for the score.
+func scoreDuplicate(a, b Account) (float64, []string) {
+	reasons := []string{}
+
+	name := trigramSimilarity(companyName(a.Name), companyName(b.Name))
+	if name >= 0.5 {
+		reasons = append(reasons, "similar_name")
+	}
+
+	email := 0.0
+	switch {
+	case a.ContactEmail == "" || b.ContactEmail == "":
+	case a.ContactEmail == b.ContactEmail:
+		email = 1
+		reasons = append(reasons, "same_contact_email")
+	case emailutil.Domain(a.ContactEmail) == emailutil.Domain(b.ContactEmail) && !emailutil.IsFreemail(emailutil.Domain(a.ContactEmail)):
+		email = 0.5
+		reasons = append(reasons, "same_contact_domain")
+	}
+
+	return 0.7*name + 0.3*email, reasons
+}
+
+// companySuffixes are dropped from names before comparing them, since they
+// are often omitted or abbreviated differently.
+var companySuffixes = map[string]bool{
+	"inc": true, "incorporated": true, "llc": true, "ltd": true, "limited": true,
+	"corp": true, "corporation": true, "co": true, "company": true,
+	"gmbh": true, "plc": true, "sa": true, "bv": true,
+}
+
+func companyName(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return r == ' ' || r == ',' || r == '.'
+	})
+
+	kept := words[:0]
+	for _, word := range words {
+		if !companySuffixes[word] {
+			kept = append(kept, word)
+		}
+	}
+
+	return strings.Join(kept, " ")
+}
+
+// DuplicateDetector periodically compares active accounts and records the
+// pairs likely to belong to the same customer for review.
+type DuplicateDetector struct {
+	repo     Repository
+	logger   log.Logger
+	interval time.Duration
+
+	once sync.Once
+	stop chan struct{}
+}
+
+// NewDuplicateDetector creates a detector which scans every interval once started.
+func NewDuplicateDetector(repo Repository, logger log.Logger, interval time.Duration) *DuplicateDetector {
+	return &DuplicateDetector{
+		repo:     repo,
+		logger:   log.With(logger, "component", "duplicate-detector"),
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start scans for duplicates in the background until Stop is called.
+func (d *DuplicateDetector) Start() {
+	go func() {
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := d.Detect(context.Background()); err != nil {
+					d.logger.Log("level", "error", "message", "failed to detect duplicate accounts", "error", err.Error())
+				}
+			case <-d.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the background scans.
+func (d *DuplicateDetector) Stop() {
+	d.once.Do(func() {
+		close(d.stop)
+	})
+}
+
+// Detect compares active accounts sharing a name trigram or contact domain
+// and records every pair scoring above the threshold, returning how many
+// were found. Recording a pair again updates its score without reopening it
+// if it was dismissed.
+func (d *DuplicateDetector) Detect(ctx context.Context) (int, error) {
+	accounts, err := d.repo.SelectAccounts(ctx, map[string]interface{}{"status": AccountActive})
+	if err != nil {
+		return 0, err
+	}
+
+	blocks := map[string][]int{}
+	for i, account := range accounts {
+		for t := range trigrams(companyName(account.Name)) {
+			blocks["name:"+t] = append(blocks["name:"+t], i)
+		}
+
+		if account.ContactEmail != "" {
+			blocks["domain:"+emailutil.Domain(account.ContactEmail)] = append(blocks["domain:"+emailutil.Domain(account.ContactEmail)], i)
+		}
+	}
+
+	compared := map[[2]int]bool{}
+	found := 0
+	for _, block := range blocks {
+		if len(block) > maxBlockSize {
+			continue
+		}
+
+		for x := 0; x < len(block); x++ {
+			for y := x + 1; y < len(block); y++ {
+				pair := [2]int{block[x], block[y]}
+				if compared[pair] {
+					continue
+				}
+				compared[pair] = true
+
+				a, b := accounts[pair[0]], accounts[pair[1]]
+				score, reasons := scoreDuplicate(a, b)
+				if score < duplicateThreshold {
+					continue
+				}
+
+				first, second := orderedPair(a.ID, b.ID)
+				_, err := d.repo.UpsertDuplicateCandidate(ctx, DuplicateCandidate{
+					AccountID:   first,
+					DuplicateID: second,
+					Score:       score,
+					Reasons:     reasons,
+					Status:      DuplicateOpen,
+				})
+				if err != nil {
+					return found, err
+				}
+				found++
+			}
+		}
+	}
+
+	d.logger.Log("level", "info", "message", "detected duplicate accounts", "accounts", len(accounts), "duplicates", found)
+
+	return found, nil
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+)
+
+func TestMergeDuplicateAccounts(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	repo.accounts["a1"] = Account{ID: "a1", Name: "Acme Inc.", ContactEmail: "ops@acme.com", Status: AccountActive}
+	repo.accounts["a2"] = Account{ID: "a2", Name: "ACME", ContactEmail: "billing@acme.com", Status: AccountActive}
+	repo.accounts["g1"] = Account{ID: "g1", Name: "Globex", ContactEmail: "ops@gmail.com", Status: AccountActive}
+	repo.accounts["i1"] = Account{ID: "i1", Name: "Initech", ContactEmail: "it@gmail.com", Status: AccountActive}
+	repo.memberships = []Membership{
+		{AccountID: "a1", UserID: "alice", Role: RoleOwner, Status: UserActive},
+		{AccountID: "a2", UserID: "bob", Role: RoleOwner, Status: UserActive},
+		{AccountID: "a2", UserID: "alice", Role: RoleMember, Status: UserActive},
+	}
+	staff := "staff-2"
+	repo.groups = []Group{
+		{ID: "staff-1", AccountID: "a1", Name: "Staff"},
+		{ID: "staff-2", AccountID: "a2", Name: "Staff"},
+		{ID: "nurses", AccountID: "a2", ParentID: &staff, Name: "Nurses"},
+	}
+	repo.groupMembers = []GroupMember{{GroupID: "nurses", AccountID: "a2", UserID: "bob"}}
+	repo.apiKeys = []APIKey{{ID: "key", AccountID: "a2", Status: APIKeyActive}}
+	repo.flagOverrides = []FlagOverride{{FlagKey: "beta", AccountID: "a2", Enabled: true}}
+	repo.actions = []ScheduledAction{{ID: "action", AccountID: "a2", ToStatus: string(AccountInactive), Status: ScheduledActionPending}}
+	svc := newTestService(repo)
+
+	found, err := NewDuplicateDetector(repo, log.NewNopLogger(), time.Hour).Detect(ctx)
+	if err != nil || found != 1 {
+		t.Fatalf("expected one duplicate, got %d, %v", found, err)
+	}
+
+	candidates, err := svc.ListDuplicateAccounts(ctx, ListDuplicateAccountsRequest{AccountID: "a2"})
+	if err != nil || len(candidates) != 1 || candidates[0].AccountID != "a1" || candidates[0].DuplicateID != "a2" {
+		t.Fatalf("unexpected candidates %+v, %v", candidates, err)
+	}
+
+	if _, err := svc.MergeAccounts(ctx, MergeAccountsRequest{SourceID: "a2", TargetID: "a2"}); errors.Cause(err) != ErrInvalidArgument {
+		t.Errorf("self merge: expected ErrInvalidArgument, got %v", err)
+	}
+
+	repo.failMerges = true
+	if _, err := svc.MergeAccounts(ctx, MergeAccountsRequest{SourceID: "a2", TargetID: "a1"}); err == nil {
+		t.Fatal("expected the merge to fail")
+	}
+	if repo.accounts["a2"].Status != AccountActive || len(repo.memberships) != 3 {
+		t.Fatalf("failed merge should leave the accounts unchanged, got %+v, %+v", repo.accounts["a2"], repo.memberships)
+	}
+	if repo.groups[1].AccountID != "a2" || repo.apiKeys[0].Status != APIKeyActive || len(repo.flagOverrides) != 1 || repo.actions[0].Status != ScheduledActionPending {
+		t.Fatalf("failed merge should leave the source's records unchanged")
+	}
+	repo.failMerges = false
+
+	merge, err := svc.MergeAccounts(ctx, MergeAccountsRequest{SourceID: "a2", TargetID: "a1", MergedBy: "support"})
+	if err != nil || merge.MovedUsers != 2 || len(repo.merges) != 1 {
+		t.Fatalf("unexpected merge %+v, %v", merge, err)
+	}
+
+	memberships, _ := repo.SelectMemberships(ctx, map[string]interface{}{"account_id": "a1"})
+	roles := map[string]Role{}
+	for _, m := range memberships {
+		roles[m.UserID] = m.Role
+	}
+	if len(memberships) != 2 || roles["alice"] != RoleOwner || roles["bob"] != RoleAdmin {
+		t.Errorf("unexpected memberships after merge %+v", memberships)
+	}
+
+	if remaining, _ := repo.SelectMemberships(ctx, map[string]interface{}{"account_id": "a2"}); len(remaining) != 0 {
+		t.Errorf("merged account kept memberships %+v", remaining)
+	}
+
+	groups, _ := repo.SelectGroups(ctx, map[string]interface{}{"account_id": "a1"})
+	names := map[string]string{}
+	for _, g := range groups {
+		names[g.ID] = g.Name
+	}
+	if len(groups) != 3 || names["staff-2"] != "Staff (ACME)" || names["nurses"] != "Nurses" {
+		t.Errorf("unexpected groups after merge %+v", groups)
+	}
+	if nurses, _ := repo.SelectGroups(ctx, map[string]interface{}{"id": "nurses"}); nurses[0].ParentID == nil || *nurses[0].ParentID != "staff-2" {
+		t.Errorf("nested groups should keep their parent, got %+v", nurses[0])
+	}
+	if len(repo.groupMembers) != 1 || repo.groupMembers[0].AccountID != "a1" {
+		t.Errorf("group members should follow their groups, got %+v", repo.groupMembers)
+	}
+
+	if repo.apiKeys[0].Status != APIKeyRevoked || len(repo.flagOverrides) != 0 || repo.actions[0].Status != ScheduledActionCancelled {
+		t.Errorf("the source's keys, overrides and scheduled actions should be dropped, got %+v, %+v, %+v", repo.apiKeys, repo.flagOverrides, repo.actions)
+	}
+
+	account, err := svc.GetAccount(ctx, GetAccountRequest{ID: "a2"})
+	if err != nil || account.ID != "a1" {
+		t.Errorf("merged account should redirect to the survivor, got %+v, %v", account, err)
+	}
+
+	if open, _ := svc.ListDuplicateAccounts(ctx, ListDuplicateAccountsRequest{}); len(open) != 0 {
+		t.Errorf("merged duplicates should no longer be open: %+v", open)
+	}
+
+	if _, err := svc.MergeAccounts(ctx, MergeAccountsRequest{SourceID: "a2", TargetID: "g1"}); errors.Cause(err) != ErrFailedPrecondition {
+		t.Errorf("merging a merged account elsewhere: expected ErrFailedPrecondition, got %v", err)
+	}
+}
+
+func TestMergeAccounts(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		target  string
+		parents map[string]string
+	}{
+		// corp has the children emea and us, and the grandchild uk
+		{"into unrelated account", "other", map[string]string{"emea": "other", "us": "other", "uk": "emea", "other": ""}},
+		{"into child", "emea", map[string]string{"emea": "root", "us": "emea", "uk": "emea"}},
+		{"into grandchild", "uk", map[string]string{"uk": "root", "emea": "uk", "us": "uk"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepository()
+			for _, id := range []string{"root", "corp", "emea", "uk", "us", "other"} {
+				repo.accounts[id] = Account{ID: id, Status: AccountActive}
+			}
+			for child, parent := range map[string]string{"corp": "root", "emea": "corp", "us": "corp", "uk": "emea"} {
+				account := repo.accounts[child]
+				parent := parent
+				account.ParentID = &parent
+				repo.accounts[child] = account
+			}
+			svc := newTestService(repo)
+
+			if _, err := svc.MergeAccounts(ctx, MergeAccountsRequest{SourceID: "corp", TargetID: tt.target}); err != nil {
+				t.Fatal(err)
+			}
+
+			for id, want := range tt.parents {
+				got := ""
+				if parent := repo.accounts[id].ParentID; parent != nil {
+					got = *parent
+				}
+				if got != want {
+					t.Errorf("parent of %s = %q, want %q", id, got, want)
+				}
+			}
+
+			for id := range repo.accounts {
+				seen := map[string]bool{id: true}
+				for a := repo.accounts[id]; a.ParentID != nil; a = repo.accounts[*a.ParentID] {
+					if seen[*a.ParentID] {
+						t.Fatalf("merge left a cycle through %s", id)
+					}
+					seen[*a.ParentID] = true
+				}
+			}
+		})
+	}
+}
+
+func TestMergeCarriesCustomFields(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	repo.accounts["source"] = Account{ID: "source", Status: AccountActive}
+	repo.accounts["target"] = Account{ID: "target", Status: AccountActive}
+	repo.customFields = []CustomField{
+		{ID: "f1", AccountID: "target", Key: "npi", Type: CustomFieldString, Required: true, Pattern: `^\d{10}$`},
+		{ID: "f2", AccountID: "target", Key: "fte", Type: CustomFieldNumber},
+	}
+	repo.memberships = []Membership{
+		{AccountID: "source", UserID: "bob", Role: RoleMember, Status: UserActive, CustomFields: map[string]string{"npi": "1234567890", "shoe": "9"}},
+		{AccountID: "source", UserID: "carol", Role: RoleMember, Status: UserActive},
+		{AccountID: "target", UserID: "alice", Role: RoleOwner, Status: UserActive, CustomFields: map[string]string{"npi": "0987654321"}},
+		{AccountID: "source", UserID: "alice", Role: RoleMember, Status: UserActive, CustomFields: map[string]string{"npi": "1111111111", "fte": "1"}},
+	}
+	svc := newTestService(repo)
+
+	if _, err := svc.MergeAccounts(ctx, MergeAccountsRequest{SourceID: "source", TargetID: "target"}); errors.Cause(err) != ErrFailedPrecondition {
+		t.Fatalf("member without a required value: expected ErrFailedPrecondition, got %v", err)
+	}
+
+	repo.memberships[1].CustomFields = map[string]string{"npi": "12345"}
+	if _, err := svc.MergeAccounts(ctx, MergeAccountsRequest{SourceID: "source", TargetID: "target"}); errors.Cause(err) != ErrFailedPrecondition {
+		t.Fatalf("member with an invalid value: expected ErrFailedPrecondition, got %v", err)
+	}
+
+	repo.memberships[1].CustomFields = map[string]string{"npi": "5555555555"}
+	if _, err := svc.MergeAccounts(ctx, MergeAccountsRequest{SourceID: "source", TargetID: "target"}); err != nil {
+		t.Fatal(err)
+	}
+
+	bob, _ := svc.membership(ctx, "target", "bob")
+	if len(bob.CustomFields) != 1 || bob.CustomFields["npi"] != "1234567890" {
+		t.Errorf("expected bob's values for the target's fields to be carried over, got %v", bob.CustomFields)
+	}
+
+	alice, _ := svc.membership(ctx, "target", "alice")
+	if alice.CustomFields["npi"] != "0987654321" || alice.CustomFields["fte"] != "1" || alice.Role != RoleOwner {
+		t.Errorf("expected alice to keep their values and gain the missing ones, got %+v", alice)
+	}
+}
@@ -0,0 +1,19 @@
+package service
+
+import "github.com/pkg/errors"
+
+var (
+	// ErrPermissionDenied is returned when the caller may not perform an operation.
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrInvalidArgument is returned when a request fails validation.
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrNotFound is returned when a referenced record does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists is returned when creating a record that already exists.
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrFailedPrecondition is returned when the system is not in a state
+	// that allows the operation, e.g. removing the last owner of an account.
+	ErrFailedPrecondition = errors.New("failed precondition")
+	// ErrResourceExhausted is returned when a rate limit or quota is exceeded.
+	ErrResourceExhausted = errors.New("resource exhausted")
+)
@@ -0,0 +1,460 @@
+package service
+
+import (
+	"context"
+	"hash/fnv"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// flagPollInterval is how often WatchFlags re-evaluates flags, so changes
+// made through other instances and to rollouts are pushed without being
+// announced to this one.
+const flagPollInterval = 30 * time.Second
+
+// FeatureFlag gates a feature being rolled out. Flags apply to every account,
+// unless an override says otherwise.
+type FeatureFlag struct {
+	ID          string `db:"id"`
+	Key         string `db:"key"`
+	Description string `db:"description"`
+	// Enabled turns the flag on everywhere it is not overridden.
+	Enabled bool `db:"enabled"`
+	// Percentage turns a disabled flag on for a stable share of accounts,
+	// from 0 to 100.
+	Percentage int       `db:"percentage"`
+	UpdatedAt  time.Time `db:"updated_at"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+// FlagOverride sets a flag for an account, or for one of its users when
+// UserID is set, regardless of the flag's rollout.
+type FlagOverride struct {
+	FlagKey   string `db:"flag_key"`
+	AccountID string `db:"account_id"`
+	UserID    string `db:"user_id"`
+	Enabled   bool   `db:"enabled"`
+	// Percentage turns a disabled account override on for a stable share of
+	// the account's users. User overrides have no percentage.
+	Percentage int       `db:"percentage"`
+	CreatedBy  string    `db:"created_by"`
+	UpdatedAt  time.Time `db:"updated_at"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+type FlagSource string
+
+const (
+	FlagFromDefault FlagSource = "default"
+	FlagFromAccount FlagSource = "account"
+	FlagFromUser    FlagSource = "user"
+)
+
+// FlagEvaluation is whether a flag is on for an account or user, and why.
+type FlagEvaluation struct {
+	Key     string
+	Enabled bool
+	Source  FlagSource
+}
+
+type CreateFeatureFlagRequest struct {
+	Key         string
+	Description string
+	Enabled     bool
+	Percentage  int
+}
+
+type ListFeatureFlagsRequest struct{}
+
+// UpdateFeatureFlagRequest changes a flag. Fields left nil are unchanged.
+type UpdateFeatureFlagRequest struct {
+	Key         string
+	Description *string
+	Enabled     *bool
+	Percentage  *int
+}
+
+type DeleteFeatureFlagRequest struct {
+	Key string
+}
+
+type SetFlagOverrideRequest struct {
+	FlagKey    string
+	AccountID  string
+	UserID     string
+	Enabled    bool
+	Percentage int
+	CreatedBy  string
+}
+
+type RemoveFlagOverrideRequest struct {
+	FlagKey   string
+	AccountID string
+	UserID    string
+}
+
+type ListFlagOverridesRequest struct {
+	AccountID string
+}
+
+// EvaluateFlagsRequest evaluates flags for an account, or for one of its
+// users when UserID is set.
+type EvaluateFlagsRequest struct {
+	AccountID string
+	UserID    string
+}
+
+type WatchFlagsRequest struct {
+	AccountID string
+	UserID    string
+	// Send is called with every flag whenever any of them changes, starting
+	// with their current values. Watching stops if it returns an error.
+	Send func([]FlagEvaluation) error
+}
+
+func (svc *customersService) CreateFeatureFlag(ctx context.Context, req CreateFeatureFlagRequest) (flag FeatureFlag, err error) {
+	if !scopePattern.MatchString(req.Key) {
+		return flag, errors.Wrapf(ErrInvalidArgument, "invalid flag key %q", req.Key)
+	}
+
+	if err = validatePercentage(req.Percentage); err != nil {
+		return
+	}
+
+	existing, err := svc.repo.SelectFeatureFlags(ctx, map[string]interface{}{"key": req.Key})
+	if err != nil {
+		return
+	}
+
+	if len(existing) > 0 {
+		return flag, errors.Wrapf(ErrAlreadyExists, "flag %s already exists", req.Key)
+	}
+
+	flag, err = svc.repo.InsertFeatureFlag(ctx, FeatureFlag{
+		Key:         req.Key,
+		Description: strings.TrimSpace(req.Description),
+		Enabled:     req.Enabled,
+		Percentage:  req.Percentage,
+	})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to insert feature flag", "error", err.Error())
+		return
+	}
+
+	svc.flagWatchers.notify()
+
+	return
+}
+
+func (svc *customersService) ListFeatureFlags(ctx context.Context, req ListFeatureFlagsRequest) (flags []FeatureFlag, err error) {
+	flags, err = svc.repo.SelectFeatureFlags(ctx, map[string]interface{}{})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to retrieve feature flags", "error", err.Error())
+	}
+
+	return
+}
+
+func (svc *customersService) UpdateFeatureFlag(ctx context.Context, req UpdateFeatureFlagRequest) (flag FeatureFlag, err error) {
+	flag, err = svc.featureFlag(ctx, req.Key)
+	if err != nil {
+		return
+	}
+
+	if req.Description != nil {
+		flag.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Enabled != nil {
+		flag.Enabled = *req.Enabled
+	}
+	if req.Percentage != nil {
+		if err = validatePercentage(*req.Percentage); err != nil {
+			return
+		}
+		flag.Percentage = *req.Percentage
+	}
+
+	flag, err = svc.repo.UpdateFeatureFlag(ctx, flag)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to update feature flag", "error", err.Error())
+		return
+	}
+
+	svc.flagWatchers.notify()
+
+	return
+}
+
+// DeleteFeatureFlag deletes a flag along with its overrides.
+func (svc *customersService) DeleteFeatureFlag(ctx context.Context, req DeleteFeatureFlagRequest) (err error) {
+	flag, err := svc.featureFlag(ctx, req.Key)
+	if err != nil {
+		return
+	}
+
+	if err = svc.repo.DeleteFeatureFlag(ctx, flag); err != nil {
+		svc.logger.Log("level", "error", "message", "failed to delete feature flag", "error", err.Error())
+		return
+	}
+
+	svc.flagWatchers.notify()
+
+	return
+}
+
+func (svc *customersService) SetFlagOverride(ctx context.Context, req SetFlagOverrideRequest) (override FlagOverride, err error) {
+	if err = validatePercentage(req.Percentage); err != nil {
+		return
+	}
+
+	if req.UserID != "" && req.Percentage != 0 {
+		return override, errors.Wrap(ErrInvalidArgument, "user overrides cannot roll out to a percentage")
+	}
+
+	if _, err = svc.featureFlag(ctx, req.FlagKey); err != nil {
+		return
+	}
+
+	if req.UserID == "" {
+		_, err = svc.repo.GetAccountByID(ctx, req.AccountID)
+	} else {
+		_, err = svc.membership(ctx, req.AccountID, req.UserID)
+	}
+	if err != nil {
+		return
+	}
+
+	override, err = svc.repo.UpsertFlagOverride(ctx, FlagOverride{
+		FlagKey:    req.FlagKey,
+		AccountID:  req.AccountID,
+		UserID:     req.UserID,
+		Enabled:    req.Enabled,
+		Percentage: req.Percentage,
+		CreatedBy:  req.CreatedBy,
+	})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to set flag override", "error", err.Error())
+		return
+	}
+
+	svc.flagWatchers.notify()
+
+	return
+}
+
+func (svc *customersService) RemoveFlagOverride(ctx context.Context, req RemoveFlagOverrideRequest) (err error) {
+	overrides, err := svc.repo.SelectFlagOverrides(ctx, map[string]interface{}{
+		"flag_key":   req.FlagKey,
+		"account_id": req.AccountID,
+		"user_id":    req.UserID,
+	})
+	if err != nil {
+		return
+	}
+
+	if len(overrides) == 0 {
+		return errors.Wrapf(ErrNotFound, "account %s has no override for %s", req.AccountID, req.FlagKey)
+	}
+
+	if err = svc.repo.DeleteFlagOverride(ctx, overrides[0]); err != nil {
+		svc.logger.Log("level", "error", "message", "failed to remove flag override", "error", err.Error())
+		return
+	}
+
+	svc.flagWatchers.notify()
+
+	return
+}
+
+// ListFlagOverrides lists the overrides of an account and its users.
+func (svc *customersService) ListFlagOverrides(ctx context.Context, req ListFlagOverridesRequest) (overrides []FlagOverride, err error) {
+	overrides, err = svc.repo.SelectFlagOverrides(ctx, map[string]interface{}{"account_id": req.AccountID})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to retrieve flag overrides", "error", err.Error())
+	}
+
+	return
+}
+
+// EvaluateFlags returns every flag, sorted by key, as it applies to an account
+// or user. A user override takes precedence over an account override, which
+// takes precedence over the flag itself.
+func (svc *customersService) EvaluateFlags(ctx context.Context, req EvaluateFlagsRequest) (evaluations []FlagEvaluation, err error) {
+	if _, err = svc.repo.GetAccountByID(ctx, req.AccountID); err != nil {
+		return
+	}
+
+	if req.UserID != "" {
+		if _, err = svc.membership(ctx, req.AccountID, req.UserID); err != nil {
+			return
+		}
+	}
+
+	return svc.evaluateFlags(ctx, req.AccountID, req.UserID)
+}
+
+// WatchFlags sends an account or user's flags, and sends them again whenever
+// they change, until ctx is done or sending fails.
+func (svc *customersService) WatchFlags(ctx context.Context, req WatchFlagsRequest) (err error) {
+	if req.Send == nil {
+		return errors.Wrap(ErrInvalidArgument, "watching flags requires a sender")
+	}
+
+	changed := svc.flagWatchers.subscribe()
+	defer svc.flagWatchers.unsubscribe(changed)
+
+	sent, err := svc.EvaluateFlags(ctx, EvaluateFlagsRequest{AccountID: req.AccountID, UserID: req.UserID})
+	if err != nil {
+		return
+	}
+
+	if err = req.Send(sent); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(flagPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		case <-ticker.C:
+		}
+
+		evaluations, err := svc.evaluateFlags(ctx, req.AccountID, req.UserID)
+		if err != nil {
+			svc.logger.Log("level", "error", "message", "failed to evaluate flags", "error", err.Error())
+			continue
+		}
+
+		if reflect.DeepEqual(evaluations, sent) {
+			continue
+		}
+
+		if err := req.Send(evaluations); err != nil {
+			return err
+		}
+		sent = evaluations
+	}
+}
+
+func (svc *customersService) evaluateFlags(ctx context.Context, accountID, userID string) ([]FlagEvaluation, error) {
+	flags, err := svc.repo.SelectFeatureFlags(ctx, map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := svc.repo.SelectFlagOverrides(ctx, map[string]interface{}{"account_id": accountID})
+	if err != nil {
+		return nil, err
+	}
+
+	accountOverrides := map[string]FlagOverride{}
+	userOverrides := map[string]FlagOverride{}
+	for _, o := range overrides {
+		switch o.UserID {
+		case "":
+			accountOverrides[o.FlagKey] = o
+		case userID:
+			userOverrides[o.FlagKey] = o
+		}
+	}
+
+	evaluations := make([]FlagEvaluation, 0, len(flags))
+	for _, flag := range flags {
+		evaluation := FlagEvaluation{Key: flag.Key, Source: FlagFromDefault}
+		if o, ok := userOverrides[flag.Key]; ok {
+			evaluation.Enabled = o.Enabled
+			evaluation.Source = FlagFromUser
+		} else if o, ok := accountOverrides[flag.Key]; ok {
+			evaluation.Enabled = o.Enabled || (userID != "" && inRollout(flag.Key, userID, o.Percentage))
+			evaluation.Source = FlagFromAccount
+		} else {
+			evaluation.Enabled = flag.Enabled || inRollout(flag.Key, accountID, flag.Percentage)
+		}
+
+		evaluations = append(evaluations, evaluation)
+	}
+
+	sort.Slice(evaluations, func(i, j int) bool { return evaluations[i].Key < evaluations[j].Key })
+
+	return evaluations, nil
+}
+
+func (svc *customersService) featureFlag(ctx context.Context, key string) (FeatureFlag, error) {
+	flags, err := svc.repo.SelectFeatureFlags(ctx, map[string]interface{}{"key": key})
+	if err != nil {
+		return FeatureFlag{}, err
+	}
+
+	if len(flags) == 0 {
+		return FeatureFlag{}, errors.Wrapf(ErrNotFound, "flag %s", key)
+	}
+
+	return flags[0], nil
+}
+
+// inRollout places id in one of 100 buckets, which differ between flags so the
+// same accounts are not always first to get new features, and reports whether
+// the bucket is within the percentage.
+func inRollout(key, id string, percentage int) bool {
+	h := fnv.New32a()
+	h.Write([]byte(key + ":" + id))
+	return int(h.Sum32()%100) < percentage
+}
+
+func validatePercentage(percentage int) error {
+	if percentage < 0 || percentage > 100 {
+		return errors.Wrapf(ErrInvalidArgument, "percentage %d is not between 0 and 100", percentage)
+	}
+
+	return nil
+}
+
+// flagWatchers wakes WatchFlags calls when flags change. The zero value is
+// ready to use.
+type flagWatchers struct {
+	mu       sync.Mutex
+	watchers map[chan struct{}]struct{}
+}
+
+func (w *flagWatchers) subscribe() chan struct{} {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.watchers == nil {
+		w.watchers = map[chan struct{}]struct{}{}
+	}
+
+	changed := make(chan struct{}, 1)
+	w.watchers[changed] = struct{}{}
+	return changed
+}
+
+func (w *flagWatchers) unsubscribe(changed chan struct{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	delete(w.watchers, changed)
+}
+
+// notify wakes every watcher without blocking; a watcher which has yet to
+// handle an earlier change re-evaluates once for both.
+func (w *flagWatchers) notify() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for changed := range w.watchers {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+}
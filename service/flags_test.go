@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestInRollout(t *testing.T) {
+	in := 0
+	for i := 0; i < 1000; i++ {
+		id := fmt.Sprintf("acct-%d", i)
+		if inRollout("flag", id, 30) {
+			in++
+			if !inRollout("flag", id, 31) {
+				t.Errorf("%s left the rollout when it grew", id)
+			}
+		}
+		if inRollout("flag", id, 0) || !inRollout("flag", id, 100) {
+			t.Errorf("%s: 0%% and 100%% rollouts should include no one and everyone", id)
+		}
+	}
+	if in < 250 || in > 350 {
+		t.Errorf("expected about 300 of 1000 ids in a 30%% rollout, got %d", in)
+	}
+}
+
+func TestFeatureFlags(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	repo.accounts["acct"] = Account{ID: "acct", Status: AccountActive}
+	repo.memberships = []Membership{
+		{AccountID: "acct", UserID: "alice", Role: RoleMember, Status: UserActive},
+		{AccountID: "acct", UserID: "bob", Role: RoleMember, Status: UserActive},
+	}
+	svc := newTestService(repo)
+
+	if _, err := svc.CreateFeatureFlag(ctx, CreateFeatureFlagRequest{Key: "editor", Percentage: 150}); errors.Cause(err) != ErrInvalidArgument {
+		t.Errorf("invalid percentage: expected ErrInvalidArgument, got %v", err)
+	}
+	if _, err := svc.CreateFeatureFlag(ctx, CreateFeatureFlagRequest{Key: "editor"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CreateFeatureFlag(ctx, CreateFeatureFlagRequest{Key: "beta", Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CreateFeatureFlag(ctx, CreateFeatureFlagRequest{Key: "beta"}); errors.Cause(err) != ErrAlreadyExists {
+		t.Errorf("duplicate flag: expected ErrAlreadyExists, got %v", err)
+	}
+
+	evaluate := func(userID string) map[string]FlagEvaluation {
+		evaluations, err := svc.EvaluateFlags(ctx, EvaluateFlagsRequest{AccountID: "acct", UserID: userID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		byKey := map[string]FlagEvaluation{}
+		for _, e := range evaluations {
+			byKey[e.Key] = e
+		}
+		return byKey
+	}
+
+	if flags := evaluate(""); flags["editor"].Enabled || !flags["beta"].Enabled || flags["beta"].Source != FlagFromDefault {
+		t.Errorf("defaults: unexpected %+v", flags)
+	}
+
+	if _, err := svc.SetFlagOverride(ctx, SetFlagOverrideRequest{FlagKey: "editor", AccountID: "acct", Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.SetFlagOverride(ctx, SetFlagOverrideRequest{FlagKey: "editor", AccountID: "acct", UserID: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.SetFlagOverride(ctx, SetFlagOverrideRequest{FlagKey: "editor", AccountID: "acct", UserID: "bob", Percentage: 50}); errors.Cause(err) != ErrInvalidArgument {
+		t.Errorf("user rollout: expected ErrInvalidArgument, got %v", err)
+	}
+	if _, err := svc.SetFlagOverride(ctx, SetFlagOverrideRequest{FlagKey: "editor", AccountID: "acct", UserID: "carol"}); errors.Cause(err) != ErrNotFound {
+		t.Errorf("non-member override: expected ErrNotFound, got %v", err)
+	}
+
+	if e := evaluate("alice")["editor"]; e.Enabled || e.Source != FlagFromUser {
+		t.Errorf("user override: unexpected %+v", e)
+	}
+	if e := evaluate("bob")["editor"]; !e.Enabled || e.Source != FlagFromAccount {
+		t.Errorf("account override: unexpected %+v", e)
+	}
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	sent := make(chan []FlagEvaluation)
+	done := make(chan error)
+	go func() {
+		done <- svc.WatchFlags(watchCtx, WatchFlagsRequest{AccountID: "acct", UserID: "bob", Send: func(evaluations []FlagEvaluation) error {
+			sent <- evaluations
+			return nil
+		}})
+	}()
+
+	if initial := <-sent; len(initial) != 2 || !initial[0].Enabled {
+		t.Errorf("expected the current flags first, got %+v", initial)
+	}
+	disabled := false
+	if _, err := svc.UpdateFeatureFlag(ctx, UpdateFeatureFlagRequest{Key: "beta", Enabled: &disabled}); err != nil {
+		t.Fatal(err)
+	}
+	if changed := <-sent; changed[0].Key != "beta" || changed[0].Enabled {
+		t.Errorf("expected the change to be pushed, got %+v", changed)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("watching should end cleanly, got %v", err)
+	}
+
+	if err := svc.DeleteFeatureFlag(ctx, DeleteFeatureFlagRequest{Key: "editor"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.flagOverrides) != 0 {
+		t.Errorf("expected overrides to be deleted with their flag, got %+v", repo.flagOverrides)
+	}
+	if err := svc.RemoveFlagOverride(ctx, RemoveFlagOverrideRequest{FlagKey: "editor", AccountID: "acct"}); errors.Cause(err) != ErrNotFound {
+		t.Errorf("removing a missing override: expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,376 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Group collects users of an account, such as "Clinicians". Groups may be
+// nested under a parent group, whose members include the members of all of
+// its descendants.
+type Group struct {
+	ID          string    `db:"id"`
+	AccountID   string    `db:"account_id"`
+	ParentID    *string   `db:"parent_id"`
+	Name        string    `db:"name"`
+	Description string    `db:"description"`
+	UpdatedAt   time.Time `db:"updated_at"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+// GroupMember places a user of an account in one of its groups.
+type GroupMember struct {
+	GroupID   string    `db:"group_id"`
+	AccountID string    `db:"account_id"`
+	UserID    string    `db:"user_id"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type CreateGroupRequest struct {
+	AccountID   string
+	ParentID    string
+	Name        string
+	Description string
+}
+
+type GetGroupRequest struct {
+	AccountID string
+	ID        string
+}
+
+type ListGroupsRequest struct {
+	AccountID string
+}
+
+type UpdateGroupRequest struct {
+	AccountID   string
+	ID          string
+	Name        *string
+	Description *string
+	// ParentID moves the group when set, to the top level if it is empty.
+	ParentID *string
+}
+
+type DeleteGroupRequest struct {
+	AccountID string
+	ID        string
+}
+
+type AddGroupMemberRequest struct {
+	AccountID string
+	GroupID   string
+	UserID    string
+}
+
+type RemoveGroupMemberRequest struct {
+	AccountID string
+	GroupID   string
+	UserID    string
+}
+
+type ListGroupMembersRequest struct {
+	AccountID string
+	GroupID   string
+	// Recursive includes the members of nested groups.
+	Recursive bool
+}
+
+func (svc *customersService) CreateGroup(ctx context.Context, req CreateGroupRequest) (group Group, err error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return group, errors.Wrap(ErrInvalidArgument, "group name is required")
+	}
+
+	if _, err = svc.repo.GetAccountByID(ctx, req.AccountID); err != nil {
+		return
+	}
+
+	if err = svc.ensureGroupNameAvailable(ctx, req.AccountID, "", name); err != nil {
+		return
+	}
+
+	var parentID *string
+	if req.ParentID != "" {
+		if _, err = svc.group(ctx, req.AccountID, req.ParentID); err != nil {
+			return
+		}
+		parentID = &req.ParentID
+	}
+
+	group, err = svc.repo.InsertGroup(ctx, Group{
+		AccountID:   req.AccountID,
+		ParentID:    parentID,
+		Name:        name,
+		Description: req.Description,
+	})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to insert group", "error", err.Error())
+	}
+
+	return
+}
+
+func (svc *customersService) GetGroup(ctx context.Context, req GetGroupRequest) (Group, error) {
+	return svc.group(ctx, req.AccountID, req.ID)
+}
+
+func (svc *customersService) ListGroups(ctx context.Context, req ListGroupsRequest) (groups []Group, err error) {
+	groups, err = svc.repo.SelectGroups(ctx, map[string]interface{}{"account_id": req.AccountID})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to retrieve groups", "error", err.Error())
+	}
+
+	return
+}
+
+// UpdateGroup renames, describes or moves a group. A group cannot be moved
+// beneath itself or any of its nested groups.
+func (svc *customersService) UpdateGroup(ctx context.Context, req UpdateGroupRequest) (group Group, err error) {
+	group, err = svc.group(ctx, req.AccountID, req.ID)
+	if err != nil {
+		return
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return group, errors.Wrap(ErrInvalidArgument, "group name is required")
+		}
+
+		if err = svc.ensureGroupNameAvailable(ctx, group.AccountID, group.ID, name); err != nil {
+			return
+		}
+		group.Name = name
+	}
+
+	if req.Description != nil {
+		group.Description = *req.Description
+	}
+
+	if req.ParentID != nil {
+		group.ParentID = nil
+		if *req.ParentID != "" {
+			descendants, err := svc.groupTree(ctx, group)
+			if err != nil {
+				return group, err
+			}
+
+			for _, id := range descendants {
+				if id == *req.ParentID {
+					return group, errors.Wrapf(ErrFailedPrecondition, "group %s is nested within group %s", id, group.ID)
+				}
+			}
+
+			if _, err := svc.group(ctx, group.AccountID, *req.ParentID); err != nil {
+				return group, err
+			}
+			group.ParentID = req.ParentID
+		}
+	}
+
+	group, err = svc.repo.UpdateGroup(ctx, group)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to update group", "error", err.Error())
+	}
+
+	return
+}
+
+// DeleteGroup deletes a group and its memberships. Groups nested within it
+// move up to its parent.
+func (svc *customersService) DeleteGroup(ctx context.Context, req DeleteGroupRequest) (err error) {
+	group, err := svc.group(ctx, req.AccountID, req.ID)
+	if err != nil {
+		return
+	}
+
+	children, err := svc.repo.SelectGroups(ctx, map[string]interface{}{"parent_id": group.ID})
+	if err != nil {
+		return
+	}
+
+	for _, child := range children {
+		child.ParentID = group.ParentID
+		if _, err = svc.repo.UpdateGroup(ctx, child); err != nil {
+			svc.logger.Log("level", "error", "message", "failed to update group", "error", err.Error())
+			return
+		}
+	}
+
+	err = svc.repo.DeleteGroup(ctx, group)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to delete group", "error", err.Error())
+	}
+
+	return
+}
+
+// AddGroupMember adds a user of the group's account to the group.
+func (svc *customersService) AddGroupMember(ctx context.Context, req AddGroupMemberRequest) (member GroupMember, err error) {
+	if _, err = svc.group(ctx, req.AccountID, req.GroupID); err != nil {
+		return
+	}
+
+	if _, err = svc.membership(ctx, req.AccountID, req.UserID); err != nil {
+		return
+	}
+
+	existing, err := svc.repo.SelectGroupMembers(ctx, map[string]interface{}{
+		"group_id": req.GroupID,
+		"user_id":  req.UserID,
+	})
+	if err != nil {
+		return
+	}
+
+	if len(existing) > 0 {
+		return existing[0], nil
+	}
+
+	member, err = svc.repo.InsertGroupMember(ctx, GroupMember{
+		GroupID:   req.GroupID,
+		AccountID: req.AccountID,
+		UserID:    req.UserID,
+	})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to insert group member", "error", err.Error())
+	}
+
+	return
+}
+
+func (svc *customersService) RemoveGroupMember(ctx context.Context, req RemoveGroupMemberRequest) (err error) {
+	if _, err = svc.group(ctx, req.AccountID, req.GroupID); err != nil {
+		return
+	}
+
+	members, err := svc.repo.SelectGroupMembers(ctx, map[string]interface{}{
+		"group_id": req.GroupID,
+		"user_id":  req.UserID,
+	})
+	if err != nil {
+		return
+	}
+
+	if len(members) == 0 {
+		return errors.Wrapf(ErrNotFound, "user %s is not a member of group %s", req.UserID, req.GroupID)
+	}
+
+	err = svc.repo.DeleteGroupMember(ctx, members[0])
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to delete group member", "error", err.Error())
+	}
+
+	return
+}
+
+// ListGroupMembers lists the members of a group, including the members of its
+// nested groups when Recursive is set. Users belonging to several of the
+// groups are listed once, with the group nearest the top.
+func (svc *customersService) ListGroupMembers(ctx context.Context, req ListGroupMembersRequest) (members []GroupMember, err error) {
+	group, err := svc.group(ctx, req.AccountID, req.GroupID)
+	if err != nil {
+		return
+	}
+
+	groupIDs := []string{group.ID}
+	if req.Recursive {
+		if groupIDs, err = svc.groupTree(ctx, group); err != nil {
+			return
+		}
+	}
+
+	selected, err := svc.repo.SelectGroupMembers(ctx, map[string]interface{}{"group_id": groupIDs})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to retrieve group members", "error", err.Error())
+		return
+	}
+
+	depth := map[string]int{}
+	for i, id := range groupIDs {
+		depth[id] = i
+	}
+
+	nearest := map[string]GroupMember{}
+	for _, member := range selected {
+		if existing, ok := nearest[member.UserID]; !ok || depth[member.GroupID] < depth[existing.GroupID] {
+			nearest[member.UserID] = member
+		}
+	}
+
+	members = []GroupMember{}
+	for _, member := range selected {
+		if nearest[member.UserID] == member {
+			members = append(members, member)
+		}
+	}
+
+	return
+}
+
+// group loads a group, failing with ErrNotFound if it belongs to another account.
+func (svc *customersService) group(ctx context.Context, accountID, id string) (Group, error) {
+	groups, err := svc.repo.SelectGroups(ctx, map[string]interface{}{
+		"id":         id,
+		"account_id": accountID,
+	})
+	if err != nil {
+		return Group{}, err
+	}
+
+	if len(groups) == 0 {
+		return Group{}, errors.Wrapf(ErrNotFound, "group %s", id)
+	}
+
+	return groups[0], nil
+}
+
+// groupTree returns the ids of a group and every group nested within it,
+// ordered breadth first.
+func (svc *customersService) groupTree(ctx context.Context, group Group) ([]string, error) {
+	groups, err := svc.repo.SelectGroups(ctx, map[string]interface{}{"account_id": group.AccountID})
+	if err != nil {
+		return nil, err
+	}
+
+	children := map[string][]string{}
+	for _, g := range groups {
+		if g.ParentID != nil {
+			children[*g.ParentID] = append(children[*g.ParentID], g.ID)
+		}
+	}
+
+	tree := []string{group.ID}
+	seen := map[string]bool{group.ID: true}
+	for i := 0; i < len(tree); i++ {
+		for _, child := range children[tree[i]] {
+			if !seen[child] {
+				seen[child] = true
+				tree = append(tree, child)
+			}
+		}
+	}
+
+	return tree, nil
+}
+
+func (svc *customersService) ensureGroupNameAvailable(ctx context.Context, accountID, groupID, name string) error {
+	groups, err := svc.repo.SelectGroups(ctx, map[string]interface{}{
+		"account_id": accountID,
+		"name":       name,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, g := range groups {
+		if g.ID != groupID {
+			return errors.Wrapf(ErrAlreadyExists, "account %s already has a group named %q", accountID, name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestGroups(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	repo.accounts["acct"] = Account{ID: "acct", Status: AccountActive}
+	repo.accounts["other"] = Account{ID: "other", Status: AccountActive}
+	for _, id := range []string{"alice", "bob", "carol"} {
+		repo.users[id] = User{ID: id, Kind: UserHuman}
+		repo.memberships = append(repo.memberships, Membership{AccountID: "acct", UserID: id, Role: RoleMember})
+	}
+	svc := newTestService(repo)
+
+	clinicians, err := svc.CreateGroup(ctx, CreateGroupRequest{AccountID: "acct", Name: "Clinicians"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nurses, err := svc.CreateGroup(ctx, CreateGroupRequest{AccountID: "acct", Name: "Nurses", ParentID: clinicians.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.CreateGroup(ctx, CreateGroupRequest{AccountID: "acct", Name: "clinicians"}); errors.Cause(err) != ErrAlreadyExists {
+		t.Errorf("duplicate name: expected ErrAlreadyExists, got %v", err)
+	}
+
+	parent := nurses.ID
+	if _, err := svc.UpdateGroup(ctx, UpdateGroupRequest{AccountID: "acct", ID: clinicians.ID, ParentID: &parent}); errors.Cause(err) != ErrFailedPrecondition {
+		t.Errorf("nesting a group within itself: expected ErrFailedPrecondition, got %v", err)
+	}
+
+	add := func(group Group, user string) {
+		if _, err := svc.AddGroupMember(ctx, AddGroupMemberRequest{AccountID: "acct", GroupID: group.ID, UserID: user}); err != nil {
+			t.Fatalf("adding %s to %s: %v", user, group.Name, err)
+		}
+	}
+	add(clinicians, "alice")
+	add(nurses, "bob")
+	add(nurses, "alice")
+
+	if _, err := svc.AddGroupMember(ctx, AddGroupMemberRequest{AccountID: "acct", GroupID: nurses.ID, UserID: "mallory"}); errors.Cause(err) != ErrNotFound {
+		t.Errorf("adding a non-member: expected ErrNotFound, got %v", err)
+	}
+	if _, err := svc.GetGroup(ctx, GetGroupRequest{AccountID: "other", ID: nurses.ID}); errors.Cause(err) != ErrNotFound {
+		t.Errorf("group of another account: expected ErrNotFound, got %v", err)
+	}
+
+	members, err := svc.ListGroupMembers(ctx, ListGroupMembersRequest{AccountID: "acct", GroupID: clinicians.ID, Recursive: true})
+	if err != nil || len(members) != 2 {
+		t.Fatalf("unexpected members %+v, %v", members, err)
+	}
+	for _, m := range members {
+		if m.UserID == "alice" && m.GroupID != clinicians.ID {
+			t.Errorf("members should be listed with their nearest group, got %+v", m)
+		}
+	}
+
+	users, err := svc.FetchUsers(ctx, FetchUsersRequest{GroupID: clinicians.ID})
+	if err != nil || len(users) != 2 {
+		t.Errorf("users of nested groups: got %+v, %v", users, err)
+	}
+
+	if _, err := svc.FetchUsers(ctx, FetchUsersRequest{GroupID: clinicians.ID, AccountIDs: []string{"other"}}); errors.Cause(err) != ErrNotFound {
+		t.Errorf("group outside the caller's accounts: expected ErrNotFound, got %v", err)
+	}
+
+	if err := svc.DeleteGroup(ctx, DeleteGroupRequest{AccountID: "acct", ID: clinicians.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if group, _ := svc.GetGroup(ctx, GetGroupRequest{AccountID: "acct", ID: nurses.ID}); group.ParentID != nil {
+		t.Errorf("nested groups should move up when their parent is deleted, got %+v", group)
+	}
+}
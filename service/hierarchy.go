@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+type SetAccountParentRequest struct {
+	AccountID string
+	// ParentID is the new parent of the account, or empty to detach it.
+	ParentID string
+}
+
+type ListChildAccountsRequest struct {
+	AccountID string
+	// Recursive lists every descendant rather than only direct children.
+	Recursive bool
+}
+
+type ListAncestorAccountsRequest struct {
+	AccountID string
+}
+
+// SetAccountParent places an account under a parent account, or detaches it
+// when no parent is given. An account cannot be placed beneath itself or any
+// of its descendants.
+func (svc *customersService) SetAccountParent(ctx context.Context, req SetAccountParentRequest) (account Account, err error) {
+	account, err = svc.repo.GetAccountByID(ctx, req.AccountID)
+	if err != nil {
+		return
+	}
+
+	if req.ParentID == "" {
+		if account.ParentID == nil {
+			return
+		}
+		account.ParentID = nil
+	} else {
+		if err = svc.ensureParent(ctx, account.ID, req.ParentID); err != nil {
+			return
+		}
+		account.ParentID = &req.ParentID
+	}
+
+	account, err = svc.repo.UpdateAccount(ctx, account)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to update account parent", "error", err.Error())
+	}
+
+	return
+}
+
+// ListChildAccounts lists the children of an account, or its whole subtree
+// excluding the account itself when Recursive is set.
+func (svc *customersService) ListChildAccounts(ctx context.Context, req ListChildAccountsRequest) (children []Account, err error) {
+	if _, err = svc.repo.GetAccountByID(ctx, req.AccountID); err != nil {
+		return
+	}
+
+	filters := map[string]interface{}{"parent_id": req.AccountID}
+	if req.Recursive {
+		filters = map[string]interface{}{"subtree_of": req.AccountID}
+	}
+
+	accounts, err := svc.repo.SelectAccounts(ctx, filters)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to retrieve child accounts", "error", err.Error())
+		return
+	}
+
+	children = []Account{}
+	for _, account := range accounts {
+		if account.ID != req.AccountID {
+			children = append(children, account)
+		}
+	}
+
+	return
+}
+
+// ListAncestorAccounts lists the parents of an account up to the root of its
+// hierarchy, nearest first.
+func (svc *customersService) ListAncestorAccounts(ctx context.Context, req ListAncestorAccountsRequest) (ancestors []Account, err error) {
+	ancestors, err = svc.repo.SelectAccountAncestors(ctx, req.AccountID)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to retrieve ancestor accounts", "error", err.Error())
+	}
+
+	return
+}
+
+// ensureParent fails unless parentID names an account which may become the
+// parent of accountID. accountID is empty for accounts being created.
+func (svc *customersService) ensureParent(ctx context.Context, accountID, parentID string) error {
+	if parentID == accountID {
+		return errors.Wrap(ErrInvalidArgument, "an account cannot be its own parent")
+	}
+
+	parent, err := svc.repo.GetAccountByID(ctx, parentID)
+	if err != nil {
+		return err
+	}
+
+	if parent.Status == AccountMerged {
+		return errors.Wrapf(ErrFailedPrecondition, "account %s has been merged", parent.ID)
+	}
+
+	if accountID == "" {
+		return nil
+	}
+
+	ancestors, err := svc.repo.SelectAccountAncestors(ctx, parentID)
+	if err != nil {
+		return err
+	}
+
+	for _, ancestor := range ancestors {
+		if ancestor.ID == accountID {
+			return errors.Wrapf(ErrFailedPrecondition, "account %s is a descendant of account %s", parentID, accountID)
+		}
+	}
+
+	return nil
+}
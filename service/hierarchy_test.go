@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestAccountHierarchy(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	for _, id := range []string{"corp", "emea", "uk", "us"} {
+		repo.accounts[id] = Account{ID: id, Status: AccountActive}
+	}
+	svc := newTestService(repo)
+
+	for _, link := range [][2]string{{"emea", "corp"}, {"uk", "emea"}, {"us", "corp"}} {
+		if _, err := svc.SetAccountParent(ctx, SetAccountParentRequest{AccountID: link[0], ParentID: link[1]}); err != nil {
+			t.Fatalf("setting parent of %s: %v", link[0], err)
+		}
+	}
+
+	if _, err := svc.SetAccountParent(ctx, SetAccountParentRequest{AccountID: "corp", ParentID: "uk"}); errors.Cause(err) != ErrFailedPrecondition {
+		t.Errorf("cycle: expected ErrFailedPrecondition, got %v", err)
+	}
+	if _, err := svc.SetAccountParent(ctx, SetAccountParentRequest{AccountID: "corp", ParentID: "corp"}); errors.Cause(err) != ErrInvalidArgument {
+		t.Errorf("own parent: expected ErrInvalidArgument, got %v", err)
+	}
+
+	ids := func(accounts []Account) map[string]bool {
+		set := map[string]bool{}
+		for _, a := range accounts {
+			set[a.ID] = true
+		}
+		return set
+	}
+
+	children, _ := svc.ListChildAccounts(ctx, ListChildAccountsRequest{AccountID: "corp"})
+	if got := ids(children); len(got) != 2 || !got["emea"] || !got["us"] {
+		t.Errorf("unexpected children %v", got)
+	}
+
+	descendants, _ := svc.ListChildAccounts(ctx, ListChildAccountsRequest{AccountID: "corp", Recursive: true})
+	if got := ids(descendants); len(got) != 3 || !got["uk"] {
+		t.Errorf("unexpected descendants %v", got)
+	}
+
+	ancestors, _ := svc.ListAncestorAccounts(ctx, ListAncestorAccountsRequest{AccountID: "uk"})
+	if len(ancestors) != 2 || ancestors[0].ID != "emea" || ancestors[1].ID != "corp" {
+		t.Errorf("unexpected ancestors %+v", ancestors)
+	}
+
+	subtree, _ := svc.FetchAccounts(ctx, FetchAccountsRequest{SubtreeOf: "emea"})
+	if got := ids(subtree); len(got) != 2 || !got["emea"] || !got["uk"] {
+		t.Errorf("unexpected subtree %v", got)
+	}
+
+	if _, err := svc.SetAccountParent(ctx, SetAccountParentRequest{AccountID: "uk"}); err != nil || repo.accounts["uk"].ParentID != nil {
+		t.Errorf("detaching account: %v", err)
+	}
+}
@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// invitationTTL is how long an invitation token may be used after it was
+// last issued.
+const invitationTTL = 7 * 24 * time.Hour
+
+type InvitationStatus string
+
+const (
+	InvitationPending  InvitationStatus = "pending"
+	InvitationAccepted InvitationStatus = "accepted"
+	InvitationRevoked  InvitationStatus = "revoked"
+)
+
+// Invitation offers a role within an account to whoever holds its token. Only
+// a hash of the token is stored.
+type Invitation struct {
+	ID         string           `db:"id"`
+	AccountID  string           `db:"account_id"`
+	Email      string           `db:"email"`
+	Name       string           `db:"name"`
+	Role       Role             `db:"role"`
+	Status     InvitationStatus `db:"status"`
+	TokenHash  string           `db:"token_hash"`
+	InvitedBy  string           `db:"invited_by"`
+	UserID     *string          `db:"user_id"`
+	ExpiresAt  time.Time        `db:"expires_at"`
+	AcceptedAt *time.Time       `db:"accepted_at"`
+	UpdatedAt  time.Time        `db:"updated_at"`
+	CreatedAt  time.Time        `db:"created_at"`
+}
+
+// Expired reports whether the invitation can no longer be accepted.
+func (i Invitation) Expired(now time.Time) bool {
+	return !now.Before(i.ExpiresAt)
+}
+
+// IssuedInvitation is returned when an invitation is created or re-sent and
+// carries the plaintext token, which is never available again.
+type IssuedInvitation struct {
+	Invitation
+	Token string
+}
+
+type InviteUserRequest struct {
+	AccountID string
+	Email     string
+	Name      string
+	Role      Role
+	InvitedBy string
+}
+
+type ListInvitationsRequest struct {
+	AccountID string
+	Status    InvitationStatus
+}
+
+type RevokeInvitationRequest struct {
+	AccountID string
+	ID        string
+}
+
+type AcceptInvitationRequest struct {
+	Token string
+	Name  string
+}
+
+// InviteUser issues an invitation to join an account. Inviting an address
+// with a pending invitation re-issues that invitation with a fresh token and
+// expiry rather than creating a second one.
+func (svc *customersService) InviteUser(ctx context.Context, req InviteUserRequest) (issued IssuedInvitation, err error) {
+	role := req.Role
+	if role == "" {
+		role = RoleMember
+	}
+
+	if !role.Valid() {
+		return issued, errors.Wrapf(ErrInvalidArgument, "unknown role %q", role)
+	}
+
+	email, _, err := normalizeEmail(req.Email)
+	if err != nil {
+		return
+	}
+
+	account, err := svc.repo.GetAccountByID(ctx, req.AccountID)
+	if err != nil {
+		return
+	}
+
+	if account.Status != AccountActive {
+		return issued, errors.Wrapf(ErrFailedPrecondition, "account %s is not active", account.ID)
+	}
+
+	if err = svc.ensureNotMember(ctx, req.AccountID, email); err != nil {
+		return
+	}
+
+	token, hash, err := newToken()
+	if err != nil {
+		return
+	}
+
+	pending, err := svc.repo.SelectInvitations(ctx, map[string]interface{}{
+		"account_id": req.AccountID,
+		"email":      email,
+		"status":     InvitationPending,
+	})
+	if err != nil {
+		return
+	}
+
+	if len(pending) > 0 {
+		invitation := pending[0]
+		invitation.Role = role
+		invitation.Name = req.Name
+		invitation.InvitedBy = req.InvitedBy
+		invitation.TokenHash = hash
+		invitation.ExpiresAt = time.Now().Add(invitationTTL)
+
+		issued.Invitation, err = svc.repo.UpdateInvitation(ctx, invitation)
+		if err != nil {
+			svc.logger.Log("level", "error", "message", "failed to update invitation", "error", err.Error())
+			return
+		}
+		issued.Token = token
+
+		return
+	}
+
+	issued.Invitation, err = svc.repo.InsertInvitation(ctx, Invitation{
+		AccountID: req.AccountID,
+		Email:     email,
+		Name:      req.Name,
+		Role:      role,
+		Status:    InvitationPending,
+		TokenHash: hash,
+		InvitedBy: req.InvitedBy,
+		ExpiresAt: time.Now().Add(invitationTTL),
+	})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to insert invitation", "error", err.Error())
+		return
+	}
+	issued.Token = token
+
+	return
+}
+
+func (svc *customersService) ListInvitations(ctx context.Context, req ListInvitationsRequest) (invitations []Invitation, err error) {
+	filters := map[string]interface{}{"account_id": req.AccountID}
+	if req.Status != "" {
+		filters["status"] = req.Status
+	}
+
+	invitations, err = svc.repo.SelectInvitations(ctx, filters)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to retrieve invitations", "error", err.Error())
+	}
+
+	return
+}
+
+func (svc *customersService) RevokeInvitation(ctx context.Context, req RevokeInvitationRequest) (invitation Invitation, err error) {
+	invitations, err := svc.repo.SelectInvitations(ctx, map[string]interface{}{
+		"id":         req.ID,
+		"account_id": req.AccountID,
+	})
+	if err != nil {
+		return
+	}
+
+	if len(invitations) == 0 {
+		return invitation, errors.Wrapf(ErrNotFound, "invitation %s", req.ID)
+	}
+
+	invitation = invitations[0]
+	switch invitation.Status {
+	case InvitationRevoked:
+		return
+	case InvitationAccepted:
+		return invitation, errors.Wrapf(ErrFailedPrecondition, "invitation %s has already been accepted", req.ID)
+	}
+
+	invitation.Status = InvitationRevoked
+	invitation, err = svc.repo.UpdateInvitation(ctx, invitation)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to revoke invitation", "error", err.Error())
+	}
+
+	return
+}
+
+// AcceptInvitation redeems an invitation token, creating the user if no user
+// with the invited email exists yet and granting them the invited role.
+func (svc *customersService) AcceptInvitation(ctx context.Context, req AcceptInvitationRequest) (membership Membership, err error) {
+	invitations, err := svc.repo.SelectInvitations(ctx, map[string]interface{}{
+		"token_hash": hashToken(req.Token),
+		"status":     InvitationPending,
+	})
+	if err != nil {
+		return
+	}
+
+	// unknown, used, revoked and expired tokens are indistinguishable to the caller
+	if len(invitations) == 0 || invitations[0].Expired(time.Now()) {
+		return membership, errors.Wrap(ErrNotFound, "invitation is invalid or has expired")
+	}
+	invitation := invitations[0]
+
+	account, err := svc.repo.GetAccountByID(ctx, invitation.AccountID)
+	if err != nil {
+		return
+	}
+
+	if account.Status != AccountActive {
+		return membership, errors.Wrapf(ErrFailedPrecondition, "account %s is not active", account.ID)
+	}
+
+	// someone added directly since being invited keeps their membership, and
+	// the invitation stays pending
+	if err = svc.ensureNotMember(ctx, invitation.AccountID, invitation.Email); err != nil {
+		return
+	}
+
+	user, err := svc.repo.GetUserByEmail(ctx, invitation.Email)
+	if err != nil && errors.Cause(err) != ErrNotFound {
+		return
+	}
+
+	created := errors.Cause(err) == ErrNotFound
+	if !created && !user.Interactive() {
+		return membership, errors.Wrapf(ErrFailedPrecondition, "invitation was sent to service account %s", user.ID)
+	}
+
+	if created {
+		name := req.Name
+		if name == "" {
+			name = invitation.Name
+		}
+
+		email, canonical, normalizeErr := normalizeEmail(invitation.Email)
+		if normalizeErr != nil {
+			return membership, normalizeErr
+		}
+
+		user = User{
+			Kind:           UserHuman,
+			Email:          email,
+			EmailCanonical: canonical,
+			Name:           name,
+			Status:         svc.initialUserStatus(),
+		}
+	}
+
+	// an invitation cannot be redeemed while the account has no seat free, so
+	// it can be accepted once one has been freed. The user is only created
+	// once a seat is known to be free, and together with their membership
+	// and the consumed invitation.
+	err = svc.repo.Transaction(ctx, func(ctx context.Context) error {
+		return svc.withSeat(ctx, invitation.AccountID, func(ctx context.Context) (err error) {
+			if created {
+				user, err = svc.repo.InsertUser(ctx, user)
+				if err != nil {
+					svc.logger.Log("level", "error", "message", "failed to insert user", "error", err.Error())
+					return
+				}
+			}
+
+			// consume the invitation before granting access so a token can
+			// only be redeemed once, even by concurrent callers
+			now := time.Now()
+			invitation.Status = InvitationAccepted
+			invitation.AcceptedAt = &now
+			invitation.UserID = &user.ID
+			if _, err = svc.repo.AcceptInvitation(ctx, invitation); errors.Cause(err) == ErrNotFound {
+				return errors.Wrap(ErrNotFound, "invitation is invalid or has expired")
+			} else if err != nil {
+				svc.logger.Log("level", "error", "message", "failed to accept invitation", "error", err.Error())
+				return
+			}
+
+			membership, err = svc.repo.InsertMembership(ctx, Membership{
+				AccountID: invitation.AccountID,
+				UserID:    user.ID,
+				Role:      invitation.Role,
+				Status:    UserActive,
+			})
+			if err != nil {
+				svc.logger.Log("level", "error", "message", "failed to insert membership", "error", err.Error())
+			}
+
+			return
+		})
+	})
+	if err != nil {
+		return
+	}
+
+	if created {
+		svc.sendInitialVerification(ctx, user)
+	}
+
+	return
+}
+
+// ensureNotMember fails when a user with the email already belongs to the account.
+func (svc *customersService) ensureNotMember(ctx context.Context, accountID, email string) error {
+	user, err := svc.repo.GetUserByEmail(ctx, email)
+	if errors.Cause(err) == ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	_, err = svc.membership(ctx, accountID, user.ID)
+	if err == nil {
+		return errors.Wrapf(ErrAlreadyExists, "%s already belongs to account %s", email, accountID)
+	} else if errors.Cause(err) != ErrNotFound {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestInvitations(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	repo.accounts["acct"] = Account{ID: "acct", Status: AccountActive}
+	svc := newTestService(repo)
+
+	first, err := svc.InviteUser(ctx, InviteUserRequest{AccountID: "acct", Email: "alice@example.com", Role: RoleAdmin})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := svc.InviteUser(ctx, InviteUserRequest{AccountID: "acct", Email: "alice@example.com", Role: RoleBilling})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.ID != second.ID || len(repo.invitations) != 1 {
+		t.Fatalf("re-inviting should reuse the pending invitation, got %d invitations", len(repo.invitations))
+	}
+	if first.Token == second.Token {
+		t.Error("re-inviting should issue a fresh token")
+	}
+	if repo.invitations[0].TokenHash == second.Token {
+		t.Error("the plaintext token must not be stored")
+	}
+
+	if _, err := svc.AcceptInvitation(ctx, AcceptInvitationRequest{Token: first.Token}); errors.Cause(err) != ErrNotFound {
+		t.Errorf("superseded token: expected ErrNotFound, got %v", err)
+	}
+
+	membership, err := svc.AcceptInvitation(ctx, AcceptInvitationRequest{Token: second.Token, Name: "Alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if membership.Role != RoleBilling || repo.users[membership.UserID].Email != "alice@example.com" {
+		t.Errorf("unexpected membership %+v", membership)
+	}
+
+	if _, err := svc.AcceptInvitation(ctx, AcceptInvitationRequest{Token: second.Token}); errors.Cause(err) != ErrNotFound {
+		t.Errorf("tokens are single use: expected ErrNotFound, got %v", err)
+	}
+
+	if _, err := svc.InviteUser(ctx, InviteUserRequest{AccountID: "acct", Email: "alice@example.com"}); errors.Cause(err) != ErrAlreadyExists {
+		t.Errorf("inviting a member: expected ErrAlreadyExists, got %v", err)
+	}
+
+	expired, err := svc.InviteUser(ctx, InviteUserRequest{AccountID: "acct", Email: "bob@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.invitations[1].ExpiresAt = time.Now().Add(-time.Minute)
+	if _, err := svc.AcceptInvitation(ctx, AcceptInvitationRequest{Token: expired.Token}); errors.Cause(err) != ErrNotFound {
+		t.Errorf("expired token: expected ErrNotFound, got %v", err)
+	}
+
+	joined, err := svc.InviteUser(ctx, InviteUserRequest{AccountID: "acct", Email: "carol@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CreateUser(ctx, CreateUserRequest{AccountID: "acct", Email: "carol@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.AcceptInvitation(ctx, AcceptInvitationRequest{Token: joined.Token}); errors.Cause(err) != ErrAlreadyExists {
+		t.Errorf("accepted by a member: expected ErrAlreadyExists, got %v", err)
+	}
+	if repo.invitations[2].Status != InvitationPending {
+		t.Errorf("a refused invitation should stay pending, got %s", repo.invitations[2].Status)
+	}
+
+	suspended, err := svc.InviteUser(ctx, InviteUserRequest{AccountID: "acct", Email: "dave@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.accounts["acct"] = Account{ID: "acct", Status: AccountSuspended}
+	if _, err := svc.AcceptInvitation(ctx, AcceptInvitationRequest{Token: suspended.Token}); errors.Cause(err) != ErrFailedPrecondition {
+		t.Errorf("suspended account: expected ErrFailedPrecondition, got %v", err)
+	}
+	if _, err := repo.GetUserByEmail(ctx, "dave@example.com"); errors.Cause(err) != ErrNotFound {
+		t.Errorf("no user should be created for a suspended account: %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxLabels is the number of labels an account or user may carry.
+	maxLabels = 64
+	// maxMetadataSize is the size in bytes of the largest metadata document.
+	maxMetadataSize = 32 << 10
+)
+
+var (
+	// labelNamePattern matches label names and non-empty label values: up to
+	// 63 alphanumerics, dashes, underscores and dots, starting and ending
+	// with an alphanumeric.
+	labelNamePattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9._-]{0,61}[A-Za-z0-9])?$`)
+	// labelPrefixPattern matches the optional DNS subdomain prefixing a label
+	// name, such as "billing.example.com" in "billing.example.com/tier".
+	labelPrefixPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*$`)
+)
+
+// validateLabelKey checks a key of the form "[prefix/]name".
+func validateLabelKey(key string) error {
+	name := key
+	if i := strings.IndexByte(key, '/'); i >= 0 {
+		prefix := key[:i]
+		name = key[i+1:]
+		if len(prefix) > 253 || !labelPrefixPattern.MatchString(prefix) {
+			return errors.Wrapf(ErrInvalidArgument, "invalid label key %q: prefix must be a DNS subdomain", key)
+		}
+	}
+
+	if !labelNamePattern.MatchString(name) {
+		return errors.Wrapf(ErrInvalidArgument, "invalid label key %q", key)
+	}
+
+	return nil
+}
+
+func validateLabelValue(key, value string) error {
+	if value != "" && !labelNamePattern.MatchString(value) {
+		return errors.Wrapf(ErrInvalidArgument, "invalid value %q for label %q", value, key)
+	}
+
+	return nil
+}
+
+func validateLabels(labels map[string]string) error {
+	if len(labels) > maxLabels {
+		return errors.Wrapf(ErrInvalidArgument, "at most %d labels are allowed", maxLabels)
+	}
+
+	for key, value := range labels {
+		if err := validateLabelKey(key); err != nil {
+			return err
+		}
+		if err := validateLabelValue(key, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyLabels sets and removes labels, returning the validated result without
+// modifying current.
+func applyLabels(current, set map[string]string, remove []string) (map[string]string, error) {
+	labels := make(map[string]string, len(current)+len(set))
+	for key, value := range current {
+		labels[key] = value
+	}
+	for _, key := range remove {
+		delete(labels, key)
+	}
+	for key, value := range set {
+		labels[key] = value
+	}
+
+	if err := validateLabels(labels); err != nil {
+		return nil, err
+	}
+
+	return labels, nil
+}
+
+// validateMetadata checks that metadata is a JSON object within the size limit.
+// Empty metadata is allowed.
+func validateMetadata(metadata json.RawMessage) error {
+	if len(metadata) == 0 {
+		return nil
+	}
+
+	if len(metadata) > maxMetadataSize {
+		return errors.Wrapf(ErrInvalidArgument, "metadata may be at most %d bytes", maxMetadataSize)
+	}
+
+	var object map[string]json.RawMessage
+	if !bytes.HasPrefix(bytes.TrimSpace(metadata), []byte("{")) || json.Unmarshal(metadata, &object) != nil {
+		return errors.Wrap(ErrInvalidArgument, "metadata must be a JSON object")
+	}
+
+	return nil
+}
+
+type SelectorOperator string
+
+const (
+	SelectorEquals    SelectorOperator = "="
+	SelectorNotEquals SelectorOperator = "!="
+	SelectorExists    SelectorOperator = "exists"
+	SelectorNotExists SelectorOperator = "!exists"
+)
+
+// Requirement is a single condition of a label selector.
+type Requirement struct {
+	Key      string
+	Operator SelectorOperator
+	Value    string
+}
+
+// Matches reports whether the labels satisfy the requirement. A label which
+// is absent satisfies "!=".
+func (r Requirement) Matches(labels map[string]string) bool {
+	value, ok := labels[r.Key]
+	switch r.Operator {
+	case SelectorEquals:
+		return ok && value == r.Value
+	case SelectorNotEquals:
+		return !ok || value != r.Value
+	case SelectorExists:
+		return ok
+	case SelectorNotExists:
+		return !ok
+	}
+
+	return false
+}
+
+func (r Requirement) String() string {
+	switch r.Operator {
+	case SelectorExists:
+		return r.Key
+	case SelectorNotExists:
+		return "!" + r.Key
+	}
+
+	return r.Key + string(r.Operator) + r.Value
+}
+
+// Selector matches labels satisfying all of its requirements. The empty
+// selector matches everything.
+type Selector []Requirement
+
+// ParseSelector parses a comma separated list of requirements, each of the
+// form "key=value", "key==value", "key!=value", "key" or "!key".
+func ParseSelector(selector string) (Selector, error) {
+	var parsed Selector
+	if strings.TrimSpace(selector) == "" {
+		return parsed, nil
+	}
+
+	for _, term := range strings.Split(selector, ",") {
+		term = strings.TrimSpace(term)
+
+		var r Requirement
+		switch {
+		case strings.HasPrefix(term, "!"):
+			r = Requirement{Key: strings.TrimSpace(term[1:]), Operator: SelectorNotExists}
+		case strings.Contains(term, "!="):
+			parts := strings.SplitN(term, "!=", 2)
+			r = Requirement{Key: strings.TrimSpace(parts[0]), Operator: SelectorNotEquals, Value: strings.TrimSpace(parts[1])}
+		case strings.Contains(term, "="):
+			parts := strings.SplitN(term, "=", 2)
+			r = Requirement{Key: strings.TrimSpace(parts[0]), Operator: SelectorEquals, Value: strings.TrimSpace(strings.TrimPrefix(parts[1], "="))}
+		default:
+			r = Requirement{Key: term, Operator: SelectorExists}
+		}
+
+		if err := validateLabelKey(r.Key); err != nil {
+			return nil, errors.Wrapf(err, "parsing selector %q", selector)
+		}
+		if err := validateLabelValue(r.Key, r.Value); err != nil {
+			return nil, errors.Wrapf(err, "parsing selector %q", selector)
+		}
+
+		parsed = append(parsed, r)
+	}
+
+	return parsed, nil
+}
+
+// Matches reports whether the labels satisfy every requirement.
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, r := range s {
+		if !r.Matches(labels) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s Selector) String() string {
+	terms := make([]string, len(s))
+	for i, r := range s {
+		terms[i] = r.String()
+	}
+
+	return strings.Join(terms, ",")
+}
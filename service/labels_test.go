@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestParseSelector(t *testing.T) {
+	labels := map[string]string{"tier": "gold", "region": "us", "example.com/beta": ""}
+	for selector, matches := range map[string]bool{
+		"":                       true,
+		"tier=gold":              true,
+		"tier==gold, region!=eu": true,
+		"tier=silver":            false,
+		"region!=us":             false,
+		"plan!=free":             true,
+		"example.com/beta":       true,
+		"!example.com/beta":      false,
+		"!plan,tier":             true,
+	} {
+		parsed, err := ParseSelector(selector)
+		if err != nil {
+			t.Errorf("parsing %q: %v", selector, err)
+			continue
+		}
+		if parsed.Matches(labels) != matches {
+			t.Errorf("%q matching %v: expected %t", selector, labels, matches)
+		}
+	}
+
+	for _, selector := range []string{"=gold", "tier=gold,", "Bad_Prefix/tier", "tier=not valid"} {
+		if _, err := ParseSelector(selector); errors.Cause(err) != ErrInvalidArgument {
+			t.Errorf("parsing %q: expected ErrInvalidArgument, got %v", selector, err)
+		}
+	}
+}
+
+func TestLabelsAndMetadata(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	repo.accounts["gold"] = Account{ID: "gold", Status: AccountActive, Labels: map[string]string{"tier": "gold", "region": "us"}}
+	repo.accounts["eu"] = Account{ID: "eu", Status: AccountActive, Labels: map[string]string{"tier": "gold", "region": "eu"}}
+	svc := newTestService(repo)
+
+	accounts, err := svc.FetchAccounts(ctx, FetchAccountsRequest{LabelSelector: "tier=gold,region!=eu"})
+	if err != nil || len(accounts) != 1 || accounts[0].ID != "gold" {
+		t.Errorf("unexpected accounts %+v, %v", accounts, err)
+	}
+	if _, err := svc.FetchAccounts(ctx, FetchAccountsRequest{LabelSelector: "tier=="}); err != nil {
+		t.Errorf("empty value: %v", err)
+	}
+	if _, err := svc.FetchAccounts(ctx, FetchAccountsRequest{LabelSelector: "tier=gold,,"}); errors.Cause(err) != ErrInvalidArgument {
+		t.Errorf("malformed selector: expected ErrInvalidArgument, got %v", err)
+	}
+
+	account, err := svc.UpdateAccount(ctx, UpdateAccountRequest{
+		ID:           "eu",
+		Labels:       map[string]string{"tier": "silver"},
+		RemoveLabels: []string{"region"},
+		Metadata:     json.RawMessage(`{"crm_id": 42}`),
+	})
+	if err != nil || len(account.Labels) != 1 || account.Labels["tier"] != "silver" || string(account.Metadata) != `{"crm_id": 42}` {
+		t.Errorf("unexpected account %+v, %v", account, err)
+	}
+
+	for _, req := range []UpdateAccountRequest{
+		{ID: "eu", Labels: map[string]string{"bad key": "x"}},
+		{ID: "eu", Metadata: json.RawMessage(`[1, 2]`)},
+		{ID: "eu", Metadata: json.RawMessage(`{"notes": "` + strings.Repeat("x", maxMetadataSize) + `"}`)},
+	} {
+		if _, err := svc.UpdateAccount(ctx, req); errors.Cause(err) != ErrInvalidArgument {
+			t.Errorf("updating with %.40v: expected ErrInvalidArgument, got %v", req, err)
+		}
+	}
+
+	user, err := svc.CreateUser(ctx, CreateUserRequest{AccountID: "gold", Email: "alice@example.com", Labels: map[string]string{"team": "support"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if users, _ := svc.FetchUsers(ctx, FetchUsersRequest{LabelSelector: "team=support"}); len(users) != 1 || users[0].ID != user.ID {
+		t.Errorf("unexpected users %+v", users)
+	}
+	if users, _ := svc.FetchUsers(ctx, FetchUsersRequest{LabelSelector: "!team"}); len(users) != 0 {
+		t.Errorf("unexpected users %+v", users)
+	}
+}
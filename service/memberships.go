@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Role is the level of access a user holds within an account.
+type Role string
+
+const (
+	RoleOwner    Role = "owner"
+	RoleAdmin    Role = "admin"
+	RoleMember   Role = "member"
+	RoleBilling  Role = "billing"
+	RoleReadOnly Role = "read_only"
+)
+
+// Valid reports whether r is one of the known roles.
+func (r Role) Valid() bool {
+	switch r {
+	case RoleOwner, RoleAdmin, RoleMember, RoleBilling, RoleReadOnly:
+		return true
+	}
+
+	return false
+}
+
+// Membership associates a user with an account, granting them a role and
+// tracking their status within that account.
+type Membership struct {
+	AccountID string     `db:"account_id"`
+	UserID    string     `db:"user_id"`
+	Role      Role       `db:"role"`
+	Status    UserStatus `db:"status"`
+	// CustomFields holds the user's values for the account's custom fields.
+	CustomFields map[string]string `db:"custom_fields"`
+	UpdatedAt    time.Time         `db:"updated_at"`
+	CreatedAt    time.Time         `db:"created_at"`
+}
+
+// UserAccount is an account a user belongs to, along with the user's role and
+// status within it.
+type UserAccount struct {
+	Account Account
+	Role    Role
+	Status  UserStatus
+}
+
+type GrantRoleRequest struct {
+	AccountID string
+	UserID    string
+	Role      Role
+}
+
+type ChangeRoleRequest struct {
+	AccountID string
+	UserID    string
+	Role      Role
+}
+
+type RevokeRoleRequest struct {
+	AccountID string
+	UserID    string
+}
+
+type FetchMembershipsRequest struct {
+	AccountID string
+	UserID    string
+}
+
+type ChangeMembershipStatusRequest struct {
+	AccountID string
+	UserID    string
+	Status    UserStatus
+}
+
+type FetchUserAccountsRequest struct {
+	UserID string
+}
+
+func (svc *customersService) GrantRole(ctx context.Context, req GrantRoleRequest) (membership Membership, err error) {
+	if !req.Role.Valid() {
+		return membership, errors.Wrapf(ErrInvalidArgument, "unknown role %q", req.Role)
+	}
+
+	if _, err = svc.repo.GetAccountByID(ctx, req.AccountID); err != nil {
+		return
+	}
+
+	user, err := svc.repo.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		return
+	}
+
+	// service accounts are created within, and belong only to, a single account
+	if !user.Interactive() {
+		return membership, errors.Wrapf(ErrFailedPrecondition, "user %s is a service account", user.ID)
+	}
+
+	existing, err := svc.membership(ctx, req.AccountID, req.UserID)
+	if err == nil {
+		return existing, errors.Wrapf(ErrAlreadyExists, "user %s already holds role %s", req.UserID, existing.Role)
+	} else if errors.Cause(err) != ErrNotFound {
+		return
+	}
+
+	err = svc.withSeat(ctx, req.AccountID, func(ctx context.Context) (err error) {
+		membership, err = svc.repo.InsertMembership(ctx, Membership{
+			AccountID: req.AccountID,
+			UserID:    req.UserID,
+			Role:      req.Role,
+			Status:    UserActive,
+		})
+		if err != nil {
+			svc.logger.Log("level", "error", "message", "failed to insert membership", "error", err.Error())
+		}
+
+		return
+	})
+
+	return
+}
+
+func (svc *customersService) ChangeRole(ctx context.Context, req ChangeRoleRequest) (membership Membership, err error) {
+	if !req.Role.Valid() {
+		return membership, errors.Wrapf(ErrInvalidArgument, "unknown role %q", req.Role)
+	}
+
+	membership, err = svc.membership(ctx, req.AccountID, req.UserID)
+	if err != nil {
+		return
+	}
+
+	if membership.Role == req.Role {
+		return
+	}
+
+	if membership.Role == RoleOwner {
+		if err = svc.ensureOtherOwner(ctx, membership); err != nil {
+			return
+		}
+	}
+
+	if req.Role == RoleOwner {
+		user, err := svc.repo.GetUserByID(ctx, req.UserID)
+		if err != nil {
+			return membership, err
+		}
+
+		if err = ensureCanOwn(user); err != nil {
+			return membership, err
+		}
+	}
+
+	membership.Role = req.Role
+	membership, err = svc.repo.UpdateMembership(ctx, membership)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to update membership", "error", err.Error())
+	}
+
+	return
+}
+
+func (svc *customersService) RevokeRole(ctx context.Context, req RevokeRoleRequest) (err error) {
+	membership, err := svc.membership(ctx, req.AccountID, req.UserID)
+	if err != nil {
+		return
+	}
+
+	if membership.Role == RoleOwner {
+		if err = svc.ensureOtherOwner(ctx, membership); err != nil {
+			return
+		}
+	}
+
+	err = svc.repo.DeleteMembership(ctx, membership)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to delete membership", "error", err.Error())
+	}
+
+	return
+}
+
+func (svc *customersService) FetchMemberships(ctx context.Context, req FetchMembershipsRequest) (memberships []Membership, err error) {
+	filters := map[string]interface{}{}
+	if req.AccountID != "" {
+		filters["account_id"] = req.AccountID
+	}
+	if req.UserID != "" {
+		filters["user_id"] = req.UserID
+	}
+
+	memberships, err = svc.repo.SelectMemberships(ctx, filters)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to retrieve memberships", "error", err.Error())
+	}
+
+	return
+}
+
+func (svc *customersService) ChangeMembershipStatus(ctx context.Context, req ChangeMembershipStatusRequest) (membership Membership, err error) {
+	switch req.Status {
+	case UserActive, UserSuspended, UserInactive:
+	default:
+		return membership, errors.Wrapf(ErrInvalidArgument, "unknown status %q", req.Status)
+	}
+
+	membership, err = svc.membership(ctx, req.AccountID, req.UserID)
+	if err != nil || membership.Status == req.Status {
+		return
+	}
+
+	if req.Status != UserActive {
+		if err = svc.ensureOtherOwner(ctx, membership); err != nil {
+			return
+		}
+
+		return svc.updateMembershipStatus(ctx, membership, req.Status)
+	}
+
+	user, err := svc.repo.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		return
+	}
+
+	if svc.requireVerifiedEmail && user.Interactive() && user.VerifiedAt == nil {
+		return membership, errors.Wrapf(ErrFailedPrecondition, "user %s has not verified their email", user.ID)
+	}
+
+	// reactivated members take up a seat again
+	err = svc.withSeatFor(ctx, req.AccountID, user, func(ctx context.Context) (err error) {
+		membership, err = svc.updateMembershipStatus(ctx, membership, req.Status)
+		return
+	})
+
+	return
+}
+
+func (svc *customersService) updateMembershipStatus(ctx context.Context, membership Membership, status UserStatus) (Membership, error) {
+	membership.Status = status
+	membership, err := svc.repo.UpdateMembership(ctx, membership)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to update membership", "error", err.Error())
+	}
+
+	return membership, err
+}
+
+func (svc *customersService) FetchUserAccounts(ctx context.Context, req FetchUserAccountsRequest) (accounts []UserAccount, err error) {
+	if _, err = svc.repo.GetUserByID(ctx, req.UserID); err != nil {
+		return
+	}
+
+	accounts, err = svc.repo.SelectUserAccounts(ctx, req.UserID)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to retrieve user accounts", "error", err.Error())
+	}
+
+	return
+}
+
+// membership loads the role a user holds within an account.
+func (svc *customersService) membership(ctx context.Context, accountID, userID string) (Membership, error) {
+	memberships, err := svc.repo.SelectMemberships(ctx, map[string]interface{}{
+		"account_id": accountID,
+		"user_id":    userID,
+	})
+	if err != nil {
+		return Membership{}, err
+	}
+
+	if len(memberships) == 0 {
+		return Membership{}, errors.Wrapf(ErrNotFound, "user %s has no role in account %s", userID, accountID)
+	}
+
+	return memberships[0], nil
+}
+
+// ensureOtherOwner fails when removing the owner role from membership, or
+// deactivating it, would leave an active account without an active owner. The
+// repository enforces the same invariant transactionally for concurrent
+// changes.
+func (svc *customersService) ensureOtherOwner(ctx context.Context, membership Membership) error {
+	if membership.Role != RoleOwner || membership.Status != UserActive {
+		return nil
+	}
+
+	account, err := svc.repo.GetAccountByID(ctx, membership.AccountID)
+	if err != nil {
+		return err
+	}
+
+	if account.Status != AccountActive {
+		return nil
+	}
+
+	owners, err := svc.repo.SelectMemberships(ctx, map[string]interface{}{
+		"account_id": membership.AccountID,
+		"role":       RoleOwner,
+		"status":     UserActive,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, owner := range owners {
+		if owner.UserID != membership.UserID {
+			return nil
+		}
+	}
+
+	return errors.Wrapf(ErrFailedPrecondition, "account %s must retain at least one owner", membership.AccountID)
+}
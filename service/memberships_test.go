@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestMembershipsRetainOwner(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	repo.accounts["acct"] = Account{ID: "acct", Status: AccountActive}
+	repo.users["alice"] = User{ID: "alice"}
+	repo.users["bob"] = User{ID: "bob"}
+	svc := newTestService(repo)
+
+	if _, err := svc.GrantRole(ctx, GrantRoleRequest{AccountID: "acct", UserID: "alice", Role: RoleOwner}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.GrantRole(ctx, GrantRoleRequest{AccountID: "acct", UserID: "alice", Role: RoleAdmin}); errors.Cause(err) != ErrAlreadyExists {
+		t.Errorf("expected ErrAlreadyExists, got %v", err)
+	}
+
+	if _, err := svc.GrantRole(ctx, GrantRoleRequest{AccountID: "acct", UserID: "bob", Role: "superuser"}); errors.Cause(err) != ErrInvalidArgument {
+		t.Errorf("expected ErrInvalidArgument, got %v", err)
+	}
+
+	if _, err := svc.ChangeRole(ctx, ChangeRoleRequest{AccountID: "acct", UserID: "alice", Role: RoleMember}); errors.Cause(err) != ErrFailedPrecondition {
+		t.Errorf("demoting the last owner: expected ErrFailedPrecondition, got %v", err)
+	}
+
+	if err := svc.RevokeRole(ctx, RevokeRoleRequest{AccountID: "acct", UserID: "alice"}); errors.Cause(err) != ErrFailedPrecondition {
+		t.Errorf("revoking the last owner: expected ErrFailedPrecondition, got %v", err)
+	}
+
+	if _, err := svc.ChangeMembershipStatus(ctx, ChangeMembershipStatusRequest{AccountID: "acct", UserID: "alice", Status: UserSuspended}); errors.Cause(err) != ErrFailedPrecondition {
+		t.Errorf("suspending the last owner: expected ErrFailedPrecondition, got %v", err)
+	}
+
+	if _, err := svc.GrantRole(ctx, GrantRoleRequest{AccountID: "acct", UserID: "bob", Role: RoleOwner}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.ChangeMembershipStatus(ctx, ChangeMembershipStatusRequest{AccountID: "acct", UserID: "bob", Status: UserInactive}); err != nil {
+		t.Fatalf("deactivating an owner while another remains: %v", err)
+	}
+
+	if err := svc.RevokeRole(ctx, RevokeRoleRequest{AccountID: "acct", UserID: "alice"}); errors.Cause(err) != ErrFailedPrecondition {
+		t.Errorf("revoking the last active owner: expected ErrFailedPrecondition, got %v", err)
+	}
+
+	if _, err := svc.ChangeMembershipStatus(ctx, ChangeMembershipStatusRequest{AccountID: "acct", UserID: "bob", Status: UserActive}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := svc.RevokeRole(ctx, RevokeRoleRequest{AccountID: "acct", UserID: "alice"}); err != nil {
+		t.Errorf("revoking an owner while another remains: %v", err)
+	}
+
+	repo.accounts["acct"] = Account{ID: "acct", Status: AccountInactive}
+	if err := svc.RevokeRole(ctx, RevokeRoleRequest{AccountID: "acct", UserID: "bob"}); err != nil {
+		t.Errorf("inactive accounts may lose their last owner: %v", err)
+	}
+}
@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/symptomatichq/customers/events"
+	"github.com/symptomatichq/customers/notify"
+)
+
+// ownershipTransferTTL is how long the new owner has to accept a transfer.
+const ownershipTransferTTL = 72 * time.Hour
+
+type OwnershipTransferStatus string
+
+const (
+	OwnershipTransferPending   OwnershipTransferStatus = "pending"
+	OwnershipTransferAccepted  OwnershipTransferStatus = "accepted"
+	OwnershipTransferCancelled OwnershipTransferStatus = "cancelled"
+	OwnershipTransferExpired   OwnershipTransferStatus = "expired"
+)
+
+// OwnershipTransfer hands an account from one of its owners to another of its
+// members. Transfers are never deleted and serve as the audit trail of who
+// owned an account.
+type OwnershipTransfer struct {
+	ID          string                  `db:"id"`
+	AccountID   string                  `db:"account_id"`
+	FromUserID  string                  `db:"from_user_id"`
+	ToUserID    string                  `db:"to_user_id"`
+	Status      OwnershipTransferStatus `db:"status"`
+	InitiatedBy string                  `db:"initiated_by"`
+	// Override is set when the transfer was initiated by staff or an account
+	// admin rather than the owner handing over the account.
+	Override    bool       `db:"override"`
+	CancelledBy string     `db:"cancelled_by"`
+	ExpiresAt   time.Time  `db:"expires_at"`
+	AcceptedAt  *time.Time `db:"accepted_at"`
+	CancelledAt *time.Time `db:"cancelled_at"`
+	UpdatedAt   time.Time  `db:"updated_at"`
+	CreatedAt   time.Time  `db:"created_at"`
+}
+
+// Expired reports whether the transfer can no longer be accepted.
+func (t OwnershipTransfer) Expired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+type InitiateOwnershipTransferRequest struct {
+	AccountID   string
+	FromUserID  string
+	ToUserID    string
+	InitiatedBy string
+	Override    bool
+}
+
+type AcceptOwnershipTransferRequest struct {
+	ID string
+	// UserID is the user accepting, who must be the transfer's recipient.
+	UserID string
+}
+
+type CancelOwnershipTransferRequest struct {
+	AccountID   string
+	ID          string
+	CancelledBy string
+}
+
+type ListOwnershipTransfersRequest struct {
+	AccountID string
+	Status    OwnershipTransferStatus
+}
+
+// InitiateOwnershipTransfer offers ownership of an account held by one owner
+// to another active member, who becomes an owner once they accept. An account
+// may only have one pending transfer at a time.
+func (svc *customersService) InitiateOwnershipTransfer(ctx context.Context, req InitiateOwnershipTransferRequest) (transfer OwnershipTransfer, err error) {
+	if req.FromUserID == req.ToUserID {
+		return transfer, errors.Wrap(ErrInvalidArgument, "ownership must be transferred to another user")
+	}
+
+	account, err := svc.repo.GetAccountByID(ctx, req.AccountID)
+	if err != nil {
+		return
+	}
+
+	if account.Status != AccountActive {
+		return transfer, errors.Wrapf(ErrFailedPrecondition, "account %s is not active", account.ID)
+	}
+
+	from, err := svc.membership(ctx, req.AccountID, req.FromUserID)
+	if err != nil {
+		return
+	}
+
+	if from.Role != RoleOwner {
+		return transfer, errors.Wrapf(ErrFailedPrecondition, "user %s is not an owner of account %s", req.FromUserID, req.AccountID)
+	}
+
+	to, err := svc.membership(ctx, req.AccountID, req.ToUserID)
+	if err != nil {
+		return
+	}
+
+	if to.Status != UserActive {
+		return transfer, errors.Wrapf(ErrFailedPrecondition, "user %s is not active in account %s", req.ToUserID, req.AccountID)
+	}
+
+	if to.Role == RoleOwner {
+		return transfer, errors.Wrapf(ErrFailedPrecondition, "user %s already owns account %s", req.ToUserID, req.AccountID)
+	}
+
+	recipient, err := svc.repo.GetUserByID(ctx, req.ToUserID)
+	if err != nil {
+		return
+	}
+
+	if err = ensureCanOwn(recipient); err != nil {
+		return
+	}
+
+	if _, err = svc.pendingOwnershipTransfer(ctx, req.AccountID); err == nil {
+		return transfer, errors.Wrapf(ErrAlreadyExists, "account %s already has a pending ownership transfer", req.AccountID)
+	} else if errors.Cause(err) != ErrNotFound {
+		return
+	}
+
+	transfer, err = svc.repo.InsertOwnershipTransfer(ctx, OwnershipTransfer{
+		AccountID:   req.AccountID,
+		FromUserID:  req.FromUserID,
+		ToUserID:    req.ToUserID,
+		Status:      OwnershipTransferPending,
+		InitiatedBy: req.InitiatedBy,
+		Override:    req.Override,
+		ExpiresAt:   time.Now().Add(ownershipTransferTTL),
+	})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to insert ownership transfer", "error", err.Error())
+		return
+	}
+
+	err = svc.notifier.Notify(ctx, notify.Message{
+		Kind:    "ownership_transfer",
+		To:      recipient.Email,
+		Subject: "You have been offered ownership of " + account.Name,
+		Data: map[string]string{
+			"transfer_id":  transfer.ID,
+			"account_id":   account.ID,
+			"account_name": account.Name,
+			"name":         recipient.Name,
+		},
+	})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to send ownership transfer", "error", err.Error())
+	}
+
+	svc.publishOwnershipTransfer(ctx, "account.ownership_transfer_initiated", transfer)
+
+	return transfer, nil
+}
+
+// AcceptOwnershipTransfer makes the recipient an owner of the account. The
+// previous owner stays on as an admin.
+func (svc *customersService) AcceptOwnershipTransfer(ctx context.Context, req AcceptOwnershipTransferRequest) (transfer OwnershipTransfer, err error) {
+	transfers, err := svc.repo.SelectOwnershipTransfers(ctx, map[string]interface{}{
+		"id":     req.ID,
+		"status": OwnershipTransferPending,
+	})
+	if err != nil {
+		return
+	}
+
+	// transfers offered to someone else are indistinguishable from unknown ones
+	if len(transfers) == 0 || transfers[0].ToUserID != req.UserID || transfers[0].Expired(time.Now()) {
+		return transfer, errors.Wrap(ErrNotFound, "ownership transfer is invalid or has expired")
+	}
+	transfer = transfers[0]
+
+	account, err := svc.repo.GetAccountByID(ctx, transfer.AccountID)
+	if err != nil {
+		return
+	}
+
+	if account.Status != AccountActive {
+		return transfer, errors.Wrapf(ErrFailedPrecondition, "account %s is not active", account.ID)
+	}
+
+	to, err := svc.membership(ctx, transfer.AccountID, transfer.ToUserID)
+	if err != nil {
+		return
+	}
+
+	if to.Status != UserActive {
+		return transfer, errors.Wrapf(ErrFailedPrecondition, "user %s is not active in account %s", to.UserID, to.AccountID)
+	}
+
+	// promote the new owner before demoting the old one so the account never
+	// lacks an owner
+	to.Role = RoleOwner
+	if _, err = svc.repo.UpdateMembership(ctx, to); err != nil {
+		svc.logger.Log("level", "error", "message", "failed to update membership", "error", err.Error())
+		return
+	}
+
+	from, err := svc.membership(ctx, transfer.AccountID, transfer.FromUserID)
+	if err == nil && from.Role == RoleOwner {
+		from.Role = RoleAdmin
+		if _, err = svc.repo.UpdateMembership(ctx, from); err != nil {
+			svc.logger.Log("level", "error", "message", "failed to update membership", "error", err.Error())
+			return
+		}
+	} else if err != nil && errors.Cause(err) != ErrNotFound {
+		return
+	}
+
+	now := time.Now()
+	transfer.Status = OwnershipTransferAccepted
+	transfer.AcceptedAt = &now
+	transfer, err = svc.repo.UpdateOwnershipTransfer(ctx, transfer)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to accept ownership transfer", "error", err.Error())
+		return
+	}
+
+	svc.publishOwnershipTransfer(ctx, "account.ownership_transferred", transfer)
+
+	return
+}
+
+// CancelOwnershipTransfer withdraws a pending transfer.
+func (svc *customersService) CancelOwnershipTransfer(ctx context.Context, req CancelOwnershipTransferRequest) (transfer OwnershipTransfer, err error) {
+	transfers, err := svc.repo.SelectOwnershipTransfers(ctx, map[string]interface{}{
+		"id":         req.ID,
+		"account_id": req.AccountID,
+	})
+	if err != nil {
+		return
+	}
+
+	if len(transfers) == 0 {
+		return transfer, errors.Wrapf(ErrNotFound, "ownership transfer %s", req.ID)
+	}
+
+	transfer = transfers[0]
+	switch {
+	case transfer.Status == OwnershipTransferCancelled:
+		return
+	case transfer.Status != OwnershipTransferPending || transfer.Expired(time.Now()):
+		return transfer, errors.Wrapf(ErrFailedPrecondition, "ownership transfer %s is no longer pending", req.ID)
+	}
+
+	now := time.Now()
+	transfer.Status = OwnershipTransferCancelled
+	transfer.CancelledBy = req.CancelledBy
+	transfer.CancelledAt = &now
+	transfer, err = svc.repo.UpdateOwnershipTransfer(ctx, transfer)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to cancel ownership transfer", "error", err.Error())
+		return
+	}
+
+	svc.publishOwnershipTransfer(ctx, "account.ownership_transfer_cancelled", transfer)
+
+	return
+}
+
+func (svc *customersService) ListOwnershipTransfers(ctx context.Context, req ListOwnershipTransfersRequest) (transfers []OwnershipTransfer, err error) {
+	filters := map[string]interface{}{"account_id": req.AccountID}
+	if req.Status != "" {
+		filters["status"] = req.Status
+	}
+
+	transfers, err = svc.repo.SelectOwnershipTransfers(ctx, filters)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to retrieve ownership transfers", "error", err.Error())
+	}
+
+	return
+}
+
+// pendingOwnershipTransfer returns the account's pending transfer, marking it
+// expired instead when it can no longer be accepted.
+func (svc *customersService) pendingOwnershipTransfer(ctx context.Context, accountID string) (OwnershipTransfer, error) {
+	transfers, err := svc.repo.SelectOwnershipTransfers(ctx, map[string]interface{}{
+		"account_id": accountID,
+		"status":     OwnershipTransferPending,
+	})
+	if err != nil {
+		return OwnershipTransfer{}, err
+	}
+
+	for _, transfer := range transfers {
+		if !transfer.Expired(time.Now()) {
+			return transfer, nil
+		}
+
+		transfer.Status = OwnershipTransferExpired
+		if _, err := svc.repo.UpdateOwnershipTransfer(ctx, transfer); err != nil {
+			return OwnershipTransfer{}, err
+		}
+	}
+
+	return OwnershipTransfer{}, errors.Wrapf(ErrNotFound, "account %s has no pending ownership transfer", accountID)
+}
+
+func (svc *customersService) publishOwnershipTransfer(ctx context.Context, kind string, transfer OwnershipTransfer) {
+	svc.publish(ctx, events.Event{
+		Type:    kind,
+		Subject: transfer.AccountID,
+		Data: map[string]string{
+			"transfer_id":  transfer.ID,
+			"from_user_id": transfer.FromUserID,
+			"to_user_id":   transfer.ToUserID,
+			"initiated_by": transfer.InitiatedBy,
+			"cancelled_by": transfer.CancelledBy,
+		},
+	})
+}
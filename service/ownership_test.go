@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestOwnershipTransfer(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	repo.accounts["acct"] = Account{ID: "acct", Name: "Acme", Status: AccountActive}
+	repo.users["alice"] = User{ID: "alice", Email: "alice@example.com"}
+	repo.users["bob"] = User{ID: "bob", Email: "bob@example.com"}
+	repo.memberships = []Membership{
+		{AccountID: "acct", UserID: "alice", Role: RoleOwner, Status: UserActive},
+		{AccountID: "acct", UserID: "bob", Role: RoleMember, Status: UserActive},
+	}
+	notifier := &recordingNotifier{}
+	svc := newTestService(repo, WithNotifier(notifier))
+
+	initiate := InitiateOwnershipTransferRequest{AccountID: "acct", FromUserID: "alice", ToUserID: "bob", InitiatedBy: "alice"}
+	transfer, err := svc.InitiateOwnershipTransfer(ctx, initiate)
+	if err != nil || transfer.Status != OwnershipTransferPending {
+		t.Fatalf("unexpected transfer %+v, %v", transfer, err)
+	}
+
+	if len(notifier.messages) != 1 || notifier.messages[0].To != "bob@example.com" {
+		t.Errorf("expected the new owner to be notified, got %+v", notifier.messages)
+	}
+
+	if _, err := svc.InitiateOwnershipTransfer(ctx, initiate); errors.Cause(err) != ErrAlreadyExists {
+		t.Errorf("second pending transfer: expected ErrAlreadyExists, got %v", err)
+	}
+
+	if _, err := svc.AcceptOwnershipTransfer(ctx, AcceptOwnershipTransferRequest{ID: transfer.ID, UserID: "mallory"}); errors.Cause(err) != ErrNotFound {
+		t.Errorf("accepting someone else's transfer: expected ErrNotFound, got %v", err)
+	}
+
+	transfer, err = svc.AcceptOwnershipTransfer(ctx, AcceptOwnershipTransferRequest{ID: transfer.ID, UserID: "bob"})
+	if err != nil || transfer.Status != OwnershipTransferAccepted || transfer.AcceptedAt == nil {
+		t.Fatalf("unexpected accepted transfer %+v, %v", transfer, err)
+	}
+
+	if m, _ := svc.membership(ctx, "acct", "bob"); m.Role != RoleOwner {
+		t.Errorf("new owner has role %s", m.Role)
+	}
+	if m, _ := svc.membership(ctx, "acct", "alice"); m.Role != RoleAdmin {
+		t.Errorf("previous owner has role %s", m.Role)
+	}
+
+	// an expired transfer no longer blocks a new one and cannot be accepted
+	transfer, err = svc.InitiateOwnershipTransfer(ctx, InitiateOwnershipTransferRequest{AccountID: "acct", FromUserID: "bob", ToUserID: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.ownership[len(repo.ownership)-1].ExpiresAt = time.Now().Add(-time.Minute)
+
+	if _, err := svc.AcceptOwnershipTransfer(ctx, AcceptOwnershipTransferRequest{ID: transfer.ID, UserID: "alice"}); errors.Cause(err) != ErrNotFound {
+		t.Errorf("accepting an expired transfer: expected ErrNotFound, got %v", err)
+	}
+
+	transfer, err = svc.InitiateOwnershipTransfer(ctx, InitiateOwnershipTransferRequest{AccountID: "acct", FromUserID: "bob", ToUserID: "alice"})
+	if err != nil {
+		t.Fatalf("replacing an expired transfer: %v", err)
+	}
+
+	transfer, err = svc.CancelOwnershipTransfer(ctx, CancelOwnershipTransferRequest{AccountID: "acct", ID: transfer.ID, CancelledBy: "bob"})
+	if err != nil || transfer.Status != OwnershipTransferCancelled || transfer.CancelledBy != "bob" {
+		t.Errorf("unexpected cancelled transfer %+v, %v", transfer, err)
+	}
+
+	history, _ := svc.ListOwnershipTransfers(ctx, ListOwnershipTransfersRequest{AccountID: "acct"})
+	statuses := []OwnershipTransferStatus{}
+	for _, h := range history {
+		statuses = append(statuses, h.Status)
+	}
+	if len(statuses) != 3 || statuses[0] != OwnershipTransferAccepted || statuses[1] != OwnershipTransferExpired || statuses[2] != OwnershipTransferCancelled {
+		t.Errorf("unexpected history %v", statuses)
+	}
+}
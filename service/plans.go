@@ -0,0 +1,594 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultEntitlementCacheTTL bounds how long another instance's changes
+	// to plans and overrides take to be seen by CheckEntitlement.
+	defaultEntitlementCacheTTL = time.Minute
+	// maxCachedEntitlements is the number of accounts whose entitlements are
+	// cached at once.
+	maxCachedEntitlements = 10000
+)
+
+// Plan is an entry in the catalog of subscription plans. Archived plans can
+// no longer be assigned but still apply to the accounts already on them.
+type Plan struct {
+	ID           string        `db:"id"`
+	Key          string        `db:"key"`
+	Name         string        `db:"name"`
+	Entitlements []Entitlement `db:"entitlements"`
+	Archived     bool          `db:"archived"`
+	UpdatedAt    time.Time     `db:"updated_at"`
+	CreatedAt    time.Time     `db:"created_at"`
+}
+
+// Entitlement grants a feature, such as "sso", optionally up to a limit, such
+// as a number of seats.
+type Entitlement struct {
+	Key     string `json:"key"`
+	Enabled bool   `json:"enabled"`
+	Limit   *int64 `json:"limit,omitempty"`
+}
+
+// AccountPlan places an account on a plan from StartsAt until EndsAt, or
+// indefinitely. When assignments overlap the one starting last applies.
+type AccountPlan struct {
+	ID         string     `db:"id"`
+	AccountID  string     `db:"account_id"`
+	PlanID     string     `db:"plan_id"`
+	StartsAt   time.Time  `db:"starts_at"`
+	EndsAt     *time.Time `db:"ends_at"`
+	AssignedBy string     `db:"assigned_by"`
+	CreatedAt  time.Time  `db:"created_at"`
+}
+
+// EffectiveAt reports whether the assignment applies at t.
+func (p AccountPlan) EffectiveAt(t time.Time) bool {
+	return !t.Before(p.StartsAt) && (p.EndsAt == nil || t.Before(*p.EndsAt))
+}
+
+// EntitlementOverride grants or withholds an entitlement for one account
+// regardless of its plan.
+type EntitlementOverride struct {
+	AccountID string     `db:"account_id"`
+	Key       string     `db:"key"`
+	Enabled   bool       `db:"enabled"`
+	Limit     *int64     `db:"limit"`
+	Reason    string     `db:"reason"`
+	ExpiresAt *time.Time `db:"expires_at"`
+	CreatedBy string     `db:"created_by"`
+	UpdatedAt time.Time  `db:"updated_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+// Expired reports whether the override no longer applies.
+func (o EntitlementOverride) Expired(now time.Time) bool {
+	return o.ExpiresAt != nil && !now.Before(*o.ExpiresAt)
+}
+
+type EntitlementSource string
+
+const (
+	EntitlementFromPlan     EntitlementSource = "plan"
+	EntitlementFromOverride EntitlementSource = "override"
+	// EntitlementUndefined is reported for entitlements neither the
+	// account's plan nor its overrides mention, which are not allowed.
+	EntitlementUndefined EntitlementSource = "undefined"
+)
+
+// EntitlementCheck is an account's resolved entitlement.
+type EntitlementCheck struct {
+	AccountID string
+	Key       string
+	Allowed   bool
+	Limit     *int64
+	Source    EntitlementSource
+	// PlanID is the account's current plan, if any.
+	PlanID string
+}
+
+type CreatePlanRequest struct {
+	Key          string
+	Name         string
+	Entitlements []Entitlement
+}
+
+type ListPlansRequest struct {
+	IncludeArchived bool
+}
+
+type UpdatePlanRequest struct {
+	ID   string
+	Name *string
+	// Entitlements replace the plan's entitlements when not nil.
+	Entitlements []Entitlement
+	Archived     *bool
+}
+
+type AssignPlanRequest struct {
+	AccountID string
+	PlanID    string
+	// StartsAt defaults to now. EndsAt is nil for an open ended assignment.
+	StartsAt   *time.Time
+	EndsAt     *time.Time
+	AssignedBy string
+}
+
+type ListAccountPlansRequest struct {
+	AccountID string
+}
+
+type SetEntitlementOverrideRequest struct {
+	AccountID string
+	Key       string
+	Enabled   bool
+	Limit     *int64
+	Reason    string
+	ExpiresAt *time.Time
+	CreatedBy string
+}
+
+type RemoveEntitlementOverrideRequest struct {
+	AccountID string
+	Key       string
+}
+
+type ListAccountEntitlementsRequest struct {
+	AccountID string
+}
+
+type CheckEntitlementRequest struct {
+	AccountID string
+	Key       string
+}
+
+func (svc *customersService) CreatePlan(ctx context.Context, req CreatePlanRequest) (plan Plan, err error) {
+	if !scopePattern.MatchString(req.Key) {
+		return plan, errors.Wrapf(ErrInvalidArgument, "invalid plan key %q", req.Key)
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return plan, errors.Wrap(ErrInvalidArgument, "plan name is required")
+	}
+
+	if err = validateEntitlements(req.Entitlements); err != nil {
+		return
+	}
+
+	existing, err := svc.repo.SelectPlans(ctx, map[string]interface{}{"key": req.Key})
+	if err != nil {
+		return
+	}
+
+	if len(existing) > 0 {
+		return plan, errors.Wrapf(ErrAlreadyExists, "plan %s already exists", req.Key)
+	}
+
+	plan, err = svc.repo.InsertPlan(ctx, Plan{Key: req.Key, Name: name, Entitlements: req.Entitlements})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to insert plan", "error", err.Error())
+	}
+
+	return
+}
+
+func (svc *customersService) ListPlans(ctx context.Context, req ListPlansRequest) (plans []Plan, err error) {
+	filters := map[string]interface{}{}
+	if !req.IncludeArchived {
+		filters["archived"] = false
+	}
+
+	plans, err = svc.repo.SelectPlans(ctx, filters)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to retrieve plans", "error", err.Error())
+	}
+
+	return
+}
+
+// UpdatePlan changes a plan, affecting every account on it.
+func (svc *customersService) UpdatePlan(ctx context.Context, req UpdatePlanRequest) (plan Plan, err error) {
+	plan, err = svc.plan(ctx, req.ID)
+	if err != nil {
+		return
+	}
+
+	if req.Name != nil {
+		if plan.Name = strings.TrimSpace(*req.Name); plan.Name == "" {
+			return plan, errors.Wrap(ErrInvalidArgument, "plan name is required")
+		}
+	}
+
+	if req.Entitlements != nil {
+		if err = validateEntitlements(req.Entitlements); err != nil {
+			return
+		}
+		plan.Entitlements = req.Entitlements
+	}
+
+	if req.Archived != nil {
+		plan.Archived = *req.Archived
+	}
+
+	plan, err = svc.repo.UpdatePlan(ctx, plan)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to update plan", "error", err.Error())
+		return
+	}
+
+	svc.entitlements.flush()
+
+	return
+}
+
+// AssignPlan places an account on a plan from now or a later date.
+func (svc *customersService) AssignPlan(ctx context.Context, req AssignPlanRequest) (assignment AccountPlan, err error) {
+	startsAt := time.Now()
+	if req.StartsAt != nil {
+		startsAt = *req.StartsAt
+	}
+
+	if req.EndsAt != nil && !req.EndsAt.After(startsAt) {
+		return assignment, errors.Wrap(ErrInvalidArgument, "plan assignment must end after it starts")
+	}
+
+	if _, err = svc.repo.GetAccountByID(ctx, req.AccountID); err != nil {
+		return
+	}
+
+	plan, err := svc.plan(ctx, req.PlanID)
+	if err != nil {
+		return
+	}
+
+	if plan.Archived {
+		return assignment, errors.Wrapf(ErrFailedPrecondition, "plan %s is archived", plan.Key)
+	}
+
+	assignment, err = svc.repo.InsertAccountPlan(ctx, AccountPlan{
+		AccountID:  req.AccountID,
+		PlanID:     plan.ID,
+		StartsAt:   startsAt,
+		EndsAt:     req.EndsAt,
+		AssignedBy: req.AssignedBy,
+	})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to assign plan", "error", err.Error())
+		return
+	}
+
+	svc.entitlements.invalidate(req.AccountID)
+
+	return
+}
+
+func (svc *customersService) ListAccountPlans(ctx context.Context, req ListAccountPlansRequest) (assignments []AccountPlan, err error) {
+	assignments, err = svc.repo.SelectAccountPlans(ctx, map[string]interface{}{"account_id": req.AccountID})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to retrieve account plans", "error", err.Error())
+	}
+
+	return
+}
+
+func (svc *customersService) SetEntitlementOverride(ctx context.Context, req SetEntitlementOverrideRequest) (override EntitlementOverride, err error) {
+	if err = validateEntitlements([]Entitlement{{Key: req.Key, Enabled: req.Enabled, Limit: req.Limit}}); err != nil {
+		return
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return override, errors.Wrap(ErrInvalidArgument, "override must expire in the future")
+	}
+
+	if _, err = svc.repo.GetAccountByID(ctx, req.AccountID); err != nil {
+		return
+	}
+
+	override, err = svc.repo.UpsertEntitlementOverride(ctx, EntitlementOverride{
+		AccountID: req.AccountID,
+		Key:       req.Key,
+		Enabled:   req.Enabled,
+		Limit:     req.Limit,
+		Reason:    req.Reason,
+		ExpiresAt: req.ExpiresAt,
+		CreatedBy: req.CreatedBy,
+	})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to set entitlement override", "error", err.Error())
+		return
+	}
+
+	svc.entitlements.invalidate(req.AccountID)
+
+	return
+}
+
+func (svc *customersService) RemoveEntitlementOverride(ctx context.Context, req RemoveEntitlementOverrideRequest) (err error) {
+	overrides, err := svc.repo.SelectEntitlementOverrides(ctx, map[string]interface{}{
+		"account_id": req.AccountID,
+		"key":        req.Key,
+	})
+	if err != nil {
+		return
+	}
+
+	if len(overrides) == 0 {
+		return errors.Wrapf(ErrNotFound, "account %s has no override for %s", req.AccountID, req.Key)
+	}
+
+	if err = svc.repo.DeleteEntitlementOverride(ctx, overrides[0]); err != nil {
+		svc.logger.Log("level", "error", "message", "failed to remove entitlement override", "error", err.Error())
+		return
+	}
+
+	svc.entitlements.invalidate(req.AccountID)
+
+	return
+}
+
+// ListAccountEntitlements lists every entitlement an account's plan or
+// overrides define, ordered by key.
+func (svc *customersService) ListAccountEntitlements(ctx context.Context, req ListAccountEntitlementsRequest) ([]EntitlementCheck, error) {
+	resolved, err := svc.accountEntitlements(ctx, req.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := make([]EntitlementCheck, 0, len(resolved.checks))
+	for _, check := range resolved.checks {
+		checks = append(checks, check)
+	}
+	sort.Slice(checks, func(i, j int) bool { return checks[i].Key < checks[j].Key })
+
+	return checks, nil
+}
+
+// CheckEntitlement reports whether an account is entitled to a feature. It
+// is served from a cache and is cheap enough for hot paths.
+func (svc *customersService) CheckEntitlement(ctx context.Context, req CheckEntitlementRequest) (EntitlementCheck, error) {
+	resolved, err := svc.accountEntitlements(ctx, req.AccountID)
+	if err != nil {
+		return EntitlementCheck{}, err
+	}
+
+	if check, ok := resolved.checks[req.Key]; ok {
+		return check, nil
+	}
+
+	return EntitlementCheck{
+		AccountID: req.AccountID,
+		Key:       req.Key,
+		Source:    EntitlementUndefined,
+		PlanID:    resolved.planID,
+	}, nil
+}
+
+// accountEntitlements returns an account's resolved entitlements, from the
+// cache when possible.
+func (svc *customersService) accountEntitlements(ctx context.Context, accountID string) (resolvedEntitlements, error) {
+	now := time.Now()
+	if resolved, ok := svc.entitlements.get(accountID, now); ok {
+		return resolved, nil
+	}
+
+	resolved, err := svc.resolveEntitlements(ctx, accountID, now)
+	if err != nil {
+		return resolved, err
+	}
+
+	svc.entitlements.put(accountID, resolved, now)
+
+	return resolved, nil
+}
+
+// resolvedEntitlements are an account's entitlements at a point in time,
+// which hold until the next scheduled change to its plans or overrides.
+type resolvedEntitlements struct {
+	planID     string
+	checks     map[string]EntitlementCheck
+	validUntil *time.Time
+}
+
+func (svc *customersService) resolveEntitlements(ctx context.Context, accountID string, now time.Time) (resolved resolvedEntitlements, err error) {
+	if _, err = svc.repo.GetAccountByID(ctx, accountID); err != nil {
+		return
+	}
+
+	assignments, err := svc.repo.SelectAccountPlans(ctx, map[string]interface{}{"account_id": accountID})
+	if err != nil {
+		return
+	}
+
+	changeAt := func(t time.Time) {
+		if t.After(now) && (resolved.validUntil == nil || t.Before(*resolved.validUntil)) {
+			resolved.validUntil = &t
+		}
+	}
+
+	var current *AccountPlan
+	for i, assignment := range assignments {
+		changeAt(assignment.StartsAt)
+		if assignment.EndsAt != nil {
+			changeAt(*assignment.EndsAt)
+		}
+
+		if assignment.EffectiveAt(now) && (current == nil || assignment.StartsAt.After(current.StartsAt)) {
+			current = &assignments[i]
+		}
+	}
+
+	resolved.checks = map[string]EntitlementCheck{}
+	if current != nil {
+		plan, err := svc.plan(ctx, current.PlanID)
+		if err != nil {
+			return resolved, err
+		}
+
+		resolved.planID = plan.ID
+		for _, entitlement := range plan.Entitlements {
+			resolved.checks[entitlement.Key] = EntitlementCheck{
+				AccountID: accountID,
+				Key:       entitlement.Key,
+				Allowed:   entitlement.Enabled,
+				Limit:     entitlement.Limit,
+				Source:    EntitlementFromPlan,
+				PlanID:    plan.ID,
+			}
+		}
+	}
+
+	overrides, err := svc.repo.SelectEntitlementOverrides(ctx, map[string]interface{}{"account_id": accountID})
+	if err != nil {
+		return
+	}
+
+	for _, override := range overrides {
+		if override.Expired(now) {
+			continue
+		}
+		if override.ExpiresAt != nil {
+			changeAt(*override.ExpiresAt)
+		}
+
+		resolved.checks[override.Key] = EntitlementCheck{
+			AccountID: accountID,
+			Key:       override.Key,
+			Allowed:   override.Enabled,
+			Limit:     override.Limit,
+			Source:    EntitlementFromOverride,
+			PlanID:    resolved.planID,
+		}
+	}
+
+	return resolved, nil
+}
+
+func (svc *customersService) plan(ctx context.Context, id string) (Plan, error) {
+	plans, err := svc.repo.SelectPlans(ctx, map[string]interface{}{"id": id})
+	if err != nil {
+		return Plan{}, err
+	}
+
+	if len(plans) == 0 {
+		return Plan{}, errors.Wrapf(ErrNotFound, "plan %s", id)
+	}
+
+	return plans[0], nil
+}
+
+func validateEntitlements(entitlements []Entitlement) error {
+	seen := map[string]bool{}
+	for _, entitlement := range entitlements {
+		if !scopePattern.MatchString(entitlement.Key) {
+			return errors.Wrapf(ErrInvalidArgument, "invalid entitlement key %q", entitlement.Key)
+		}
+
+		if seen[entitlement.Key] {
+			return errors.Wrapf(ErrInvalidArgument, "entitlement %s is listed more than once", entitlement.Key)
+		}
+		seen[entitlement.Key] = true
+
+		if entitlement.Limit != nil && *entitlement.Limit < 0 {
+			return errors.Wrapf(ErrInvalidArgument, "limit of entitlement %s cannot be negative", entitlement.Key)
+		}
+	}
+
+	return nil
+}
+
+// entitlementCache keeps resolved entitlements per account. Entries are
+// dropped when this instance changes them and otherwise expire after the
+// TTL, or sooner when a plan assignment or override is scheduled to change.
+// A nil cache caches nothing.
+type entitlementCache struct {
+	ttl time.Duration
+
+	mu      sync.RWMutex
+	entries map[string]cachedEntitlements
+}
+
+type cachedEntitlements struct {
+	resolved  resolvedEntitlements
+	expiresAt time.Time
+}
+
+func newEntitlementCache(ttl time.Duration) *entitlementCache {
+	if ttl <= 0 {
+		return nil
+	}
+
+	return &entitlementCache{ttl: ttl, entries: map[string]cachedEntitlements{}}
+}
+
+func (c *entitlementCache) get(accountID string, now time.Time) (resolvedEntitlements, bool) {
+	if c == nil {
+		return resolvedEntitlements{}, false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[accountID]
+	if !ok || !now.Before(entry.expiresAt) {
+		return resolvedEntitlements{}, false
+	}
+
+	return entry.resolved, true
+}
+
+func (c *entitlementCache) put(accountID string, resolved resolvedEntitlements, now time.Time) {
+	if c == nil {
+		return
+	}
+
+	expiresAt := now.Add(c.ttl)
+	if resolved.validUntil != nil && resolved.validUntil.Before(expiresAt) {
+		expiresAt = *resolved.validUntil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= maxCachedEntitlements {
+		for id, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+		if len(c.entries) >= maxCachedEntitlements {
+			c.entries = map[string]cachedEntitlements{}
+		}
+	}
+
+	c.entries[accountID] = cachedEntitlements{resolved: resolved, expiresAt: expiresAt}
+}
+
+func (c *entitlementCache) invalidate(accountID string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	delete(c.entries, accountID)
+	c.mu.Unlock()
+}
+
+func (c *entitlementCache) flush() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	c.entries = map[string]cachedEntitlements{}
+	c.mu.Unlock()
+}
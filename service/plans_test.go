@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestEntitlements(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	repo.accounts["acct"] = Account{ID: "acct", Status: AccountActive}
+	svc := newTestService(repo, WithEntitlementCacheTTL(time.Minute))
+
+	seats := int64(10)
+	basic, err := svc.CreatePlan(ctx, CreatePlanRequest{Key: "basic", Name: "Basic", Entitlements: []Entitlement{{Key: "seats", Enabled: true, Limit: &seats}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pro, err := svc.CreatePlan(ctx, CreatePlanRequest{Key: "pro", Name: "Pro", Entitlements: []Entitlement{{Key: "sso", Enabled: true}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CreatePlan(ctx, CreatePlanRequest{Key: "dupe", Name: "Dupe", Entitlements: []Entitlement{{Key: "sso"}, {Key: "sso"}}}); errors.Cause(err) != ErrInvalidArgument {
+		t.Errorf("duplicate entitlement: expected ErrInvalidArgument, got %v", err)
+	}
+
+	check := func(key string) EntitlementCheck {
+		check, err := svc.CheckEntitlement(ctx, CheckEntitlementRequest{AccountID: "acct", Key: key})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return check
+	}
+
+	if c := check("sso"); c.Allowed || c.Source != EntitlementUndefined {
+		t.Errorf("account without a plan: unexpected %+v", c)
+	}
+
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	if _, err := svc.AssignPlan(ctx, AssignPlanRequest{AccountID: "acct", PlanID: basic.ID, StartsAt: &past}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.AssignPlan(ctx, AssignPlanRequest{AccountID: "acct", PlanID: pro.ID, StartsAt: &future}); err != nil {
+		t.Fatal(err)
+	}
+
+	if c := check("seats"); !c.Allowed || c.Limit == nil || *c.Limit != 10 || c.PlanID != basic.ID {
+		t.Errorf("current plan: unexpected %+v", c)
+	}
+	if c := check("sso"); c.Allowed {
+		t.Errorf("scheduled plan should not apply yet: %+v", c)
+	}
+	if resolved, _ := svc.entitlements.get("acct", time.Now()); resolved.validUntil == nil || !resolved.validUntil.Equal(future) {
+		t.Errorf("cache entry should expire when the scheduled plan starts, got %+v", resolved.validUntil)
+	}
+
+	// changes made elsewhere are not seen until the entry expires, while
+	// changes made through the service invalidate it
+	repo.plans[0].Entitlements = nil
+	if c := check("seats"); !c.Allowed {
+		t.Errorf("expected cached entitlement, got %+v", c)
+	}
+	name := "Basic"
+	if _, err := svc.UpdatePlan(ctx, UpdatePlanRequest{ID: basic.ID, Name: &name}); err != nil {
+		t.Fatal(err)
+	}
+	if c := check("seats"); c.Allowed {
+		t.Errorf("expected plan change to be seen, got %+v", c)
+	}
+
+	if _, err := svc.SetEntitlementOverride(ctx, SetEntitlementOverrideRequest{AccountID: "acct", Key: "sso", Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	if c := check("sso"); !c.Allowed || c.Source != EntitlementFromOverride {
+		t.Errorf("override: unexpected %+v", c)
+	}
+	if err := svc.RemoveEntitlementOverride(ctx, RemoveEntitlementOverrideRequest{AccountID: "acct", Key: "sso"}); err != nil {
+		t.Fatal(err)
+	}
+	if c := check("sso"); c.Allowed {
+		t.Errorf("removed override still applies: %+v", c)
+	}
+
+	if _, err := svc.CheckEntitlement(ctx, CheckEntitlementRequest{AccountID: "missing", Key: "sso"}); errors.Cause(err) != ErrNotFound {
+		t.Errorf("unknown account: expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+)
+
+type NotificationChannel string
+
+const (
+	NotificationEmail NotificationChannel = "email"
+	NotificationSMS   NotificationChannel = "sms"
+	NotificationPush  NotificationChannel = "push"
+	NotificationInApp NotificationChannel = "in_app"
+)
+
+// Valid reports whether c is one of the known channels.
+func (c NotificationChannel) Valid() bool {
+	switch c {
+	case NotificationEmail, NotificationSMS, NotificationPush, NotificationInApp:
+		return true
+	}
+
+	return false
+}
+
+// Preferences are how a user would like to be addressed and notified. Unset
+// preferences are inherited: a user's from the account they are acting in,
+// an account's from its parents and finally from the service defaults.
+type Preferences struct {
+	// Locale is a BCP 47 language tag, such as "en-GB".
+	Locale string `json:"locale,omitempty"`
+	// TimeZone is an IANA time zone name, such as "Europe/London".
+	TimeZone string `json:"time_zone,omitempty"`
+	// Notifications turns channels on or off. Channels which are absent are
+	// inherited.
+	Notifications map[NotificationChannel]bool `json:"notifications,omitempty"`
+}
+
+// defaultPreferences apply where neither a user nor their accounts say otherwise.
+var defaultPreferences = Preferences{
+	Locale:   "en-US",
+	TimeZone: "UTC",
+	Notifications: map[NotificationChannel]bool{
+		NotificationEmail: true,
+		NotificationSMS:   false,
+		NotificationPush:  false,
+		NotificationInApp: true,
+	},
+}
+
+// inherit fills the preferences p leaves unset from parent.
+func (p Preferences) inherit(parent Preferences) Preferences {
+	inherited := Preferences{Locale: p.Locale, TimeZone: p.TimeZone, Notifications: map[NotificationChannel]bool{}}
+	if inherited.Locale == "" {
+		inherited.Locale = parent.Locale
+	}
+	if inherited.TimeZone == "" {
+		inherited.TimeZone = parent.TimeZone
+	}
+	for channel, enabled := range parent.Notifications {
+		inherited.Notifications[channel] = enabled
+	}
+	for channel, enabled := range p.Notifications {
+		inherited.Notifications[channel] = enabled
+	}
+
+	return inherited
+}
+
+// UserPreferences are a user's own preferences along with those in effect
+// when they act in an account.
+type UserPreferences struct {
+	UserID      string
+	AccountID   string
+	Preferences Preferences
+	Effective   Preferences
+}
+
+// AccountPreferences are the defaults an account sets for its users along
+// with those in effect after inheriting from its parents.
+type AccountPreferences struct {
+	AccountID   string
+	Preferences Preferences
+	Effective   Preferences
+}
+
+// GetUserPreferencesRequest resolves a user's preferences within an account.
+// Without an account only the service defaults are inherited.
+type GetUserPreferencesRequest struct {
+	UserID    string
+	AccountID string
+}
+
+// UpdateUserPreferencesRequest changes a user's preferences, returning them as
+// resolved within AccountID. Locale and TimeZone are left unchanged when nil
+// and inherited again when empty.
+type UpdateUserPreferencesRequest struct {
+	UserID    string
+	AccountID string
+	Locale    *string
+	TimeZone  *string
+	// Notifications are set after ClearNotifications are inherited again.
+	Notifications      map[NotificationChannel]bool
+	ClearNotifications []NotificationChannel
+}
+
+type GetAccountPreferencesRequest struct {
+	AccountID string
+}
+
+// UpdateAccountPreferencesRequest changes the defaults of an account's users,
+// with the same fields as UpdateUserPreferencesRequest.
+type UpdateAccountPreferencesRequest struct {
+	AccountID          string
+	Locale             *string
+	TimeZone           *string
+	Notifications      map[NotificationChannel]bool
+	ClearNotifications []NotificationChannel
+}
+
+func (svc *customersService) GetUserPreferences(ctx context.Context, req GetUserPreferencesRequest) (preferences UserPreferences, err error) {
+	user, err := svc.repo.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		return
+	}
+
+	return svc.userPreferences(ctx, user, req.AccountID)
+}
+
+func (svc *customersService) UpdateUserPreferences(ctx context.Context, req UpdateUserPreferencesRequest) (preferences UserPreferences, err error) {
+	user, err := svc.repo.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		return
+	}
+
+	if req.AccountID != "" {
+		if _, err = svc.membership(ctx, req.AccountID, user.ID); err != nil {
+			return
+		}
+	}
+
+	user.Preferences, err = applyPreferences(user.Preferences, req.Locale, req.TimeZone, req.Notifications, req.ClearNotifications)
+	if err != nil {
+		return
+	}
+
+	user, err = svc.repo.UpdateUser(ctx, user)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to update user preferences", "error", err.Error())
+		return
+	}
+
+	return svc.userPreferences(ctx, user, req.AccountID)
+}
+
+func (svc *customersService) GetAccountPreferences(ctx context.Context, req GetAccountPreferencesRequest) (preferences AccountPreferences, err error) {
+	account, err := svc.repo.GetAccountByID(ctx, req.AccountID)
+	if err != nil {
+		return
+	}
+
+	return svc.accountPreferences(ctx, account)
+}
+
+// UpdateAccountPreferences changes an account's defaults, which also apply to
+// the users of its child accounts unless those set their own.
+func (svc *customersService) UpdateAccountPreferences(ctx context.Context, req UpdateAccountPreferencesRequest) (preferences AccountPreferences, err error) {
+	account, err := svc.repo.GetAccountByID(ctx, req.AccountID)
+	if err != nil {
+		return
+	}
+
+	account.DefaultPreferences, err = applyPreferences(account.DefaultPreferences, req.Locale, req.TimeZone, req.Notifications, req.ClearNotifications)
+	if err != nil {
+		return
+	}
+
+	account, err = svc.repo.UpdateAccount(ctx, account)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to update account preferences", "error", err.Error())
+		return
+	}
+
+	return svc.accountPreferences(ctx, account)
+}
+
+func (svc *customersService) userPreferences(ctx context.Context, user User, accountID string) (UserPreferences, error) {
+	preferences := UserPreferences{UserID: user.ID, AccountID: accountID, Preferences: user.Preferences}
+	if accountID == "" {
+		preferences.Effective = user.Preferences.inherit(defaultPreferences)
+		return preferences, nil
+	}
+
+	if _, err := svc.membership(ctx, accountID, user.ID); err != nil {
+		return preferences, err
+	}
+
+	account, err := svc.repo.GetAccountByID(ctx, accountID)
+	if err != nil {
+		return preferences, err
+	}
+
+	inherited, err := svc.accountPreferences(ctx, account)
+	if err != nil {
+		return preferences, err
+	}
+
+	preferences.Effective = user.Preferences.inherit(inherited.Effective)
+	return preferences, nil
+}
+
+func (svc *customersService) accountPreferences(ctx context.Context, account Account) (AccountPreferences, error) {
+	ancestors, err := svc.repo.SelectAccountAncestors(ctx, account.ID)
+	if err != nil {
+		return AccountPreferences{}, err
+	}
+
+	// apply the service defaults, then each ancestor from the root down
+	effective := defaultPreferences
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		effective = ancestors[i].DefaultPreferences.inherit(effective)
+	}
+
+	return AccountPreferences{
+		AccountID:   account.ID,
+		Preferences: account.DefaultPreferences,
+		Effective:   account.DefaultPreferences.inherit(effective),
+	}, nil
+}
+
+// applyPreferences makes the changes of an update request, returning the
+// validated result without modifying current.
+func applyPreferences(current Preferences, locale, timeZone *string, notifications map[NotificationChannel]bool, reset []NotificationChannel) (Preferences, error) {
+	updated := Preferences{Locale: current.Locale, TimeZone: current.TimeZone}
+
+	if locale != nil {
+		canonical, err := canonicalLocale(*locale)
+		if err != nil {
+			return updated, err
+		}
+		updated.Locale = canonical
+	}
+
+	if timeZone != nil {
+		if err := validateTimeZone(*timeZone); err != nil {
+			return updated, err
+		}
+		updated.TimeZone = *timeZone
+	}
+
+	updated.Notifications = make(map[NotificationChannel]bool, len(current.Notifications)+len(notifications))
+	for channel, enabled := range current.Notifications {
+		updated.Notifications[channel] = enabled
+	}
+	for _, channel := range reset {
+		delete(updated.Notifications, channel)
+	}
+	for channel, enabled := range notifications {
+		if !channel.Valid() {
+			return updated, errors.Wrapf(ErrInvalidArgument, "unknown notification channel %q", channel)
+		}
+		updated.Notifications[channel] = enabled
+	}
+
+	return updated, nil
+}
+
+// canonicalLocale validates a BCP 47 language tag, returning it in canonical
+// form so "en-gb" is stored as "en-GB". The empty locale is left empty.
+func canonicalLocale(locale string) (string, error) {
+	if locale == "" {
+		return "", nil
+	}
+
+	tag, err := language.Parse(locale)
+	if err != nil || tag == language.Und {
+		return "", errors.Wrapf(ErrInvalidArgument, "invalid locale %q", locale)
+	}
+
+	return tag.String(), nil
+}
+
+// validateTimeZone checks for an IANA time zone name. The empty time zone is
+// allowed, while "Local" is not as it depends on the server.
+func validateTimeZone(timeZone string) error {
+	if timeZone == "" {
+		return nil
+	}
+
+	if timeZone == "Local" {
+		return errors.Wrapf(ErrInvalidArgument, "invalid time zone %q", timeZone)
+	}
+
+	if _, err := time.LoadLocation(timeZone); err != nil {
+		return errors.Wrapf(ErrInvalidArgument, "invalid time zone %q", timeZone)
+	}
+
+	return nil
+}
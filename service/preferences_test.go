@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestPreferences(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	root := "root"
+	repo.accounts["root"] = Account{ID: "root", Status: AccountActive, DefaultPreferences: Preferences{
+		TimeZone:      "Europe/London",
+		Notifications: map[NotificationChannel]bool{NotificationSMS: true},
+	}}
+	repo.accounts["acct"] = Account{ID: "acct", Status: AccountActive, ParentID: &root}
+	repo.users["alice"] = User{ID: "alice", Kind: UserHuman}
+	repo.memberships = []Membership{{AccountID: "acct", UserID: "alice", Role: RoleMember, Status: UserActive}}
+	svc := newTestService(repo)
+
+	str := func(s string) *string { return &s }
+	for _, req := range []UpdateAccountPreferencesRequest{
+		{AccountID: "acct", TimeZone: str("Mars/Olympus_Mons")},
+		{AccountID: "acct", TimeZone: str("Local")},
+		{AccountID: "acct", Locale: str("english")},
+		{AccountID: "acct", Locale: str("und")},
+		{AccountID: "acct", Notifications: map[NotificationChannel]bool{"fax": true}},
+	} {
+		if _, err := svc.UpdateAccountPreferences(ctx, req); errors.Cause(err) != ErrInvalidArgument {
+			t.Errorf("%+v: expected ErrInvalidArgument, got %v", req, err)
+		}
+	}
+
+	account, err := svc.UpdateAccountPreferences(ctx, UpdateAccountPreferencesRequest{AccountID: "acct", Locale: str("fr-fr")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Preferences.Locale != "fr-FR" || account.Effective.TimeZone != "Europe/London" || !account.Effective.Notifications[NotificationSMS] {
+		t.Errorf("account should inherit from its parent, got %+v", account)
+	}
+
+	user, err := svc.UpdateUserPreferences(ctx, UpdateUserPreferencesRequest{
+		UserID:        "alice",
+		AccountID:     "acct",
+		Locale:        str("en-gb"),
+		Notifications: map[NotificationChannel]bool{NotificationEmail: false},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := user.Effective; e.Locale != "en-GB" || e.TimeZone != "Europe/London" || e.Notifications[NotificationEmail] || !e.Notifications[NotificationSMS] || !e.Notifications[NotificationInApp] {
+		t.Errorf("unexpected effective preferences %+v", e)
+	}
+
+	standalone, err := svc.GetUserPreferences(ctx, GetUserPreferencesRequest{UserID: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := standalone.Effective; e.TimeZone != "UTC" || e.Notifications[NotificationSMS] {
+		t.Errorf("without an account only the defaults should be inherited, got %+v", e)
+	}
+
+	user, err = svc.UpdateUserPreferences(ctx, UpdateUserPreferencesRequest{UserID: "alice", AccountID: "acct", Locale: str(""), ClearNotifications: []NotificationChannel{NotificationEmail}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := user.Effective; e.Locale != "fr-FR" || !e.Notifications[NotificationEmail] {
+		t.Errorf("cleared preferences should be inherited again, got %+v", e)
+	}
+
+	if _, err := svc.GetUserPreferences(ctx, GetUserPreferencesRequest{UserID: "alice", AccountID: "root"}); errors.Cause(err) != ErrNotFound {
+		t.Errorf("non-member account: expected ErrNotFound, got %v", err)
+	}
+}
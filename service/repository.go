@@ -2,24 +2,224 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
 	"github.com/symptomatichq/kit/pgutil"
 )
 
 type Repository interface {
+	// Transaction runs fn in a single transaction, committing it when fn
+	// succeeds and rolling it back otherwise. Repository calls made with the
+	// context given to fn join the transaction, as do nested transactions.
+	Transaction(context.Context, func(context.Context) error) error
 	InsertAccount(context.Context, Account) (Account, error)
 	GetAccountByID(context.Context, string) (Account, error)
+	// SelectAccounts filters on "subtree_of" matching an account and all of
+	// its descendants, on "labels" matching a Selector and on
+	// "trial_ends_before" matching trials which end by the given time.
 	SelectAccounts(context.Context, map[string]interface{}) ([]Account, error)
+	// SelectAccountAncestors returns the parents of an account up to the root
+	// of its hierarchy, nearest first.
+	SelectAccountAncestors(context.Context, string) ([]Account, error)
+	UpdateAccount(context.Context, Account) (Account, error)
 	InsertUser(context.Context, User) (User, error)
 	GetUserByID(context.Context, string) (User, error)
+	// GetUserByEmail looks up a user by their normalized email address,
+	// returning ErrNotFound when no user has the address.
+	GetUserByEmail(context.Context, string) (User, error)
+	UpdateUser(context.Context, User) (User, error)
+	// SelectUsers filters on "account_id" through the users' memberships, on
+	// "group_id" through their group memberships, on "kind", on "labels"
+	// matching a Selector and on "custom_fields" through the values held on
+	// their membership of "account_id".
 	SelectUsers(context.Context, map[string]interface{}) ([]User, error)
+	InsertMembership(context.Context, Membership) (Membership, error)
+	UpdateMembership(context.Context, Membership) (Membership, error)
+	DeleteMembership(context.Context, Membership) error
+	SelectMemberships(context.Context, map[string]interface{}) ([]Membership, error)
+	SelectUserAccounts(context.Context, string) ([]UserAccount, error)
+	InsertInvitation(context.Context, Invitation) (Invitation, error)
+	UpdateInvitation(context.Context, Invitation) (Invitation, error)
+	// AcceptInvitation updates an invitation only while it is still
+	// pending, returning ErrNotFound otherwise, so that concurrent callers
+	// cannot both accept it.
+	AcceptInvitation(context.Context, Invitation) (Invitation, error)
+	SelectInvitations(context.Context, map[string]interface{}) ([]Invitation, error)
+	InsertEmailVerification(context.Context, EmailVerification) (EmailVerification, error)
+	UpdateEmailVerification(context.Context, EmailVerification) (EmailVerification, error)
+	SelectEmailVerifications(context.Context, map[string]interface{}) ([]EmailVerification, error)
+	// SearchCustomers ranks accounts and users using the trigram and
+	// full-text indexes, so the repository is the default Searcher.
+	SearchCustomers(context.Context, SearchQuery) ([]SearchResult, error)
+	// UpsertDuplicateCandidate records a suspected duplicate pair, keeping the
+	// status of a pair which has already been recorded.
+	UpsertDuplicateCandidate(context.Context, DuplicateCandidate) (DuplicateCandidate, error)
+	UpdateDuplicateCandidate(context.Context, DuplicateCandidate) (DuplicateCandidate, error)
+	// SelectDuplicateCandidates filters on "involves" matching either account of a pair.
+	SelectDuplicateCandidates(context.Context, map[string]interface{}) ([]DuplicateCandidate, error)
+	InsertAccountMerge(context.Context, AccountMerge) (AccountMerge, error)
+	// TransferMembership replaces the first membership with the second and
+	// records the transfer in a single transaction.
+	TransferMembership(context.Context, Membership, Membership, UserTransfer) (UserTransfer, error)
+	InsertOwnershipTransfer(context.Context, OwnershipTransfer) (OwnershipTransfer, error)
+	UpdateOwnershipTransfer(context.Context, OwnershipTransfer) (OwnershipTransfer, error)
+	SelectOwnershipTransfers(context.Context, map[string]interface{}) ([]OwnershipTransfer, error)
+	InsertGroup(context.Context, Group) (Group, error)
+	UpdateGroup(context.Context, Group) (Group, error)
+	DeleteGroup(context.Context, Group) error
+	SelectGroups(context.Context, map[string]interface{}) ([]Group, error)
+	InsertGroupMember(context.Context, GroupMember) (GroupMember, error)
+	DeleteGroupMember(context.Context, GroupMember) error
+	SelectGroupMembers(context.Context, map[string]interface{}) ([]GroupMember, error)
+	InsertAPIKey(context.Context, APIKey) (APIKey, error)
+	UpdateAPIKey(context.Context, APIKey) (APIKey, error)
+	SelectAPIKeys(context.Context, map[string]interface{}) ([]APIKey, error)
+	InsertCustomField(context.Context, CustomField) (CustomField, error)
+	UpdateCustomField(context.Context, CustomField) (CustomField, error)
+	// DeleteCustomField deletes a field and removes its values from the
+	// memberships of its account in a single transaction.
+	DeleteCustomField(context.Context, CustomField) error
+	SelectCustomFields(context.Context, map[string]interface{}) ([]CustomField, error)
+	InsertPlan(context.Context, Plan) (Plan, error)
+	UpdatePlan(context.Context, Plan) (Plan, error)
+	SelectPlans(context.Context, map[string]interface{}) ([]Plan, error)
+	InsertAccountPlan(context.Context, AccountPlan) (AccountPlan, error)
+	SelectAccountPlans(context.Context, map[string]interface{}) ([]AccountPlan, error)
+	// UpsertEntitlementOverride replaces any override of the same key for the account.
+	UpsertEntitlementOverride(context.Context, EntitlementOverride) (EntitlementOverride, error)
+	DeleteEntitlementOverride(context.Context, EntitlementOverride) error
+	SelectEntitlementOverrides(context.Context, map[string]interface{}) ([]EntitlementOverride, error)
+	// LockAccountSeats runs fn in a transaction holding a lock on the
+	// account, so seats are counted and taken without racing other callers.
+	// Repository calls made with the context given to fn join the
+	// transaction, which itself joins any transaction of the context given.
+	LockAccountSeats(context.Context, string, func(context.Context) error) error
+	// CountSeats counts the active memberships of human users in an account.
+	CountSeats(context.Context, string) (int64, error)
+	InsertScheduledAction(context.Context, ScheduledAction) (ScheduledAction, error)
+	UpdateScheduledAction(context.Context, ScheduledAction) (ScheduledAction, error)
+	SelectScheduledActions(context.Context, map[string]interface{}) ([]ScheduledAction, error)
+	// ClaimScheduledActions locks up to limit pending actions due by the
+	// given time which no runner holds, earliest first, until the second
+	// time and counts an attempt of each. Concurrent runners claim distinct
+	// actions.
+	ClaimScheduledActions(context.Context, time.Time, time.Time, int) ([]ScheduledAction, error)
+	InsertFeatureFlag(context.Context, FeatureFlag) (FeatureFlag, error)
+	UpdateFeatureFlag(context.Context, FeatureFlag) (FeatureFlag, error)
+	// DeleteFeatureFlag deletes a flag along with its overrides.
+	DeleteFeatureFlag(context.Context, FeatureFlag) error
+	SelectFeatureFlags(context.Context, map[string]interface{}) ([]FeatureFlag, error)
+	// UpsertFlagOverride replaces any override of the same flag for the
+	// account or user.
+	UpsertFlagOverride(context.Context, FlagOverride) (FlagOverride, error)
+	DeleteFlagOverride(context.Context, FlagOverride) error
+	SelectFlagOverrides(context.Context, map[string]interface{}) ([]FlagOverride, error)
 }
 
-func NewRepository(dbConfig *pgutil.ConnectionOptions) Repository {
-	return &repository{}
+func NewRepository(dbConfig *pgutil.ConnectionOptions) (Repository, error) {
+	db, err := sql.Open("postgres", dbConfig.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open the database")
+	}
+
+	return &repository{db: db}, nil
+}
+
+type repository struct {
+	db *sql.DB
+}
+
+// conditions builds the WHERE clause of a query from filters, numbering the
+// placeholders of its arguments as they are added.
+type conditions struct {
+	clauses []string
+	args    []interface{}
+}
+
+// arg adds an argument to the query and returns its placeholder.
+func (c *conditions) arg(value interface{}) string {
+	c.args = append(c.args, value)
+	return fmt.Sprintf("$%d", len(c.args))
+}
+
+func (c *conditions) add(clause string) {
+	c.clauses = append(c.clauses, clause)
+}
+
+// anyOf matches column against a filter of either one value or a list.
+func (c *conditions) anyOf(column string, value interface{}) {
+	if values, ok := value.([]string); ok {
+		c.add(fmt.Sprintf("%s = ANY(%s)", column, c.arg(pq.Array(values))))
+		return
+	}
+	c.add(fmt.Sprintf("%s = %s", column, c.arg(value)))
+}
+
+// labels matches a JSONB labels column against a selector.
+func (c *conditions) labels(column string, selector Selector) {
+	clause, args := labelSelectorSQL(column, selector, len(c.args))
+	c.add(clause)
+	c.args = append(c.args, args...)
+}
+
+func (c *conditions) String() string {
+	if len(c.clauses) == 0 {
+		return "TRUE"
+	}
+	return strings.Join(c.clauses, " AND ")
+}
+
+// accountColumns are scanned by scanAccounts, in order.
+const accountColumns = `"accounts"."id", COALESCE("accounts"."name", ''), COALESCE("accounts"."contact_email", ''),
+    "accounts"."status", "accounts"."parent_id", "accounts"."merged_into", "accounts"."labels", "accounts"."metadata",
+    "accounts"."default_preferences", "accounts"."trial_started_at", "accounts"."trial_ends_at",
+    "accounts"."trial_converted_at", "accounts"."updated_at", "accounts"."created_at"`
+
+func scanAccounts(rows *sql.Rows) (accounts []Account, err error) {
+	defer rows.Close()
+	for rows.Next() {
+		var account Account
+		if err = scanAccount(rows, &account); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, errors.Wrap(rows.Err(), "failed to read accounts")
 }
 
-type repository struct{}
+// scanAccount scans accountColumns followed by any extra columns.
+func scanAccount(rows *sql.Rows, account *Account, extra ...interface{}) error {
+	var labels, metadata, preferences []byte
+	if err := rows.Scan(append([]interface{}{
+		&account.ID, &account.Name, &account.ContactEmail,
+		&account.Status, &account.ParentID, &account.MergedInto, &labels, &metadata,
+		&preferences, &account.TrialStartedAt, &account.TrialEndsAt,
+		&account.TrialConvertedAt, &account.UpdatedAt, &account.CreatedAt,
+	}, extra...)...); err != nil {
+		return errors.Wrap(err, "failed to scan account")
+	}
+	if err := json.Unmarshal(labels, &account.Labels); err != nil {
+		return errors.Wrapf(err, "failed to decode labels of account %s", account.ID)
+	}
+	if err := json.Unmarshal(preferences, &account.DefaultPreferences); err != nil {
+		return errors.Wrapf(err, "failed to decode default preferences of account %s", account.ID)
+	}
+	account.Metadata = json.RawMessage(metadata)
+	return nil
+}
+
+// Transaction fails rather than running fn outside of a transaction, as the
+// repository has no database to hold it in.
+func (r *repository) Transaction(ctx context.Context, fn func(context.Context) error) (err error) {
+	return errors.New("cannot begin a transaction: the repository has no database")
+}
 
 func (r *repository) InsertAccount(ctx context.Context, newAccount Account) (account Account, err error) {
 	return
@@ -29,7 +229,93 @@ func (r *repository) GetAccountByID(ctx context.Context, id string) (account Acc
 	return
 }
 
-func (r *repository) SelectAccounts(ctx context.Context, filters map[string]interface{}) (account []Account, err error) {
+func (r *repository) SelectAccounts(ctx context.Context, filters map[string]interface{}) (accounts []Account, err error) {
+	var where conditions
+	for key, value := range filters {
+		switch key {
+		case "id", "parent_id", "status", "contact_email":
+			where.anyOf(fmt.Sprintf(`"accounts".%q`, key), value)
+		case "subtree_of":
+			where.add(fmt.Sprintf(accountSubtreeCondition, where.arg(value)))
+		case "trial_ends_before":
+			where.add(fmt.Sprintf(`"accounts"."trial_ends_at" < %s`, where.arg(value)))
+		case "labels":
+			where.labels(`"accounts"."labels"`, value.(Selector))
+		default:
+			return nil, errors.Errorf("cannot select accounts by %s", key)
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`SELECT %s FROM "accounts" WHERE %s ORDER BY "accounts"."id"`, accountColumns, &where), where.args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to select accounts")
+	}
+	return scanAccounts(rows)
+}
+
+// accountSubtreeCondition matches an account and all of its descendants.
+const accountSubtreeCondition = `"accounts"."id" IN (
+    WITH RECURSIVE "subtree" ("id") AS (
+        SELECT "id" FROM "accounts" WHERE "id" = %s
+        UNION
+        SELECT "accounts"."id" FROM "accounts" JOIN "subtree" ON "accounts"."parent_id" = "subtree"."id"
+    )
+    SELECT "id" FROM "subtree"
+)`
+
+// selectAccountAncestors selects the parents of an account, nearest first.
+const selectAccountAncestors = `
+WITH RECURSIVE "ancestors" ("id", "depth") AS (
+    SELECT "parent_id", 1 FROM "accounts" WHERE "id" = $1 AND "parent_id" IS NOT NULL
+    UNION ALL
+    SELECT "accounts"."parent_id", "ancestors"."depth" + 1
+    FROM "accounts" JOIN "ancestors" ON "accounts"."id" = "ancestors"."id"
+    WHERE "accounts"."parent_id" IS NOT NULL
+)
+SELECT ` + accountColumns + ` FROM "accounts" JOIN "ancestors" USING ("id") ORDER BY "ancestors"."depth"`
+
+// labelSelectorSQL translates a selector into a condition on a JSONB labels
+// column, numbering its placeholders from offset+1. Equality uses containment
+// and existence the "?" operator, so both are served by the GIN index.
+func labelSelectorSQL(column string, selector Selector, offset int) (string, []interface{}) {
+	conditions := make([]string, 0, len(selector))
+	args := make([]interface{}, 0, len(selector))
+	for _, r := range selector {
+		placeholder := fmt.Sprintf("$%d", offset+len(args)+1)
+		switch r.Operator {
+		case SelectorEquals, SelectorNotEquals:
+			document, _ := json.Marshal(map[string]string{r.Key: r.Value})
+			condition := fmt.Sprintf(`%s @> %s::jsonb`, column, placeholder)
+			if r.Operator == SelectorNotEquals {
+				condition = "NOT " + condition
+			}
+			conditions = append(conditions, condition)
+			args = append(args, string(document))
+		case SelectorExists:
+			conditions = append(conditions, fmt.Sprintf(`%s ? %s`, column, placeholder))
+			args = append(args, r.Key)
+		case SelectorNotExists:
+			conditions = append(conditions, fmt.Sprintf(`NOT %s ? %s`, column, placeholder))
+			args = append(args, r.Key)
+		}
+	}
+
+	if len(conditions) == 0 {
+		return "TRUE", nil
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+func (r *repository) SelectAccountAncestors(ctx context.Context, id string) (ancestors []Account, err error) {
+	rows, err := r.db.QueryContext(ctx, selectAccountAncestors, id)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to select ancestors of account %s", id)
+	}
+	return scanAccounts(rows)
+}
+
+func (r *repository) UpdateAccount(ctx context.Context, changed Account) (account Account, err error) {
 	return
 }
 
@@ -43,6 +329,394 @@ func (r *repository) GetUserByID(ctx context.Context, id string) (user User, err
 
 }
 
+func (r *repository) GetUserByEmail(ctx context.Context, email string) (user User, err error) {
+	return
+}
+
+func (r *repository) UpdateUser(ctx context.Context, changed User) (user User, err error) {
+	return
+}
+
 func (r *repository) SelectUsers(ctx context.Context, filters map[string]interface{}) (users []User, err error) {
+	var where conditions
+	var memberships []string
+	for key, value := range filters {
+		switch key {
+		case "kind", "email_canonical":
+			where.anyOf(fmt.Sprintf(`"users".%q`, key), value)
+		case "labels":
+			where.labels(`"users"."labels"`, value.(Selector))
+		case "account_id":
+			memberships = append(memberships, fmt.Sprintf(`"memberships"."account_id" = ANY(%s)`, where.arg(pq.Array(value.([]string)))))
+		case "custom_fields":
+			document, err := json.Marshal(value)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to encode custom field filter")
+			}
+			memberships = append(memberships, fmt.Sprintf(`"memberships"."custom_fields" @> %s::jsonb`, where.arg(string(document))))
+		case "group_id":
+			where.add(fmt.Sprintf(`"users"."id" IN (SELECT "user_id" FROM "group_members" WHERE "group_id" = ANY(%s))`, where.arg(pq.Array(value.([]string)))))
+		default:
+			return nil, errors.Errorf("cannot select users by %s", key)
+		}
+	}
+	// custom fields are held on the membership of the account filtered on,
+	// so both must match the same membership
+	if len(memberships) > 0 {
+		where.add(fmt.Sprintf(`"users"."id" IN (SELECT "memberships"."user_id" FROM "memberships" WHERE %s)`, strings.Join(memberships, " AND ")))
+	}
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`SELECT %s FROM "users" WHERE %s ORDER BY "users"."id"`, userColumns, &where), where.args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to select users")
+	}
+	return scanUsers(rows)
+}
+
+// userColumns are scanned by scanUsers, in order.
+const userColumns = `"users"."id", "users"."kind", "users"."status", "users"."email", "users"."email_canonical",
+    "users"."name", "users"."labels", "users"."metadata", "users"."preferences", "users"."verified_at",
+    "users"."updated_at", "users"."created_at", "users"."last_login"`
+
+func scanUsers(rows *sql.Rows) (users []User, err error) {
+	defer rows.Close()
+	for rows.Next() {
+		var user User
+		if err = scanUser(rows, &user); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, errors.Wrap(rows.Err(), "failed to read users")
+}
+
+// scanUser scans userColumns followed by any extra columns.
+func scanUser(rows *sql.Rows, user *User, extra ...interface{}) error {
+	var labels, metadata, preferences []byte
+	if err := rows.Scan(append([]interface{}{
+		&user.ID, &user.Kind, &user.Status, &user.Email, &user.EmailCanonical,
+		&user.Name, &labels, &metadata, &preferences, &user.VerifiedAt,
+		&user.UpdatedAt, &user.CreatedAt, &user.LastLogin,
+	}, extra...)...); err != nil {
+		return errors.Wrap(err, "failed to scan user")
+	}
+	if err := json.Unmarshal(labels, &user.Labels); err != nil {
+		return errors.Wrapf(err, "failed to decode labels of user %s", user.ID)
+	}
+	if err := json.Unmarshal(preferences, &user.Preferences); err != nil {
+		return errors.Wrapf(err, "failed to decode preferences of user %s", user.ID)
+	}
+	user.Metadata = json.RawMessage(metadata)
+	return nil
+}
+
+func (r *repository) InsertMembership(ctx context.Context, newMembership Membership) (membership Membership, err error) {
+	return
+}
+
+func (r *repository) UpdateMembership(ctx context.Context, changed Membership) (membership Membership, err error) {
+	return
+}
+
+func (r *repository) DeleteMembership(ctx context.Context, membership Membership) (err error) {
+	return
+}
+
+func (r *repository) SelectMemberships(ctx context.Context, filters map[string]interface{}) (memberships []Membership, err error) {
+	return
+}
+
+func (r *repository) SelectUserAccounts(ctx context.Context, userID string) (accounts []UserAccount, err error) {
+	return
+}
+
+func (r *repository) InsertInvitation(ctx context.Context, newInvitation Invitation) (invitation Invitation, err error) {
+	return
+}
+
+func (r *repository) UpdateInvitation(ctx context.Context, changed Invitation) (invitation Invitation, err error) {
+	return
+}
+
+func (r *repository) AcceptInvitation(ctx context.Context, accepted Invitation) (invitation Invitation, err error) {
+	return
+}
+
+func (r *repository) SelectInvitations(ctx context.Context, filters map[string]interface{}) (invitations []Invitation, err error) {
+	return
+}
+
+func (r *repository) InsertEmailVerification(ctx context.Context, newVerification EmailVerification) (verification EmailVerification, err error) {
+	return
+}
+
+func (r *repository) UpdateEmailVerification(ctx context.Context, changed EmailVerification) (verification EmailVerification, err error) {
+	return
+}
+
+func (r *repository) SelectEmailVerifications(ctx context.Context, filters map[string]interface{}) (verifications []EmailVerification, err error) {
+	return
+}
+
+// SearchCustomers ranks accounts and users in separate queries and merges
+// them by score. Records match when the trigram similarity of their name or
+// email reaches the pg_trgm threshold, when the query closely matches a part
+// of either, or on whole words through the full-text index; the latter two
+// rank from 0.6 as containing matches do in the memory searcher.
+func (r *repository) SearchCustomers(ctx context.Context, query SearchQuery) (results []SearchResult, err error) {
+	limit := sql.NullInt64{Int64: int64(query.Limit), Valid: query.Limit > 0}
+
+	if query.includes(SearchAccounts) {
+		where := conditions{args: []interface{}{query.Text, limit}}
+		where.add(`"accounts"."status" <> 'merged'`)
+		if len(query.AccountIDs) > 0 {
+			where.anyOf(`"accounts"."id"`, query.AccountIDs)
+		}
+
+		rows, err := r.db.QueryContext(ctx, searchSQL("accounts", "contact_email", accountColumns, &where), where.args...)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to search accounts")
+		}
+		defer rows.Close()
+		for rows.Next() {
+			result := SearchResult{Kind: SearchAccounts, Account: &Account{}}
+			if err = scanAccount(rows, result.Account, &result.Score); err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+		}
+		if err = rows.Err(); err != nil {
+			return nil, errors.Wrap(err, "failed to read accounts")
+		}
+	}
+
+	if query.includes(SearchUsers) {
+		where := conditions{args: []interface{}{query.Text, limit}}
+		where.add(`"users"."kind" = 'human'`)
+		if len(query.AccountIDs) > 0 {
+			where.add(fmt.Sprintf(`"users"."id" IN (SELECT "user_id" FROM "memberships" WHERE "account_id" = ANY(%s))`, where.arg(pq.Array(query.AccountIDs))))
+		}
+
+		rows, err := r.db.QueryContext(ctx, searchSQL("users", "email", userColumns, &where), where.args...)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to search users")
+		}
+		defer rows.Close()
+		for rows.Next() {
+			result := SearchResult{Kind: SearchUsers, User: &User{}}
+			if err = scanUser(rows, result.User, &result.Score); err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+		}
+		if err = rows.Err(); err != nil {
+			return nil, errors.Wrap(err, "failed to read users")
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if query.Limit > 0 && len(results) > query.Limit {
+		results = results[:query.Limit]
+	}
+
+	return results, nil
+}
+
+// searchSQL selects the columns and score of the records of a table matching
+// the text in $1, up to the limit in $2. The document expression matches the
+// full-text indexes created alongside the trigram ones.
+func searchSQL(table, emailColumn, columns string, where *conditions) string {
+	name := fmt.Sprintf(`%q."name"`, table)
+	email := fmt.Sprintf(`%q.%q::TEXT`, table, emailColumn)
+	document := fmt.Sprintf(`to_tsvector('simple', coalesce(%s, '') || ' ' || coalesce(%s, ''))`, name, email)
+
+	return fmt.Sprintf(`SELECT %[1]s, GREATEST(
+    similarity(%[2]s, $1), similarity(%[3]s, $1),
+    word_similarity($1, %[2]s), word_similarity($1, %[3]s),
+    CASE WHEN %[4]s @@ plainto_tsquery('simple', $1) THEN 0.6 + 0.4 * ts_rank(%[4]s, plainto_tsquery('simple', $1)) END
+) AS "score"
+FROM %[5]q
+WHERE (%[2]s %% $1 OR %[3]s %% $1 OR $1 <%% %[2]s OR $1 <%% %[3]s OR %[4]s @@ plainto_tsquery('simple', $1))
+    AND %[6]s
+ORDER BY "score" DESC
+LIMIT $2`, columns, name, email, document, table, where)
+}
+
+func (r *repository) UpsertDuplicateCandidate(ctx context.Context, candidate DuplicateCandidate) (upserted DuplicateCandidate, err error) {
+	return
+}
+
+func (r *repository) UpdateDuplicateCandidate(ctx context.Context, changed DuplicateCandidate) (candidate DuplicateCandidate, err error) {
+	return
+}
+
+func (r *repository) SelectDuplicateCandidates(ctx context.Context, filters map[string]interface{}) (candidates []DuplicateCandidate, err error) {
+	return
+}
+
+func (r *repository) InsertAccountMerge(ctx context.Context, newMerge AccountMerge) (merge AccountMerge, err error) {
+	return
+}
+
+func (r *repository) TransferMembership(ctx context.Context, from Membership, to Membership, newTransfer UserTransfer) (transfer UserTransfer, err error) {
+	return
+}
+
+func (r *repository) InsertOwnershipTransfer(ctx context.Context, newTransfer OwnershipTransfer) (transfer OwnershipTransfer, err error) {
+	return
+}
+
+func (r *repository) UpdateOwnershipTransfer(ctx context.Context, changed OwnershipTransfer) (transfer OwnershipTransfer, err error) {
+	return
+}
+
+func (r *repository) SelectOwnershipTransfers(ctx context.Context, filters map[string]interface{}) (transfers []OwnershipTransfer, err error) {
+	return
+}
+
+func (r *repository) InsertGroup(ctx context.Context, newGroup Group) (group Group, err error) {
+	return
+}
+
+func (r *repository) UpdateGroup(ctx context.Context, changed Group) (group Group, err error) {
+	return
+}
+
+func (r *repository) DeleteGroup(ctx context.Context, group Group) (err error) {
+	return
+}
+
+func (r *repository) SelectGroups(ctx context.Context, filters map[string]interface{}) (groups []Group, err error) {
+	return
+}
+
+func (r *repository) InsertGroupMember(ctx context.Context, newMember GroupMember) (member GroupMember, err error) {
+	return
+}
+
+func (r *repository) DeleteGroupMember(ctx context.Context, member GroupMember) (err error) {
+	return
+}
+
+func (r *repository) SelectGroupMembers(ctx context.Context, filters map[string]interface{}) (members []GroupMember, err error) {
+	return
+}
+
+func (r *repository) InsertAPIKey(ctx context.Context, newKey APIKey) (key APIKey, err error) {
+	return
+}
+
+func (r *repository) UpdateAPIKey(ctx context.Context, changed APIKey) (key APIKey, err error) {
+	return
+}
+
+func (r *repository) SelectAPIKeys(ctx context.Context, filters map[string]interface{}) (keys []APIKey, err error) {
+	return
+}
+
+func (r *repository) InsertCustomField(ctx context.Context, newField CustomField) (field CustomField, err error) {
+	return
+}
+
+func (r *repository) UpdateCustomField(ctx context.Context, changed CustomField) (field CustomField, err error) {
+	return
+}
+
+func (r *repository) DeleteCustomField(ctx context.Context, field CustomField) (err error) {
+	return
+}
+
+func (r *repository) SelectCustomFields(ctx context.Context, filters map[string]interface{}) (fields []CustomField, err error) {
+	return
+}
+
+func (r *repository) InsertPlan(ctx context.Context, newPlan Plan) (plan Plan, err error) {
+	return
+}
+
+func (r *repository) UpdatePlan(ctx context.Context, changed Plan) (plan Plan, err error) {
+	return
+}
+
+func (r *repository) SelectPlans(ctx context.Context, filters map[string]interface{}) (plans []Plan, err error) {
+	return
+}
+
+func (r *repository) InsertAccountPlan(ctx context.Context, newAssignment AccountPlan) (assignment AccountPlan, err error) {
+	return
+}
+
+func (r *repository) SelectAccountPlans(ctx context.Context, filters map[string]interface{}) (assignments []AccountPlan, err error) {
+	return
+}
+
+func (r *repository) UpsertEntitlementOverride(ctx context.Context, newOverride EntitlementOverride) (override EntitlementOverride, err error) {
+	return
+}
+
+func (r *repository) DeleteEntitlementOverride(ctx context.Context, override EntitlementOverride) (err error) {
+	return
+}
+
+func (r *repository) SelectEntitlementOverrides(ctx context.Context, filters map[string]interface{}) (overrides []EntitlementOverride, err error) {
+	return
+}
+
+// LockAccountSeats fails rather than running fn without the lock, as the
+// repository has no database to hold it in.
+func (r *repository) LockAccountSeats(ctx context.Context, accountID string, fn func(context.Context) error) (err error) {
+	return errors.Errorf("cannot lock the seats of account %s: the repository has no database", accountID)
+}
+
+func (r *repository) CountSeats(ctx context.Context, accountID string) (seats int64, err error) {
+	return
+}
+
+func (r *repository) InsertScheduledAction(ctx context.Context, newAction ScheduledAction) (action ScheduledAction, err error) {
+	return
+}
+
+func (r *repository) UpdateScheduledAction(ctx context.Context, action ScheduledAction) (updated ScheduledAction, err error) {
+	return
+}
+
+func (r *repository) SelectScheduledActions(ctx context.Context, filters map[string]interface{}) (actions []ScheduledAction, err error) {
+	return
+}
+
+// ClaimScheduledActions fails rather than claiming nothing, as the repository
+// has no database to hold the claims in.
+func (r *repository) ClaimScheduledActions(ctx context.Context, now, lockUntil time.Time, limit int) (actions []ScheduledAction, err error) {
+	return nil, errors.New("cannot claim scheduled actions: the repository has no database")
+}
+
+func (r *repository) InsertFeatureFlag(ctx context.Context, newFlag FeatureFlag) (flag FeatureFlag, err error) {
+	return
+}
+
+func (r *repository) UpdateFeatureFlag(ctx context.Context, flag FeatureFlag) (updated FeatureFlag, err error) {
+	return
+}
+
+func (r *repository) DeleteFeatureFlag(ctx context.Context, flag FeatureFlag) (err error) {
+	return
+}
+
+func (r *repository) SelectFeatureFlags(ctx context.Context, filters map[string]interface{}) (flags []FeatureFlag, err error) {
+	return
+}
+
+func (r *repository) UpsertFlagOverride(ctx context.Context, newOverride FlagOverride) (override FlagOverride, err error) {
+	return
+}
+
+func (r *repository) DeleteFlagOverride(ctx context.Context, override FlagOverride) (err error) {
+	return
+}
+
+func (r *repository) SelectFlagOverrides(ctx context.Context, filters map[string]interface{}) (overrides []FlagOverride, err error) {
 	return
 }
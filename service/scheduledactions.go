@@ -0,0 +1,324 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+)
+
+const (
+	// scheduledActionLease is how long a runner may take to run an action it
+	// claimed before the action is handed to another runner.
+	scheduledActionLease = 5 * time.Minute
+	// scheduledActionBatch is the number of due actions claimed at a time.
+	scheduledActionBatch = 100
+	// maxScheduledActionAttempts is how many times an action failing with a
+	// transient error is run before it is given up on.
+	maxScheduledActionAttempts = 10
+	// maxScheduledActionBackoff caps the delay between attempts.
+	maxScheduledActionBackoff = time.Hour
+)
+
+type ScheduledActionStatus string
+
+const (
+	ScheduledActionPending   ScheduledActionStatus = "pending"
+	ScheduledActionCompleted ScheduledActionStatus = "completed"
+	ScheduledActionFailed    ScheduledActionStatus = "failed"
+	ScheduledActionCancelled ScheduledActionStatus = "cancelled"
+)
+
+// ScheduledAction changes the status of an account, or of a user's membership
+// of it when UserID is set, at a future time.
+type ScheduledAction struct {
+	ID        string `db:"id"`
+	AccountID string `db:"account_id"`
+	UserID    string `db:"user_id"`
+	// ToStatus is an AccountStatus, or a UserStatus when UserID is set.
+	ToStatus string                `db:"to_status"`
+	Status   ScheduledActionStatus `db:"status"`
+	RunAt    time.Time             `db:"run_at"`
+	// Attempts counts how many times the action was claimed to be run, and
+	// LastError holds why the latest attempt failed.
+	Attempts  int    `db:"attempts"`
+	LastError string `db:"last_error"`
+	// LockedUntil is set while a runner holds the action.
+	LockedUntil *time.Time `db:"locked_until"`
+	ScheduledBy string     `db:"scheduled_by"`
+	CancelledBy string     `db:"cancelled_by"`
+	CompletedAt *time.Time `db:"completed_at"`
+	CancelledAt *time.Time `db:"cancelled_at"`
+	UpdatedAt   time.Time  `db:"updated_at"`
+	CreatedAt   time.Time  `db:"created_at"`
+}
+
+// Running reports whether a runner holds the action.
+func (a ScheduledAction) Running(now time.Time) bool {
+	return a.LockedUntil != nil && a.LockedUntil.After(now)
+}
+
+type ScheduleStatusChangeRequest struct {
+	AccountID string
+	// UserID schedules a change to the user's membership of the account
+	// rather than to the account itself.
+	UserID      string
+	Status      string
+	RunAt       time.Time
+	ScheduledBy string
+}
+
+type ListScheduledActionsRequest struct {
+	AccountID string
+	UserID    string
+	Status    ScheduledActionStatus
+}
+
+type CancelScheduledActionRequest struct {
+	AccountID   string
+	ID          string
+	CancelledBy string
+	// AccountChanges allows cancelling changes to the account's own status
+	// rather than only to its memberships.
+	AccountChanges bool
+}
+
+// ScheduleStatusChange schedules an account or membership to change status at
+// a future time. Nothing prevents several changes being scheduled for the same
+// account or membership; they run in the order they are due.
+func (svc *customersService) ScheduleStatusChange(ctx context.Context, req ScheduleStatusChangeRequest) (action ScheduledAction, err error) {
+	if !req.RunAt.After(time.Now()) {
+		return action, errors.Wrap(ErrInvalidArgument, "status changes must be scheduled for the future")
+	}
+
+	if req.UserID == "" {
+		switch AccountStatus(req.Status) {
+		case AccountActive, AccountSuspended, AccountInactive:
+		default:
+			return action, errors.Wrapf(ErrInvalidArgument, "unknown account status %q", req.Status)
+		}
+
+		var account Account
+		if account, err = svc.repo.GetAccountByID(ctx, req.AccountID); err != nil {
+			return
+		}
+
+		if account.Status == AccountMerged {
+			return action, errors.Wrapf(ErrFailedPrecondition, "account %s has been merged", account.ID)
+		}
+	} else {
+		switch UserStatus(req.Status) {
+		case UserActive, UserSuspended, UserInactive:
+		default:
+			return action, errors.Wrapf(ErrInvalidArgument, "unknown user status %q", req.Status)
+		}
+
+		if _, err = svc.membership(ctx, req.AccountID, req.UserID); err != nil {
+			return
+		}
+	}
+
+	action, err = svc.repo.InsertScheduledAction(ctx, ScheduledAction{
+		AccountID:   req.AccountID,
+		UserID:      req.UserID,
+		ToStatus:    req.Status,
+		Status:      ScheduledActionPending,
+		RunAt:       req.RunAt,
+		ScheduledBy: req.ScheduledBy,
+	})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to insert scheduled action", "error", err.Error())
+	}
+
+	return
+}
+
+func (svc *customersService) ListScheduledActions(ctx context.Context, req ListScheduledActionsRequest) (actions []ScheduledAction, err error) {
+	filters := map[string]interface{}{"account_id": req.AccountID}
+	if req.UserID != "" {
+		filters["user_id"] = req.UserID
+	}
+	if req.Status != "" {
+		filters["status"] = req.Status
+	}
+
+	actions, err = svc.repo.SelectScheduledActions(ctx, filters)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to retrieve scheduled actions", "error", err.Error())
+	}
+
+	return
+}
+
+// CancelScheduledAction cancels a pending action. Actions a runner is running
+// cannot be cancelled, as they may already have taken effect.
+func (svc *customersService) CancelScheduledAction(ctx context.Context, req CancelScheduledActionRequest) (action ScheduledAction, err error) {
+	actions, err := svc.repo.SelectScheduledActions(ctx, map[string]interface{}{"account_id": req.AccountID, "id": req.ID})
+	if err != nil {
+		return
+	}
+
+	if len(actions) == 0 {
+		return action, errors.Wrapf(ErrNotFound, "scheduled action %s", req.ID)
+	}
+
+	action = actions[0]
+	if action.UserID == "" && !req.AccountChanges {
+		return action, errors.Wrapf(ErrPermissionDenied, "scheduled action %s changes the account's status", action.ID)
+	}
+
+	now := time.Now()
+	if action.Status != ScheduledActionPending {
+		return action, errors.Wrapf(ErrFailedPrecondition, "scheduled action %s is %s", action.ID, action.Status)
+	}
+
+	if action.Running(now) {
+		return action, errors.Wrapf(ErrFailedPrecondition, "scheduled action %s is running", action.ID)
+	}
+
+	action.Status = ScheduledActionCancelled
+	action.CancelledBy = req.CancelledBy
+	action.CancelledAt = &now
+	action, err = svc.repo.UpdateScheduledAction(ctx, action)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to cancel scheduled action", "error", err.Error())
+	}
+
+	return
+}
+
+// ScheduledActionRunner periodically runs scheduled actions which are due.
+// Actions are claimed for a lease before being run and only marked completed
+// afterwards, so an action whose runner dies is run again once its lease
+// expires: every action runs at least once. Status changes which are already
+// in effect succeed, so running an action twice is harmless.
+type ScheduledActionRunner struct {
+	svc      Service
+	repo     Repository
+	logger   log.Logger
+	interval time.Duration
+
+	once sync.Once
+	stop chan struct{}
+}
+
+// NewScheduledActionRunner creates a runner which checks for due actions every
+// interval once started. Actions are run through svc, which should not be
+// wrapped in authorization.
+func NewScheduledActionRunner(svc Service, repo Repository, logger log.Logger, interval time.Duration) *ScheduledActionRunner {
+	return &ScheduledActionRunner{
+		svc:      svc,
+		repo:     repo,
+		logger:   log.With(logger, "component", "scheduled-action-runner"),
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start runs due actions in the background until Stop is called.
+func (r *ScheduledActionRunner) Start() {
+	go func() {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := r.Run(context.Background(), time.Now()); err != nil {
+					r.logger.Log("level", "error", "message", "failed to run scheduled actions", "error", err.Error())
+				}
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the background runs.
+func (r *ScheduledActionRunner) Stop() {
+	r.once.Do(func() {
+		close(r.stop)
+	})
+}
+
+// Run claims and runs the actions due by now, returning how many were run.
+// Actions failing with a transient error are retried with a growing delay,
+// while those which can never succeed, such as a change to a user who has
+// since left the account, are marked failed.
+func (r *ScheduledActionRunner) Run(ctx context.Context, now time.Time) (int, error) {
+	run := 0
+	for {
+		actions, err := r.repo.ClaimScheduledActions(ctx, now, now.Add(scheduledActionLease), scheduledActionBatch)
+		if err != nil {
+			return run, err
+		}
+
+		for _, action := range actions {
+			if err := r.runAction(ctx, action, now); err != nil {
+				return run, err
+			}
+
+			run++
+		}
+
+		if len(actions) < scheduledActionBatch {
+			return run, nil
+		}
+	}
+}
+
+// runAction runs a claimed action and records the outcome, returning an error
+// only if the outcome could not be recorded.
+func (r *ScheduledActionRunner) runAction(ctx context.Context, action ScheduledAction, now time.Time) error {
+	var err error
+	if action.UserID == "" {
+		_, err = r.svc.ChangeAccountStatus(ctx, ChangeAccountStatusRequest{ID: action.AccountID, Status: AccountStatus(action.ToStatus)})
+	} else {
+		_, err = r.svc.ChangeMembershipStatus(ctx, ChangeMembershipStatusRequest{AccountID: action.AccountID, UserID: action.UserID, Status: UserStatus(action.ToStatus)})
+	}
+
+	action.LockedUntil = nil
+	switch {
+	case err == nil:
+		action.Status = ScheduledActionCompleted
+		action.CompletedAt = &now
+		action.LastError = ""
+	case permanent(err) || action.Attempts >= maxScheduledActionAttempts:
+		r.logger.Log("level", "error", "message", "scheduled action failed", "action_id", action.ID, "attempts", action.Attempts, "error", err.Error())
+		action.Status = ScheduledActionFailed
+		action.LastError = err.Error()
+	default:
+		action.RunAt = now.Add(scheduledActionBackoff(action.Attempts))
+		action.LastError = err.Error()
+	}
+
+	_, err = r.repo.UpdateScheduledAction(ctx, action)
+	return err
+}
+
+// permanent reports whether an error will recur however often the action is retried.
+func permanent(err error) bool {
+	switch errors.Cause(err) {
+	case ErrInvalidArgument, ErrNotFound, ErrFailedPrecondition:
+		return true
+	}
+
+	return false
+}
+
+// scheduledActionBackoff doubles the delay before each retry, starting from a
+// minute.
+func scheduledActionBackoff(attempts int) time.Duration {
+	backoff := time.Minute
+	for i := 1; i < attempts && backoff < maxScheduledActionBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > maxScheduledActionBackoff {
+		return maxScheduledActionBackoff
+	}
+
+	return backoff
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+)
+
+func TestScheduledActions(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	repo.accounts["acct"] = Account{ID: "acct", Status: AccountActive}
+	repo.users["alice"] = User{ID: "alice", Kind: UserHuman}
+	repo.users["bob"] = User{ID: "bob", Kind: UserHuman}
+	repo.memberships = []Membership{
+		{AccountID: "acct", UserID: "alice", Role: RoleMember, Status: UserActive},
+		{AccountID: "acct", UserID: "bob", Role: RoleMember, Status: UserActive},
+	}
+	svc := newTestService(repo)
+	runner := NewScheduledActionRunner(svc, repo, log.NewNopLogger(), time.Minute)
+
+	now := time.Now()
+	due := now.Add(time.Hour)
+	schedule := func(userID, status string) (ScheduledAction, error) {
+		return svc.ScheduleStatusChange(ctx, ScheduleStatusChangeRequest{AccountID: "acct", UserID: userID, Status: status, RunAt: due})
+	}
+
+	if _, err := svc.ScheduleStatusChange(ctx, ScheduleStatusChangeRequest{AccountID: "acct", Status: "suspended", RunAt: now.Add(-time.Minute)}); errors.Cause(err) != ErrInvalidArgument {
+		t.Errorf("change in the past: expected ErrInvalidArgument, got %v", err)
+	}
+	if _, err := schedule("", string(AccountMerged)); errors.Cause(err) != ErrInvalidArgument {
+		t.Errorf("merging: expected ErrInvalidArgument, got %v", err)
+	}
+	if _, err := schedule("carol", string(UserInactive)); errors.Cause(err) != ErrNotFound {
+		t.Errorf("non-member: expected ErrNotFound, got %v", err)
+	}
+
+	suspend, err := schedule("", string(AccountSuspended))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deactivate, err := schedule("alice", string(UserInactive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancelled, err := schedule("bob", string(UserSuspended))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.CancelScheduledAction(ctx, CancelScheduledActionRequest{AccountID: "acct", ID: suspend.ID}); errors.Cause(err) != ErrPermissionDenied {
+		t.Errorf("cancelling an account change: expected ErrPermissionDenied, got %v", err)
+	}
+	if _, err := svc.CancelScheduledAction(ctx, CancelScheduledActionRequest{AccountID: "acct", ID: cancelled.ID, CancelledBy: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CancelScheduledAction(ctx, CancelScheduledActionRequest{AccountID: "acct", ID: cancelled.ID}); errors.Cause(err) != ErrFailedPrecondition {
+		t.Errorf("cancelling twice: expected ErrFailedPrecondition, got %v", err)
+	}
+
+	if n, err := runner.Run(ctx, now); err != nil || n != 0 {
+		t.Fatalf("expected nothing to be due, got %d, %v", n, err)
+	}
+	if n, err := runner.Run(ctx, due); err != nil || n != 2 {
+		t.Fatalf("expected 2 actions to run, got %d, %v", n, err)
+	}
+	if a := repo.accounts["acct"]; a.Status != AccountSuspended {
+		t.Errorf("expected the account to be suspended, got %s", a.Status)
+	}
+	if m, _ := svc.membership(ctx, "acct", "alice"); m.Status != UserInactive {
+		t.Errorf("expected alice to be deactivated, got %s", m.Status)
+	}
+	if m, _ := svc.membership(ctx, "acct", "bob"); m.Status != UserActive {
+		t.Errorf("cancelled change to bob ran, got %s", m.Status)
+	}
+
+	actions, err := svc.ListScheduledActions(ctx, ListScheduledActionsRequest{AccountID: "acct", Status: ScheduledActionCompleted})
+	if err != nil || len(actions) != 2 || actions[0].ID != suspend.ID || actions[1].ID != deactivate.ID {
+		t.Errorf("expected both actions to be completed, got %+v, %v", actions, err)
+	}
+
+	// an action claimed by a runner which died is run again once its lease expires
+	reactivate, err := schedule("", string(AccountActive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.ClaimScheduledActions(ctx, due, due.Add(scheduledActionLease), 10); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CancelScheduledAction(ctx, CancelScheduledActionRequest{AccountID: "acct", ID: reactivate.ID, AccountChanges: true}); errors.Cause(err) != ErrFailedPrecondition {
+		t.Errorf("cancelling a running action: expected ErrFailedPrecondition, got %v", err)
+	}
+	if n, _ := runner.Run(ctx, due); n != 0 {
+		t.Errorf("expected the claimed action to be skipped, ran %d", n)
+	}
+	if n, _ := runner.Run(ctx, due.Add(scheduledActionLease)); n != 1 || repo.accounts["acct"].Status != AccountActive {
+		t.Errorf("expected the action to run after its lease expired, ran %d", n)
+	}
+	if a := repo.actions[len(repo.actions)-1]; a.Status != ScheduledActionCompleted || a.Attempts != 2 {
+		t.Errorf("unexpected action %+v", a)
+	}
+
+	// changes which can never succeed are not retried
+	if _, err := schedule("bob", string(UserInactive)); err != nil {
+		t.Fatal(err)
+	}
+	repo.memberships = repo.memberships[:1]
+	if n, err := runner.Run(ctx, due); err != nil || n != 1 {
+		t.Fatalf("expected 1 action to run, got %d, %v", n, err)
+	}
+	if a := repo.actions[len(repo.actions)-1]; a.Status != ScheduledActionFailed || a.LastError == "" {
+		t.Errorf("expected the action to fail, got %+v", a)
+	}
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultSearchLimit caps the results returned when no limit is requested.
+	defaultSearchLimit = 25
+	// searchThreshold is the minimum score for a record to match, mirroring
+	// the default pg_trgm similarity threshold.
+	searchThreshold = 0.3
+)
+
+type SearchKind string
+
+const (
+	SearchAccounts SearchKind = "account"
+	SearchUsers    SearchKind = "user"
+)
+
+// SearchQuery describes a search for customers.
+type SearchQuery struct {
+	Text  string
+	Kinds []SearchKind
+	// AccountIDs restricts the search to the given accounts and their users when set.
+	AccountIDs []string
+	Limit      int
+}
+
+// includes reports whether the query searches records of the given kind.
+func (q SearchQuery) includes(kind SearchKind) bool {
+	if len(q.Kinds) == 0 {
+		return true
+	}
+
+	for _, k := range q.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Match is the byte range of a field value which matched the query.
+type Match struct {
+	Start int
+	End   int
+}
+
+// Highlight marks where the query matched a field of a result.
+type Highlight struct {
+	Field   string
+	Value   string
+	Matches []Match
+}
+
+// SearchResult is a single account or user matching a search, ranked by Score.
+type SearchResult struct {
+	Kind       SearchKind
+	Account    *Account
+	User       *User
+	Score      float64
+	Highlights []Highlight
+}
+
+// Searcher ranks the customers matching a query. Merged accounts and service
+// accounts are never matched. The Postgres repository implements it using
+// trigram and full-text indexes.
+type Searcher interface {
+	SearchCustomers(context.Context, SearchQuery) ([]SearchResult, error)
+}
+
+type SearchCustomersRequest struct {
+	Query string
+	Kinds []SearchKind
+	// AccountIDs restricts the search to the given accounts and their users when set.
+	AccountIDs []string
+	Limit      int
+}
+
+// SearchCustomers finds accounts and users by partial name or email, ranking
+// both kinds of record together and highlighting the fields that matched.
+func (svc *customersService) SearchCustomers(ctx context.Context, req SearchCustomersRequest) (results []SearchResult, err error) {
+	text := strings.TrimSpace(req.Query)
+	if text == "" {
+		return nil, errors.Wrap(ErrInvalidArgument, "query is required")
+	}
+
+	for _, kind := range req.Kinds {
+		if kind != SearchAccounts && kind != SearchUsers {
+			return nil, errors.Wrapf(ErrInvalidArgument, "unknown search kind %q", kind)
+		}
+	}
+
+	limit := req.Limit
+	if limit <= 0 || limit > defaultSearchLimit {
+		limit = defaultSearchLimit
+	}
+
+	results, err = svc.searcher.SearchCustomers(ctx, SearchQuery{
+		Text:       text,
+		Kinds:      req.Kinds,
+		AccountIDs: req.AccountIDs,
+		Limit:      limit,
+	})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to search customers", "error", err.Error())
+		return
+	}
+
+	for i := range results {
+		results[i].Highlights = highlight(text, results[i])
+	}
+
+	return
+}
+
+// NewMemorySearcher returns a Searcher which loads candidates from the
+// repository and ranks them in memory. It is intended for small datasets and
+// databases without the pg_trgm extension.
+func NewMemorySearcher(repo Repository) Searcher {
+	return &memorySearcher{repo: repo}
+}
+
+type memorySearcher struct {
+	repo Repository
+}
+
+func (s *memorySearcher) SearchCustomers(ctx context.Context, q SearchQuery) ([]SearchResult, error) {
+	results := []SearchResult{}
+
+	if q.includes(SearchAccounts) {
+		filters := map[string]interface{}{}
+		if len(q.AccountIDs) > 0 {
+			filters["id"] = q.AccountIDs
+		}
+
+		accounts, err := s.repo.SelectAccounts(ctx, filters)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range accounts {
+			// merged accounts only redirect to the account they were merged into
+			if accounts[i].Status == AccountMerged {
+				continue
+			}
+
+			score := bestScore(q.Text, accounts[i].Name, accounts[i].ContactEmail)
+			if score >= searchThreshold {
+				results = append(results, SearchResult{Kind: SearchAccounts, Account: &accounts[i], Score: score})
+			}
+		}
+	}
+
+	if q.includes(SearchUsers) {
+		filters := map[string]interface{}{"kind": UserHuman}
+		if len(q.AccountIDs) > 0 {
+			filters["account_id"] = q.AccountIDs
+		}
+
+		users, err := s.repo.SelectUsers(ctx, filters)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range users {
+			score := bestScore(q.Text, users[i].Name, users[i].Email)
+			if score >= searchThreshold {
+				results = append(results, SearchResult{Kind: SearchUsers, User: &users[i], Score: score})
+			}
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if q.Limit > 0 && len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+
+	return results, nil
+}
+
+// bestScore returns the highest score of the query against any of the values.
+func bestScore(query string, values ...string) float64 {
+	best := 0.0
+	for _, v := range values {
+		if score := matchScore(query, v); score > best {
+			best = score
+		}
+	}
+
+	return best
+}
+
+// matchScore scores how well value matches query between 0 and 1. Values
+// containing the query score at least 0.6, ranking higher the more of the
+// value the query covers; otherwise the trigram similarity is used so that
+// misspellings still match.
+func matchScore(query, value string) float64 {
+	query, value = strings.ToLower(query), strings.ToLower(value)
+	if query == "" || value == "" {
+		return 0
+	}
+
+	score := trigramSimilarity(query, value)
+	if strings.Contains(value, query) {
+		contained := 0.6 + 0.4*float64(len(query))/float64(len(value))
+		if contained > score {
+			score = contained
+		}
+	}
+
+	return score
+}
+
+// trigramSimilarity mirrors pg_trgm's similarity: the number of trigrams the
+// strings share divided by the number of distinct trigrams in either.
+func trigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for t := range ta {
+		if tb[t] {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+// trigrams splits s into words of letters and digits, padding each as
+// pg_trgm does with two leading spaces and one trailing space.
+func trigrams(s string) map[string]bool {
+	set := map[string]bool{}
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+
+	return set
+}
+
+// highlight finds every occurrence of each word of the query in the fields
+// of a result, case-insensitively.
+func highlight(query string, result SearchResult) []Highlight {
+	var fields [][2]string
+	switch {
+	case result.Account != nil:
+		fields = [][2]string{{"name", result.Account.Name}, {"contact_email", result.Account.ContactEmail}}
+	case result.User != nil:
+		fields = [][2]string{{"name", result.User.Name}, {"email", result.User.Email}}
+	}
+
+	terms := strings.Fields(strings.ToLower(query))
+
+	highlights := []Highlight{}
+	for _, field := range fields {
+		matches := findMatches(field[1], terms)
+		if len(matches) > 0 {
+			highlights = append(highlights, Highlight{Field: field[0], Value: field[1], Matches: matches})
+		}
+	}
+
+	return highlights
+}
+
+// findMatches returns the merged, ordered ranges of value matching any term.
+func findMatches(value string, terms []string) []Match {
+	lower := strings.ToLower(value)
+	// lowercasing can change byte lengths, in which case offsets would not
+	// line up with the original value
+	if len(lower) != len(value) {
+		return nil
+	}
+
+	var matches []Match
+	for _, term := range terms {
+		for offset := 0; offset < len(lower); {
+			i := strings.Index(lower[offset:], term)
+			if i < 0 {
+				break
+			}
+
+			start := offset + i
+			matches = append(matches, Match{Start: start, End: start + len(term)})
+			offset = start + len(term)
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Start < matches[j].Start
+	})
+
+	merged := []Match{}
+	for _, m := range matches {
+		if n := len(merged); n > 0 && m.Start <= merged[n-1].End {
+			if m.End > merged[n-1].End {
+				merged[n-1].End = m.End
+			}
+			continue
+		}
+		merged = append(merged, m)
+	}
+
+	return merged
+}
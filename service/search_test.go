@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestSearchCustomers(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	repo.accounts["acme"] = Account{ID: "acme", Name: "Acme Corporation", ContactEmail: "billing@acme.com"}
+	repo.accounts["globex"] = Account{ID: "globex", Name: "Globex", ContactEmail: "ops@globex.com"}
+	repo.accounts["acme-old"] = Account{ID: "acme-old", Name: "Acme Corporation", Status: AccountMerged}
+	repo.users["wile"] = User{ID: "wile", Kind: UserHuman, Name: "Wile E. Coyote", Email: "wile@acme.com"}
+	repo.users["hank"] = User{ID: "hank", Kind: UserHuman, Name: "Hank Scorpio", Email: "hank@globex.com"}
+	repo.users["bot"] = User{ID: "bot", Kind: UserService, Name: "Acme Corp CI"}
+	svc := newTestService(repo, WithSearcher(NewMemorySearcher(repo)))
+
+	results, err := svc.SearchCustomers(ctx, SearchCustomersRequest{Query: "ACME corp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 2 || results[0].Account == nil || results[0].Account.ID != "acme" || results[1].User == nil || results[1].User.ID != "wile" {
+		t.Fatalf("unexpected results %+v", results)
+	}
+
+	highlights := results[0].Highlights
+	if len(highlights) != 2 || highlights[0].Field != "name" || len(highlights[0].Matches) != 2 || highlights[0].Matches[1] != (Match{Start: 5, End: 9}) {
+		t.Errorf("unexpected highlights %+v", highlights)
+	}
+
+	results, err = svc.SearchCustomers(ctx, SearchCustomersRequest{Query: "scorpoi", Kinds: []SearchKind{SearchUsers}})
+	if err != nil || len(results) != 1 || results[0].User.ID != "hank" {
+		t.Errorf("misspelled search: got %+v, %v", results, err)
+	}
+
+	if _, err := svc.SearchCustomers(ctx, SearchCustomersRequest{Query: "  "}); errors.Cause(err) != ErrInvalidArgument {
+		t.Errorf("empty query: expected ErrInvalidArgument, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"fmt"
+)
+
+// seatsEntitlement is the entitlement whose limit caps the number of active
+// human members of an account. Accounts without such a limit have unlimited
+// seats.
+const seatsEntitlement = "seats"
+
+// SeatLimitError is returned when an account has no seat free for another
+// active member. Its cause is ErrResourceExhausted.
+type SeatLimitError struct {
+	AccountID string
+	Used      int64
+	Limit     int64
+}
+
+func (e *SeatLimitError) Error() string {
+	return fmt.Sprintf("account %s has used %d of %d seats: %s", e.AccountID, e.Used, e.Limit, ErrResourceExhausted)
+}
+
+// Cause lets errors.Cause unwrap the error to ErrResourceExhausted.
+func (e *SeatLimitError) Cause() error {
+	return ErrResourceExhausted
+}
+
+// AccountUsage reports how much of its quotas an account uses.
+type AccountUsage struct {
+	AccountID string
+	// SeatsUsed counts active human members. Service accounts do not take up seats.
+	SeatsUsed int64
+	// SeatLimit is nil when the account's seats are unlimited.
+	SeatLimit *int64
+}
+
+type GetAccountUsageRequest struct {
+	AccountID string
+}
+
+func (svc *customersService) GetAccountUsage(ctx context.Context, req GetAccountUsageRequest) (usage AccountUsage, err error) {
+	limit, err := svc.seatLimit(ctx, req.AccountID)
+	if err != nil {
+		return
+	}
+
+	used, err := svc.repo.CountSeats(ctx, req.AccountID)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to count seats", "error", err.Error())
+		return
+	}
+
+	return AccountUsage{AccountID: req.AccountID, SeatsUsed: used, SeatLimit: limit}, nil
+}
+
+// withSeat runs fn, which takes up a seat of the account, failing with a
+// SeatLimitError when none is free. Seats are counted and taken while holding
+// the account's seat lock, so concurrent callers cannot exceed the limit.
+func (svc *customersService) withSeat(ctx context.Context, accountID string, fn func(context.Context) error) error {
+	return svc.withSeats(ctx, accountID, 1, fn)
+}
+
+// withSeats is withSeat for fn taking up n seats at once, failing unless all
+// of them are free.
+func (svc *customersService) withSeats(ctx context.Context, accountID string, n int64, fn func(context.Context) error) error {
+	limit, err := svc.seatLimit(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	if limit == nil || n == 0 {
+		return fn(ctx)
+	}
+
+	return svc.repo.LockAccountSeats(ctx, accountID, func(ctx context.Context) error {
+		used, err := svc.repo.CountSeats(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		if used+n > *limit {
+			return &SeatLimitError{AccountID: accountID, Used: used, Limit: *limit}
+		}
+
+		return fn(ctx)
+	})
+}
+
+// withSeatFor is withSeat for a user who only takes up a seat if they are human.
+func (svc *customersService) withSeatFor(ctx context.Context, accountID string, user User, fn func(context.Context) error) error {
+	if !user.Interactive() {
+		return fn(ctx)
+	}
+
+	return svc.withSeat(ctx, accountID, fn)
+}
+
+func (svc *customersService) seatLimit(ctx context.Context, accountID string) (*int64, error) {
+	check, err := svc.CheckEntitlement(ctx, CheckEntitlementRequest{AccountID: accountID, Key: seatsEntitlement})
+	if err != nil {
+		return nil, err
+	}
+
+	// an account explicitly denied seats has none at all
+	if check.Source != EntitlementUndefined && !check.Allowed {
+		none := int64(0)
+		return &none, nil
+	}
+
+	return check.Limit, nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestSeatLimits(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	repo.accounts["acct"] = Account{ID: "acct", Status: AccountActive}
+	repo.accounts["other"] = Account{ID: "other", Status: AccountActive}
+	svc := newTestService(repo, WithEntitlementCacheTTL(time.Minute))
+
+	seats := int64(3)
+	plan, err := svc.CreatePlan(ctx, CreatePlanRequest{Key: "team", Name: "Team", Entitlements: []Entitlement{{Key: "seats", Enabled: true, Limit: &seats}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.AssignPlan(ctx, AssignPlanRequest{AccountID: "acct", PlanID: plan.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.CreateServiceAccount(ctx, CreateServiceAccountRequest{AccountID: "acct", Name: "CI"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// concurrent callers cannot exceed the limit
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := svc.CreateUser(ctx, CreateUserRequest{AccountID: "acct", Email: fmt.Sprintf("user%d@example.com", i)})
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	var exhausted int
+	for err := range errs {
+		if errors.Cause(err) == ErrResourceExhausted {
+			exhausted++
+			if limitErr, ok := err.(*SeatLimitError); !ok || limitErr.Used != 3 || limitErr.Limit != 3 {
+				t.Errorf("expected quota details, got %v", err)
+			}
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if exhausted != 2 {
+		t.Errorf("expected 2 users to be refused, got %d", exhausted)
+	}
+
+	usage, err := svc.GetAccountUsage(ctx, GetAccountUsageRequest{AccountID: "acct"})
+	if err != nil || usage.SeatsUsed != 3 || usage.SeatLimit == nil || *usage.SeatLimit != 3 {
+		t.Errorf("unexpected usage %+v, %v", usage, err)
+	}
+
+	member := repo.memberships[len(repo.memberships)-1]
+	if _, err := svc.ChangeMembershipStatus(ctx, ChangeMembershipStatusRequest{AccountID: "acct", UserID: member.UserID, Status: UserSuspended}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CreateUser(ctx, CreateUserRequest{AccountID: "acct", Email: "late@example.com"}); err != nil {
+		t.Fatalf("a suspended member should free their seat: %v", err)
+	}
+	if _, err := svc.ChangeMembershipStatus(ctx, ChangeMembershipStatusRequest{AccountID: "acct", UserID: member.UserID, Status: UserActive}); errors.Cause(err) != ErrResourceExhausted {
+		t.Errorf("reactivation: expected ErrResourceExhausted, got %v", err)
+	}
+
+	notifier := svc.notifier.(*recordingNotifier)
+	sent := len(notifier.messages)
+	invitation, err := svc.InviteUser(ctx, InviteUserRequest{AccountID: "acct", Email: "invitee@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.AcceptInvitation(ctx, AcceptInvitationRequest{Token: invitation.Token}); errors.Cause(err) != ErrResourceExhausted {
+		t.Errorf("invitation: expected ErrResourceExhausted, got %v", err)
+	}
+	if _, err := repo.GetUserByEmail(ctx, "invitee@example.com"); errors.Cause(err) != ErrNotFound {
+		t.Errorf("a refused invitation should not create a user: %v", err)
+	}
+	if len(notifier.messages) != sent {
+		t.Errorf("a refused invitation should not send a verification, got %d messages", len(notifier.messages)-sent)
+	}
+
+	repo.users["carol"] = User{ID: "carol", Kind: UserHuman}
+	repo.memberships = append(repo.memberships, Membership{AccountID: "other", UserID: "carol", Role: RoleMember, Status: UserActive})
+	if _, err := svc.TransferUser(ctx, TransferUserRequest{UserID: "carol", FromAccountID: "other", ToAccountID: "acct"}); errors.Cause(err) != ErrResourceExhausted {
+		t.Errorf("transfer: expected ErrResourceExhausted, got %v", err)
+	}
+
+	if _, err := svc.MergeAccounts(ctx, MergeAccountsRequest{SourceID: "other", TargetID: "acct"}); errors.Cause(err) != ErrResourceExhausted {
+		t.Errorf("merge: expected ErrResourceExhausted, got %v", err)
+	}
+	if _, err := svc.membership(ctx, "other", "carol"); err != nil || repo.accounts["other"].Status != AccountActive {
+		t.Errorf("a refused merge should leave the source account unchanged: %v", err)
+	}
+
+	// accounts denied seats outright have none, rather than unlimited seats
+	repo.accounts["denied"] = Account{ID: "denied", Status: AccountActive}
+	if _, err := svc.SetEntitlementOverride(ctx, SetEntitlementOverrideRequest{AccountID: "denied", Key: "seats", Enabled: false, Reason: "unpaid"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.CreateUser(ctx, CreateUserRequest{AccountID: "denied", Email: "dave@example.com"}); errors.Cause(err) != ErrResourceExhausted {
+		t.Errorf("denied seats: expected ErrResourceExhausted, got %v", err)
+	}
+}
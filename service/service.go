@@ -2,10 +2,16 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"time"
 
 	"github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
 
+	"github.com/symptomatichq/customers/emailutil"
+	"github.com/symptomatichq/customers/events"
+	"github.com/symptomatichq/customers/notify"
+	"github.com/symptomatichq/customers/telemetry"
 	"github.com/symptomatichq/kit/logutil"
 )
 
@@ -15,6 +21,9 @@ const (
 	AccountActive    AccountStatus = "active"
 	AccountSuspended AccountStatus = "suspended"
 	AccountInactive  AccountStatus = "inactive"
+	// AccountMerged accounts have been merged into the account named by
+	// MergedInto and only remain to redirect to it.
+	AccountMerged AccountStatus = "merged"
 )
 
 type Account struct {
@@ -22,8 +31,23 @@ type Account struct {
 	Name         string        `db:"name"`
 	ContactEmail string        `db:"contact_email"`
 	Status       AccountStatus `db:"status"`
-	UpdatedAt    time.Time     `db:"updated_at"`
-	CreatedAt    time.Time     `db:"created_at"`
+	// ParentID is set for accounts rolled up under a parent account.
+	ParentID   *string `db:"parent_id"`
+	MergedInto *string `db:"merged_into"`
+	// Labels and Metadata are owned by the clients of this service, which may
+	// select accounts by their labels.
+	Labels   map[string]string `db:"labels"`
+	Metadata json.RawMessage   `db:"metadata"`
+	// DefaultPreferences apply to the account's users and the accounts below
+	// it wherever those do not set their own.
+	DefaultPreferences Preferences `db:"default_preferences"`
+	// TrialEndsAt is set while the account is a trial, which is suspended
+	// once it ends unless it is extended or converted.
+	TrialStartedAt   *time.Time `db:"trial_started_at"`
+	TrialEndsAt      *time.Time `db:"trial_ends_at"`
+	TrialConvertedAt *time.Time `db:"trial_converted_at"`
+	UpdatedAt        time.Time  `db:"updated_at"`
+	CreatedAt        time.Time  `db:"created_at"`
 }
 
 type UserStatus string
@@ -32,44 +56,140 @@ const (
 	UserActive    UserStatus = "active"
 	UserSuspended UserStatus = "suspended"
 	UserInactive  UserStatus = "inactive"
+	// UserPending users are waiting to verify their email address before
+	// they may become active.
+	UserPending UserStatus = "pending"
 )
 
+// UserKind distinguishes people from the automation identities of an account.
+type UserKind string
+
+const (
+	UserHuman UserKind = "human"
+	// UserService accounts belong to a single account, need no email address,
+	// cannot log in interactively and do not take up seats.
+	UserService UserKind = "service"
+)
+
+// User is a single identity which may belong to several accounts through its
+// memberships.
 type User struct {
-	ID        string     `db:"id"`
-	AccountID string     `db:"account_id"`
-	Status    UserStatus `db:"status"`
-	Email     string     `db:"email"`
-	Name      string     `db:"name"`
-	UpdatedAt time.Time  `db:"updated_at"`
-	CreatedAt time.Time  `db:"created_at"`
-	LastLogin *time.Time `db:"last_login"`
+	ID     string     `db:"id"`
+	Kind   UserKind   `db:"kind"`
+	Status UserStatus `db:"status"`
+	Email  string     `db:"email"`
+	// EmailCanonical is Email with provider-specific rules applied, such as
+	// dropping "+tag" suffixes, and is only used for lookups.
+	EmailCanonical string            `db:"email_canonical"`
+	Name           string            `db:"name"`
+	Labels         map[string]string `db:"labels"`
+	Metadata       json.RawMessage   `db:"metadata"`
+	Preferences    Preferences       `db:"preferences"`
+	VerifiedAt     *time.Time        `db:"verified_at"`
+	UpdatedAt      time.Time         `db:"updated_at"`
+	CreatedAt      time.Time         `db:"created_at"`
+	LastLogin      *time.Time        `db:"last_login"`
+}
+
+// Interactive reports whether the user may log in interactively. Service
+// accounts authenticate with credentials issued to them instead.
+func (u User) Interactive() bool {
+	return u.Kind != UserService
 }
 
 type CreateAccountRequest struct {
 	Name         string `db:"name"`
 	ContactEmail string `db:"contact_email"`
+	ParentID     string `db:"parent_id"`
+	Labels       map[string]string
+	Metadata     json.RawMessage
+	// TrialEndsAt creates the account as a trial ending at the given time.
+	TrialEndsAt *time.Time
 }
 
 type GetAccountRequest struct {
 	ID string
 }
 
+type FindAccountsByContactEmailRequest struct {
+	Email string
+}
+
 type FetchAccountsRequest struct {
 	ID string
+	// AccountIDs restricts the results to the given accounts when set.
+	AccountIDs []string
+	// SubtreeOf restricts the results to the given account and its descendants when set.
+	SubtreeOf string
+	// LabelSelector restricts the results to accounts whose labels match, such
+	// as "tier=gold,region!=eu".
+	LabelSelector string
+}
+
+type UpdateAccountRequest struct {
+	ID string
+	// Labels are set, replacing existing values, after RemoveLabels are removed.
+	Labels       map[string]string
+	RemoveLabels []string
+	// Metadata replaces the account's metadata when set.
+	Metadata json.RawMessage
+}
+
+type ChangeAccountStatusRequest struct {
+	ID     string
+	Status AccountStatus
 }
 
 type CreateUserRequest struct {
 	AccountID string `db:"account_id"`
 	Name      string `db:"name"`
 	Email     string `db:"email"`
+	// Role is granted to the new user within the account, defaulting to RoleMember.
+	Role     Role
+	Labels   map[string]string
+	Metadata json.RawMessage
+	// CustomFields are the user's values for the account's custom fields.
+	CustomFields map[string]string
+}
+
+type UpdateUserRequest struct {
+	ID string
+	// Name and Email are left unchanged when nil.
+	Name  *string
+	Email *string
+	// Labels are set, replacing existing values, after RemoveLabels are removed.
+	Labels       map[string]string
+	RemoveLabels []string
+	// Metadata replaces the user's metadata when set.
+	Metadata json.RawMessage
 }
 
 type GetUserRequest struct {
 	ID string
 }
 
+type GetUserByEmailRequest struct {
+	Email string
+	// MatchCanonical falls back to matching the canonical form of the address,
+	// so "a.lice+tag@gmail.com" finds "alice@gmail.com", when no user has the
+	// exact address.
+	MatchCanonical bool
+}
+
 type FetchUsersRequest struct {
 	ID string
+	// AccountIDs restricts the results to users of the given accounts when set.
+	AccountIDs []string
+	// GroupID restricts the results to members of the group or any group
+	// nested within it when set.
+	GroupID string
+	// Kind selects which kind of user to list, defaulting to humans.
+	Kind UserKind
+	// LabelSelector restricts the results to users whose labels match.
+	LabelSelector string
+	// CustomFields restricts the results to users holding the given custom
+	// field values. It requires exactly one account in AccountIDs.
+	CustomFields map[string]string
 }
 
 type Service interface {
@@ -79,25 +199,210 @@ type Service interface {
 	CreateUser(context.Context, CreateUserRequest) (User, error)
 	GetUser(context.Context, GetUserRequest) (User, error)
 	FetchUsers(context.Context, FetchUsersRequest) ([]User, error)
+	GrantRole(context.Context, GrantRoleRequest) (Membership, error)
+	ChangeRole(context.Context, ChangeRoleRequest) (Membership, error)
+	RevokeRole(context.Context, RevokeRoleRequest) error
+	FetchMemberships(context.Context, FetchMembershipsRequest) ([]Membership, error)
+	ChangeMembershipStatus(context.Context, ChangeMembershipStatusRequest) (Membership, error)
+	FetchUserAccounts(context.Context, FetchUserAccountsRequest) ([]UserAccount, error)
+	InviteUser(context.Context, InviteUserRequest) (IssuedInvitation, error)
+	ListInvitations(context.Context, ListInvitationsRequest) ([]Invitation, error)
+	RevokeInvitation(context.Context, RevokeInvitationRequest) (Invitation, error)
+	AcceptInvitation(context.Context, AcceptInvitationRequest) (Membership, error)
+	RequestEmailVerification(context.Context, RequestEmailVerificationRequest) (EmailVerification, error)
+	ConfirmEmailVerification(context.Context, ConfirmEmailVerificationRequest) (User, error)
+	UpdateUser(context.Context, UpdateUserRequest) (User, error)
+	GetUserByEmail(context.Context, GetUserByEmailRequest) (User, error)
+	FindAccountsByContactEmail(context.Context, FindAccountsByContactEmailRequest) ([]Account, error)
+	SearchCustomers(context.Context, SearchCustomersRequest) ([]SearchResult, error)
+	ListDuplicateAccounts(context.Context, ListDuplicateAccountsRequest) ([]DuplicateCandidate, error)
+	MergeAccounts(context.Context, MergeAccountsRequest) (AccountMerge, error)
+	TransferUser(context.Context, TransferUserRequest) (UserTransfer, error)
+	InitiateOwnershipTransfer(context.Context, InitiateOwnershipTransferRequest) (OwnershipTransfer, error)
+	AcceptOwnershipTransfer(context.Context, AcceptOwnershipTransferRequest) (OwnershipTransfer, error)
+	CancelOwnershipTransfer(context.Context, CancelOwnershipTransferRequest) (OwnershipTransfer, error)
+	ListOwnershipTransfers(context.Context, ListOwnershipTransfersRequest) ([]OwnershipTransfer, error)
+	SetAccountParent(context.Context, SetAccountParentRequest) (Account, error)
+	ListChildAccounts(context.Context, ListChildAccountsRequest) ([]Account, error)
+	ListAncestorAccounts(context.Context, ListAncestorAccountsRequest) ([]Account, error)
+	CreateGroup(context.Context, CreateGroupRequest) (Group, error)
+	GetGroup(context.Context, GetGroupRequest) (Group, error)
+	ListGroups(context.Context, ListGroupsRequest) ([]Group, error)
+	UpdateGroup(context.Context, UpdateGroupRequest) (Group, error)
+	DeleteGroup(context.Context, DeleteGroupRequest) error
+	AddGroupMember(context.Context, AddGroupMemberRequest) (GroupMember, error)
+	RemoveGroupMember(context.Context, RemoveGroupMemberRequest) error
+	ListGroupMembers(context.Context, ListGroupMembersRequest) ([]GroupMember, error)
+	CreateAPIKey(context.Context, CreateAPIKeyRequest) (IssuedAPIKey, error)
+	ListAPIKeys(context.Context, ListAPIKeysRequest) ([]APIKey, error)
+	RotateAPIKey(context.Context, RotateAPIKeyRequest) (IssuedAPIKey, error)
+	RevokeAPIKey(context.Context, RevokeAPIKeyRequest) (APIKey, error)
+	VerifyAPIKey(context.Context, VerifyAPIKeyRequest) (APIKeyVerification, error)
+	CreateServiceAccount(context.Context, CreateServiceAccountRequest) (User, error)
+	ListServiceAccounts(context.Context, ListServiceAccountsRequest) ([]User, error)
+	UpdateServiceAccount(context.Context, UpdateServiceAccountRequest) (User, error)
+	DeleteServiceAccount(context.Context, DeleteServiceAccountRequest) error
+	UpdateAccount(context.Context, UpdateAccountRequest) (Account, error)
+	ChangeAccountStatus(context.Context, ChangeAccountStatusRequest) (Account, error)
+	DefineCustomField(context.Context, DefineCustomFieldRequest) (CustomField, error)
+	ListCustomFields(context.Context, ListCustomFieldsRequest) ([]CustomField, error)
+	UpdateCustomField(context.Context, UpdateCustomFieldRequest) (CustomField, error)
+	DeleteCustomField(context.Context, DeleteCustomFieldRequest) error
+	SetCustomFieldValues(context.Context, SetCustomFieldValuesRequest) (Membership, error)
+	CreatePlan(context.Context, CreatePlanRequest) (Plan, error)
+	ListPlans(context.Context, ListPlansRequest) ([]Plan, error)
+	UpdatePlan(context.Context, UpdatePlanRequest) (Plan, error)
+	AssignPlan(context.Context, AssignPlanRequest) (AccountPlan, error)
+	ListAccountPlans(context.Context, ListAccountPlansRequest) ([]AccountPlan, error)
+	SetEntitlementOverride(context.Context, SetEntitlementOverrideRequest) (EntitlementOverride, error)
+	RemoveEntitlementOverride(context.Context, RemoveEntitlementOverrideRequest) error
+	ListAccountEntitlements(context.Context, ListAccountEntitlementsRequest) ([]EntitlementCheck, error)
+	CheckEntitlement(context.Context, CheckEntitlementRequest) (EntitlementCheck, error)
+	GetAccountUsage(context.Context, GetAccountUsageRequest) (AccountUsage, error)
+	ExtendTrial(context.Context, ExtendTrialRequest) (Account, error)
+	ConvertTrial(context.Context, ConvertTrialRequest) (Account, error)
+	ScheduleStatusChange(context.Context, ScheduleStatusChangeRequest) (ScheduledAction, error)
+	ListScheduledActions(context.Context, ListScheduledActionsRequest) ([]ScheduledAction, error)
+	CancelScheduledAction(context.Context, CancelScheduledActionRequest) (ScheduledAction, error)
+	CreateFeatureFlag(context.Context, CreateFeatureFlagRequest) (FeatureFlag, error)
+	ListFeatureFlags(context.Context, ListFeatureFlagsRequest) ([]FeatureFlag, error)
+	UpdateFeatureFlag(context.Context, UpdateFeatureFlagRequest) (FeatureFlag, error)
+	DeleteFeatureFlag(context.Context, DeleteFeatureFlagRequest) error
+	SetFlagOverride(context.Context, SetFlagOverrideRequest) (FlagOverride, error)
+	RemoveFlagOverride(context.Context, RemoveFlagOverrideRequest) error
+	ListFlagOverrides(context.Context, ListFlagOverridesRequest) ([]FlagOverride, error)
+	EvaluateFlags(context.Context, EvaluateFlagsRequest) ([]FlagEvaluation, error)
+	WatchFlags(context.Context, WatchFlagsRequest) error
+	GetUserPreferences(context.Context, GetUserPreferencesRequest) (UserPreferences, error)
+	UpdateUserPreferences(context.Context, UpdateUserPreferencesRequest) (UserPreferences, error)
+	GetAccountPreferences(context.Context, GetAccountPreferencesRequest) (AccountPreferences, error)
+	UpdateAccountPreferences(context.Context, UpdateAccountPreferencesRequest) (AccountPreferences, error)
+}
+
+// Option configures optional behaviour of the service.
+type Option func(*customersService)
+
+// WithNotifier sets the notifier used to deliver messages to users. Messages
+// are logged when no notifier is configured.
+func WithNotifier(notifier notify.Notifier) Option {
+	return func(svc *customersService) {
+		svc.notifier = notifier
+	}
+}
+
+// WithPublisher sets the publisher used to announce changes to customers.
+// Events are logged when no publisher is configured.
+func WithPublisher(publisher events.Publisher) Option {
+	return func(svc *customersService) {
+		svc.publisher = publisher
+	}
+}
+
+// WithVerifiedEmailRequired withholds UserActive status from users until they
+// have verified their email address.
+func WithVerifiedEmailRequired(required bool) Option {
+	return func(svc *customersService) {
+		svc.requireVerifiedEmail = required
+	}
+}
+
+// WithSearcher sets the engine used by SearchCustomers. The repository's
+// indexed search is used when no searcher is configured.
+func WithSearcher(searcher Searcher) Option {
+	return func(svc *customersService) {
+		svc.searcher = searcher
+	}
+}
+
+// WithEntitlementCacheTTL sets how long resolved entitlements are cached. A
+// TTL of zero disables the cache.
+func WithEntitlementCacheTTL(ttl time.Duration) Option {
+	return func(svc *customersService) {
+		svc.entitlements = newEntitlementCache(ttl)
+	}
 }
 
 // NewService ...
-func NewService(repo Repository) Service {
-	return &customersService{
-		logger: logutil.NewServerLogger(false, "customers"),
-		repo:   repo,
+func NewService(repo Repository, opts ...Option) Service {
+	svc := &customersService{
+		logger:   logutil.NewServerLogger(false, "customers"),
+		repo:     repo,
+		searcher: repo,
+
+		entitlements: newEntitlementCache(defaultEntitlementCacheTTL),
 	}
+	svc.notifier = notify.NewLogNotifier(svc.logger)
+	svc.publisher = events.NewLogPublisher(svc.logger)
+
+	for _, opt := range opts {
+		opt(svc)
+	}
+
+	return svc
 }
 
 type customersService struct {
-	logger log.Logger
-	repo   Repository
+	logger    log.Logger
+	repo      Repository
+	notifier  notify.Notifier
+	publisher events.Publisher
+	searcher  Searcher
+
+	entitlements         *entitlementCache
+	flagWatchers         flagWatchers
+	requireVerifiedEmail bool
 }
 
 func (svc *customersService) CreateAccount(ctx context.Context, req CreateAccountRequest) (account Account, err error) {
-	account, err = svc.repo.InsertAccount(ctx, Account{ContactEmail: req.ContactEmail, Name: req.Name})
+	contactEmail := req.ContactEmail
+	if contactEmail != "" {
+		if contactEmail, _, err = normalizeEmail(contactEmail); err != nil {
+			return
+		}
+	}
+
+	if err = validateLabels(req.Labels); err != nil {
+		return
+	}
+
+	if err = validateMetadata(req.Metadata); err != nil {
+		return
+	}
+
+	var trialStartedAt *time.Time
+	if req.TrialEndsAt != nil {
+		now := time.Now()
+		if !req.TrialEndsAt.After(now) {
+			return account, errors.Wrap(ErrInvalidArgument, "trial must end in the future")
+		}
+		trialStartedAt = &now
+	}
+
+	var parentID *string
+	if req.ParentID != "" {
+		if err = svc.ensureParent(ctx, "", req.ParentID); err != nil {
+			return
+		}
+		parentID = &req.ParentID
+	}
+
+	account, err = svc.repo.InsertAccount(ctx, Account{
+		ContactEmail: contactEmail,
+		Name:         req.Name,
+		ParentID:     parentID,
+		Labels:       req.Labels,
+		Metadata:     req.Metadata,
+
+		TrialStartedAt: trialStartedAt,
+		TrialEndsAt:    req.TrialEndsAt,
+	})
 	if err != nil {
 		svc.logger.Log("level", "error", "message", "error", err.Error(), "message", "failed to insert account")
+		return
+	}
+
+	if account.InTrial() {
+		telemetry.TrialStarted()
 	}
 
 	return
@@ -107,23 +412,236 @@ func (svc *customersService) GetAccount(ctx context.Context, req GetAccountReque
 	account, err = svc.repo.GetAccountByID(ctx, req.ID)
 	if err != nil {
 		svc.logger.Log("level", "error", "message", "error", err.Error(), "message", "failed to retrieve account")
+		return
 	}
 
-	return
+	return svc.resolveAccount(ctx, account)
 }
 
 func (svc *customersService) FetchAccounts(ctx context.Context, req FetchAccountsRequest) (accounts []Account, err error) {
-	accounts, err = svc.repo.SelectAccounts(ctx, map[string]interface{}{})
+	filters := map[string]interface{}{}
+	if len(req.AccountIDs) > 0 {
+		filters["id"] = req.AccountIDs
+	}
+	if req.SubtreeOf != "" {
+		filters["subtree_of"] = req.SubtreeOf
+	}
+	if req.LabelSelector != "" {
+		if filters["labels"], err = ParseSelector(req.LabelSelector); err != nil {
+			return
+		}
+	}
+
+	accounts, err = svc.repo.SelectAccounts(ctx, filters)
 	if err != nil {
 		svc.logger.Log("level", "error", "message", "error", err.Error(), "message", "failed to retrieve accounts")
 	}
 	return
 }
 
+// UpdateAccount changes an account's labels and metadata.
+func (svc *customersService) UpdateAccount(ctx context.Context, req UpdateAccountRequest) (account Account, err error) {
+	account, err = svc.repo.GetAccountByID(ctx, req.ID)
+	if err != nil {
+		return
+	}
+
+	if account.Labels, err = applyLabels(account.Labels, req.Labels, req.RemoveLabels); err != nil {
+		return
+	}
+
+	if req.Metadata != nil {
+		if err = validateMetadata(req.Metadata); err != nil {
+			return
+		}
+		account.Metadata = req.Metadata
+	}
+
+	account, err = svc.repo.UpdateAccount(ctx, account)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to update account", "error", err.Error())
+	}
+
+	return
+}
+
+// ChangeAccountStatus activates, suspends or deactivates an account. Merged
+// accounts cannot be changed.
+func (svc *customersService) ChangeAccountStatus(ctx context.Context, req ChangeAccountStatusRequest) (account Account, err error) {
+	switch req.Status {
+	case AccountActive, AccountSuspended, AccountInactive:
+	default:
+		return account, errors.Wrapf(ErrInvalidArgument, "unknown status %q", req.Status)
+	}
+
+	account, err = svc.repo.GetAccountByID(ctx, req.ID)
+	if err != nil || account.Status == req.Status {
+		return
+	}
+
+	if account.Status == AccountMerged {
+		return account, errors.Wrapf(ErrFailedPrecondition, "account %s has been merged", account.ID)
+	}
+
+	from := account.Status
+	account.Status = req.Status
+	account, err = svc.repo.UpdateAccount(ctx, account)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to update account status", "error", err.Error())
+		return
+	}
+
+	svc.publish(ctx, events.Event{
+		Type:    "account.status_changed",
+		Subject: account.ID,
+		Data:    map[string]string{"from": string(from), "to": string(account.Status)},
+	})
+
+	return
+}
+
+func (svc *customersService) FindAccountsByContactEmail(ctx context.Context, req FindAccountsByContactEmailRequest) (accounts []Account, err error) {
+	email, _, err := normalizeEmail(req.Email)
+	if err != nil {
+		return
+	}
+
+	accounts, err = svc.repo.SelectAccounts(ctx, map[string]interface{}{"contact_email": email})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to retrieve accounts", "error", err.Error())
+	}
+
+	return
+}
+
 func (svc *customersService) CreateUser(ctx context.Context, req CreateUserRequest) (user User, err error) {
-	user, err = svc.repo.InsertUser(ctx, User{AccountID: req.AccountID, Email: req.Email, Name: req.Name})
+	role := req.Role
+	if role == "" {
+		role = RoleMember
+	}
+
+	if !role.Valid() {
+		return user, errors.Wrapf(ErrInvalidArgument, "unknown role %q", role)
+	}
+
+	email, canonical, err := normalizeEmail(req.Email)
+	if err != nil {
+		return
+	}
+
+	if err = validateLabels(req.Labels); err != nil {
+		return
+	}
+
+	if err = validateMetadata(req.Metadata); err != nil {
+		return
+	}
+
+	account, err := svc.repo.GetAccountByID(ctx, req.AccountID)
+	if err != nil {
+		return
+	}
+
+	if account.Status != AccountActive {
+		return user, errors.Wrapf(ErrFailedPrecondition, "account %s is not active", account.ID)
+	}
+
+	customFields, err := svc.validateCustomFieldValues(ctx, req.AccountID, nil, req.CustomFields)
+	if err != nil {
+		return
+	}
+
+	// the user is only created together with their membership, so a failure
+	// never leaves behind a user who belongs to no account
+	err = svc.repo.Transaction(ctx, func(ctx context.Context) error {
+		return svc.withSeat(ctx, req.AccountID, func(ctx context.Context) (err error) {
+			user, err = svc.repo.InsertUser(ctx, User{
+				Kind:           UserHuman,
+				Email:          email,
+				EmailCanonical: canonical,
+				Name:           req.Name,
+				Status:         svc.initialUserStatus(),
+				Labels:         req.Labels,
+				Metadata:       req.Metadata,
+			})
+			if err != nil {
+				svc.logger.Log("level", "error", "message", "error", err.Error(), "message", "failed to insert user")
+				return
+			}
+
+			_, err = svc.repo.InsertMembership(ctx, Membership{
+				AccountID:    req.AccountID,
+				UserID:       user.ID,
+				Role:         role,
+				Status:       UserActive,
+				CustomFields: customFields,
+			})
+			if err != nil {
+				svc.logger.Log("level", "error", "message", "failed to insert membership", "error", err.Error())
+			}
+
+			return
+		})
+	})
+	if err != nil {
+		return
+	}
+
+	svc.sendInitialVerification(ctx, user)
+
+	return
+}
+
+// UpdateUser changes a user's profile. Changing the email address clears its
+// verification, and leaves an active user pending until the new address is
+// verified when verification is required.
+func (svc *customersService) UpdateUser(ctx context.Context, req UpdateUserRequest) (user User, err error) {
+	user, err = svc.repo.GetUserByID(ctx, req.ID)
+	if err != nil {
+		return
+	}
+
+	if req.Name != nil {
+		user.Name = *req.Name
+	}
+
+	emailChanged := false
+	if req.Email != nil {
+		var email, canonical string
+		if email, canonical, err = normalizeEmail(*req.Email); err != nil {
+			return
+		}
+
+		emailChanged = email != user.Email
+		user.Email = email
+		user.EmailCanonical = canonical
+		if emailChanged {
+			user.VerifiedAt = nil
+			if user.Status == UserActive {
+				user.Status = svc.initialUserStatus()
+			}
+		}
+	}
+
+	if user.Labels, err = applyLabels(user.Labels, req.Labels, req.RemoveLabels); err != nil {
+		return
+	}
+
+	if req.Metadata != nil {
+		if err = validateMetadata(req.Metadata); err != nil {
+			return
+		}
+		user.Metadata = req.Metadata
+	}
+
+	user, err = svc.repo.UpdateUser(ctx, user)
 	if err != nil {
-		svc.logger.Log("level", "error", "message", "error", err.Error(), "message", "failed to insert user")
+		svc.logger.Log("level", "error", "message", "failed to update user", "error", err.Error())
+		return
+	}
+
+	if emailChanged {
+		svc.sendInitialVerification(ctx, user)
 	}
 
 	return
@@ -138,10 +656,100 @@ func (svc *customersService) GetUser(ctx context.Context, req GetUserRequest) (u
 	return
 }
 
+func (svc *customersService) GetUserByEmail(ctx context.Context, req GetUserByEmailRequest) (user User, err error) {
+	email, canonical, err := normalizeEmail(req.Email)
+	if err != nil {
+		return
+	}
+
+	user, err = svc.repo.GetUserByEmail(ctx, email)
+	if !req.MatchCanonical || errors.Cause(err) != ErrNotFound {
+		return
+	}
+
+	users, err := svc.repo.SelectUsers(ctx, map[string]interface{}{"email_canonical": canonical})
+	if err != nil {
+		return
+	}
+
+	switch len(users) {
+	case 0:
+		return user, errors.Wrapf(ErrNotFound, "no user with email %s", email)
+	case 1:
+		return users[0], nil
+	}
+
+	return user, errors.Wrapf(ErrFailedPrecondition, "%d users match the canonical form of %s", len(users), email)
+}
+
 func (svc *customersService) FetchUsers(ctx context.Context, req FetchUsersRequest) (users []User, err error) {
-	users, err = svc.repo.SelectUsers(ctx, map[string]interface{}{})
+	kind := req.Kind
+	if kind == "" {
+		kind = UserHuman
+	}
+
+	filters := map[string]interface{}{"kind": kind}
+	if len(req.AccountIDs) > 0 {
+		filters["account_id"] = req.AccountIDs
+	}
+	if req.LabelSelector != "" {
+		if filters["labels"], err = ParseSelector(req.LabelSelector); err != nil {
+			return
+		}
+	}
+	if len(req.CustomFields) > 0 {
+		if len(req.AccountIDs) != 1 {
+			return nil, errors.Wrap(ErrInvalidArgument, "filtering on custom fields requires a single account")
+		}
+
+		if filters["custom_fields"], err = svc.customFieldFilter(ctx, req.AccountIDs[0], req.CustomFields); err != nil {
+			return
+		}
+	}
+
+	if req.GroupID != "" {
+		groups, err := svc.repo.SelectGroups(ctx, map[string]interface{}{"id": req.GroupID})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(groups) == 0 || (len(req.AccountIDs) > 0 && !containsString(req.AccountIDs, groups[0].AccountID)) {
+			return nil, errors.Wrapf(ErrNotFound, "group %s", req.GroupID)
+		}
+
+		if filters["group_id"], err = svc.groupTree(ctx, groups[0]); err != nil {
+			return nil, err
+		}
+	}
+
+	users, err = svc.repo.SelectUsers(ctx, filters)
 	if err != nil {
 		svc.logger.Log("level", "error", "message", "error", err.Error(), "message", "failed to retrieve users")
 	}
 	return
 }
+
+// normalizeEmail returns the stored and canonical forms of an address.
+func normalizeEmail(address string) (normalized, canonical string, err error) {
+	normalized, err = emailutil.Normalize(address)
+	if err != nil {
+		return "", "", errors.Wrap(ErrInvalidArgument, err.Error())
+	}
+
+	canonical, err = emailutil.Canonical(normalized)
+	if err != nil {
+		return "", "", errors.Wrap(ErrInvalidArgument, err.Error())
+	}
+
+	return normalized, canonical, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
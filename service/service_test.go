@@ -1,2 +1,993 @@
 package service
 
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+
+	"github.com/symptomatichq/customers/events"
+	"github.com/symptomatichq/customers/notify"
+)
+
+// fakeRepository keeps records in memory. Methods a test does not exercise
+// fall through to the embedded nil Repository and panic.
+type fakeRepository struct {
+	Repository
+	fakeRecords
+
+	// failMerges makes recording account merges fail.
+	failMerges bool
+	// failMemberships makes inserting memberships fail.
+	failMemberships bool
+
+	txLock   sync.Mutex
+	seatLock sync.Mutex
+}
+
+// fakeRecords are the records a fakeRepository holds, which a failed
+// transaction restores.
+type fakeRecords struct {
+	accounts      map[string]Account
+	users         map[string]User
+	memberships   []Membership
+	invitations   []Invitation
+	verifications []EmailVerification
+	duplicates    []DuplicateCandidate
+	merges        []AccountMerge
+	transfers     []UserTransfer
+	ownership     []OwnershipTransfer
+	groups        []Group
+	groupMembers  []GroupMember
+	apiKeys       []APIKey
+	customFields  []CustomField
+	plans         []Plan
+	accountPlans  []AccountPlan
+	overrides     []EntitlementOverride
+	actions       []ScheduledAction
+	flags         []FeatureFlag
+	flagOverrides []FlagOverride
+	nextID        int
+}
+
+func (r fakeRecords) clone() fakeRecords {
+	c := r
+	c.accounts = make(map[string]Account, len(r.accounts))
+	for id, a := range r.accounts {
+		c.accounts[id] = a
+	}
+	c.users = make(map[string]User, len(r.users))
+	for id, u := range r.users {
+		c.users[id] = u
+	}
+	c.memberships = append([]Membership(nil), r.memberships...)
+	c.invitations = append([]Invitation(nil), r.invitations...)
+	c.verifications = append([]EmailVerification(nil), r.verifications...)
+	c.duplicates = append([]DuplicateCandidate(nil), r.duplicates...)
+	c.merges = append([]AccountMerge(nil), r.merges...)
+	c.transfers = append([]UserTransfer(nil), r.transfers...)
+	c.ownership = append([]OwnershipTransfer(nil), r.ownership...)
+	c.groups = append([]Group(nil), r.groups...)
+	c.groupMembers = append([]GroupMember(nil), r.groupMembers...)
+	c.apiKeys = append([]APIKey(nil), r.apiKeys...)
+	c.customFields = append([]CustomField(nil), r.customFields...)
+	c.plans = append([]Plan(nil), r.plans...)
+	c.accountPlans = append([]AccountPlan(nil), r.accountPlans...)
+	c.overrides = append([]EntitlementOverride(nil), r.overrides...)
+	c.actions = append([]ScheduledAction(nil), r.actions...)
+	c.flags = append([]FeatureFlag(nil), r.flags...)
+	c.flagOverrides = append([]FlagOverride(nil), r.flagOverrides...)
+	return c
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{
+		fakeRecords: fakeRecords{
+			accounts: map[string]Account{},
+			users:    map[string]User{},
+		},
+	}
+}
+
+type fakeTxKey struct{}
+
+// Transaction runs transactions one at a time and restores every record when
+// fn fails. Nested transactions join the outer one.
+func (r *fakeRepository) Transaction(ctx context.Context, fn func(context.Context) error) error {
+	if ctx.Value(fakeTxKey{}) != nil {
+		return fn(ctx)
+	}
+
+	r.txLock.Lock()
+	defer r.txLock.Unlock()
+
+	snapshot := r.fakeRecords.clone()
+	if err := fn(context.WithValue(ctx, fakeTxKey{}, true)); err != nil {
+		// records are only written back when fn changed them, so callers
+		// reading outside of a transaction do not race a no-op rollback
+		if !reflect.DeepEqual(r.fakeRecords, snapshot) {
+			r.fakeRecords = snapshot
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *fakeRepository) GetAccountByID(ctx context.Context, id string) (Account, error) {
+	account, ok := r.accounts[id]
+	if !ok {
+		return Account{}, ErrNotFound
+	}
+	return account, nil
+}
+
+func (r *fakeRepository) inSubtree(a Account, root string) bool {
+	for a.ID != root {
+		if a.ParentID == nil {
+			return false
+		}
+		a = r.accounts[*a.ParentID]
+	}
+	return true
+}
+
+func (r *fakeRepository) SelectAccountAncestors(ctx context.Context, id string) ([]Account, error) {
+	var ancestors []Account
+	for a := r.accounts[id]; a.ParentID != nil; {
+		a = r.accounts[*a.ParentID]
+		ancestors = append(ancestors, a)
+	}
+	return ancestors, nil
+}
+
+func (r *fakeRepository) InsertAccount(ctx context.Context, a Account) (Account, error) {
+	a.ID = r.id()
+	if a.Status == "" {
+		a.Status = AccountActive
+	}
+	r.accounts[a.ID] = a
+	return a, nil
+}
+
+func (r *fakeRepository) UpdateAccount(ctx context.Context, a Account) (Account, error) {
+	r.accounts[a.ID] = a
+	return a, nil
+}
+
+func (r *fakeRepository) UpsertDuplicateCandidate(ctx context.Context, c DuplicateCandidate) (DuplicateCandidate, error) {
+	for i, existing := range r.duplicates {
+		if existing.AccountID == c.AccountID && existing.DuplicateID == c.DuplicateID {
+			c.Status = existing.Status
+			r.duplicates[i] = c
+			return c, nil
+		}
+	}
+	r.duplicates = append(r.duplicates, c)
+	return c, nil
+}
+
+func (r *fakeRepository) UpdateDuplicateCandidate(ctx context.Context, c DuplicateCandidate) (DuplicateCandidate, error) {
+	for i, existing := range r.duplicates {
+		if existing.AccountID == c.AccountID && existing.DuplicateID == c.DuplicateID {
+			r.duplicates[i] = c
+		}
+	}
+	return c, nil
+}
+
+func (r *fakeRepository) SelectDuplicateCandidates(ctx context.Context, filters map[string]interface{}) ([]DuplicateCandidate, error) {
+	var selected []DuplicateCandidate
+	for _, c := range r.duplicates {
+		if v, ok := filters["involves"]; ok && v != c.AccountID && v != c.DuplicateID {
+			continue
+		}
+		if v, ok := filters["account_id"]; ok && v != c.AccountID {
+			continue
+		}
+		if v, ok := filters["duplicate_id"]; ok && v != c.DuplicateID {
+			continue
+		}
+		if v, ok := filters["status"]; ok && v != c.Status {
+			continue
+		}
+		selected = append(selected, c)
+	}
+	return selected, nil
+}
+
+func (r *fakeRepository) InsertAccountMerge(ctx context.Context, m AccountMerge) (AccountMerge, error) {
+	if r.failMerges {
+		return m, errors.New("merge failed")
+	}
+	m.ID = r.id()
+	r.merges = append(r.merges, m)
+	return m, nil
+}
+
+func (r *fakeRepository) GetUserByID(ctx context.Context, id string) (User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return User{}, ErrNotFound
+	}
+	return user, nil
+}
+
+func (r *fakeRepository) id() string {
+	r.nextID++
+	return fmt.Sprintf("id-%d", r.nextID)
+}
+
+func (r *fakeRepository) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	for _, u := range r.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+	return User{}, ErrNotFound
+}
+
+func (r *fakeRepository) InsertUser(ctx context.Context, u User) (User, error) {
+	u.ID = r.id()
+	r.users[u.ID] = u
+	return u, nil
+}
+
+func (r *fakeRepository) UpdateUser(ctx context.Context, u User) (User, error) {
+	r.users[u.ID] = u
+	return u, nil
+}
+
+func (r *fakeRepository) SelectAccounts(ctx context.Context, filters map[string]interface{}) ([]Account, error) {
+	var selected []Account
+	for _, a := range r.accounts {
+		if v, ok := filters["contact_email"]; ok && v != a.ContactEmail {
+			continue
+		}
+		if v, ok := filters["status"]; ok && v != a.Status {
+			continue
+		}
+		if v, ok := filters["parent_id"]; ok && (a.ParentID == nil || v != *a.ParentID) {
+			continue
+		}
+		if v, ok := filters["subtree_of"].(string); ok && !r.inSubtree(a, v) {
+			continue
+		}
+		if v, ok := filters["labels"].(Selector); ok && !v.Matches(a.Labels) {
+			continue
+		}
+		if v, ok := filters["trial_ends_before"].(time.Time); ok && (a.TrialEndsAt == nil || a.TrialEndsAt.After(v)) {
+			continue
+		}
+		selected = append(selected, a)
+	}
+	return selected, nil
+}
+
+func (r *fakeRepository) SelectUsers(ctx context.Context, filters map[string]interface{}) ([]User, error) {
+	var selected []User
+	for _, u := range r.users {
+		if v, ok := filters["group_id"].([]string); ok {
+			members, _ := r.SelectGroupMembers(ctx, map[string]interface{}{"group_id": v, "user_id": u.ID})
+			if len(members) == 0 {
+				continue
+			}
+		}
+		if v, ok := filters["account_id"].([]string); ok {
+			memberships, _ := r.SelectMemberships(ctx, map[string]interface{}{"user_id": u.ID})
+			if !r.memberOfAny(memberships, v) {
+				continue
+			}
+		}
+		if v, ok := filters["kind"]; ok && v != u.Kind {
+			continue
+		}
+		if v, ok := filters["labels"].(Selector); ok && !v.Matches(u.Labels) {
+			continue
+		}
+		if v, ok := filters["custom_fields"].(map[string]string); ok && !r.holdsCustomFields(ctx, filters["account_id"].([]string)[0], u.ID, v) {
+			continue
+		}
+		if v, ok := filters["email"]; ok && v != u.Email {
+			continue
+		}
+		if v, ok := filters["email_canonical"]; ok && v != u.EmailCanonical {
+			continue
+		}
+		selected = append(selected, u)
+	}
+	return selected, nil
+}
+
+func (r *fakeRepository) memberOfAny(memberships []Membership, accountIDs []string) bool {
+	for _, m := range memberships {
+		if containsString(accountIDs, m.AccountID) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *fakeRepository) InsertInvitation(ctx context.Context, i Invitation) (Invitation, error) {
+	i.ID = r.id()
+	r.invitations = append(r.invitations, i)
+	return i, nil
+}
+
+func (r *fakeRepository) UpdateInvitation(ctx context.Context, i Invitation) (Invitation, error) {
+	for n, existing := range r.invitations {
+		if existing.ID == i.ID {
+			r.invitations[n] = i
+		}
+	}
+	return i, nil
+}
+
+func (r *fakeRepository) AcceptInvitation(ctx context.Context, i Invitation) (Invitation, error) {
+	for n, existing := range r.invitations {
+		if existing.ID == i.ID && existing.Status == InvitationPending {
+			r.invitations[n] = i
+			return i, nil
+		}
+	}
+	return Invitation{}, ErrNotFound
+}
+
+func (r *fakeRepository) SelectInvitations(ctx context.Context, filters map[string]interface{}) ([]Invitation, error) {
+	var selected []Invitation
+	for _, i := range r.invitations {
+		if v, ok := filters["id"]; ok && v != i.ID {
+			continue
+		}
+		if v, ok := filters["account_id"]; ok && v != i.AccountID {
+			continue
+		}
+		if v, ok := filters["email"]; ok && v != i.Email {
+			continue
+		}
+		if v, ok := filters["status"]; ok && v != i.Status {
+			continue
+		}
+		if v, ok := filters["token_hash"]; ok && v != i.TokenHash {
+			continue
+		}
+		selected = append(selected, i)
+	}
+	return selected, nil
+}
+
+func (r *fakeRepository) InsertEmailVerification(ctx context.Context, v EmailVerification) (EmailVerification, error) {
+	v.ID = r.id()
+	v.CreatedAt = time.Now()
+	r.verifications = append(r.verifications, v)
+	return v, nil
+}
+
+func (r *fakeRepository) UpdateEmailVerification(ctx context.Context, v EmailVerification) (EmailVerification, error) {
+	for n, existing := range r.verifications {
+		if existing.ID == v.ID {
+			r.verifications[n] = v
+		}
+	}
+	return v, nil
+}
+
+func (r *fakeRepository) SelectEmailVerifications(ctx context.Context, filters map[string]interface{}) ([]EmailVerification, error) {
+	var selected []EmailVerification
+	for _, v := range r.verifications {
+		if id, ok := filters["user_id"]; ok && id != v.UserID {
+			continue
+		}
+		if hash, ok := filters["token_hash"]; ok && hash != v.TokenHash {
+			continue
+		}
+		if after, ok := filters["created_after"].(time.Time); ok && !v.CreatedAt.After(after) {
+			continue
+		}
+		selected = append(selected, v)
+	}
+	return selected, nil
+}
+
+func (r *fakeRepository) InsertMembership(ctx context.Context, m Membership) (Membership, error) {
+	if r.failMemberships {
+		return m, errors.New("insert failed")
+	}
+	r.memberships = append(r.memberships, m)
+	return m, nil
+}
+
+func (r *fakeRepository) UpdateMembership(ctx context.Context, m Membership) (Membership, error) {
+	for i, existing := range r.memberships {
+		if existing.AccountID == m.AccountID && existing.UserID == m.UserID {
+			r.memberships[i] = m
+		}
+	}
+	return m, nil
+}
+
+func (r *fakeRepository) DeleteMembership(ctx context.Context, m Membership) error {
+	kept := r.memberships[:0]
+	for _, existing := range r.memberships {
+		if existing.AccountID != m.AccountID || existing.UserID != m.UserID {
+			kept = append(kept, existing)
+		}
+	}
+	r.memberships = kept
+	return nil
+}
+
+func (r *fakeRepository) TransferMembership(ctx context.Context, from, to Membership, t UserTransfer) (UserTransfer, error) {
+	r.DeleteMembership(ctx, from)
+	r.InsertMembership(ctx, to)
+	t.ID = r.id()
+	r.transfers = append(r.transfers, t)
+	return t, nil
+}
+
+func (r *fakeRepository) InsertOwnershipTransfer(ctx context.Context, t OwnershipTransfer) (OwnershipTransfer, error) {
+	t.ID = r.id()
+	r.ownership = append(r.ownership, t)
+	return t, nil
+}
+
+func (r *fakeRepository) UpdateOwnershipTransfer(ctx context.Context, t OwnershipTransfer) (OwnershipTransfer, error) {
+	for i, existing := range r.ownership {
+		if existing.ID == t.ID {
+			r.ownership[i] = t
+		}
+	}
+	return t, nil
+}
+
+func (r *fakeRepository) SelectOwnershipTransfers(ctx context.Context, filters map[string]interface{}) ([]OwnershipTransfer, error) {
+	var selected []OwnershipTransfer
+	for _, t := range r.ownership {
+		if v, ok := filters["id"]; ok && v != t.ID {
+			continue
+		}
+		if v, ok := filters["account_id"]; ok && v != t.AccountID {
+			continue
+		}
+		if v, ok := filters["status"]; ok && v != t.Status {
+			continue
+		}
+		selected = append(selected, t)
+	}
+	return selected, nil
+}
+
+func (r *fakeRepository) InsertGroup(ctx context.Context, g Group) (Group, error) {
+	g.ID = r.id()
+	r.groups = append(r.groups, g)
+	return g, nil
+}
+
+func (r *fakeRepository) UpdateGroup(ctx context.Context, g Group) (Group, error) {
+	for i, existing := range r.groups {
+		if existing.ID == g.ID {
+			r.groups[i] = g
+		}
+	}
+	return g, nil
+}
+
+func (r *fakeRepository) DeleteGroup(ctx context.Context, g Group) error {
+	kept := r.groups[:0]
+	for _, existing := range r.groups {
+		if existing.ID != g.ID {
+			kept = append(kept, existing)
+		}
+	}
+	r.groups = kept
+	return nil
+}
+
+func (r *fakeRepository) SelectGroups(ctx context.Context, filters map[string]interface{}) ([]Group, error) {
+	var selected []Group
+	for _, g := range r.groups {
+		if v, ok := filters["id"]; ok && v != g.ID {
+			continue
+		}
+		if v, ok := filters["account_id"]; ok && v != g.AccountID {
+			continue
+		}
+		if v, ok := filters["parent_id"]; ok && (g.ParentID == nil || v != *g.ParentID) {
+			continue
+		}
+		if v, ok := filters["name"].(string); ok && !strings.EqualFold(v, g.Name) {
+			continue
+		}
+		selected = append(selected, g)
+	}
+	return selected, nil
+}
+
+func (r *fakeRepository) InsertGroupMember(ctx context.Context, m GroupMember) (GroupMember, error) {
+	r.groupMembers = append(r.groupMembers, m)
+	return m, nil
+}
+
+func (r *fakeRepository) DeleteGroupMember(ctx context.Context, m GroupMember) error {
+	kept := r.groupMembers[:0]
+	for _, existing := range r.groupMembers {
+		if existing.GroupID != m.GroupID || existing.UserID != m.UserID {
+			kept = append(kept, existing)
+		}
+	}
+	r.groupMembers = kept
+	return nil
+}
+
+func (r *fakeRepository) SelectGroupMembers(ctx context.Context, filters map[string]interface{}) ([]GroupMember, error) {
+	var selected []GroupMember
+	for _, m := range r.groupMembers {
+		switch v := filters["group_id"].(type) {
+		case string:
+			if v != m.GroupID {
+				continue
+			}
+		case []string:
+			if !containsString(v, m.GroupID) {
+				continue
+			}
+		}
+		if v, ok := filters["user_id"]; ok && v != m.UserID {
+			continue
+		}
+		selected = append(selected, m)
+	}
+	return selected, nil
+}
+
+func (r *fakeRepository) InsertAPIKey(ctx context.Context, k APIKey) (APIKey, error) {
+	k.ID = r.id()
+	r.apiKeys = append(r.apiKeys, k)
+	return k, nil
+}
+
+func (r *fakeRepository) UpdateAPIKey(ctx context.Context, k APIKey) (APIKey, error) {
+	for i, existing := range r.apiKeys {
+		if existing.ID == k.ID {
+			r.apiKeys[i] = k
+		}
+	}
+	return k, nil
+}
+
+func (r *fakeRepository) SelectAPIKeys(ctx context.Context, filters map[string]interface{}) ([]APIKey, error) {
+	var selected []APIKey
+	for _, k := range r.apiKeys {
+		if v, ok := filters["id"]; ok && v != k.ID {
+			continue
+		}
+		if v, ok := filters["account_id"]; ok && v != k.AccountID {
+			continue
+		}
+		if v, ok := filters["prefix"]; ok && v != k.Prefix {
+			continue
+		}
+		if v, ok := filters["status"]; ok && v != k.Status {
+			continue
+		}
+		selected = append(selected, k)
+	}
+	return selected, nil
+}
+
+func (r *fakeRepository) holdsCustomFields(ctx context.Context, accountID, userID string, values map[string]string) bool {
+	memberships, _ := r.SelectMemberships(ctx, map[string]interface{}{"account_id": accountID, "user_id": userID})
+	if len(memberships) == 0 {
+		return false
+	}
+	for key, value := range values {
+		if memberships[0].CustomFields[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *fakeRepository) InsertCustomField(ctx context.Context, f CustomField) (CustomField, error) {
+	f.ID = r.id()
+	r.customFields = append(r.customFields, f)
+	return f, nil
+}
+
+func (r *fakeRepository) UpdateCustomField(ctx context.Context, f CustomField) (CustomField, error) {
+	for i, existing := range r.customFields {
+		if existing.ID == f.ID {
+			r.customFields[i] = f
+		}
+	}
+	return f, nil
+}
+
+func (r *fakeRepository) DeleteCustomField(ctx context.Context, f CustomField) error {
+	kept := r.customFields[:0]
+	for _, existing := range r.customFields {
+		if existing.ID != f.ID {
+			kept = append(kept, existing)
+		}
+	}
+	r.customFields = kept
+	for _, m := range r.memberships {
+		if m.AccountID == f.AccountID {
+			delete(m.CustomFields, f.Key)
+		}
+	}
+	return nil
+}
+
+func (r *fakeRepository) SelectCustomFields(ctx context.Context, filters map[string]interface{}) ([]CustomField, error) {
+	var selected []CustomField
+	for _, f := range r.customFields {
+		if v, ok := filters["account_id"]; ok && v != f.AccountID {
+			continue
+		}
+		selected = append(selected, f)
+	}
+	return selected, nil
+}
+
+func (r *fakeRepository) InsertPlan(ctx context.Context, p Plan) (Plan, error) {
+	p.ID = r.id()
+	r.plans = append(r.plans, p)
+	return p, nil
+}
+
+func (r *fakeRepository) UpdatePlan(ctx context.Context, p Plan) (Plan, error) {
+	for i, existing := range r.plans {
+		if existing.ID == p.ID {
+			r.plans[i] = p
+		}
+	}
+	return p, nil
+}
+
+func (r *fakeRepository) SelectPlans(ctx context.Context, filters map[string]interface{}) ([]Plan, error) {
+	var selected []Plan
+	for _, p := range r.plans {
+		if v, ok := filters["id"]; ok && v != p.ID {
+			continue
+		}
+		if v, ok := filters["key"]; ok && v != p.Key {
+			continue
+		}
+		if v, ok := filters["archived"]; ok && v != p.Archived {
+			continue
+		}
+		selected = append(selected, p)
+	}
+	return selected, nil
+}
+
+func (r *fakeRepository) InsertAccountPlan(ctx context.Context, p AccountPlan) (AccountPlan, error) {
+	p.ID = r.id()
+	r.accountPlans = append(r.accountPlans, p)
+	return p, nil
+}
+
+func (r *fakeRepository) SelectAccountPlans(ctx context.Context, filters map[string]interface{}) ([]AccountPlan, error) {
+	var selected []AccountPlan
+	for _, p := range r.accountPlans {
+		if v, ok := filters["account_id"]; ok && v != p.AccountID {
+			continue
+		}
+		selected = append(selected, p)
+	}
+	return selected, nil
+}
+
+func (r *fakeRepository) UpsertEntitlementOverride(ctx context.Context, o EntitlementOverride) (EntitlementOverride, error) {
+	r.DeleteEntitlementOverride(ctx, o)
+	r.overrides = append(r.overrides, o)
+	return o, nil
+}
+
+func (r *fakeRepository) DeleteEntitlementOverride(ctx context.Context, o EntitlementOverride) error {
+	kept := r.overrides[:0]
+	for _, existing := range r.overrides {
+		if existing.AccountID != o.AccountID || existing.Key != o.Key {
+			kept = append(kept, existing)
+		}
+	}
+	r.overrides = kept
+	return nil
+}
+
+func (r *fakeRepository) SelectEntitlementOverrides(ctx context.Context, filters map[string]interface{}) ([]EntitlementOverride, error) {
+	var selected []EntitlementOverride
+	for _, o := range r.overrides {
+		if v, ok := filters["account_id"]; ok && v != o.AccountID {
+			continue
+		}
+		if v, ok := filters["key"]; ok && v != o.Key {
+			continue
+		}
+		selected = append(selected, o)
+	}
+	return selected, nil
+}
+
+func (r *fakeRepository) LockAccountSeats(ctx context.Context, accountID string, fn func(context.Context) error) error {
+	r.seatLock.Lock()
+	defer r.seatLock.Unlock()
+	return fn(ctx)
+}
+
+func (r *fakeRepository) CountSeats(ctx context.Context, accountID string) (int64, error) {
+	var seats int64
+	for _, m := range r.memberships {
+		if m.AccountID == accountID && m.Status == UserActive && r.users[m.UserID].Interactive() {
+			seats++
+		}
+	}
+	return seats, nil
+}
+
+func (r *fakeRepository) InsertScheduledAction(ctx context.Context, a ScheduledAction) (ScheduledAction, error) {
+	a.ID = r.id()
+	r.actions = append(r.actions, a)
+	return a, nil
+}
+
+func (r *fakeRepository) UpdateScheduledAction(ctx context.Context, a ScheduledAction) (ScheduledAction, error) {
+	for i, existing := range r.actions {
+		if existing.ID == a.ID {
+			r.actions[i] = a
+		}
+	}
+	return a, nil
+}
+
+func (r *fakeRepository) SelectScheduledActions(ctx context.Context, filters map[string]interface{}) ([]ScheduledAction, error) {
+	var selected []ScheduledAction
+	for _, a := range r.actions {
+		if v, ok := filters["id"]; ok && v != a.ID {
+			continue
+		}
+		if v, ok := filters["account_id"]; ok && v != a.AccountID {
+			continue
+		}
+		if v, ok := filters["user_id"]; ok && v != a.UserID {
+			continue
+		}
+		if v, ok := filters["status"]; ok && v != a.Status {
+			continue
+		}
+		selected = append(selected, a)
+	}
+	return selected, nil
+}
+
+func (r *fakeRepository) ClaimScheduledActions(ctx context.Context, now, lockUntil time.Time, limit int) ([]ScheduledAction, error) {
+	var claimed []ScheduledAction
+	for i, a := range r.actions {
+		if len(claimed) == limit || a.Status != ScheduledActionPending || a.RunAt.After(now) || a.Running(now) {
+			continue
+		}
+		a.LockedUntil = &lockUntil
+		a.Attempts++
+		r.actions[i] = a
+		claimed = append(claimed, a)
+	}
+	return claimed, nil
+}
+
+func (r *fakeRepository) InsertFeatureFlag(ctx context.Context, f FeatureFlag) (FeatureFlag, error) {
+	f.ID = r.id()
+	r.flags = append(r.flags, f)
+	return f, nil
+}
+
+func (r *fakeRepository) UpdateFeatureFlag(ctx context.Context, f FeatureFlag) (FeatureFlag, error) {
+	for i, existing := range r.flags {
+		if existing.ID == f.ID {
+			r.flags[i] = f
+		}
+	}
+	return f, nil
+}
+
+func (r *fakeRepository) DeleteFeatureFlag(ctx context.Context, f FeatureFlag) error {
+	kept := r.flags[:0]
+	for _, existing := range r.flags {
+		if existing.ID != f.ID {
+			kept = append(kept, existing)
+		}
+	}
+	r.flags = kept
+
+	overrides := r.flagOverrides[:0]
+	for _, o := range r.flagOverrides {
+		if o.FlagKey != f.Key {
+			overrides = append(overrides, o)
+		}
+	}
+	r.flagOverrides = overrides
+	return nil
+}
+
+func (r *fakeRepository) SelectFeatureFlags(ctx context.Context, filters map[string]interface{}) ([]FeatureFlag, error) {
+	var selected []FeatureFlag
+	for _, f := range r.flags {
+		if v, ok := filters["key"]; ok && v != f.Key {
+			continue
+		}
+		selected = append(selected, f)
+	}
+	return selected, nil
+}
+
+func (r *fakeRepository) UpsertFlagOverride(ctx context.Context, o FlagOverride) (FlagOverride, error) {
+	r.DeleteFlagOverride(ctx, o)
+	r.flagOverrides = append(r.flagOverrides, o)
+	return o, nil
+}
+
+func (r *fakeRepository) DeleteFlagOverride(ctx context.Context, o FlagOverride) error {
+	kept := r.flagOverrides[:0]
+	for _, existing := range r.flagOverrides {
+		if existing.FlagKey != o.FlagKey || existing.AccountID != o.AccountID || existing.UserID != o.UserID {
+			kept = append(kept, existing)
+		}
+	}
+	r.flagOverrides = kept
+	return nil
+}
+
+func (r *fakeRepository) SelectFlagOverrides(ctx context.Context, filters map[string]interface{}) ([]FlagOverride, error) {
+	var selected []FlagOverride
+	for _, o := range r.flagOverrides {
+		if v, ok := filters["flag_key"]; ok && v != o.FlagKey {
+			continue
+		}
+		if v, ok := filters["account_id"]; ok && v != o.AccountID {
+			continue
+		}
+		if v, ok := filters["user_id"]; ok && v != o.UserID {
+			continue
+		}
+		selected = append(selected, o)
+	}
+	return selected, nil
+}
+
+func (r *fakeRepository) SelectMemberships(ctx context.Context, filters map[string]interface{}) ([]Membership, error) {
+	var selected []Membership
+	for _, m := range r.memberships {
+		if v, ok := filters["account_id"]; ok && v != m.AccountID {
+			continue
+		}
+		if v, ok := filters["user_id"]; ok && v != m.UserID {
+			continue
+		}
+		if v, ok := filters["role"]; ok && v != m.Role {
+			continue
+		}
+		if v, ok := filters["status"]; ok && v != m.Status {
+			continue
+		}
+		selected = append(selected, m)
+	}
+	return selected, nil
+}
+
+// recordingNotifier keeps every message it is asked to deliver.
+type recordingNotifier struct {
+	messages []notify.Message
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, msg notify.Message) error {
+	n.messages = append(n.messages, msg)
+	return nil
+}
+
+// recordingPublisher keeps every event it is asked to publish.
+type recordingPublisher struct {
+	events []events.Event
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event events.Event) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func newTestService(repo Repository, opts ...Option) *customersService {
+	svc := &customersService{logger: log.NewNopLogger(), repo: repo, notifier: &recordingNotifier{}, publisher: &recordingPublisher{}}
+	for _, opt := range opts {
+		opt(svc)
+	}
+	return svc
+}
+
+func TestCreateUser(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	repo.accounts["acct"] = Account{ID: "acct", Status: AccountActive}
+	repo.accounts["closed"] = Account{ID: "closed", Status: AccountInactive}
+	svc := newTestService(repo)
+
+	if _, err := svc.CreateUser(ctx, CreateUserRequest{AccountID: "missing", Email: "alice@example.com"}); errors.Cause(err) != ErrNotFound {
+		t.Errorf("unknown account: expected ErrNotFound, got %v", err)
+	}
+	if _, err := svc.CreateUser(ctx, CreateUserRequest{AccountID: "closed", Email: "alice@example.com"}); errors.Cause(err) != ErrFailedPrecondition {
+		t.Errorf("inactive account: expected ErrFailedPrecondition, got %v", err)
+	}
+
+	repo.failMemberships = true
+	if _, err := svc.CreateUser(ctx, CreateUserRequest{AccountID: "acct", Email: "alice@example.com"}); err == nil {
+		t.Fatal("expected the membership to fail")
+	}
+	if len(repo.users) != 0 {
+		t.Errorf("a failed membership should not leave the user behind, got %+v", repo.users)
+	}
+	repo.failMemberships = false
+
+	user, err := svc.CreateUser(ctx, CreateUserRequest{AccountID: "acct", Email: "alice@example.com", Role: RoleAdmin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m, err := svc.membership(ctx, "acct", user.ID); err != nil || m.Role != RoleAdmin {
+		t.Errorf("unexpected membership %+v, %v", m, err)
+	}
+}
+
+func TestUserEmailsAreNormalized(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	repo.accounts["acct"] = Account{ID: "acct", Status: AccountActive}
+	svc := newTestService(repo)
+
+	user, err := svc.CreateUser(ctx, CreateUserRequest{AccountID: "acct", Email: " A.Lice+work@GMail.com "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Email != "a.lice+work@gmail.com" || user.EmailCanonical != "alice@gmail.com" {
+		t.Errorf("unexpected email %q, canonical %q", user.Email, user.EmailCanonical)
+	}
+
+	if _, err := svc.CreateUser(ctx, CreateUserRequest{AccountID: "acct", Email: "not-an-email"}); errors.Cause(err) != ErrInvalidArgument {
+		t.Errorf("expected ErrInvalidArgument, got %v", err)
+	}
+
+	now := time.Now()
+	user.VerifiedAt = &now
+	repo.users[user.ID] = user
+
+	email := "Alice@Example.com"
+	updated, err := svc.UpdateUser(ctx, UpdateUserRequest{ID: user.ID, Email: &email})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Email != "alice@example.com" || updated.VerifiedAt != nil {
+		t.Errorf("changing the email should normalize it and clear verification, got %+v", updated)
+	}
+}
+
+func TestGetUserByEmail(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	repo.users["alice"] = User{ID: "alice", Email: "a.lice@gmail.com", EmailCanonical: "alice@gmail.com"}
+	svc := newTestService(repo)
+
+	user, err := svc.GetUserByEmail(ctx, GetUserByEmailRequest{Email: "A.Lice@GMAIL.com"})
+	if err != nil || user.ID != "alice" {
+		t.Fatalf("exact lookup: got %+v, %v", user, err)
+	}
+
+	if _, err := svc.GetUserByEmail(ctx, GetUserByEmailRequest{Email: "alice+news@gmail.com"}); errors.Cause(err) != ErrNotFound {
+		t.Errorf("exact lookup of a variant: expected ErrNotFound, got %v", err)
+	}
+
+	user, err = svc.GetUserByEmail(ctx, GetUserByEmailRequest{Email: "alice+news@gmail.com", MatchCanonical: true})
+	if err != nil || user.ID != "alice" {
+		t.Errorf("canonical lookup: got %+v, %v", user, err)
+	}
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type CreateServiceAccountRequest struct {
+	AccountID string
+	Name      string
+	// Role is the service account's role in the account, defaulting to
+	// member. Service accounts cannot be owners.
+	Role Role
+}
+
+type ListServiceAccountsRequest struct {
+	AccountID string
+}
+
+type UpdateServiceAccountRequest struct {
+	AccountID string
+	ID        string
+	Name      *string
+	// Status suspends or reactivates the service account when set.
+	Status *UserStatus
+}
+
+type DeleteServiceAccountRequest struct {
+	AccountID string
+	ID        string
+}
+
+// CreateServiceAccount creates an active service account within an account.
+func (svc *customersService) CreateServiceAccount(ctx context.Context, req CreateServiceAccountRequest) (user User, err error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return user, errors.Wrap(ErrInvalidArgument, "service account name is required")
+	}
+
+	role := req.Role
+	if role == "" {
+		role = RoleMember
+	}
+
+	if !role.Valid() {
+		return user, errors.Wrapf(ErrInvalidArgument, "unknown role %q", role)
+	}
+
+	if role == RoleOwner {
+		return user, errors.Wrap(ErrInvalidArgument, "service accounts cannot own accounts")
+	}
+
+	account, err := svc.repo.GetAccountByID(ctx, req.AccountID)
+	if err != nil {
+		return
+	}
+
+	if account.Status != AccountActive {
+		return user, errors.Wrapf(ErrFailedPrecondition, "account %s is not active", account.ID)
+	}
+
+	user, err = svc.repo.InsertUser(ctx, User{
+		Kind:   UserService,
+		Name:   name,
+		Status: UserActive,
+	})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to insert service account", "error", err.Error())
+		return
+	}
+
+	_, err = svc.repo.InsertMembership(ctx, Membership{
+		AccountID: account.ID,
+		UserID:    user.ID,
+		Role:      role,
+		Status:    UserActive,
+	})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to insert membership", "error", err.Error())
+	}
+
+	return
+}
+
+func (svc *customersService) ListServiceAccounts(ctx context.Context, req ListServiceAccountsRequest) (users []User, err error) {
+	users, err = svc.repo.SelectUsers(ctx, map[string]interface{}{
+		"account_id": []string{req.AccountID},
+		"kind":       UserService,
+	})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to retrieve service accounts", "error", err.Error())
+	}
+
+	return
+}
+
+func (svc *customersService) UpdateServiceAccount(ctx context.Context, req UpdateServiceAccountRequest) (user User, err error) {
+	user, err = svc.serviceAccount(ctx, req.AccountID, req.ID)
+	if err != nil {
+		return
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return user, errors.Wrap(ErrInvalidArgument, "service account name is required")
+		}
+		user.Name = name
+	}
+
+	if req.Status != nil {
+		if *req.Status != UserActive && *req.Status != UserSuspended {
+			return user, errors.Wrapf(ErrInvalidArgument, "service accounts cannot be %s", *req.Status)
+		}
+		user.Status = *req.Status
+	}
+
+	user, err = svc.repo.UpdateUser(ctx, user)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to update service account", "error", err.Error())
+	}
+
+	return
+}
+
+// DeleteServiceAccount deactivates a service account and removes it from its
+// account. The user itself is kept so that records naming it stay resolvable.
+func (svc *customersService) DeleteServiceAccount(ctx context.Context, req DeleteServiceAccountRequest) (err error) {
+	user, err := svc.serviceAccount(ctx, req.AccountID, req.ID)
+	if err != nil {
+		return
+	}
+
+	membership, err := svc.membership(ctx, req.AccountID, user.ID)
+	if err != nil {
+		return
+	}
+
+	if err = svc.repo.DeleteMembership(ctx, membership); err != nil {
+		svc.logger.Log("level", "error", "message", "failed to delete membership", "error", err.Error())
+		return
+	}
+
+	user.Status = UserInactive
+	if _, err = svc.repo.UpdateUser(ctx, user); err != nil {
+		svc.logger.Log("level", "error", "message", "failed to deactivate service account", "error", err.Error())
+	}
+
+	return
+}
+
+// serviceAccount loads a service account, failing with ErrNotFound unless it
+// belongs to the account.
+func (svc *customersService) serviceAccount(ctx context.Context, accountID, id string) (User, error) {
+	user, err := svc.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return User{}, err
+	}
+
+	if user.Interactive() {
+		return User{}, errors.Wrapf(ErrNotFound, "service account %s", id)
+	}
+
+	if _, err = svc.membership(ctx, accountID, id); err != nil {
+		return User{}, err
+	}
+
+	return user, nil
+}
+
+// ensureCanOwn fails for users who may not hold the owner role.
+func ensureCanOwn(user User) error {
+	if !user.Interactive() {
+		return errors.Wrapf(ErrFailedPrecondition, "service account %s cannot own accounts", user.ID)
+	}
+
+	return nil
+}
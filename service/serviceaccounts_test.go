@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestServiceAccounts(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	repo.accounts["acct"] = Account{ID: "acct", Status: AccountActive}
+	repo.users["alice"] = User{ID: "alice", Kind: UserHuman, Email: "alice@example.com"}
+	repo.memberships = append(repo.memberships, Membership{AccountID: "acct", UserID: "alice", Role: RoleOwner, Status: UserActive})
+	svc := newTestService(repo)
+
+	if _, err := svc.CreateServiceAccount(ctx, CreateServiceAccountRequest{AccountID: "acct", Name: "CI", Role: RoleOwner}); errors.Cause(err) != ErrInvalidArgument {
+		t.Errorf("owner role: expected ErrInvalidArgument, got %v", err)
+	}
+
+	bot, err := svc.CreateServiceAccount(ctx, CreateServiceAccountRequest{AccountID: "acct", Name: "CI"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bot.Interactive() || bot.Status != UserActive || bot.Email != "" {
+		t.Errorf("unexpected service account %+v", bot)
+	}
+
+	bots, _ := svc.ListServiceAccounts(ctx, ListServiceAccountsRequest{AccountID: "acct"})
+	if len(bots) != 1 || bots[0].ID != bot.ID {
+		t.Errorf("unexpected service accounts %+v", bots)
+	}
+	humans, _ := svc.FetchUsers(ctx, FetchUsersRequest{AccountIDs: []string{"acct"}})
+	if len(humans) != 1 || humans[0].ID != "alice" {
+		t.Errorf("service accounts should be excluded from users, got %+v", humans)
+	}
+
+	if _, err := svc.ChangeRole(ctx, ChangeRoleRequest{AccountID: "acct", UserID: bot.ID, Role: RoleOwner}); errors.Cause(err) != ErrFailedPrecondition {
+		t.Errorf("promoting to owner: expected ErrFailedPrecondition, got %v", err)
+	}
+	if _, err := svc.RequestEmailVerification(ctx, RequestEmailVerificationRequest{UserID: bot.ID}); errors.Cause(err) != ErrFailedPrecondition {
+		t.Errorf("email verification: expected ErrFailedPrecondition, got %v", err)
+	}
+	if _, err := svc.UpdateServiceAccount(ctx, UpdateServiceAccountRequest{AccountID: "acct", ID: "alice"}); errors.Cause(err) != ErrNotFound {
+		t.Errorf("updating a human: expected ErrNotFound, got %v", err)
+	}
+
+	suspended := UserSuspended
+	if bot, err = svc.UpdateServiceAccount(ctx, UpdateServiceAccountRequest{AccountID: "acct", ID: bot.ID, Status: &suspended}); err != nil || bot.Status != UserSuspended {
+		t.Errorf("suspending service account: %+v, %v", bot, err)
+	}
+
+	if err := svc.DeleteServiceAccount(ctx, DeleteServiceAccountRequest{AccountID: "acct", ID: bot.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if repo.users[bot.ID].Status != UserInactive {
+		t.Errorf("deleted service account should be inactive, got %s", repo.users[bot.ID].Status)
+	}
+	if bots, _ := svc.ListServiceAccounts(ctx, ListServiceAccountsRequest{AccountID: "acct"}); len(bots) != 0 {
+		t.Errorf("deleted service account still listed: %+v", bots)
+	}
+}
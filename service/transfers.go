@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/symptomatichq/customers/events"
+)
+
+// UserTransfer records that a user was moved from one account to another.
+type UserTransfer struct {
+	ID            string    `db:"id"`
+	UserID        string    `db:"user_id"`
+	FromAccountID string    `db:"from_account_id"`
+	ToAccountID   string    `db:"to_account_id"`
+	PreviousRole  Role      `db:"previous_role"`
+	Role          Role      `db:"role"`
+	TransferredBy string    `db:"transferred_by"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+type TransferUserRequest struct {
+	UserID        string
+	FromAccountID string
+	ToAccountID   string
+	// Role is the user's role in the destination account, defaulting to
+	// their role in the account they leave.
+	Role          Role
+	TransferredBy string
+}
+
+// TransferUser moves a user's membership from one active account to another,
+// keeping a record of the move and announcing it with a "user.transferred"
+// event.
+func (svc *customersService) TransferUser(ctx context.Context, req TransferUserRequest) (transfer UserTransfer, err error) {
+	if req.FromAccountID == req.ToAccountID {
+		return transfer, errors.Wrap(ErrInvalidArgument, "user must be transferred to a different account")
+	}
+
+	if req.Role != "" && !req.Role.Valid() {
+		return transfer, errors.Wrapf(ErrInvalidArgument, "unknown role %q", req.Role)
+	}
+
+	for _, id := range []string{req.FromAccountID, req.ToAccountID} {
+		account, err := svc.repo.GetAccountByID(ctx, id)
+		if err != nil {
+			return transfer, err
+		}
+
+		if account.Status != AccountActive {
+			return transfer, errors.Wrapf(ErrFailedPrecondition, "account %s is not active", account.ID)
+		}
+	}
+
+	user, err := svc.repo.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		return
+	}
+
+	// the memberships are checked within the transaction making the move, so
+	// concurrent changes cannot leave the source without an owner or the
+	// user with two memberships of the destination
+	err = svc.repo.Transaction(ctx, func(ctx context.Context) error {
+		from, err := svc.membership(ctx, req.FromAccountID, req.UserID)
+		if err != nil {
+			return err
+		}
+
+		existing, err := svc.membership(ctx, req.ToAccountID, req.UserID)
+		if err == nil {
+			return errors.Wrapf(ErrAlreadyExists, "user %s already holds role %s in account %s", req.UserID, existing.Role, req.ToAccountID)
+		} else if errors.Cause(err) != ErrNotFound {
+			return err
+		}
+
+		if from.Role == RoleOwner {
+			if err = svc.ensureOtherOwner(ctx, from); err != nil {
+				return err
+			}
+		}
+
+		role := req.Role
+		if role == "" {
+			role = from.Role
+		}
+
+		if role == RoleOwner {
+			if err = ensureCanOwn(user); err != nil {
+				return err
+			}
+		}
+
+		to := Membership{
+			AccountID: req.ToAccountID,
+			UserID:    req.UserID,
+			Role:      role,
+			Status:    from.Status,
+		}
+
+		transferMembership := func(ctx context.Context) (err error) {
+			transfer, err = svc.repo.TransferMembership(ctx, from, to, UserTransfer{
+				UserID:        req.UserID,
+				FromAccountID: req.FromAccountID,
+				ToAccountID:   req.ToAccountID,
+				PreviousRole:  from.Role,
+				Role:          role,
+				TransferredBy: req.TransferredBy,
+			})
+			if err != nil {
+				svc.logger.Log("level", "error", "message", "failed to transfer user", "error", err.Error())
+			}
+
+			return
+		}
+
+		// inactive members do not take up a seat in the destination account
+		if to.Status == UserActive {
+			return svc.withSeatFor(ctx, req.ToAccountID, user, transferMembership)
+		}
+
+		return transferMembership(ctx)
+	})
+	if err != nil {
+		return
+	}
+
+	svc.publish(ctx, events.Event{
+		Type:    "user.transferred",
+		Subject: transfer.UserID,
+		Data: map[string]string{
+			"from_account_id": transfer.FromAccountID,
+			"to_account_id":   transfer.ToAccountID,
+			"previous_role":   string(transfer.PreviousRole),
+			"role":            string(transfer.Role),
+			"transferred_by":  transfer.TransferredBy,
+		},
+	})
+
+	return
+}
+
+// publish announces an event. Failures are logged rather than returned since
+// the change the event describes has already been made.
+func (svc *customersService) publish(ctx context.Context, event events.Event) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+
+	if err := svc.publisher.Publish(ctx, event); err != nil {
+		svc.logger.Log("level", "error", "message", "failed to publish event", "type", event.Type, "error", err.Error())
+	}
+}
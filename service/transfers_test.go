@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestTransferUser(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	repo.accounts["from"] = Account{ID: "from", Status: AccountActive}
+	repo.accounts["to"] = Account{ID: "to", Status: AccountActive}
+	repo.accounts["closed"] = Account{ID: "closed", Status: AccountInactive}
+	repo.users["alice"] = User{ID: "alice", Kind: UserHuman}
+	repo.users["bob"] = User{ID: "bob", Kind: UserHuman}
+	repo.memberships = []Membership{
+		{AccountID: "from", UserID: "alice", Role: RoleOwner, Status: UserActive},
+		{AccountID: "from", UserID: "bob", Role: RoleAdmin, Status: UserActive},
+	}
+	publisher := &recordingPublisher{}
+	svc := newTestService(repo, WithPublisher(publisher))
+
+	if _, err := svc.TransferUser(ctx, TransferUserRequest{UserID: "bob", FromAccountID: "from", ToAccountID: "closed"}); errors.Cause(err) != ErrFailedPrecondition {
+		t.Errorf("transfer to an inactive account: expected ErrFailedPrecondition, got %v", err)
+	}
+
+	if _, err := svc.TransferUser(ctx, TransferUserRequest{UserID: "alice", FromAccountID: "from", ToAccountID: "to"}); errors.Cause(err) != ErrFailedPrecondition {
+		t.Errorf("transfer of the last owner: expected ErrFailedPrecondition, got %v", err)
+	}
+
+	transfer, err := svc.TransferUser(ctx, TransferUserRequest{UserID: "bob", FromAccountID: "from", ToAccountID: "to", TransferredBy: "alice"})
+	if err != nil || transfer.Role != RoleAdmin || transfer.PreviousRole != RoleAdmin || len(repo.transfers) != 1 {
+		t.Fatalf("unexpected transfer %+v, %v", transfer, err)
+	}
+
+	if _, err := svc.membership(ctx, "to", "bob"); err != nil {
+		t.Errorf("user should belong to the destination account: %v", err)
+	}
+	if _, err := svc.membership(ctx, "from", "bob"); errors.Cause(err) != ErrNotFound {
+		t.Errorf("user should have left the source account, got %v", err)
+	}
+
+	if len(publisher.events) != 1 || publisher.events[0].Type != "user.transferred" || publisher.events[0].Data["to_account_id"] != "to" {
+		t.Errorf("unexpected events %+v", publisher.events)
+	}
+
+	if _, err := svc.TransferUser(ctx, TransferUserRequest{UserID: "bob", FromAccountID: "to", ToAccountID: "to"}); errors.Cause(err) != ErrInvalidArgument {
+		t.Errorf("transfer within an account: expected ErrInvalidArgument, got %v", err)
+	}
+}
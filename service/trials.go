@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+
+	"github.com/symptomatichq/customers/events"
+	"github.com/symptomatichq/customers/notify"
+	"github.com/symptomatichq/customers/telemetry"
+)
+
+type ExtendTrialRequest struct {
+	AccountID string
+	EndsAt    time.Time
+}
+
+type ConvertTrialRequest struct {
+	AccountID string
+	// PlanID places the account on a plan from now when set.
+	PlanID      string
+	ConvertedBy string
+}
+
+// InTrial reports whether the account is a trial which has not been
+// converted. Its trial may have expired.
+func (a Account) InTrial() bool {
+	return a.TrialEndsAt != nil
+}
+
+// ExtendTrial moves the end of an account's trial, reactivating the account
+// if it was suspended when the trial expired.
+func (svc *customersService) ExtendTrial(ctx context.Context, req ExtendTrialRequest) (account Account, err error) {
+	now := time.Now()
+	if !req.EndsAt.After(now) {
+		return account, errors.Wrap(ErrInvalidArgument, "trial must end in the future")
+	}
+
+	account, err = svc.trialAccount(ctx, req.AccountID)
+	if err != nil {
+		return
+	}
+
+	if account.Status != AccountActive && account.Status != AccountSuspended {
+		return account, errors.Wrapf(ErrFailedPrecondition, "account %s is %s", account.ID, account.Status)
+	}
+
+	if trialExpired(account, now) {
+		account.Status = AccountActive
+	}
+	account.TrialEndsAt = &req.EndsAt
+
+	account, err = svc.repo.UpdateAccount(ctx, account)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to extend trial", "error", err.Error())
+		return
+	}
+
+	telemetry.TrialExtended()
+	svc.publish(ctx, events.Event{
+		Type:    "account.trial_extended",
+		Subject: account.ID,
+		Data:    map[string]string{"trial_ends_at": req.EndsAt.UTC().Format(time.RFC3339)},
+	})
+
+	return
+}
+
+// ConvertTrial ends an account's trial, reactivating the account if it was
+// suspended when the trial expired, and optionally places it on a plan.
+func (svc *customersService) ConvertTrial(ctx context.Context, req ConvertTrialRequest) (account Account, err error) {
+	account, err = svc.trialAccount(ctx, req.AccountID)
+	if err != nil {
+		return
+	}
+
+	if account.Status != AccountActive && account.Status != AccountSuspended {
+		return account, errors.Wrapf(ErrFailedPrecondition, "account %s is %s", account.ID, account.Status)
+	}
+
+	if req.PlanID != "" {
+		if _, err = svc.AssignPlan(ctx, AssignPlanRequest{AccountID: account.ID, PlanID: req.PlanID, AssignedBy: req.ConvertedBy}); err != nil {
+			return
+		}
+	}
+
+	now := time.Now()
+	if trialExpired(account, now) {
+		account.Status = AccountActive
+	}
+	account.TrialEndsAt = nil
+	account.TrialConvertedAt = &now
+	account, err = svc.repo.UpdateAccount(ctx, account)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to convert trial", "error", err.Error())
+		return
+	}
+
+	if account.TrialStartedAt != nil {
+		telemetry.TrialConverted(now.Sub(*account.TrialStartedAt))
+	}
+	svc.publish(ctx, events.Event{
+		Type:    "account.trial_converted",
+		Subject: account.ID,
+		Data:    map[string]string{"plan_id": req.PlanID, "converted_by": req.ConvertedBy},
+	})
+
+	return
+}
+
+// trialExpired reports whether the account was suspended because its trial
+// ended, rather than for another reason.
+func trialExpired(account Account, now time.Time) bool {
+	return account.Status == AccountSuspended && account.InTrial() && !account.TrialEndsAt.After(now)
+}
+
+func (svc *customersService) trialAccount(ctx context.Context, id string) (Account, error) {
+	account, err := svc.repo.GetAccountByID(ctx, id)
+	if err != nil {
+		return account, err
+	}
+
+	if !account.InTrial() {
+		return account, errors.Wrapf(ErrFailedPrecondition, "account %s is not in a trial", account.ID)
+	}
+
+	return account, nil
+}
+
+// TrialScheduler periodically suspends accounts whose trial has ended and
+// notifies their contacts.
+type TrialScheduler struct {
+	repo     Repository
+	notifier notify.Notifier
+	logger   log.Logger
+	interval time.Duration
+
+	once sync.Once
+	stop chan struct{}
+}
+
+// NewTrialScheduler creates a scheduler which checks for expired trials every
+// interval once started.
+func NewTrialScheduler(repo Repository, notifier notify.Notifier, logger log.Logger, interval time.Duration) *TrialScheduler {
+	return &TrialScheduler{
+		repo:     repo,
+		notifier: notifier,
+		logger:   log.With(logger, "component", "trial-scheduler"),
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start expires trials in the background until Stop is called.
+func (s *TrialScheduler) Start() {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := s.Expire(context.Background(), time.Now()); err != nil {
+					s.logger.Log("level", "error", "message", "failed to expire trials", "error", err.Error())
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts the background checks.
+func (s *TrialScheduler) Stop() {
+	s.once.Do(func() {
+		close(s.stop)
+	})
+}
+
+// Expire suspends the active accounts whose trial ended by now, returning how
+// many were suspended. Notification failures are logged and do not stop the
+// remaining accounts from being expired.
+func (s *TrialScheduler) Expire(ctx context.Context, now time.Time) (int, error) {
+	accounts, err := s.repo.SelectAccounts(ctx, map[string]interface{}{
+		"status":            AccountActive,
+		"trial_ends_before": now,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, account := range accounts {
+		account.Status = AccountSuspended
+		if _, err := s.repo.UpdateAccount(ctx, account); err != nil {
+			return expired, err
+		}
+
+		expired++
+		telemetry.TrialExpired()
+
+		if account.ContactEmail == "" {
+			continue
+		}
+
+		err := s.notifier.Notify(ctx, notify.Message{
+			Kind:    "trial_expired",
+			To:      account.ContactEmail,
+			Subject: "Your trial of " + account.Name + " has ended",
+			Data: map[string]string{
+				"account_id":    account.ID,
+				"account_name":  account.Name,
+				"trial_ends_at": account.TrialEndsAt.UTC().Format(time.RFC3339),
+			},
+		})
+		if err != nil {
+			s.logger.Log("level", "error", "message", "failed to send trial expiry", "account_id", account.ID, "error", err.Error())
+		}
+	}
+
+	return expired, nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/pkg/errors"
+)
+
+func TestTrials(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	svc := newTestService(repo)
+
+	past := time.Now().Add(-time.Hour)
+	if _, err := svc.CreateAccount(ctx, CreateAccountRequest{Name: "Late", ContactEmail: "late@example.com", TrialEndsAt: &past}); errors.Cause(err) != ErrInvalidArgument {
+		t.Errorf("trial ending in the past: expected ErrInvalidArgument, got %v", err)
+	}
+
+	ends := time.Now().Add(time.Hour)
+	trial, err := svc.CreateAccount(ctx, CreateAccountRequest{Name: "Trial", ContactEmail: "trial@example.com", TrialEndsAt: &ends})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !trial.InTrial() || trial.TrialStartedAt == nil {
+		t.Fatalf("expected a trial account, got %+v", trial)
+	}
+	repo.accounts["paid"] = Account{ID: "paid", Status: AccountActive}
+
+	notifier := &recordingNotifier{}
+	scheduler := NewTrialScheduler(repo, notifier, log.NewNopLogger(), time.Hour)
+	if n, err := scheduler.Expire(ctx, time.Now()); err != nil || n != 0 {
+		t.Fatalf("expected no trials to expire yet, got %d, %v", n, err)
+	}
+	if n, err := scheduler.Expire(ctx, ends.Add(time.Minute)); err != nil || n != 1 {
+		t.Fatalf("expected the trial to expire, got %d, %v", n, err)
+	}
+	if a := repo.accounts[trial.ID]; a.Status != AccountSuspended {
+		t.Errorf("expected expired trial to be suspended, got %s", a.Status)
+	}
+	if len(notifier.messages) != 1 || notifier.messages[0].Kind != "trial_expired" || notifier.messages[0].To != "trial@example.com" {
+		t.Errorf("unexpected notifications %+v", notifier.messages)
+	}
+
+	// Expire ran ahead of the clock, so backdate the trial to when it expired
+	repo.accounts[trial.ID] = func(a Account) Account { a.TrialEndsAt = &past; return a }(repo.accounts[trial.ID])
+	extended, err := svc.ExtendTrial(ctx, ExtendTrialRequest{AccountID: trial.ID, EndsAt: time.Now().Add(24 * time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if extended.Status != AccountActive {
+		t.Errorf("extending an expired trial should reactivate it, got %s", extended.Status)
+	}
+
+	plan, err := svc.CreatePlan(ctx, CreatePlanRequest{Key: "basic", Name: "Basic"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	converted, err := svc.ConvertTrial(ctx, ConvertTrialRequest{AccountID: trial.ID, PlanID: plan.ID, ConvertedBy: "staff"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if converted.InTrial() || converted.TrialConvertedAt == nil {
+		t.Errorf("expected a converted account, got %+v", converted)
+	}
+	if len(repo.accountPlans) != 1 || repo.accountPlans[0].AccountID != trial.ID {
+		t.Errorf("expected the account to be placed on the plan, got %+v", repo.accountPlans)
+	}
+
+	if _, err := svc.ConvertTrial(ctx, ConvertTrialRequest{AccountID: "paid"}); errors.Cause(err) != ErrFailedPrecondition {
+		t.Errorf("converting a paid account: expected ErrFailedPrecondition, got %v", err)
+	}
+}
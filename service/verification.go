@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/symptomatichq/customers/notify"
+)
+
+const (
+	// verificationTTL is how long a verification token may be used.
+	verificationTTL = 24 * time.Hour
+	// verificationResendInterval is the minimum time between two
+	// verification emails to the same user.
+	verificationResendInterval = time.Minute
+	// verificationHourlyLimit caps the verification emails sent to a user
+	// within an hour.
+	verificationHourlyLimit = 5
+)
+
+// EmailVerification is a token sent to a user's email address to prove they
+// control it. Only a hash of the token is stored.
+type EmailVerification struct {
+	ID         string     `db:"id"`
+	UserID     string     `db:"user_id"`
+	Email      string     `db:"email"`
+	TokenHash  string     `db:"token_hash"`
+	ExpiresAt  time.Time  `db:"expires_at"`
+	ConsumedAt *time.Time `db:"consumed_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+}
+
+type RequestEmailVerificationRequest struct {
+	UserID string
+}
+
+type ConfirmEmailVerificationRequest struct {
+	Token string
+}
+
+// RequestEmailVerification sends a new verification token to the user's
+// current email address, subject to rate limits.
+func (svc *customersService) RequestEmailVerification(ctx context.Context, req RequestEmailVerificationRequest) (verification EmailVerification, err error) {
+	user, err := svc.repo.GetUserByID(ctx, req.UserID)
+	if err != nil {
+		return
+	}
+
+	if !user.Interactive() {
+		return verification, errors.Wrapf(ErrFailedPrecondition, "user %s is a service account", user.ID)
+	}
+
+	if user.VerifiedAt != nil {
+		return verification, errors.Wrapf(ErrFailedPrecondition, "user %s has already verified their email", user.ID)
+	}
+
+	now := time.Now()
+	recent, err := svc.repo.SelectEmailVerifications(ctx, map[string]interface{}{
+		"user_id":       user.ID,
+		"created_after": now.Add(-time.Hour),
+	})
+	if err != nil {
+		return
+	}
+
+	if len(recent) >= verificationHourlyLimit {
+		return verification, errors.Wrapf(ErrResourceExhausted, "at most %d verification emails may be sent per hour", verificationHourlyLimit)
+	}
+
+	for _, v := range recent {
+		if now.Sub(v.CreatedAt) < verificationResendInterval {
+			return verification, errors.Wrapf(ErrResourceExhausted, "verification emails may only be resent every %s", verificationResendInterval)
+		}
+	}
+
+	return svc.sendVerification(ctx, user)
+}
+
+// ConfirmEmailVerification redeems a verification token, marking the user's
+// email as verified and activating users that were waiting on verification.
+func (svc *customersService) ConfirmEmailVerification(ctx context.Context, req ConfirmEmailVerificationRequest) (user User, err error) {
+	verifications, err := svc.repo.SelectEmailVerifications(ctx, map[string]interface{}{
+		"token_hash": hashToken(req.Token),
+	})
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	if len(verifications) == 0 || verifications[0].ConsumedAt != nil || !now.Before(verifications[0].ExpiresAt) {
+		return user, errors.Wrap(ErrNotFound, "verification token is invalid or has expired")
+	}
+	verification := verifications[0]
+
+	user, err = svc.repo.GetUserByID(ctx, verification.UserID)
+	if err != nil {
+		return
+	}
+
+	// the address changed after the token was sent
+	if user.Email != verification.Email {
+		return User{}, errors.Wrap(ErrNotFound, "verification token is invalid or has expired")
+	}
+
+	verification.ConsumedAt = &now
+	if _, err = svc.repo.UpdateEmailVerification(ctx, verification); err != nil {
+		svc.logger.Log("level", "error", "message", "failed to consume verification", "error", err.Error())
+		return
+	}
+
+	user.VerifiedAt = &now
+	if user.Status == UserPending {
+		user.Status = UserActive
+	}
+
+	user, err = svc.repo.UpdateUser(ctx, user)
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to verify user", "error", err.Error())
+	}
+
+	return
+}
+
+// initialUserStatus is the status given to newly created users.
+func (svc *customersService) initialUserStatus() UserStatus {
+	if svc.requireVerifiedEmail {
+		return UserPending
+	}
+
+	return UserActive
+}
+
+// sendInitialVerification emails new users a verification token when
+// verification is required. Failures are logged, the user can request
+// another token.
+func (svc *customersService) sendInitialVerification(ctx context.Context, user User) {
+	if !svc.requireVerifiedEmail || user.Email == "" || !user.Interactive() {
+		return
+	}
+
+	if _, err := svc.sendVerification(ctx, user); err != nil {
+		svc.logger.Log("level", "error", "message", "failed to send verification email", "user_id", user.ID, "error", err.Error())
+	}
+}
+
+func (svc *customersService) sendVerification(ctx context.Context, user User) (verification EmailVerification, err error) {
+	token, hash, err := newToken()
+	if err != nil {
+		return
+	}
+
+	verification, err = svc.repo.InsertEmailVerification(ctx, EmailVerification{
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(verificationTTL),
+	})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to insert verification", "error", err.Error())
+		return
+	}
+
+	err = svc.notifier.Notify(ctx, notify.Message{
+		Kind:    "email_verification",
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Data: map[string]string{
+			"user_id": user.ID,
+			"name":    user.Name,
+			"token":   token,
+		},
+	})
+	if err != nil {
+		svc.logger.Log("level", "error", "message", "failed to deliver verification", "error", err.Error())
+	}
+
+	return
+}
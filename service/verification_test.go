@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestEmailVerification(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepository()
+	repo.accounts["acct"] = Account{ID: "acct", Status: AccountActive}
+	svc := newTestService(repo, WithVerifiedEmailRequired(true))
+	notifier := svc.notifier.(*recordingNotifier)
+
+	user, err := svc.CreateUser(ctx, CreateUserRequest{AccountID: "acct", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Status != UserPending {
+		t.Errorf("new users should be pending verification, got %q", user.Status)
+	}
+	if len(notifier.messages) != 1 {
+		t.Fatalf("expected a verification email, got %d messages", len(notifier.messages))
+	}
+
+	if _, err := svc.ChangeMembershipStatus(ctx, ChangeMembershipStatusRequest{AccountID: "acct", UserID: user.ID, Status: UserSuspended}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.ChangeMembershipStatus(ctx, ChangeMembershipStatusRequest{AccountID: "acct", UserID: user.ID, Status: UserActive}); errors.Cause(err) != ErrFailedPrecondition {
+		t.Errorf("activating an unverified user: expected ErrFailedPrecondition, got %v", err)
+	}
+
+	if _, err := svc.RequestEmailVerification(ctx, RequestEmailVerificationRequest{UserID: user.ID}); errors.Cause(err) != ErrResourceExhausted {
+		t.Errorf("immediate resend: expected ErrResourceExhausted, got %v", err)
+	}
+
+	verified, err := svc.ConfirmEmailVerification(ctx, ConfirmEmailVerificationRequest{Token: notifier.messages[0].Data["token"]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verified.VerifiedAt == nil || verified.Status != UserActive {
+		t.Errorf("unexpected user after verification %+v", verified)
+	}
+
+	if _, err := svc.ConfirmEmailVerification(ctx, ConfirmEmailVerificationRequest{Token: notifier.messages[0].Data["token"]}); errors.Cause(err) != ErrNotFound {
+		t.Errorf("reused token: expected ErrNotFound, got %v", err)
+	}
+
+	email := "alice@example.org"
+	changed, err := svc.UpdateUser(ctx, UpdateUserRequest{ID: user.ID, Email: &email})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed.VerifiedAt != nil || changed.Status != UserPending {
+		t.Errorf("changing the email should require verifying it again, got %+v", changed)
+	}
+	if len(notifier.messages) != 2 || notifier.messages[1].To != email {
+		t.Fatalf("expected a verification email to the new address, got %+v", notifier.messages)
+	}
+
+	if verified, err = svc.ConfirmEmailVerification(ctx, ConfirmEmailVerificationRequest{Token: notifier.messages[1].Data["token"]}); err != nil {
+		t.Fatal(err)
+	}
+	if verified.Status != UserActive {
+		t.Errorf("verifying the new address should reactivate the user, got %q", verified.Status)
+	}
+}
@@ -1,6 +1,8 @@
 package telemetry
 
 import (
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
@@ -16,3 +18,48 @@ var (
 func DoSomething() {
 	opsProcessed.Inc()
 }
+
+var (
+	trialsStarted = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "customers_trials_started_total",
+		Help: "The total number of accounts which started a trial",
+	})
+	trialsExtended = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "customers_trials_extended_total",
+		Help: "The total number of trials which were extended",
+	})
+	trialsExpired = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "customers_trials_expired_total",
+		Help: "The total number of trials which expired and were suspended",
+	})
+	trialsConverted = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "customers_trials_converted_total",
+		Help: "The total number of trials converted to paying accounts",
+	})
+	trialConversionDays = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "customers_trial_conversion_days",
+		Help:    "Days from the start of a trial until it was converted",
+		Buckets: []float64{1, 3, 7, 14, 30, 60, 90},
+	})
+)
+
+// TrialStarted counts an account starting a trial.
+func TrialStarted() {
+	trialsStarted.Inc()
+}
+
+// TrialExtended counts a trial being extended.
+func TrialExtended() {
+	trialsExtended.Inc()
+}
+
+// TrialExpired counts a trial expiring.
+func TrialExpired() {
+	trialsExpired.Inc()
+}
+
+// TrialConverted counts a trial converting after running for d.
+func TrialConverted(d time.Duration) {
+	trialsConverted.Inc()
+	trialConversionDays.Observe(d.Hours() / 24)
+}
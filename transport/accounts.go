@@ -0,0 +1,89 @@
+package transport
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/symptomatichq/customers/protos/customers"
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeGRPCUpdateAccountEndpoint creates UpdateAccount Endpoint for GRPC
+func MakeGRPCUpdateAccountEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.UpdateAccountRequest)
+		account, err := svc.UpdateAccount(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return account, nil
+	}
+}
+
+// MakeGRPCChangeAccountStatusEndpoint creates ChangeAccountStatus Endpoint for GRPC
+func MakeGRPCChangeAccountStatusEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ChangeAccountStatusRequest)
+		account, err := svc.ChangeAccountStatus(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return account, nil
+	}
+}
+
+// UpdateAccount
+func (s *grpcServer) UpdateAccount(ctx context.Context, req *pb.UpdateAccountRequest) (*pb.UpdateAccountResponse, error) {
+	_, resp, err := s.updateAccount.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.UpdateAccountResponse), nil
+}
+
+// ChangeAccountStatus
+func (s *grpcServer) ChangeAccountStatus(ctx context.Context, req *pb.ChangeAccountStatusRequest) (*pb.ChangeAccountStatusResponse, error) {
+	_, resp, err := s.changeAccountStatus.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.ChangeAccountStatusResponse), nil
+}
+
+// decodeGrpcUpdateAccountRequest decodes UpdateAccount requests
+func decodeGrpcUpdateAccountRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.UpdateAccountRequest)
+	return service.UpdateAccountRequest{
+		ID:           req.ID,
+		Labels:       req.Labels,
+		RemoveLabels: req.RemoveLabels,
+		Metadata:     decodeMetadata(req.Metadata),
+	}, nil
+}
+
+// encodeGrpcUpdateAccountResponse encodes UpdateAccount responses
+func encodeGrpcUpdateAccountResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.UpdateAccountResponse{
+		Account: *encodeAccount(r.(service.Account)),
+	}, nil
+}
+
+// decodeGrpcChangeAccountStatusRequest decodes ChangeAccountStatus requests
+func decodeGrpcChangeAccountStatusRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ChangeAccountStatusRequest)
+	return service.ChangeAccountStatusRequest{ID: req.ID, Status: decodeAccountStatus(req.Status)}, nil
+}
+
+// encodeGrpcChangeAccountStatusResponse encodes ChangeAccountStatus responses
+func encodeGrpcChangeAccountStatusResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.ChangeAccountStatusResponse{
+		Account: *encodeAccount(r.(service.Account)),
+	}, nil
+}
@@ -0,0 +1,250 @@
+package transport
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/symptomatichq/customers/protos/customers"
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeGRPCCreateAPIKeyEndpoint creates CreateAPIKey Endpoint for GRPC
+func MakeGRPCCreateAPIKeyEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.CreateAPIKeyRequest)
+		issued, err := svc.CreateAPIKey(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return issued, nil
+	}
+}
+
+// MakeGRPCListAPIKeysEndpoint creates ListAPIKeys Endpoint for GRPC
+func MakeGRPCListAPIKeysEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListAPIKeysRequest)
+		keys, err := svc.ListAPIKeys(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return keys, nil
+	}
+}
+
+// MakeGRPCRotateAPIKeyEndpoint creates RotateAPIKey Endpoint for GRPC
+func MakeGRPCRotateAPIKeyEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.RotateAPIKeyRequest)
+		issued, err := svc.RotateAPIKey(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return issued, nil
+	}
+}
+
+// MakeGRPCRevokeAPIKeyEndpoint creates RevokeAPIKey Endpoint for GRPC
+func MakeGRPCRevokeAPIKeyEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.RevokeAPIKeyRequest)
+		key, err := svc.RevokeAPIKey(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return key, nil
+	}
+}
+
+// MakeGRPCVerifyAPIKeyEndpoint creates VerifyAPIKey Endpoint for GRPC
+func MakeGRPCVerifyAPIKeyEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.VerifyAPIKeyRequest)
+		verification, err := svc.VerifyAPIKey(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return verification, nil
+	}
+}
+
+// CreateAPIKey
+func (s *grpcServer) CreateAPIKey(ctx context.Context, req *pb.CreateAPIKeyRequest) (*pb.CreateAPIKeyResponse, error) {
+	_, resp, err := s.createAPIKey.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.CreateAPIKeyResponse), nil
+}
+
+// ListAPIKeys
+func (s *grpcServer) ListAPIKeys(ctx context.Context, req *pb.ListAPIKeysRequest) (*pb.ListAPIKeysResponse, error) {
+	_, resp, err := s.listAPIKeys.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.ListAPIKeysResponse), nil
+}
+
+// RotateAPIKey
+func (s *grpcServer) RotateAPIKey(ctx context.Context, req *pb.RotateAPIKeyRequest) (*pb.RotateAPIKeyResponse, error) {
+	_, resp, err := s.rotateAPIKey.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.RotateAPIKeyResponse), nil
+}
+
+// RevokeAPIKey
+func (s *grpcServer) RevokeAPIKey(ctx context.Context, req *pb.RevokeAPIKeyRequest) (*pb.RevokeAPIKeyResponse, error) {
+	_, resp, err := s.revokeAPIKey.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.RevokeAPIKeyResponse), nil
+}
+
+// VerifyAPIKey
+func (s *grpcServer) VerifyAPIKey(ctx context.Context, req *pb.VerifyAPIKeyRequest) (*pb.VerifyAPIKeyResponse, error) {
+	_, resp, err := s.verifyAPIKey.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.VerifyAPIKeyResponse), nil
+}
+
+// decodeGrpcCreateAPIKeyRequest decodes CreateAPIKey requests
+func decodeGrpcCreateAPIKeyRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.CreateAPIKeyRequest)
+	return service.CreateAPIKeyRequest{
+		AccountID: req.AccountID,
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	}, nil
+}
+
+// encodeGrpcCreateAPIKeyResponse encodes CreateAPIKey responses
+func encodeGrpcCreateAPIKeyResponse(_ context.Context, r interface{}) (interface{}, error) {
+	issued := r.(service.IssuedAPIKey)
+	return &pb.CreateAPIKeyResponse{
+		Key:    *encodeAPIKey(issued.APIKey),
+		Secret: issued.Secret,
+	}, nil
+}
+
+// decodeGrpcListAPIKeysRequest decodes ListAPIKeys requests
+func decodeGrpcListAPIKeysRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ListAPIKeysRequest)
+
+	var status service.APIKeyStatus
+	switch req.Status {
+	case pb.APIKey_ACTIVE:
+		status = service.APIKeyActive
+	case pb.APIKey_REVOKED:
+		status = service.APIKeyRevoked
+	}
+
+	return service.ListAPIKeysRequest{AccountID: req.AccountID, Status: status}, nil
+}
+
+// encodeGrpcListAPIKeysResponse encodes ListAPIKeys responses
+func encodeGrpcListAPIKeysResponse(_ context.Context, r interface{}) (interface{}, error) {
+	keys := []pb.APIKey{}
+	for _, key := range r.([]service.APIKey) {
+		keys = append(keys, *encodeAPIKey(key))
+	}
+
+	return &pb.ListAPIKeysResponse{
+		Keys: keys,
+	}, nil
+}
+
+// decodeGrpcRotateAPIKeyRequest decodes RotateAPIKey requests
+func decodeGrpcRotateAPIKeyRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.RotateAPIKeyRequest)
+	return service.RotateAPIKeyRequest{
+		AccountID:   req.AccountID,
+		ID:          req.ID,
+		GracePeriod: req.GracePeriod,
+	}, nil
+}
+
+// encodeGrpcRotateAPIKeyResponse encodes RotateAPIKey responses
+func encodeGrpcRotateAPIKeyResponse(_ context.Context, r interface{}) (interface{}, error) {
+	issued := r.(service.IssuedAPIKey)
+	return &pb.RotateAPIKeyResponse{
+		Key:    *encodeAPIKey(issued.APIKey),
+		Secret: issued.Secret,
+	}, nil
+}
+
+// decodeGrpcRevokeAPIKeyRequest decodes RevokeAPIKey requests
+func decodeGrpcRevokeAPIKeyRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.RevokeAPIKeyRequest)
+	return service.RevokeAPIKeyRequest{AccountID: req.AccountID, ID: req.ID}, nil
+}
+
+// encodeGrpcRevokeAPIKeyResponse encodes RevokeAPIKey responses
+func encodeGrpcRevokeAPIKeyResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.RevokeAPIKeyResponse{
+		Key: *encodeAPIKey(r.(service.APIKey)),
+	}, nil
+}
+
+// decodeGrpcVerifyAPIKeyRequest decodes VerifyAPIKey requests
+func decodeGrpcVerifyAPIKeyRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.VerifyAPIKeyRequest)
+	return service.VerifyAPIKeyRequest{Key: req.Key, Scope: req.Scope}, nil
+}
+
+// encodeGrpcVerifyAPIKeyResponse encodes VerifyAPIKey responses
+func encodeGrpcVerifyAPIKeyResponse(_ context.Context, r interface{}) (interface{}, error) {
+	verification := r.(service.APIKeyVerification)
+	return &pb.VerifyAPIKeyResponse{
+		KeyID:     verification.KeyID,
+		AccountID: verification.AccountID,
+		Scopes:    verification.Scopes,
+	}, nil
+}
+
+// encodeAPIKey serializes an API key into its protobuf message, leaving out
+// its salt and hash
+func encodeAPIKey(k service.APIKey) *pb.APIKey {
+	encoded := &pb.APIKey{
+		ID:         k.ID,
+		AccountID:  k.AccountID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.Scopes,
+		CreatedBy:  k.CreatedBy,
+		ReplacedBy: stringValue(k.ReplacedBy),
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+		UpdatedAt:  k.UpdatedAt,
+		CreatedAt:  k.CreatedAt,
+	}
+
+	switch k.Status {
+	case service.APIKeyActive:
+		encoded.Status = pb.APIKey_ACTIVE
+	case service.APIKeyRevoked:
+		encoded.Status = pb.APIKey_REVOKED
+	}
+
+	return encoded
+}
@@ -0,0 +1,273 @@
+package transport
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/symptomatichq/customers/protos/customers"
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeGRPCDefineCustomFieldEndpoint creates DefineCustomField Endpoint for GRPC
+func MakeGRPCDefineCustomFieldEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.DefineCustomFieldRequest)
+		field, err := svc.DefineCustomField(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return field, nil
+	}
+}
+
+// MakeGRPCListCustomFieldsEndpoint creates ListCustomFields Endpoint for GRPC
+func MakeGRPCListCustomFieldsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListCustomFieldsRequest)
+		fields, err := svc.ListCustomFields(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return fields, nil
+	}
+}
+
+// MakeGRPCUpdateCustomFieldEndpoint creates UpdateCustomField Endpoint for GRPC
+func MakeGRPCUpdateCustomFieldEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.UpdateCustomFieldRequest)
+		field, err := svc.UpdateCustomField(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return field, nil
+	}
+}
+
+// MakeGRPCDeleteCustomFieldEndpoint creates DeleteCustomField Endpoint for GRPC
+func MakeGRPCDeleteCustomFieldEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.DeleteCustomFieldRequest)
+		if err := svc.DeleteCustomField(ctx, req); err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return req, nil
+	}
+}
+
+// MakeGRPCSetCustomFieldValuesEndpoint creates SetCustomFieldValues Endpoint for GRPC
+func MakeGRPCSetCustomFieldValuesEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.SetCustomFieldValuesRequest)
+		membership, err := svc.SetCustomFieldValues(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return membership, nil
+	}
+}
+
+// DefineCustomField
+func (s *grpcServer) DefineCustomField(ctx context.Context, req *pb.DefineCustomFieldRequest) (*pb.DefineCustomFieldResponse, error) {
+	_, resp, err := s.defineCustomField.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.DefineCustomFieldResponse), nil
+}
+
+// ListCustomFields
+func (s *grpcServer) ListCustomFields(ctx context.Context, req *pb.ListCustomFieldsRequest) (*pb.ListCustomFieldsResponse, error) {
+	_, resp, err := s.listCustomFields.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.ListCustomFieldsResponse), nil
+}
+
+// UpdateCustomField
+func (s *grpcServer) UpdateCustomField(ctx context.Context, req *pb.UpdateCustomFieldRequest) (*pb.UpdateCustomFieldResponse, error) {
+	_, resp, err := s.updateCustomField.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.UpdateCustomFieldResponse), nil
+}
+
+// DeleteCustomField
+func (s *grpcServer) DeleteCustomField(ctx context.Context, req *pb.DeleteCustomFieldRequest) (*pb.DeleteCustomFieldResponse, error) {
+	_, resp, err := s.deleteCustomField.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.DeleteCustomFieldResponse), nil
+}
+
+// SetCustomFieldValues
+func (s *grpcServer) SetCustomFieldValues(ctx context.Context, req *pb.SetCustomFieldValuesRequest) (*pb.SetCustomFieldValuesResponse, error) {
+	_, resp, err := s.setCustomFieldValues.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.SetCustomFieldValuesResponse), nil
+}
+
+// decodeGrpcDefineCustomFieldRequest decodes DefineCustomField requests
+func decodeGrpcDefineCustomFieldRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.DefineCustomFieldRequest)
+	return service.DefineCustomFieldRequest{
+		AccountID:  req.AccountID,
+		Key:        req.Key,
+		Label:      req.Label,
+		Type:       decodeCustomFieldType(req.Type),
+		Required:   req.Required,
+		EnumValues: req.EnumValues,
+		Pattern:    req.Pattern,
+	}, nil
+}
+
+// encodeGrpcDefineCustomFieldResponse encodes DefineCustomField responses
+func encodeGrpcDefineCustomFieldResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.DefineCustomFieldResponse{
+		Field: *encodeCustomField(r.(service.CustomField)),
+	}, nil
+}
+
+// decodeGrpcListCustomFieldsRequest decodes ListCustomFields requests
+func decodeGrpcListCustomFieldsRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ListCustomFieldsRequest)
+	return service.ListCustomFieldsRequest{AccountID: req.AccountID}, nil
+}
+
+// encodeGrpcListCustomFieldsResponse encodes ListCustomFields responses
+func encodeGrpcListCustomFieldsResponse(_ context.Context, r interface{}) (interface{}, error) {
+	fields := []pb.CustomField{}
+	for _, field := range r.([]service.CustomField) {
+		fields = append(fields, *encodeCustomField(field))
+	}
+
+	return &pb.ListCustomFieldsResponse{
+		Fields: fields,
+	}, nil
+}
+
+// decodeGrpcUpdateCustomFieldRequest decodes UpdateCustomField requests
+func decodeGrpcUpdateCustomFieldRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.UpdateCustomFieldRequest)
+
+	update := service.UpdateCustomFieldRequest{
+		AccountID:  req.AccountID,
+		Key:        req.Key,
+		EnumValues: req.EnumValues,
+	}
+	if req.Label != nil {
+		update.Label = &req.Label.Value
+	}
+	if req.Required != nil {
+		update.Required = &req.Required.Value
+	}
+	if req.Pattern != nil {
+		update.Pattern = &req.Pattern.Value
+	}
+
+	return update, nil
+}
+
+// encodeGrpcUpdateCustomFieldResponse encodes UpdateCustomField responses
+func encodeGrpcUpdateCustomFieldResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.UpdateCustomFieldResponse{
+		Field: *encodeCustomField(r.(service.CustomField)),
+	}, nil
+}
+
+// decodeGrpcDeleteCustomFieldRequest decodes DeleteCustomField requests
+func decodeGrpcDeleteCustomFieldRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.DeleteCustomFieldRequest)
+	return service.DeleteCustomFieldRequest{AccountID: req.AccountID, Key: req.Key}, nil
+}
+
+// encodeGrpcDeleteCustomFieldResponse encodes DeleteCustomField responses
+func encodeGrpcDeleteCustomFieldResponse(_ context.Context, _ interface{}) (interface{}, error) {
+	return &pb.DeleteCustomFieldResponse{}, nil
+}
+
+// decodeGrpcSetCustomFieldValuesRequest decodes SetCustomFieldValues requests
+func decodeGrpcSetCustomFieldValuesRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.SetCustomFieldValuesRequest)
+	return service.SetCustomFieldValuesRequest{
+		AccountID: req.AccountID,
+		UserID:    req.UserID,
+		Values:    req.Values,
+	}, nil
+}
+
+// encodeGrpcSetCustomFieldValuesResponse encodes SetCustomFieldValues responses
+func encodeGrpcSetCustomFieldValuesResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.SetCustomFieldValuesResponse{
+		Membership: *encodeMembership(r.(service.Membership)),
+	}, nil
+}
+
+func encodeCustomField(f service.CustomField) *pb.CustomField {
+	return &pb.CustomField{
+		ID:         f.ID,
+		AccountID:  f.AccountID,
+		Key:        f.Key,
+		Label:      f.Label,
+		Type:       encodeCustomFieldType(f.Type),
+		Required:   f.Required,
+		EnumValues: f.EnumValues,
+		Pattern:    f.Pattern,
+		UpdatedAt:  f.UpdatedAt,
+		CreatedAt:  f.CreatedAt,
+	}
+}
+
+func encodeCustomFieldType(t service.CustomFieldType) pb.CustomField_Type {
+	switch t {
+	case service.CustomFieldString:
+		return pb.CustomField_STRING
+	case service.CustomFieldNumber:
+		return pb.CustomField_NUMBER
+	case service.CustomFieldBoolean:
+		return pb.CustomField_BOOLEAN
+	case service.CustomFieldDate:
+		return pb.CustomField_DATE
+	case service.CustomFieldEnum:
+		return pb.CustomField_ENUM
+	}
+
+	return pb.CustomField_TYPE_UNSPECIFIED
+}
+
+// decodeCustomFieldType leaves the type empty when unspecified, so that the
+// service rejects it.
+func decodeCustomFieldType(t pb.CustomField_Type) service.CustomFieldType {
+	switch t {
+	case pb.CustomField_STRING:
+		return service.CustomFieldString
+	case pb.CustomField_NUMBER:
+		return service.CustomFieldNumber
+	case pb.CustomField_BOOLEAN:
+		return service.CustomFieldBoolean
+	case pb.CustomField_DATE:
+		return service.CustomFieldDate
+	case pb.CustomField_ENUM:
+		return service.CustomFieldEnum
+	}
+
+	return ""
+}
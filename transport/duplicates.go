@@ -0,0 +1,128 @@
+package transport
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/symptomatichq/customers/protos/customers"
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeGRPCListDuplicateAccountsEndpoint creates ListDuplicateAccounts Endpoint for GRPC
+func MakeGRPCListDuplicateAccountsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListDuplicateAccountsRequest)
+		candidates, err := svc.ListDuplicateAccounts(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return candidates, nil
+	}
+}
+
+// MakeGRPCMergeAccountsEndpoint creates MergeAccounts Endpoint for GRPC
+func MakeGRPCMergeAccountsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.MergeAccountsRequest)
+		merge, err := svc.MergeAccounts(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return merge, nil
+	}
+}
+
+// ListDuplicateAccounts
+func (s *grpcServer) ListDuplicateAccounts(ctx context.Context, req *pb.ListDuplicateAccountsRequest) (*pb.ListDuplicateAccountsResponse, error) {
+	_, resp, err := s.listDuplicateAccounts.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.ListDuplicateAccountsResponse), nil
+}
+
+// MergeAccounts
+func (s *grpcServer) MergeAccounts(ctx context.Context, req *pb.MergeAccountsRequest) (*pb.MergeAccountsResponse, error) {
+	_, resp, err := s.mergeAccounts.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.MergeAccountsResponse), nil
+}
+
+// decodeGrpcListDuplicateAccountsRequest decodes ListDuplicateAccounts requests
+func decodeGrpcListDuplicateAccountsRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ListDuplicateAccountsRequest)
+
+	var status service.DuplicateStatus
+	switch req.Status {
+	case pb.DuplicateCandidate_OPEN:
+		status = service.DuplicateOpen
+	case pb.DuplicateCandidate_DISMISSED:
+		status = service.DuplicateDismissed
+	case pb.DuplicateCandidate_MERGED:
+		status = service.DuplicateMerged
+	}
+
+	return service.ListDuplicateAccountsRequest{
+		AccountID: req.AccountID,
+		Status:    status,
+		MinScore:  req.MinScore,
+	}, nil
+}
+
+// encodeGrpcListDuplicateAccountsResponse encodes ListDuplicateAccounts responses
+func encodeGrpcListDuplicateAccountsResponse(_ context.Context, r interface{}) (interface{}, error) {
+	candidates := []pb.DuplicateCandidate{}
+	for _, candidate := range r.([]service.DuplicateCandidate) {
+		encoded := pb.DuplicateCandidate{
+			AccountID:   candidate.AccountID,
+			DuplicateID: candidate.DuplicateID,
+			Score:       candidate.Score,
+			Reasons:     candidate.Reasons,
+			UpdatedAt:   candidate.UpdatedAt,
+			CreatedAt:   candidate.CreatedAt,
+		}
+		switch candidate.Status {
+		case service.DuplicateOpen:
+			encoded.Status = pb.DuplicateCandidate_OPEN
+		case service.DuplicateDismissed:
+			encoded.Status = pb.DuplicateCandidate_DISMISSED
+		case service.DuplicateMerged:
+			encoded.Status = pb.DuplicateCandidate_MERGED
+		}
+		candidates = append(candidates, encoded)
+	}
+
+	return &pb.ListDuplicateAccountsResponse{
+		Candidates: candidates,
+	}, nil
+}
+
+// decodeGrpcMergeAccountsRequest decodes MergeAccounts requests
+func decodeGrpcMergeAccountsRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.MergeAccountsRequest)
+	return service.MergeAccountsRequest{SourceID: req.SourceID, TargetID: req.TargetID}, nil
+}
+
+// encodeGrpcMergeAccountsResponse encodes MergeAccounts responses
+func encodeGrpcMergeAccountsResponse(_ context.Context, r interface{}) (interface{}, error) {
+	merge := r.(service.AccountMerge)
+	return &pb.MergeAccountsResponse{
+		Merge: pb.AccountMerge{
+			ID:         merge.ID,
+			SourceID:   merge.SourceID,
+			TargetID:   merge.TargetID,
+			MergedBy:   merge.MergedBy,
+			MovedUsers: int32(merge.MovedUsers),
+			CreatedAt:  merge.CreatedAt,
+		},
+	}, nil
+}
@@ -0,0 +1,405 @@
+package transport
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/symptomatichq/customers/protos/customers"
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeGRPCCreateFeatureFlagEndpoint creates CreateFeatureFlag Endpoint for GRPC
+func MakeGRPCCreateFeatureFlagEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.CreateFeatureFlagRequest)
+		flag, err := svc.CreateFeatureFlag(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return flag, nil
+	}
+}
+
+// MakeGRPCListFeatureFlagsEndpoint creates ListFeatureFlags Endpoint for GRPC
+func MakeGRPCListFeatureFlagsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListFeatureFlagsRequest)
+		flags, err := svc.ListFeatureFlags(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return flags, nil
+	}
+}
+
+// MakeGRPCUpdateFeatureFlagEndpoint creates UpdateFeatureFlag Endpoint for GRPC
+func MakeGRPCUpdateFeatureFlagEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.UpdateFeatureFlagRequest)
+		flag, err := svc.UpdateFeatureFlag(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return flag, nil
+	}
+}
+
+// MakeGRPCDeleteFeatureFlagEndpoint creates DeleteFeatureFlag Endpoint for GRPC
+func MakeGRPCDeleteFeatureFlagEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.DeleteFeatureFlagRequest)
+		if err := svc.DeleteFeatureFlag(ctx, req); err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return req, nil
+	}
+}
+
+// MakeGRPCSetFlagOverrideEndpoint creates SetFlagOverride Endpoint for GRPC
+func MakeGRPCSetFlagOverrideEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.SetFlagOverrideRequest)
+		override, err := svc.SetFlagOverride(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return override, nil
+	}
+}
+
+// MakeGRPCRemoveFlagOverrideEndpoint creates RemoveFlagOverride Endpoint for GRPC
+func MakeGRPCRemoveFlagOverrideEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.RemoveFlagOverrideRequest)
+		if err := svc.RemoveFlagOverride(ctx, req); err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return req, nil
+	}
+}
+
+// MakeGRPCListFlagOverridesEndpoint creates ListFlagOverrides Endpoint for GRPC
+func MakeGRPCListFlagOverridesEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListFlagOverridesRequest)
+		overrides, err := svc.ListFlagOverrides(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return overrides, nil
+	}
+}
+
+// MakeGRPCEvaluateFlagsEndpoint creates EvaluateFlags Endpoint for GRPC
+func MakeGRPCEvaluateFlagsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.EvaluateFlagsRequest)
+		evaluations, err := svc.EvaluateFlags(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return evaluations, nil
+	}
+}
+
+// MakeGRPCWatchFlagsEndpoint creates WatchFlags Endpoint for GRPC. It is
+// invoked by the WatchFlags stream handler, and returns once the stream ends.
+func MakeGRPCWatchFlagsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.WatchFlagsRequest)
+		if err := svc.WatchFlags(ctx, req); err != nil {
+			// errors sending on the stream already carry a gRPC status
+			if _, ok := status.FromError(err); ok {
+				return nil, err
+			}
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return nil, nil
+	}
+}
+
+// CreateFeatureFlag
+func (s *grpcServer) CreateFeatureFlag(ctx context.Context, req *pb.CreateFeatureFlagRequest) (*pb.CreateFeatureFlagResponse, error) {
+	_, resp, err := s.createFeatureFlag.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.CreateFeatureFlagResponse), nil
+}
+
+// ListFeatureFlags
+func (s *grpcServer) ListFeatureFlags(ctx context.Context, req *pb.ListFeatureFlagsRequest) (*pb.ListFeatureFlagsResponse, error) {
+	_, resp, err := s.listFeatureFlags.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.ListFeatureFlagsResponse), nil
+}
+
+// UpdateFeatureFlag
+func (s *grpcServer) UpdateFeatureFlag(ctx context.Context, req *pb.UpdateFeatureFlagRequest) (*pb.UpdateFeatureFlagResponse, error) {
+	_, resp, err := s.updateFeatureFlag.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.UpdateFeatureFlagResponse), nil
+}
+
+// DeleteFeatureFlag
+func (s *grpcServer) DeleteFeatureFlag(ctx context.Context, req *pb.DeleteFeatureFlagRequest) (*pb.DeleteFeatureFlagResponse, error) {
+	_, resp, err := s.deleteFeatureFlag.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.DeleteFeatureFlagResponse), nil
+}
+
+// SetFlagOverride
+func (s *grpcServer) SetFlagOverride(ctx context.Context, req *pb.SetFlagOverrideRequest) (*pb.SetFlagOverrideResponse, error) {
+	_, resp, err := s.setFlagOverride.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.SetFlagOverrideResponse), nil
+}
+
+// RemoveFlagOverride
+func (s *grpcServer) RemoveFlagOverride(ctx context.Context, req *pb.RemoveFlagOverrideRequest) (*pb.RemoveFlagOverrideResponse, error) {
+	_, resp, err := s.removeFlagOverride.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.RemoveFlagOverrideResponse), nil
+}
+
+// ListFlagOverrides
+func (s *grpcServer) ListFlagOverrides(ctx context.Context, req *pb.ListFlagOverridesRequest) (*pb.ListFlagOverridesResponse, error) {
+	_, resp, err := s.listFlagOverrides.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.ListFlagOverridesResponse), nil
+}
+
+// EvaluateFlags
+func (s *grpcServer) EvaluateFlags(ctx context.Context, req *pb.EvaluateFlagsRequest) (*pb.EvaluateFlagsResponse, error) {
+	_, resp, err := s.evaluateFlags.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.EvaluateFlagsResponse), nil
+}
+
+// WatchFlags streams the caller's flags, sending them again whenever any of
+// them changes, until the client goes away.
+func (s *grpcServer) WatchFlags(req *pb.WatchFlagsRequest, stream pb.Customers_WatchFlagsServer) error {
+	_, err := s.watchFlags(stream.Context(), service.WatchFlagsRequest{
+		AccountID: req.AccountID,
+		UserID:    req.UserID,
+		Send: func(evaluations []service.FlagEvaluation) error {
+			return stream.Send(&pb.WatchFlagsResponse{
+				Flags: encodeFlagEvaluations(evaluations),
+			})
+		},
+	})
+	return err
+}
+
+// decodeGrpcCreateFeatureFlagRequest decodes CreateFeatureFlag requests
+func decodeGrpcCreateFeatureFlagRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.CreateFeatureFlagRequest)
+	return service.CreateFeatureFlagRequest{
+		Key:         req.Key,
+		Description: req.Description,
+		Enabled:     req.Enabled,
+		Percentage:  int(req.Percentage),
+	}, nil
+}
+
+// encodeGrpcCreateFeatureFlagResponse encodes CreateFeatureFlag responses
+func encodeGrpcCreateFeatureFlagResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.CreateFeatureFlagResponse{
+		Flag: *encodeFeatureFlag(r.(service.FeatureFlag)),
+	}, nil
+}
+
+// decodeGrpcListFeatureFlagsRequest decodes ListFeatureFlags requests
+func decodeGrpcListFeatureFlagsRequest(_ context.Context, r interface{}) (interface{}, error) {
+	return service.ListFeatureFlagsRequest{}, nil
+}
+
+// encodeGrpcListFeatureFlagsResponse encodes ListFeatureFlags responses
+func encodeGrpcListFeatureFlagsResponse(_ context.Context, r interface{}) (interface{}, error) {
+	flags := []pb.FeatureFlag{}
+	for _, flag := range r.([]service.FeatureFlag) {
+		flags = append(flags, *encodeFeatureFlag(flag))
+	}
+
+	return &pb.ListFeatureFlagsResponse{
+		Flags: flags,
+	}, nil
+}
+
+// decodeGrpcUpdateFeatureFlagRequest decodes UpdateFeatureFlag requests
+func decodeGrpcUpdateFeatureFlagRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.UpdateFeatureFlagRequest)
+	update := service.UpdateFeatureFlagRequest{Key: req.Key}
+	if req.Description != nil {
+		update.Description = &req.Description.Value
+	}
+	if req.Enabled != nil {
+		update.Enabled = &req.Enabled.Value
+	}
+	if req.Percentage != nil {
+		percentage := int(req.Percentage.Value)
+		update.Percentage = &percentage
+	}
+
+	return update, nil
+}
+
+// encodeGrpcUpdateFeatureFlagResponse encodes UpdateFeatureFlag responses
+func encodeGrpcUpdateFeatureFlagResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.UpdateFeatureFlagResponse{
+		Flag: *encodeFeatureFlag(r.(service.FeatureFlag)),
+	}, nil
+}
+
+// decodeGrpcDeleteFeatureFlagRequest decodes DeleteFeatureFlag requests
+func decodeGrpcDeleteFeatureFlagRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.DeleteFeatureFlagRequest)
+	return service.DeleteFeatureFlagRequest{Key: req.Key}, nil
+}
+
+// encodeGrpcDeleteFeatureFlagResponse encodes DeleteFeatureFlag responses
+func encodeGrpcDeleteFeatureFlagResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.DeleteFeatureFlagResponse{}, nil
+}
+
+// decodeGrpcSetFlagOverrideRequest decodes SetFlagOverride requests
+func decodeGrpcSetFlagOverrideRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.SetFlagOverrideRequest)
+	return service.SetFlagOverrideRequest{
+		FlagKey:    req.FlagKey,
+		AccountID:  req.AccountID,
+		UserID:     req.UserID,
+		Enabled:    req.Enabled,
+		Percentage: int(req.Percentage),
+	}, nil
+}
+
+// encodeGrpcSetFlagOverrideResponse encodes SetFlagOverride responses
+func encodeGrpcSetFlagOverrideResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.SetFlagOverrideResponse{
+		Override: *encodeFlagOverride(r.(service.FlagOverride)),
+	}, nil
+}
+
+// decodeGrpcRemoveFlagOverrideRequest decodes RemoveFlagOverride requests
+func decodeGrpcRemoveFlagOverrideRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.RemoveFlagOverrideRequest)
+	return service.RemoveFlagOverrideRequest{
+		FlagKey:   req.FlagKey,
+		AccountID: req.AccountID,
+		UserID:    req.UserID,
+	}, nil
+}
+
+// encodeGrpcRemoveFlagOverrideResponse encodes RemoveFlagOverride responses
+func encodeGrpcRemoveFlagOverrideResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.RemoveFlagOverrideResponse{}, nil
+}
+
+// decodeGrpcListFlagOverridesRequest decodes ListFlagOverrides requests
+func decodeGrpcListFlagOverridesRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ListFlagOverridesRequest)
+	return service.ListFlagOverridesRequest{AccountID: req.AccountID}, nil
+}
+
+// encodeGrpcListFlagOverridesResponse encodes ListFlagOverrides responses
+func encodeGrpcListFlagOverridesResponse(_ context.Context, r interface{}) (interface{}, error) {
+	overrides := []pb.FlagOverride{}
+	for _, override := range r.([]service.FlagOverride) {
+		overrides = append(overrides, *encodeFlagOverride(override))
+	}
+
+	return &pb.ListFlagOverridesResponse{
+		Overrides: overrides,
+	}, nil
+}
+
+// decodeGrpcEvaluateFlagsRequest decodes EvaluateFlags requests
+func decodeGrpcEvaluateFlagsRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.EvaluateFlagsRequest)
+	return service.EvaluateFlagsRequest{AccountID: req.AccountID, UserID: req.UserID}, nil
+}
+
+// encodeGrpcEvaluateFlagsResponse encodes EvaluateFlags responses
+func encodeGrpcEvaluateFlagsResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.EvaluateFlagsResponse{
+		Flags: encodeFlagEvaluations(r.([]service.FlagEvaluation)),
+	}, nil
+}
+
+func encodeFeatureFlag(f service.FeatureFlag) *pb.FeatureFlag {
+	return &pb.FeatureFlag{
+		ID:          f.ID,
+		Key:         f.Key,
+		Description: f.Description,
+		Enabled:     f.Enabled,
+		Percentage:  int32(f.Percentage),
+		UpdatedAt:   f.UpdatedAt,
+		CreatedAt:   f.CreatedAt,
+	}
+}
+
+func encodeFlagOverride(o service.FlagOverride) *pb.FlagOverride {
+	return &pb.FlagOverride{
+		FlagKey:    o.FlagKey,
+		AccountID:  o.AccountID,
+		UserID:     o.UserID,
+		Enabled:    o.Enabled,
+		Percentage: int32(o.Percentage),
+		CreatedBy:  o.CreatedBy,
+		UpdatedAt:  o.UpdatedAt,
+		CreatedAt:  o.CreatedAt,
+	}
+}
+
+func encodeFlagEvaluations(evaluations []service.FlagEvaluation) []pb.FlagEvaluation {
+	encoded := []pb.FlagEvaluation{}
+	for _, e := range evaluations {
+		evaluation := pb.FlagEvaluation{Key: e.Key, Enabled: e.Enabled}
+		switch e.Source {
+		case service.FlagFromDefault:
+			evaluation.Source = pb.FlagEvaluation_DEFAULT
+		case service.FlagFromAccount:
+			evaluation.Source = pb.FlagEvaluation_ACCOUNT
+		case service.FlagFromUser:
+			evaluation.Source = pb.FlagEvaluation_USER
+		}
+		encoded = append(encoded, evaluation)
+	}
+
+	return encoded
+}
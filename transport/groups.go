@@ -0,0 +1,356 @@
+package transport
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/symptomatichq/customers/protos/customers"
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeGRPCCreateGroupEndpoint creates CreateGroup Endpoint for GRPC
+func MakeGRPCCreateGroupEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.CreateGroupRequest)
+		group, err := svc.CreateGroup(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return group, nil
+	}
+}
+
+// MakeGRPCGetGroupEndpoint creates GetGroup Endpoint for GRPC
+func MakeGRPCGetGroupEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.GetGroupRequest)
+		group, err := svc.GetGroup(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return group, nil
+	}
+}
+
+// MakeGRPCListGroupsEndpoint creates ListGroups Endpoint for GRPC
+func MakeGRPCListGroupsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListGroupsRequest)
+		groups, err := svc.ListGroups(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return groups, nil
+	}
+}
+
+// MakeGRPCUpdateGroupEndpoint creates UpdateGroup Endpoint for GRPC
+func MakeGRPCUpdateGroupEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.UpdateGroupRequest)
+		group, err := svc.UpdateGroup(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return group, nil
+	}
+}
+
+// MakeGRPCDeleteGroupEndpoint creates DeleteGroup Endpoint for GRPC
+func MakeGRPCDeleteGroupEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.DeleteGroupRequest)
+		if err := svc.DeleteGroup(ctx, req); err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return req, nil
+	}
+}
+
+// MakeGRPCAddGroupMemberEndpoint creates AddGroupMember Endpoint for GRPC
+func MakeGRPCAddGroupMemberEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.AddGroupMemberRequest)
+		member, err := svc.AddGroupMember(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return member, nil
+	}
+}
+
+// MakeGRPCRemoveGroupMemberEndpoint creates RemoveGroupMember Endpoint for GRPC
+func MakeGRPCRemoveGroupMemberEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.RemoveGroupMemberRequest)
+		if err := svc.RemoveGroupMember(ctx, req); err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return req, nil
+	}
+}
+
+// MakeGRPCListGroupMembersEndpoint creates ListGroupMembers Endpoint for GRPC
+func MakeGRPCListGroupMembersEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListGroupMembersRequest)
+		members, err := svc.ListGroupMembers(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return members, nil
+	}
+}
+
+// CreateGroup
+func (s *grpcServer) CreateGroup(ctx context.Context, req *pb.CreateGroupRequest) (*pb.CreateGroupResponse, error) {
+	_, resp, err := s.createGroup.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.CreateGroupResponse), nil
+}
+
+// GetGroup
+func (s *grpcServer) GetGroup(ctx context.Context, req *pb.GetGroupRequest) (*pb.GetGroupResponse, error) {
+	_, resp, err := s.getGroup.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.GetGroupResponse), nil
+}
+
+// ListGroups
+func (s *grpcServer) ListGroups(ctx context.Context, req *pb.ListGroupsRequest) (*pb.ListGroupsResponse, error) {
+	_, resp, err := s.listGroups.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.ListGroupsResponse), nil
+}
+
+// UpdateGroup
+func (s *grpcServer) UpdateGroup(ctx context.Context, req *pb.UpdateGroupRequest) (*pb.UpdateGroupResponse, error) {
+	_, resp, err := s.updateGroup.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.UpdateGroupResponse), nil
+}
+
+// DeleteGroup
+func (s *grpcServer) DeleteGroup(ctx context.Context, req *pb.DeleteGroupRequest) (*pb.DeleteGroupResponse, error) {
+	_, resp, err := s.deleteGroup.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.DeleteGroupResponse), nil
+}
+
+// AddGroupMember
+func (s *grpcServer) AddGroupMember(ctx context.Context, req *pb.AddGroupMemberRequest) (*pb.AddGroupMemberResponse, error) {
+	_, resp, err := s.addGroupMember.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.AddGroupMemberResponse), nil
+}
+
+// RemoveGroupMember
+func (s *grpcServer) RemoveGroupMember(ctx context.Context, req *pb.RemoveGroupMemberRequest) (*pb.RemoveGroupMemberResponse, error) {
+	_, resp, err := s.removeGroupMember.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.RemoveGroupMemberResponse), nil
+}
+
+// ListGroupMembers
+func (s *grpcServer) ListGroupMembers(ctx context.Context, req *pb.ListGroupMembersRequest) (*pb.ListGroupMembersResponse, error) {
+	_, resp, err := s.listGroupMembers.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.ListGroupMembersResponse), nil
+}
+
+// decodeGrpcCreateGroupRequest decodes CreateGroup requests
+func decodeGrpcCreateGroupRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.CreateGroupRequest)
+	return service.CreateGroupRequest{
+		AccountID:   req.AccountID,
+		ParentID:    req.ParentID,
+		Name:        req.Name,
+		Description: req.Description,
+	}, nil
+}
+
+// encodeGrpcCreateGroupResponse encodes CreateGroup responses
+func encodeGrpcCreateGroupResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.CreateGroupResponse{
+		Group: *encodeGroup(r.(service.Group)),
+	}, nil
+}
+
+// decodeGrpcGetGroupRequest decodes GetGroup requests
+func decodeGrpcGetGroupRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.GetGroupRequest)
+	return service.GetGroupRequest{AccountID: req.AccountID, ID: req.ID}, nil
+}
+
+// encodeGrpcGetGroupResponse encodes GetGroup responses
+func encodeGrpcGetGroupResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.GetGroupResponse{
+		Group: *encodeGroup(r.(service.Group)),
+	}, nil
+}
+
+// decodeGrpcListGroupsRequest decodes ListGroups requests
+func decodeGrpcListGroupsRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ListGroupsRequest)
+	return service.ListGroupsRequest{AccountID: req.AccountID}, nil
+}
+
+// encodeGrpcListGroupsResponse encodes ListGroups responses
+func encodeGrpcListGroupsResponse(_ context.Context, r interface{}) (interface{}, error) {
+	groups := []pb.Group{}
+	for _, group := range r.([]service.Group) {
+		groups = append(groups, *encodeGroup(group))
+	}
+
+	return &pb.ListGroupsResponse{
+		Groups: groups,
+	}, nil
+}
+
+// decodeGrpcUpdateGroupRequest decodes UpdateGroup requests
+func decodeGrpcUpdateGroupRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.UpdateGroupRequest)
+
+	update := service.UpdateGroupRequest{AccountID: req.AccountID, ID: req.ID}
+	if req.Name != nil {
+		update.Name = &req.Name.Value
+	}
+	if req.Description != nil {
+		update.Description = &req.Description.Value
+	}
+	if req.ParentID != nil {
+		update.ParentID = &req.ParentID.Value
+	}
+
+	return update, nil
+}
+
+// encodeGrpcUpdateGroupResponse encodes UpdateGroup responses
+func encodeGrpcUpdateGroupResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.UpdateGroupResponse{
+		Group: *encodeGroup(r.(service.Group)),
+	}, nil
+}
+
+// decodeGrpcDeleteGroupRequest decodes DeleteGroup requests
+func decodeGrpcDeleteGroupRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.DeleteGroupRequest)
+	return service.DeleteGroupRequest{AccountID: req.AccountID, ID: req.ID}, nil
+}
+
+// encodeGrpcDeleteGroupResponse encodes DeleteGroup responses
+func encodeGrpcDeleteGroupResponse(_ context.Context, _ interface{}) (interface{}, error) {
+	return &pb.DeleteGroupResponse{}, nil
+}
+
+// decodeGrpcAddGroupMemberRequest decodes AddGroupMember requests
+func decodeGrpcAddGroupMemberRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.AddGroupMemberRequest)
+	return service.AddGroupMemberRequest{
+		AccountID: req.AccountID,
+		GroupID:   req.GroupID,
+		UserID:    req.UserID,
+	}, nil
+}
+
+// encodeGrpcAddGroupMemberResponse encodes AddGroupMember responses
+func encodeGrpcAddGroupMemberResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.AddGroupMemberResponse{
+		Member: *encodeGroupMember(r.(service.GroupMember)),
+	}, nil
+}
+
+// decodeGrpcRemoveGroupMemberRequest decodes RemoveGroupMember requests
+func decodeGrpcRemoveGroupMemberRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.RemoveGroupMemberRequest)
+	return service.RemoveGroupMemberRequest{
+		AccountID: req.AccountID,
+		GroupID:   req.GroupID,
+		UserID:    req.UserID,
+	}, nil
+}
+
+// encodeGrpcRemoveGroupMemberResponse encodes RemoveGroupMember responses
+func encodeGrpcRemoveGroupMemberResponse(_ context.Context, _ interface{}) (interface{}, error) {
+	return &pb.RemoveGroupMemberResponse{}, nil
+}
+
+// decodeGrpcListGroupMembersRequest decodes ListGroupMembers requests
+func decodeGrpcListGroupMembersRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ListGroupMembersRequest)
+	return service.ListGroupMembersRequest{
+		AccountID: req.AccountID,
+		GroupID:   req.GroupID,
+		Recursive: req.Recursive,
+	}, nil
+}
+
+// encodeGrpcListGroupMembersResponse encodes ListGroupMembers responses
+func encodeGrpcListGroupMembersResponse(_ context.Context, r interface{}) (interface{}, error) {
+	members := []pb.GroupMember{}
+	for _, member := range r.([]service.GroupMember) {
+		members = append(members, *encodeGroupMember(member))
+	}
+
+	return &pb.ListGroupMembersResponse{
+		Members: members,
+	}, nil
+}
+
+// encodeGroup serializes a group into its protobuf message
+func encodeGroup(g service.Group) *pb.Group {
+	return &pb.Group{
+		ID:          g.ID,
+		AccountID:   g.AccountID,
+		ParentID:    stringValue(g.ParentID),
+		Name:        g.Name,
+		Description: g.Description,
+		UpdatedAt:   g.UpdatedAt,
+		CreatedAt:   g.CreatedAt,
+	}
+}
+
+// encodeGroupMember serializes a group member into its protobuf message
+func encodeGroupMember(m service.GroupMember) *pb.GroupMember {
+	return &pb.GroupMember{
+		GroupID:   m.GroupID,
+		AccountID: m.AccountID,
+		UserID:    m.UserID,
+		CreatedAt: m.CreatedAt,
+	}
+}
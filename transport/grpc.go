@@ -2,6 +2,7 @@ package transport
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/go-kit/kit/endpoint"
 	"github.com/go-kit/kit/log"
@@ -11,8 +12,8 @@ import (
 	"google.golang.org/grpc/status"
 
 	customerEndpoint "github.com/symptomatichq/customers/endpoint"
+	pb "github.com/symptomatichq/customers/protos/customers"
 	"github.com/symptomatichq/customers/service"
-	pb "github.com/symptomatichq/protos/customers"
 )
 
 type grpcServer struct {
@@ -25,6 +26,109 @@ type grpcServer struct {
 	createUser grpctransport.Handler
 	getUser    grpctransport.Handler
 	fetchUsers grpctransport.Handler
+
+	grantRole        grpctransport.Handler
+	changeRole       grpctransport.Handler
+	revokeRole       grpctransport.Handler
+	fetchMemberships grpctransport.Handler
+
+	changeMembershipStatus grpctransport.Handler
+	fetchUserAccounts      grpctransport.Handler
+
+	inviteUser       grpctransport.Handler
+	listInvitations  grpctransport.Handler
+	revokeInvitation grpctransport.Handler
+	acceptInvitation grpctransport.Handler
+
+	requestEmailVerification grpctransport.Handler
+	confirmEmailVerification grpctransport.Handler
+
+	updateUser grpctransport.Handler
+
+	getUserByEmail             grpctransport.Handler
+	findAccountsByContactEmail grpctransport.Handler
+
+	searchCustomers grpctransport.Handler
+
+	listDuplicateAccounts grpctransport.Handler
+	mergeAccounts         grpctransport.Handler
+
+	transferUser grpctransport.Handler
+
+	initiateOwnershipTransfer grpctransport.Handler
+	acceptOwnershipTransfer   grpctransport.Handler
+	cancelOwnershipTransfer   grpctransport.Handler
+	listOwnershipTransfers    grpctransport.Handler
+
+	setAccountParent     grpctransport.Handler
+	listChildAccounts    grpctransport.Handler
+	listAncestorAccounts grpctransport.Handler
+
+	createGroup       grpctransport.Handler
+	getGroup          grpctransport.Handler
+	listGroups        grpctransport.Handler
+	updateGroup       grpctransport.Handler
+	deleteGroup       grpctransport.Handler
+	addGroupMember    grpctransport.Handler
+	removeGroupMember grpctransport.Handler
+	listGroupMembers  grpctransport.Handler
+
+	createAPIKey grpctransport.Handler
+	listAPIKeys  grpctransport.Handler
+	rotateAPIKey grpctransport.Handler
+	revokeAPIKey grpctransport.Handler
+	verifyAPIKey grpctransport.Handler
+
+	createServiceAccount grpctransport.Handler
+	listServiceAccounts  grpctransport.Handler
+	updateServiceAccount grpctransport.Handler
+	deleteServiceAccount grpctransport.Handler
+
+	updateAccount grpctransport.Handler
+
+	defineCustomField    grpctransport.Handler
+	listCustomFields     grpctransport.Handler
+	updateCustomField    grpctransport.Handler
+	deleteCustomField    grpctransport.Handler
+	setCustomFieldValues grpctransport.Handler
+
+	createPlan                grpctransport.Handler
+	listPlans                 grpctransport.Handler
+	updatePlan                grpctransport.Handler
+	assignPlan                grpctransport.Handler
+	listAccountPlans          grpctransport.Handler
+	setEntitlementOverride    grpctransport.Handler
+	removeEntitlementOverride grpctransport.Handler
+	listAccountEntitlements   grpctransport.Handler
+	checkEntitlement          grpctransport.Handler
+
+	getAccountUsage grpctransport.Handler
+
+	extendTrial  grpctransport.Handler
+	convertTrial grpctransport.Handler
+
+	changeAccountStatus grpctransport.Handler
+
+	scheduleStatusChange  grpctransport.Handler
+	listScheduledActions  grpctransport.Handler
+	cancelScheduledAction grpctransport.Handler
+
+	createFeatureFlag  grpctransport.Handler
+	listFeatureFlags   grpctransport.Handler
+	updateFeatureFlag  grpctransport.Handler
+	deleteFeatureFlag  grpctransport.Handler
+	setFlagOverride    grpctransport.Handler
+	removeFlagOverride grpctransport.Handler
+	listFlagOverrides  grpctransport.Handler
+	evaluateFlags      grpctransport.Handler
+	// watchFlags is served by the WatchFlags stream handler rather than
+	// through a grpctransport.Handler, which only serves unary calls.
+	watchFlags endpoint.Endpoint
+
+	getUserPreferences       grpctransport.Handler
+	updateUserPreferences    grpctransport.Handler
+	getAccountPreferences    grpctransport.Handler
+	updateAccountPreferences grpctransport.Handler
 }
 
 // CreateAccount
@@ -87,6 +191,36 @@ func (s *grpcServer) FetchUsers(ctx context.Context, req *pb.FetchUsersRequest)
 	return resp.(*pb.FetchUsersResponse), nil
 }
 
+// UpdateUser
+func (s *grpcServer) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
+	_, resp, err := s.updateUser.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.UpdateUserResponse), nil
+}
+
+// GetUserByEmail
+func (s *grpcServer) GetUserByEmail(ctx context.Context, req *pb.GetUserByEmailRequest) (*pb.GetUserByEmailResponse, error) {
+	_, resp, err := s.getUserByEmail.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.GetUserByEmailResponse), nil
+}
+
+// FindAccountsByContactEmail
+func (s *grpcServer) FindAccountsByContactEmail(ctx context.Context, req *pb.FindAccountsByContactEmailRequest) (*pb.FindAccountsByContactEmailResponse, error) {
+	_, resp, err := s.findAccountsByContactEmail.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.FindAccountsByContactEmailResponse), nil
+}
+
 // NewGRPCServer create new grpc server
 func NewGRPCServer(endpoints customerEndpoint.Endpoints, logger log.Logger) pb.CustomersServer {
 	options := []grpctransport.ServerOption{
@@ -131,6 +265,469 @@ func NewGRPCServer(endpoints customerEndpoint.Endpoints, logger log.Logger) pb.C
 			encodeGrpcFetchUsersResponse,
 			options...,
 		),
+		grantRole: grpctransport.NewServer(
+			endpoints.GrantRoleEndpoint,
+			decodeGrpcGrantRoleRequest,
+			encodeGrpcGrantRoleResponse,
+			options...,
+		),
+		changeRole: grpctransport.NewServer(
+			endpoints.ChangeRoleEndpoint,
+			decodeGrpcChangeRoleRequest,
+			encodeGrpcChangeRoleResponse,
+			options...,
+		),
+		revokeRole: grpctransport.NewServer(
+			endpoints.RevokeRoleEndpoint,
+			decodeGrpcRevokeRoleRequest,
+			encodeGrpcRevokeRoleResponse,
+			options...,
+		),
+		fetchMemberships: grpctransport.NewServer(
+			endpoints.FetchMembershipsEndpoint,
+			decodeGrpcFetchMembershipsRequest,
+			encodeGrpcFetchMembershipsResponse,
+			options...,
+		),
+		changeMembershipStatus: grpctransport.NewServer(
+			endpoints.ChangeMembershipStatusEndpoint,
+			decodeGrpcChangeMembershipStatusRequest,
+			encodeGrpcChangeMembershipStatusResponse,
+			options...,
+		),
+		fetchUserAccounts: grpctransport.NewServer(
+			endpoints.FetchUserAccountsEndpoint,
+			decodeGrpcFetchUserAccountsRequest,
+			encodeGrpcFetchUserAccountsResponse,
+			options...,
+		),
+		inviteUser: grpctransport.NewServer(
+			endpoints.InviteUserEndpoint,
+			decodeGrpcInviteUserRequest,
+			encodeGrpcInviteUserResponse,
+			options...,
+		),
+		listInvitations: grpctransport.NewServer(
+			endpoints.ListInvitationsEndpoint,
+			decodeGrpcListInvitationsRequest,
+			encodeGrpcListInvitationsResponse,
+			options...,
+		),
+		revokeInvitation: grpctransport.NewServer(
+			endpoints.RevokeInvitationEndpoint,
+			decodeGrpcRevokeInvitationRequest,
+			encodeGrpcRevokeInvitationResponse,
+			options...,
+		),
+		acceptInvitation: grpctransport.NewServer(
+			endpoints.AcceptInvitationEndpoint,
+			decodeGrpcAcceptInvitationRequest,
+			encodeGrpcAcceptInvitationResponse,
+			options...,
+		),
+		requestEmailVerification: grpctransport.NewServer(
+			endpoints.RequestEmailVerificationEndpoint,
+			decodeGrpcRequestEmailVerificationRequest,
+			encodeGrpcRequestEmailVerificationResponse,
+			options...,
+		),
+		confirmEmailVerification: grpctransport.NewServer(
+			endpoints.ConfirmEmailVerificationEndpoint,
+			decodeGrpcConfirmEmailVerificationRequest,
+			encodeGrpcConfirmEmailVerificationResponse,
+			options...,
+		),
+		updateUser: grpctransport.NewServer(
+			endpoints.UpdateUserEndpoint,
+			decodeGrpcUpdateUserRequest,
+			encodeGrpcUpdateUserResponse,
+			options...,
+		),
+		getUserByEmail: grpctransport.NewServer(
+			endpoints.GetUserByEmailEndpoint,
+			decodeGrpcGetUserByEmailRequest,
+			encodeGrpcGetUserByEmailResponse,
+			options...,
+		),
+		findAccountsByContactEmail: grpctransport.NewServer(
+			endpoints.FindAccountsByContactEmailEndpoint,
+			decodeGrpcFindAccountsByContactEmailRequest,
+			encodeGrpcFindAccountsByContactEmailResponse,
+			options...,
+		),
+		searchCustomers: grpctransport.NewServer(
+			endpoints.SearchCustomersEndpoint,
+			decodeGrpcSearchCustomersRequest,
+			encodeGrpcSearchCustomersResponse,
+			options...,
+		),
+		listDuplicateAccounts: grpctransport.NewServer(
+			endpoints.ListDuplicateAccountsEndpoint,
+			decodeGrpcListDuplicateAccountsRequest,
+			encodeGrpcListDuplicateAccountsResponse,
+			options...,
+		),
+		mergeAccounts: grpctransport.NewServer(
+			endpoints.MergeAccountsEndpoint,
+			decodeGrpcMergeAccountsRequest,
+			encodeGrpcMergeAccountsResponse,
+			options...,
+		),
+		transferUser: grpctransport.NewServer(
+			endpoints.TransferUserEndpoint,
+			decodeGrpcTransferUserRequest,
+			encodeGrpcTransferUserResponse,
+			options...,
+		),
+		initiateOwnershipTransfer: grpctransport.NewServer(
+			endpoints.InitiateOwnershipTransferEndpoint,
+			decodeGrpcInitiateOwnershipTransferRequest,
+			encodeGrpcInitiateOwnershipTransferResponse,
+			options...,
+		),
+		acceptOwnershipTransfer: grpctransport.NewServer(
+			endpoints.AcceptOwnershipTransferEndpoint,
+			decodeGrpcAcceptOwnershipTransferRequest,
+			encodeGrpcAcceptOwnershipTransferResponse,
+			options...,
+		),
+		cancelOwnershipTransfer: grpctransport.NewServer(
+			endpoints.CancelOwnershipTransferEndpoint,
+			decodeGrpcCancelOwnershipTransferRequest,
+			encodeGrpcCancelOwnershipTransferResponse,
+			options...,
+		),
+		listOwnershipTransfers: grpctransport.NewServer(
+			endpoints.ListOwnershipTransfersEndpoint,
+			decodeGrpcListOwnershipTransfersRequest,
+			encodeGrpcListOwnershipTransfersResponse,
+			options...,
+		),
+		setAccountParent: grpctransport.NewServer(
+			endpoints.SetAccountParentEndpoint,
+			decodeGrpcSetAccountParentRequest,
+			encodeGrpcSetAccountParentResponse,
+			options...,
+		),
+		listChildAccounts: grpctransport.NewServer(
+			endpoints.ListChildAccountsEndpoint,
+			decodeGrpcListChildAccountsRequest,
+			encodeGrpcListChildAccountsResponse,
+			options...,
+		),
+		listAncestorAccounts: grpctransport.NewServer(
+			endpoints.ListAncestorAccountsEndpoint,
+			decodeGrpcListAncestorAccountsRequest,
+			encodeGrpcListAncestorAccountsResponse,
+			options...,
+		),
+		createGroup: grpctransport.NewServer(
+			endpoints.CreateGroupEndpoint,
+			decodeGrpcCreateGroupRequest,
+			encodeGrpcCreateGroupResponse,
+			options...,
+		),
+		getGroup: grpctransport.NewServer(
+			endpoints.GetGroupEndpoint,
+			decodeGrpcGetGroupRequest,
+			encodeGrpcGetGroupResponse,
+			options...,
+		),
+		listGroups: grpctransport.NewServer(
+			endpoints.ListGroupsEndpoint,
+			decodeGrpcListGroupsRequest,
+			encodeGrpcListGroupsResponse,
+			options...,
+		),
+		updateGroup: grpctransport.NewServer(
+			endpoints.UpdateGroupEndpoint,
+			decodeGrpcUpdateGroupRequest,
+			encodeGrpcUpdateGroupResponse,
+			options...,
+		),
+		deleteGroup: grpctransport.NewServer(
+			endpoints.DeleteGroupEndpoint,
+			decodeGrpcDeleteGroupRequest,
+			encodeGrpcDeleteGroupResponse,
+			options...,
+		),
+		addGroupMember: grpctransport.NewServer(
+			endpoints.AddGroupMemberEndpoint,
+			decodeGrpcAddGroupMemberRequest,
+			encodeGrpcAddGroupMemberResponse,
+			options...,
+		),
+		removeGroupMember: grpctransport.NewServer(
+			endpoints.RemoveGroupMemberEndpoint,
+			decodeGrpcRemoveGroupMemberRequest,
+			encodeGrpcRemoveGroupMemberResponse,
+			options...,
+		),
+		listGroupMembers: grpctransport.NewServer(
+			endpoints.ListGroupMembersEndpoint,
+			decodeGrpcListGroupMembersRequest,
+			encodeGrpcListGroupMembersResponse,
+			options...,
+		),
+		createAPIKey: grpctransport.NewServer(
+			endpoints.CreateAPIKeyEndpoint,
+			decodeGrpcCreateAPIKeyRequest,
+			encodeGrpcCreateAPIKeyResponse,
+			options...,
+		),
+		listAPIKeys: grpctransport.NewServer(
+			endpoints.ListAPIKeysEndpoint,
+			decodeGrpcListAPIKeysRequest,
+			encodeGrpcListAPIKeysResponse,
+			options...,
+		),
+		rotateAPIKey: grpctransport.NewServer(
+			endpoints.RotateAPIKeyEndpoint,
+			decodeGrpcRotateAPIKeyRequest,
+			encodeGrpcRotateAPIKeyResponse,
+			options...,
+		),
+		revokeAPIKey: grpctransport.NewServer(
+			endpoints.RevokeAPIKeyEndpoint,
+			decodeGrpcRevokeAPIKeyRequest,
+			encodeGrpcRevokeAPIKeyResponse,
+			options...,
+		),
+		verifyAPIKey: grpctransport.NewServer(
+			endpoints.VerifyAPIKeyEndpoint,
+			decodeGrpcVerifyAPIKeyRequest,
+			encodeGrpcVerifyAPIKeyResponse,
+			options...,
+		),
+		createServiceAccount: grpctransport.NewServer(
+			endpoints.CreateServiceAccountEndpoint,
+			decodeGrpcCreateServiceAccountRequest,
+			encodeGrpcCreateServiceAccountResponse,
+			options...,
+		),
+		listServiceAccounts: grpctransport.NewServer(
+			endpoints.ListServiceAccountsEndpoint,
+			decodeGrpcListServiceAccountsRequest,
+			encodeGrpcListServiceAccountsResponse,
+			options...,
+		),
+		updateServiceAccount: grpctransport.NewServer(
+			endpoints.UpdateServiceAccountEndpoint,
+			decodeGrpcUpdateServiceAccountRequest,
+			encodeGrpcUpdateServiceAccountResponse,
+			options...,
+		),
+		deleteServiceAccount: grpctransport.NewServer(
+			endpoints.DeleteServiceAccountEndpoint,
+			decodeGrpcDeleteServiceAccountRequest,
+			encodeGrpcDeleteServiceAccountResponse,
+			options...,
+		),
+		updateAccount: grpctransport.NewServer(
+			endpoints.UpdateAccountEndpoint,
+			decodeGrpcUpdateAccountRequest,
+			encodeGrpcUpdateAccountResponse,
+			options...,
+		),
+		defineCustomField: grpctransport.NewServer(
+			endpoints.DefineCustomFieldEndpoint,
+			decodeGrpcDefineCustomFieldRequest,
+			encodeGrpcDefineCustomFieldResponse,
+			options...,
+		),
+		listCustomFields: grpctransport.NewServer(
+			endpoints.ListCustomFieldsEndpoint,
+			decodeGrpcListCustomFieldsRequest,
+			encodeGrpcListCustomFieldsResponse,
+			options...,
+		),
+		updateCustomField: grpctransport.NewServer(
+			endpoints.UpdateCustomFieldEndpoint,
+			decodeGrpcUpdateCustomFieldRequest,
+			encodeGrpcUpdateCustomFieldResponse,
+			options...,
+		),
+		deleteCustomField: grpctransport.NewServer(
+			endpoints.DeleteCustomFieldEndpoint,
+			decodeGrpcDeleteCustomFieldRequest,
+			encodeGrpcDeleteCustomFieldResponse,
+			options...,
+		),
+		setCustomFieldValues: grpctransport.NewServer(
+			endpoints.SetCustomFieldValuesEndpoint,
+			decodeGrpcSetCustomFieldValuesRequest,
+			encodeGrpcSetCustomFieldValuesResponse,
+			options...,
+		),
+		createPlan: grpctransport.NewServer(
+			endpoints.CreatePlanEndpoint,
+			decodeGrpcCreatePlanRequest,
+			encodeGrpcCreatePlanResponse,
+			options...,
+		),
+		listPlans: grpctransport.NewServer(
+			endpoints.ListPlansEndpoint,
+			decodeGrpcListPlansRequest,
+			encodeGrpcListPlansResponse,
+			options...,
+		),
+		updatePlan: grpctransport.NewServer(
+			endpoints.UpdatePlanEndpoint,
+			decodeGrpcUpdatePlanRequest,
+			encodeGrpcUpdatePlanResponse,
+			options...,
+		),
+		assignPlan: grpctransport.NewServer(
+			endpoints.AssignPlanEndpoint,
+			decodeGrpcAssignPlanRequest,
+			encodeGrpcAssignPlanResponse,
+			options...,
+		),
+		listAccountPlans: grpctransport.NewServer(
+			endpoints.ListAccountPlansEndpoint,
+			decodeGrpcListAccountPlansRequest,
+			encodeGrpcListAccountPlansResponse,
+			options...,
+		),
+		setEntitlementOverride: grpctransport.NewServer(
+			endpoints.SetEntitlementOverrideEndpoint,
+			decodeGrpcSetEntitlementOverrideRequest,
+			encodeGrpcSetEntitlementOverrideResponse,
+			options...,
+		),
+		removeEntitlementOverride: grpctransport.NewServer(
+			endpoints.RemoveEntitlementOverrideEndpoint,
+			decodeGrpcRemoveEntitlementOverrideRequest,
+			encodeGrpcRemoveEntitlementOverrideResponse,
+			options...,
+		),
+		listAccountEntitlements: grpctransport.NewServer(
+			endpoints.ListAccountEntitlementsEndpoint,
+			decodeGrpcListAccountEntitlementsRequest,
+			encodeGrpcListAccountEntitlementsResponse,
+			options...,
+		),
+		checkEntitlement: grpctransport.NewServer(
+			endpoints.CheckEntitlementEndpoint,
+			decodeGrpcCheckEntitlementRequest,
+			encodeGrpcCheckEntitlementResponse,
+			options...,
+		),
+		getAccountUsage: grpctransport.NewServer(
+			endpoints.GetAccountUsageEndpoint,
+			decodeGrpcGetAccountUsageRequest,
+			encodeGrpcGetAccountUsageResponse,
+			options...,
+		),
+		extendTrial: grpctransport.NewServer(
+			endpoints.ExtendTrialEndpoint,
+			decodeGrpcExtendTrialRequest,
+			encodeGrpcExtendTrialResponse,
+			options...,
+		),
+		convertTrial: grpctransport.NewServer(
+			endpoints.ConvertTrialEndpoint,
+			decodeGrpcConvertTrialRequest,
+			encodeGrpcConvertTrialResponse,
+			options...,
+		),
+		changeAccountStatus: grpctransport.NewServer(
+			endpoints.ChangeAccountStatusEndpoint,
+			decodeGrpcChangeAccountStatusRequest,
+			encodeGrpcChangeAccountStatusResponse,
+			options...,
+		),
+		scheduleStatusChange: grpctransport.NewServer(
+			endpoints.ScheduleStatusChangeEndpoint,
+			decodeGrpcScheduleStatusChangeRequest,
+			encodeGrpcScheduleStatusChangeResponse,
+			options...,
+		),
+		listScheduledActions: grpctransport.NewServer(
+			endpoints.ListScheduledActionsEndpoint,
+			decodeGrpcListScheduledActionsRequest,
+			encodeGrpcListScheduledActionsResponse,
+			options...,
+		),
+		cancelScheduledAction: grpctransport.NewServer(
+			endpoints.CancelScheduledActionEndpoint,
+			decodeGrpcCancelScheduledActionRequest,
+			encodeGrpcCancelScheduledActionResponse,
+			options...,
+		),
+		createFeatureFlag: grpctransport.NewServer(
+			endpoints.CreateFeatureFlagEndpoint,
+			decodeGrpcCreateFeatureFlagRequest,
+			encodeGrpcCreateFeatureFlagResponse,
+			options...,
+		),
+		listFeatureFlags: grpctransport.NewServer(
+			endpoints.ListFeatureFlagsEndpoint,
+			decodeGrpcListFeatureFlagsRequest,
+			encodeGrpcListFeatureFlagsResponse,
+			options...,
+		),
+		updateFeatureFlag: grpctransport.NewServer(
+			endpoints.UpdateFeatureFlagEndpoint,
+			decodeGrpcUpdateFeatureFlagRequest,
+			encodeGrpcUpdateFeatureFlagResponse,
+			options...,
+		),
+		deleteFeatureFlag: grpctransport.NewServer(
+			endpoints.DeleteFeatureFlagEndpoint,
+			decodeGrpcDeleteFeatureFlagRequest,
+			encodeGrpcDeleteFeatureFlagResponse,
+			options...,
+		),
+		setFlagOverride: grpctransport.NewServer(
+			endpoints.SetFlagOverrideEndpoint,
+			decodeGrpcSetFlagOverrideRequest,
+			encodeGrpcSetFlagOverrideResponse,
+			options...,
+		),
+		removeFlagOverride: grpctransport.NewServer(
+			endpoints.RemoveFlagOverrideEndpoint,
+			decodeGrpcRemoveFlagOverrideRequest,
+			encodeGrpcRemoveFlagOverrideResponse,
+			options...,
+		),
+		listFlagOverrides: grpctransport.NewServer(
+			endpoints.ListFlagOverridesEndpoint,
+			decodeGrpcListFlagOverridesRequest,
+			encodeGrpcListFlagOverridesResponse,
+			options...,
+		),
+		evaluateFlags: grpctransport.NewServer(
+			endpoints.EvaluateFlagsEndpoint,
+			decodeGrpcEvaluateFlagsRequest,
+			encodeGrpcEvaluateFlagsResponse,
+			options...,
+		),
+		watchFlags: endpoints.WatchFlagsEndpoint,
+		getUserPreferences: grpctransport.NewServer(
+			endpoints.GetUserPreferencesEndpoint,
+			decodeGrpcGetUserPreferencesRequest,
+			encodeGrpcGetUserPreferencesResponse,
+			options...,
+		),
+		updateUserPreferences: grpctransport.NewServer(
+			endpoints.UpdateUserPreferencesEndpoint,
+			decodeGrpcUpdateUserPreferencesRequest,
+			encodeGrpcUpdateUserPreferencesResponse,
+			options...,
+		),
+		getAccountPreferences: grpctransport.NewServer(
+			endpoints.GetAccountPreferencesEndpoint,
+			decodeGrpcGetAccountPreferencesRequest,
+			encodeGrpcGetAccountPreferencesResponse,
+			options...,
+		),
+		updateAccountPreferences: grpctransport.NewServer(
+			endpoints.UpdateAccountPreferencesEndpoint,
+			decodeGrpcUpdateAccountPreferencesRequest,
+			encodeGrpcUpdateAccountPreferencesResponse,
+			options...,
+		),
 	}
 }
 
@@ -140,7 +737,7 @@ func MakeGRPCGetAccountEndpoint(svc service.Service) endpoint.Endpoint {
 		req := request.(service.GetAccountRequest)
 		account, err := svc.GetAccount(ctx, req)
 		if err != nil {
-			return nil, status.Errorf(codes.Internal, errors.Wrap(err, "internal error").Error())
+			return nil, encodeError(err, status.Errorf(codes.Internal, errors.Wrap(err, "internal error").Error()))
 		}
 
 		return account, nil
@@ -153,7 +750,7 @@ func MakeGRPCCreateAccountEndpoint(svc service.Service) endpoint.Endpoint {
 		req := request.(service.CreateAccountRequest)
 		account, err := svc.CreateAccount(ctx, req)
 		if err != nil {
-			return nil, status.Errorf(codes.InvalidArgument, "invalid request")
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
 		}
 
 		return account, nil
@@ -166,20 +763,46 @@ func MakeGRPCFetchAccountsEndpoint(svc service.Service) endpoint.Endpoint {
 		req := request.(service.FetchAccountsRequest)
 		accounts, err := svc.FetchAccounts(ctx, req)
 		if err != nil {
-			return nil, status.Errorf(codes.InvalidArgument, "invalid request")
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
 		}
 
 		return accounts, nil
 	}
 }
 
+// MakeGRPCFindAccountsByContactEmailEndpoint creates FindAccountsByContactEmail Endpoint for GRPC
+func MakeGRPCFindAccountsByContactEmailEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.FindAccountsByContactEmailRequest)
+		accounts, err := svc.FindAccountsByContactEmail(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return accounts, nil
+	}
+}
+
+// MakeGRPCGetUserByEmailEndpoint creates GetUserByEmail Endpoint for GRPC
+func MakeGRPCGetUserByEmailEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.GetUserByEmailRequest)
+		user, err := svc.GetUserByEmail(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.Internal, errors.Wrap(err, "internal error").Error()))
+		}
+
+		return user, nil
+	}
+}
+
 // MakeGRPCGetUserEndpoint creates GetUser Endpoint for GRPC
 func MakeGRPCGetUserEndpoint(svc service.Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(service.GetUserRequest)
 		user, err := svc.GetUser(ctx, req)
 		if err != nil {
-			return nil, status.Errorf(codes.Internal, errors.Wrap(err, "internal error").Error())
+			return nil, encodeError(err, status.Errorf(codes.Internal, errors.Wrap(err, "internal error").Error()))
 		}
 
 		return user, nil
@@ -192,7 +815,20 @@ func MakeGRPCCreateUserEndpoint(svc service.Service) endpoint.Endpoint {
 		req := request.(service.CreateUserRequest)
 		user, err := svc.CreateUser(ctx, req)
 		if err != nil {
-			return nil, status.Errorf(codes.InvalidArgument, "invalid request")
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return user, nil
+	}
+}
+
+// MakeGRPCUpdateUserEndpoint creates UpdateUser endpoint.Endpoint for GRPC
+func MakeGRPCUpdateUserEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.UpdateUserRequest)
+		user, err := svc.UpdateUser(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
 		}
 
 		return user, nil
@@ -205,22 +841,59 @@ func MakeGRPCFetchUsersEndpoint(svc service.Service) endpoint.Endpoint {
 		req := request.(service.FetchUsersRequest)
 		users, err := svc.FetchUsers(ctx, req)
 		if err != nil {
-			return nil, status.Errorf(codes.InvalidArgument, "invalid request")
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
 		}
 
 		return users, nil
 	}
 }
 
+// encodeError converts errors classified by the service into the matching
+// gRPC status, returning fallback for any other error.
+func encodeError(err error, fallback error) error {
+	switch errors.Cause(err) {
+	case service.ErrPermissionDenied:
+		return status.Error(codes.PermissionDenied, err.Error())
+	case service.ErrInvalidArgument:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case service.ErrNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case service.ErrAlreadyExists:
+		return status.Error(codes.AlreadyExists, err.Error())
+	case service.ErrFailedPrecondition:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case service.ErrResourceExhausted:
+		return encodeResourceExhausted(err)
+	}
+
+	return fallback
+}
+
+// stringValue dereferences optional strings, which protobuf represents as empty
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
+
 // encodeAccount serializes the engine into a valid protobuf response
 func encodeAccount(a service.Account) *pb.Account {
 	return &pb.Account{
-		ID:           a.ID,
-		Name:         a.Name,
-		ContactEmail: a.ContactEmail,
-		Status:       encodeAccountStatus(a.Status),
-		UpdatedAt:    a.UpdatedAt,
-		CreatedAt:    a.CreatedAt,
+		ID:               a.ID,
+		Name:             a.Name,
+		ContactEmail:     a.ContactEmail,
+		Status:           encodeAccountStatus(a.Status),
+		MergedInto:       stringValue(a.MergedInto),
+		ParentID:         stringValue(a.ParentID),
+		Labels:           a.Labels,
+		Metadata:         a.Metadata,
+		TrialStartedAt:   a.TrialStartedAt,
+		TrialEndsAt:      a.TrialEndsAt,
+		TrialConvertedAt: a.TrialConvertedAt,
+		UpdatedAt:        a.UpdatedAt,
+		CreatedAt:        a.CreatedAt,
 	}
 }
 
@@ -239,13 +912,17 @@ func decodeAccount(a *pb.Account) service.Account {
 // encodeUser serializes the engine into a valid protobuf response
 func encodeUser(a service.User) *pb.User {
 	return &pb.User{
-		ID:        a.ID,
-		Name:      a.Name,
-		Email:     a.Email,
-		Status:    encodeUserStatus(a.Status),
-		LastLogin: a.LastLogin,
-		UpdatedAt: a.UpdatedAt,
-		CreatedAt: a.CreatedAt,
+		ID:         a.ID,
+		Name:       a.Name,
+		Email:      a.Email,
+		Status:     encodeUserStatus(a.Status),
+		LastLogin:  a.LastLogin,
+		VerifiedAt: a.VerifiedAt,
+		Kind:       encodeUserKind(a.Kind),
+		Labels:     a.Labels,
+		Metadata:   a.Metadata,
+		UpdatedAt:  a.UpdatedAt,
+		CreatedAt:  a.CreatedAt,
 	}
 }
 
@@ -264,7 +941,9 @@ func decodeUser(a *pb.User) service.User {
 // encodeGrpcAccountResponse encodes pb Account responses
 func encodeGrpcAccountResponse(_ context.Context, r interface{}) (interface{}, error) {
 	resp := r.(service.Account)
-	return encodeAccount(resp), nil
+	return &pb.GetAccountResponse{
+		Account: *encodeAccount(resp),
+	}, nil
 }
 
 // decodeGrpcCreateAccountRequest decodes Account requests
@@ -274,19 +953,23 @@ func decodeGrpcCreateAccountRequest(ctx context.Context, r interface{}) (interfa
 	return service.CreateAccountRequest{
 		Name:         req.Name,
 		ContactEmail: req.ContactEmail,
+		ParentID:     req.ParentID,
+		Labels:       req.Labels,
+		Metadata:     decodeMetadata(req.Metadata),
+		TrialEndsAt:  req.TrialEndsAt,
 	}, nil
 }
 
-// decodeGrpcGetAccountRequest encodes FetchAccounts responses
+// decodeGrpcGetAccountRequest decodes GetAccount requests
 func decodeGrpcGetAccountRequest(ctx context.Context, r interface{}) (interface{}, error) {
 	req := r.(*pb.GetAccountRequest)
-	return req.ID, nil
+	return service.GetAccountRequest{ID: req.ID}, nil
 }
 
 // decodeGrpcFetchAccountsRequest encodes FetchAccounts responses
 func decodeGrpcFetchAccountsRequest(ctx context.Context, r interface{}) (interface{}, error) {
-	_ = r.(*pb.FetchAccountsRequest)
-	return service.FetchAccountsRequest{}, nil
+	req := r.(*pb.FetchAccountsRequest)
+	return service.FetchAccountsRequest{SubtreeOf: req.SubtreeOf, LabelSelector: req.LabelSelector}, nil
 }
 
 // encodeGrpcCreateAccountResponse encodes CreateAccountResponse responses
@@ -310,12 +993,32 @@ func encodeGrpcFetchAccountsResponse(ctx context.Context, r interface{}) (interf
 	}, nil
 }
 
+// decodeGrpcFindAccountsByContactEmailRequest decodes FindAccountsByContactEmail requests
+func decodeGrpcFindAccountsByContactEmailRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.FindAccountsByContactEmailRequest)
+	return service.FindAccountsByContactEmailRequest{Email: req.Email}, nil
+}
+
+// encodeGrpcFindAccountsByContactEmailResponse encodes FindAccountsByContactEmail responses
+func encodeGrpcFindAccountsByContactEmailResponse(_ context.Context, r interface{}) (interface{}, error) {
+	accounts := []pb.Account{}
+	for _, account := range r.([]service.Account) {
+		accounts = append(accounts, *encodeAccount(account))
+	}
+
+	return &pb.FindAccountsByContactEmailResponse{
+		Accounts: accounts,
+	}, nil
+}
+
 func encodeAccountStatus(status service.AccountStatus) pb.Account_Status {
 	switch status {
 	case service.AccountActive:
 		return pb.Account_ACTIVE
 	case service.AccountSuspended:
 		return pb.Account_SUSPENDED
+	case service.AccountMerged:
+		return pb.Account_MERGED
 	}
 
 	return pb.Account_INACTIVE
@@ -327,6 +1030,8 @@ func decodeAccountStatus(status pb.Account_Status) service.AccountStatus {
 		return service.AccountActive
 	case pb.Account_SUSPENDED:
 		return service.AccountSuspended
+	case pb.Account_MERGED:
+		return service.AccountMerged
 	}
 
 	return service.AccountInactive
@@ -335,7 +1040,9 @@ func decodeAccountStatus(status pb.Account_Status) service.AccountStatus {
 // encodeGrpcUserResponse encodes pb User responses
 func encodeGrpcUserResponse(_ context.Context, r interface{}) (interface{}, error) {
 	resp := r.(service.User)
-	return encodeUser(resp), nil
+	return &pb.GetUserResponse{
+		User: *encodeUser(resp),
+	}, nil
 }
 
 // decodeGrpcCreateUserRequest decodes User requests
@@ -343,22 +1050,32 @@ func decodeGrpcCreateUserRequest(ctx context.Context, r interface{}) (interface{
 	req := r.(*pb.CreateUserRequest)
 
 	return service.CreateUserRequest{
-		AccountID: req.AccountID,
-		Email:     req.Email,
-		Name:      req.Name,
+		AccountID:    req.AccountID,
+		Email:        req.Email,
+		Name:         req.Name,
+		Role:         decodeRole(req.Role),
+		Labels:       req.Labels,
+		Metadata:     decodeMetadata(req.Metadata),
+		CustomFields: req.CustomFields,
 	}, nil
 }
 
-// decodeGrpcGetUserRequest encodes FetchUsers responses
+// decodeGrpcGetUserRequest decodes GetUser requests
 func decodeGrpcGetUserRequest(ctx context.Context, r interface{}) (interface{}, error) {
 	req := r.(*pb.GetUserRequest)
-	return req.ID, nil
+	return service.GetUserRequest{ID: req.ID}, nil
 }
 
 // decodeGrpcFetchUsersRequest encodes FetchUsers responses
 func decodeGrpcFetchUsersRequest(ctx context.Context, r interface{}) (interface{}, error) {
-	_ = r.(*pb.FetchUsersRequest)
-	return service.FetchUsersRequest{}, nil
+	req := r.(*pb.FetchUsersRequest)
+	return service.FetchUsersRequest{
+		GroupID:       req.GroupID,
+		Kind:          decodeUserKind(req.Kind),
+		LabelSelector: req.LabelSelector,
+		AccountIDs:    req.AccountIDs,
+		CustomFields:  req.CustomFields,
+	}, nil
 }
 
 // encodeGrpcCreateUserResponse encodes CreateUserResponse responses
@@ -382,12 +1099,54 @@ func encodeGrpcFetchUsersResponse(ctx context.Context, r interface{}) (interface
 	}, nil
 }
 
+// decodeGrpcUpdateUserRequest decodes UpdateUser requests
+func decodeGrpcUpdateUserRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.UpdateUserRequest)
+
+	update := service.UpdateUserRequest{
+		ID:           req.ID,
+		Labels:       req.Labels,
+		RemoveLabels: req.RemoveLabels,
+		Metadata:     decodeMetadata(req.Metadata),
+	}
+	if req.Name != nil {
+		update.Name = &req.Name.Value
+	}
+	if req.Email != nil {
+		update.Email = &req.Email.Value
+	}
+
+	return update, nil
+}
+
+// encodeGrpcUpdateUserResponse encodes UpdateUser responses
+func encodeGrpcUpdateUserResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.UpdateUserResponse{
+		User: *encodeUser(r.(service.User)),
+	}, nil
+}
+
+// decodeGrpcGetUserByEmailRequest decodes GetUserByEmail requests
+func decodeGrpcGetUserByEmailRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.GetUserByEmailRequest)
+	return service.GetUserByEmailRequest{Email: req.Email, MatchCanonical: req.MatchCanonical}, nil
+}
+
+// encodeGrpcGetUserByEmailResponse encodes GetUserByEmail responses
+func encodeGrpcGetUserByEmailResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.GetUserByEmailResponse{
+		User: *encodeUser(r.(service.User)),
+	}, nil
+}
+
 func encodeUserStatus(status service.UserStatus) pb.User_Status {
 	switch status {
 	case service.UserActive:
 		return pb.User_ACTIVE
 	case service.UserSuspended:
 		return pb.User_SUSPENDED
+	case service.UserPending:
+		return pb.User_PENDING
 	}
 
 	return pb.User_INACTIVE
@@ -399,7 +1158,34 @@ func decodeUserStatus(status pb.User_Status) service.UserStatus {
 		return service.UserActive
 	case pb.User_SUSPENDED:
 		return service.UserSuspended
+	case pb.User_PENDING:
+		return service.UserPending
 	}
 
 	return service.UserInactive
 }
+
+func encodeUserKind(kind service.UserKind) pb.User_Kind {
+	if kind == service.UserService {
+		return pb.User_SERVICE
+	}
+
+	return pb.User_HUMAN
+}
+
+func decodeUserKind(kind pb.User_Kind) service.UserKind {
+	if kind == pb.User_SERVICE {
+		return service.UserService
+	}
+
+	return service.UserHuman
+}
+
+// decodeMetadata leaves metadata unset when the request carries none.
+func decodeMetadata(metadata []byte) json.RawMessage {
+	if len(metadata) == 0 {
+		return nil
+	}
+
+	return json.RawMessage(metadata)
+}
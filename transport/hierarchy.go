@@ -0,0 +1,130 @@
+package transport
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/symptomatichq/customers/protos/customers"
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeGRPCSetAccountParentEndpoint creates SetAccountParent Endpoint for GRPC
+func MakeGRPCSetAccountParentEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.SetAccountParentRequest)
+		account, err := svc.SetAccountParent(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return account, nil
+	}
+}
+
+// MakeGRPCListChildAccountsEndpoint creates ListChildAccounts Endpoint for GRPC
+func MakeGRPCListChildAccountsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListChildAccountsRequest)
+		children, err := svc.ListChildAccounts(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return children, nil
+	}
+}
+
+// MakeGRPCListAncestorAccountsEndpoint creates ListAncestorAccounts Endpoint for GRPC
+func MakeGRPCListAncestorAccountsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListAncestorAccountsRequest)
+		ancestors, err := svc.ListAncestorAccounts(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return ancestors, nil
+	}
+}
+
+// SetAccountParent
+func (s *grpcServer) SetAccountParent(ctx context.Context, req *pb.SetAccountParentRequest) (*pb.SetAccountParentResponse, error) {
+	_, resp, err := s.setAccountParent.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.SetAccountParentResponse), nil
+}
+
+// ListChildAccounts
+func (s *grpcServer) ListChildAccounts(ctx context.Context, req *pb.ListChildAccountsRequest) (*pb.ListChildAccountsResponse, error) {
+	_, resp, err := s.listChildAccounts.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.ListChildAccountsResponse), nil
+}
+
+// ListAncestorAccounts
+func (s *grpcServer) ListAncestorAccounts(ctx context.Context, req *pb.ListAncestorAccountsRequest) (*pb.ListAncestorAccountsResponse, error) {
+	_, resp, err := s.listAncestorAccounts.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.ListAncestorAccountsResponse), nil
+}
+
+// decodeGrpcSetAccountParentRequest decodes SetAccountParent requests
+func decodeGrpcSetAccountParentRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.SetAccountParentRequest)
+	return service.SetAccountParentRequest{AccountID: req.AccountID, ParentID: req.ParentID}, nil
+}
+
+// encodeGrpcSetAccountParentResponse encodes SetAccountParent responses
+func encodeGrpcSetAccountParentResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.SetAccountParentResponse{
+		Account: *encodeAccount(r.(service.Account)),
+	}, nil
+}
+
+// decodeGrpcListChildAccountsRequest decodes ListChildAccounts requests
+func decodeGrpcListChildAccountsRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ListChildAccountsRequest)
+	return service.ListChildAccountsRequest{AccountID: req.AccountID, Recursive: req.Recursive}, nil
+}
+
+// encodeGrpcListChildAccountsResponse encodes ListChildAccounts responses
+func encodeGrpcListChildAccountsResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.ListChildAccountsResponse{
+		Accounts: encodeAccounts(r.([]service.Account)),
+	}, nil
+}
+
+// decodeGrpcListAncestorAccountsRequest decodes ListAncestorAccounts requests
+func decodeGrpcListAncestorAccountsRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ListAncestorAccountsRequest)
+	return service.ListAncestorAccountsRequest{AccountID: req.AccountID}, nil
+}
+
+// encodeGrpcListAncestorAccountsResponse encodes ListAncestorAccounts responses
+func encodeGrpcListAncestorAccountsResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.ListAncestorAccountsResponse{
+		Accounts: encodeAccounts(r.([]service.Account)),
+	}, nil
+}
+
+// encodeAccounts serializes a list of accounts into protobuf messages
+func encodeAccounts(accounts []service.Account) []pb.Account {
+	encoded := []pb.Account{}
+	for _, account := range accounts {
+		encoded = append(encoded, *encodeAccount(account))
+	}
+
+	return encoded
+}
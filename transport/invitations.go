@@ -0,0 +1,216 @@
+package transport
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/symptomatichq/customers/protos/customers"
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeGRPCInviteUserEndpoint creates InviteUser Endpoint for GRPC
+func MakeGRPCInviteUserEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.InviteUserRequest)
+		invitation, err := svc.InviteUser(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return invitation, nil
+	}
+}
+
+// MakeGRPCListInvitationsEndpoint creates ListInvitations Endpoint for GRPC
+func MakeGRPCListInvitationsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListInvitationsRequest)
+		invitations, err := svc.ListInvitations(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return invitations, nil
+	}
+}
+
+// MakeGRPCRevokeInvitationEndpoint creates RevokeInvitation Endpoint for GRPC
+func MakeGRPCRevokeInvitationEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.RevokeInvitationRequest)
+		invitation, err := svc.RevokeInvitation(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return invitation, nil
+	}
+}
+
+// MakeGRPCAcceptInvitationEndpoint creates AcceptInvitation Endpoint for GRPC
+func MakeGRPCAcceptInvitationEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.AcceptInvitationRequest)
+		membership, err := svc.AcceptInvitation(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return membership, nil
+	}
+}
+
+// InviteUser
+func (s *grpcServer) InviteUser(ctx context.Context, req *pb.InviteUserRequest) (*pb.InviteUserResponse, error) {
+	_, resp, err := s.inviteUser.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.InviteUserResponse), nil
+}
+
+// ListInvitations
+func (s *grpcServer) ListInvitations(ctx context.Context, req *pb.ListInvitationsRequest) (*pb.ListInvitationsResponse, error) {
+	_, resp, err := s.listInvitations.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.ListInvitationsResponse), nil
+}
+
+// RevokeInvitation
+func (s *grpcServer) RevokeInvitation(ctx context.Context, req *pb.RevokeInvitationRequest) (*pb.RevokeInvitationResponse, error) {
+	_, resp, err := s.revokeInvitation.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.RevokeInvitationResponse), nil
+}
+
+// AcceptInvitation
+func (s *grpcServer) AcceptInvitation(ctx context.Context, req *pb.AcceptInvitationRequest) (*pb.AcceptInvitationResponse, error) {
+	_, resp, err := s.acceptInvitation.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.AcceptInvitationResponse), nil
+}
+
+// decodeGrpcInviteUserRequest decodes InviteUser requests
+func decodeGrpcInviteUserRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.InviteUserRequest)
+	return service.InviteUserRequest{
+		AccountID: req.AccountID,
+		Email:     req.Email,
+		Name:      req.Name,
+		Role:      decodeRole(req.Role),
+	}, nil
+}
+
+// encodeGrpcInviteUserResponse encodes InviteUser responses
+func encodeGrpcInviteUserResponse(_ context.Context, r interface{}) (interface{}, error) {
+	issued := r.(service.IssuedInvitation)
+	return &pb.InviteUserResponse{
+		Invitation: *encodeInvitation(issued.Invitation),
+		Token:      issued.Token,
+	}, nil
+}
+
+// decodeGrpcListInvitationsRequest decodes ListInvitations requests
+func decodeGrpcListInvitationsRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ListInvitationsRequest)
+	return service.ListInvitationsRequest{
+		AccountID: req.AccountID,
+		Status:    decodeInvitationStatus(req.Status),
+	}, nil
+}
+
+// encodeGrpcListInvitationsResponse encodes ListInvitations responses
+func encodeGrpcListInvitationsResponse(_ context.Context, r interface{}) (interface{}, error) {
+	invitations := []pb.Invitation{}
+	for _, invitation := range r.([]service.Invitation) {
+		invitations = append(invitations, *encodeInvitation(invitation))
+	}
+
+	return &pb.ListInvitationsResponse{
+		Invitations: invitations,
+	}, nil
+}
+
+// decodeGrpcRevokeInvitationRequest decodes RevokeInvitation requests
+func decodeGrpcRevokeInvitationRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.RevokeInvitationRequest)
+	return service.RevokeInvitationRequest{AccountID: req.AccountID, ID: req.ID}, nil
+}
+
+// encodeGrpcRevokeInvitationResponse encodes RevokeInvitation responses
+func encodeGrpcRevokeInvitationResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.RevokeInvitationResponse{
+		Invitation: *encodeInvitation(r.(service.Invitation)),
+	}, nil
+}
+
+// decodeGrpcAcceptInvitationRequest decodes AcceptInvitation requests
+func decodeGrpcAcceptInvitationRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.AcceptInvitationRequest)
+	return service.AcceptInvitationRequest{Token: req.Token, Name: req.Name}, nil
+}
+
+// encodeGrpcAcceptInvitationResponse encodes AcceptInvitation responses
+func encodeGrpcAcceptInvitationResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.AcceptInvitationResponse{
+		Membership: *encodeMembership(r.(service.Membership)),
+	}, nil
+}
+
+// encodeInvitation serializes an invitation into its protobuf message,
+// leaving out the token hash
+func encodeInvitation(i service.Invitation) *pb.Invitation {
+	return &pb.Invitation{
+		ID:         i.ID,
+		AccountID:  i.AccountID,
+		Email:      i.Email,
+		Name:       i.Name,
+		Role:       encodeRole(i.Role),
+		Status:     encodeInvitationStatus(i.Status),
+		InvitedBy:  i.InvitedBy,
+		UserID:     stringValue(i.UserID),
+		ExpiresAt:  i.ExpiresAt,
+		AcceptedAt: i.AcceptedAt,
+		UpdatedAt:  i.UpdatedAt,
+		CreatedAt:  i.CreatedAt,
+	}
+}
+
+func encodeInvitationStatus(status service.InvitationStatus) pb.Invitation_Status {
+	switch status {
+	case service.InvitationPending:
+		return pb.Invitation_PENDING
+	case service.InvitationAccepted:
+		return pb.Invitation_ACCEPTED
+	case service.InvitationRevoked:
+		return pb.Invitation_REVOKED
+	}
+
+	return pb.Invitation_STATUS_UNSPECIFIED
+}
+
+func decodeInvitationStatus(status pb.Invitation_Status) service.InvitationStatus {
+	switch status {
+	case pb.Invitation_PENDING:
+		return service.InvitationPending
+	case pb.Invitation_ACCEPTED:
+		return service.InvitationAccepted
+	case pb.Invitation_REVOKED:
+		return service.InvitationRevoked
+	}
+
+	return ""
+}
@@ -0,0 +1,349 @@
+package transport
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/symptomatichq/customers/protos/customers"
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeGRPCGrantRoleEndpoint creates GrantRole Endpoint for GRPC
+func MakeGRPCGrantRoleEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.GrantRoleRequest)
+		membership, err := svc.GrantRole(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return membership, nil
+	}
+}
+
+// MakeGRPCChangeRoleEndpoint creates ChangeRole Endpoint for GRPC
+func MakeGRPCChangeRoleEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ChangeRoleRequest)
+		membership, err := svc.ChangeRole(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return membership, nil
+	}
+}
+
+// MakeGRPCRevokeRoleEndpoint creates RevokeRole Endpoint for GRPC
+func MakeGRPCRevokeRoleEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.RevokeRoleRequest)
+		if err := svc.RevokeRole(ctx, req); err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return req, nil
+	}
+}
+
+// MakeGRPCFetchMembershipsEndpoint creates FetchMemberships Endpoint for GRPC
+func MakeGRPCFetchMembershipsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.FetchMembershipsRequest)
+		memberships, err := svc.FetchMemberships(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return memberships, nil
+	}
+}
+
+// MakeGRPCChangeMembershipStatusEndpoint creates ChangeMembershipStatus Endpoint for GRPC
+func MakeGRPCChangeMembershipStatusEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ChangeMembershipStatusRequest)
+		membership, err := svc.ChangeMembershipStatus(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return membership, nil
+	}
+}
+
+// MakeGRPCFetchUserAccountsEndpoint creates FetchUserAccounts Endpoint for GRPC
+func MakeGRPCFetchUserAccountsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.FetchUserAccountsRequest)
+		accounts, err := svc.FetchUserAccounts(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return accounts, nil
+	}
+}
+
+// MakeGRPCTransferUserEndpoint creates TransferUser Endpoint for GRPC
+func MakeGRPCTransferUserEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.TransferUserRequest)
+		transfer, err := svc.TransferUser(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return transfer, nil
+	}
+}
+
+// GrantRole
+func (s *grpcServer) GrantRole(ctx context.Context, req *pb.GrantRoleRequest) (*pb.GrantRoleResponse, error) {
+	_, resp, err := s.grantRole.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.GrantRoleResponse), nil
+}
+
+// ChangeRole
+func (s *grpcServer) ChangeRole(ctx context.Context, req *pb.ChangeRoleRequest) (*pb.ChangeRoleResponse, error) {
+	_, resp, err := s.changeRole.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.ChangeRoleResponse), nil
+}
+
+// RevokeRole
+func (s *grpcServer) RevokeRole(ctx context.Context, req *pb.RevokeRoleRequest) (*pb.RevokeRoleResponse, error) {
+	_, resp, err := s.revokeRole.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.RevokeRoleResponse), nil
+}
+
+// FetchMemberships
+func (s *grpcServer) FetchMemberships(ctx context.Context, req *pb.FetchMembershipsRequest) (*pb.FetchMembershipsResponse, error) {
+	_, resp, err := s.fetchMemberships.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.FetchMembershipsResponse), nil
+}
+
+// ChangeMembershipStatus
+func (s *grpcServer) ChangeMembershipStatus(ctx context.Context, req *pb.ChangeMembershipStatusRequest) (*pb.ChangeMembershipStatusResponse, error) {
+	_, resp, err := s.changeMembershipStatus.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.ChangeMembershipStatusResponse), nil
+}
+
+// FetchUserAccounts
+func (s *grpcServer) FetchUserAccounts(ctx context.Context, req *pb.FetchUserAccountsRequest) (*pb.FetchUserAccountsResponse, error) {
+	_, resp, err := s.fetchUserAccounts.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.FetchUserAccountsResponse), nil
+}
+
+// TransferUser
+func (s *grpcServer) TransferUser(ctx context.Context, req *pb.TransferUserRequest) (*pb.TransferUserResponse, error) {
+	_, resp, err := s.transferUser.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.TransferUserResponse), nil
+}
+
+// decodeGrpcGrantRoleRequest decodes GrantRole requests
+func decodeGrpcGrantRoleRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.GrantRoleRequest)
+	return service.GrantRoleRequest{
+		AccountID: req.AccountID,
+		UserID:    req.UserID,
+		Role:      decodeRole(req.Role),
+	}, nil
+}
+
+// encodeGrpcGrantRoleResponse encodes GrantRole responses
+func encodeGrpcGrantRoleResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.GrantRoleResponse{
+		Membership: *encodeMembership(r.(service.Membership)),
+	}, nil
+}
+
+// decodeGrpcChangeRoleRequest decodes ChangeRole requests
+func decodeGrpcChangeRoleRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ChangeRoleRequest)
+	return service.ChangeRoleRequest{
+		AccountID: req.AccountID,
+		UserID:    req.UserID,
+		Role:      decodeRole(req.Role),
+	}, nil
+}
+
+// encodeGrpcChangeRoleResponse encodes ChangeRole responses
+func encodeGrpcChangeRoleResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.ChangeRoleResponse{
+		Membership: *encodeMembership(r.(service.Membership)),
+	}, nil
+}
+
+// decodeGrpcRevokeRoleRequest decodes RevokeRole requests
+func decodeGrpcRevokeRoleRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.RevokeRoleRequest)
+	return service.RevokeRoleRequest{AccountID: req.AccountID, UserID: req.UserID}, nil
+}
+
+// encodeGrpcRevokeRoleResponse encodes RevokeRole responses
+func encodeGrpcRevokeRoleResponse(_ context.Context, _ interface{}) (interface{}, error) {
+	return &pb.RevokeRoleResponse{}, nil
+}
+
+// decodeGrpcFetchMembershipsRequest decodes FetchMemberships requests
+func decodeGrpcFetchMembershipsRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.FetchMembershipsRequest)
+	return service.FetchMembershipsRequest{AccountID: req.AccountID, UserID: req.UserID}, nil
+}
+
+// encodeGrpcFetchMembershipsResponse encodes FetchMemberships responses
+func encodeGrpcFetchMembershipsResponse(_ context.Context, r interface{}) (interface{}, error) {
+	memberships := []pb.Membership{}
+	for _, membership := range r.([]service.Membership) {
+		memberships = append(memberships, *encodeMembership(membership))
+	}
+
+	return &pb.FetchMembershipsResponse{
+		Memberships: memberships,
+	}, nil
+}
+
+// decodeGrpcChangeMembershipStatusRequest decodes ChangeMembershipStatus requests
+func decodeGrpcChangeMembershipStatusRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ChangeMembershipStatusRequest)
+	return service.ChangeMembershipStatusRequest{
+		AccountID: req.AccountID,
+		UserID:    req.UserID,
+		Status:    decodeUserStatus(req.Status),
+	}, nil
+}
+
+// encodeGrpcChangeMembershipStatusResponse encodes ChangeMembershipStatus responses
+func encodeGrpcChangeMembershipStatusResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.ChangeMembershipStatusResponse{
+		Membership: *encodeMembership(r.(service.Membership)),
+	}, nil
+}
+
+// decodeGrpcFetchUserAccountsRequest decodes FetchUserAccounts requests
+func decodeGrpcFetchUserAccountsRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.FetchUserAccountsRequest)
+	return service.FetchUserAccountsRequest{UserID: req.UserID}, nil
+}
+
+// encodeGrpcFetchUserAccountsResponse encodes FetchUserAccounts responses
+func encodeGrpcFetchUserAccountsResponse(_ context.Context, r interface{}) (interface{}, error) {
+	accounts := []pb.UserAccount{}
+	for _, account := range r.([]service.UserAccount) {
+		accounts = append(accounts, pb.UserAccount{
+			Account: *encodeAccount(account.Account),
+			Role:    encodeRole(account.Role),
+			Status:  encodeUserStatus(account.Status),
+		})
+	}
+
+	return &pb.FetchUserAccountsResponse{
+		Accounts: accounts,
+	}, nil
+}
+
+// decodeGrpcTransferUserRequest decodes TransferUser requests
+func decodeGrpcTransferUserRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.TransferUserRequest)
+	return service.TransferUserRequest{
+		UserID:        req.UserID,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Role:          decodeRole(req.Role),
+	}, nil
+}
+
+// encodeGrpcTransferUserResponse encodes TransferUser responses
+func encodeGrpcTransferUserResponse(_ context.Context, r interface{}) (interface{}, error) {
+	transfer := r.(service.UserTransfer)
+	return &pb.TransferUserResponse{
+		Transfer: pb.UserTransfer{
+			ID:            transfer.ID,
+			UserID:        transfer.UserID,
+			FromAccountID: transfer.FromAccountID,
+			ToAccountID:   transfer.ToAccountID,
+			PreviousRole:  encodeRole(transfer.PreviousRole),
+			Role:          encodeRole(transfer.Role),
+			TransferredBy: transfer.TransferredBy,
+			CreatedAt:     transfer.CreatedAt,
+		},
+	}, nil
+}
+
+// encodeMembership serializes a membership into its protobuf message
+func encodeMembership(m service.Membership) *pb.Membership {
+	return &pb.Membership{
+		AccountID:    m.AccountID,
+		UserID:       m.UserID,
+		Role:         encodeRole(m.Role),
+		Status:       encodeUserStatus(m.Status),
+		CustomFields: m.CustomFields,
+		UpdatedAt:    m.UpdatedAt,
+		CreatedAt:    m.CreatedAt,
+	}
+}
+
+func encodeRole(role service.Role) pb.Membership_Role {
+	switch role {
+	case service.RoleOwner:
+		return pb.Membership_OWNER
+	case service.RoleAdmin:
+		return pb.Membership_ADMIN
+	case service.RoleMember:
+		return pb.Membership_MEMBER
+	case service.RoleBilling:
+		return pb.Membership_BILLING
+	case service.RoleReadOnly:
+		return pb.Membership_READ_ONLY
+	}
+
+	return pb.Membership_ROLE_UNSPECIFIED
+}
+
+func decodeRole(role pb.Membership_Role) service.Role {
+	switch role {
+	case pb.Membership_OWNER:
+		return service.RoleOwner
+	case pb.Membership_ADMIN:
+		return service.RoleAdmin
+	case pb.Membership_MEMBER:
+		return service.RoleMember
+	case pb.Membership_BILLING:
+		return service.RoleBilling
+	case pb.Membership_READ_ONLY:
+		return service.RoleReadOnly
+	}
+
+	return ""
+}
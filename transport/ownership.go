@@ -0,0 +1,209 @@
+package transport
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/symptomatichq/customers/protos/customers"
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeGRPCInitiateOwnershipTransferEndpoint creates InitiateOwnershipTransfer Endpoint for GRPC
+func MakeGRPCInitiateOwnershipTransferEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.InitiateOwnershipTransferRequest)
+		transfer, err := svc.InitiateOwnershipTransfer(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return transfer, nil
+	}
+}
+
+// MakeGRPCAcceptOwnershipTransferEndpoint creates AcceptOwnershipTransfer Endpoint for GRPC
+func MakeGRPCAcceptOwnershipTransferEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.AcceptOwnershipTransferRequest)
+		transfer, err := svc.AcceptOwnershipTransfer(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return transfer, nil
+	}
+}
+
+// MakeGRPCCancelOwnershipTransferEndpoint creates CancelOwnershipTransfer Endpoint for GRPC
+func MakeGRPCCancelOwnershipTransferEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.CancelOwnershipTransferRequest)
+		transfer, err := svc.CancelOwnershipTransfer(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return transfer, nil
+	}
+}
+
+// MakeGRPCListOwnershipTransfersEndpoint creates ListOwnershipTransfers Endpoint for GRPC
+func MakeGRPCListOwnershipTransfersEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListOwnershipTransfersRequest)
+		transfers, err := svc.ListOwnershipTransfers(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return transfers, nil
+	}
+}
+
+// InitiateOwnershipTransfer
+func (s *grpcServer) InitiateOwnershipTransfer(ctx context.Context, req *pb.InitiateOwnershipTransferRequest) (*pb.InitiateOwnershipTransferResponse, error) {
+	_, resp, err := s.initiateOwnershipTransfer.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.InitiateOwnershipTransferResponse), nil
+}
+
+// AcceptOwnershipTransfer
+func (s *grpcServer) AcceptOwnershipTransfer(ctx context.Context, req *pb.AcceptOwnershipTransferRequest) (*pb.AcceptOwnershipTransferResponse, error) {
+	_, resp, err := s.acceptOwnershipTransfer.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.AcceptOwnershipTransferResponse), nil
+}
+
+// CancelOwnershipTransfer
+func (s *grpcServer) CancelOwnershipTransfer(ctx context.Context, req *pb.CancelOwnershipTransferRequest) (*pb.CancelOwnershipTransferResponse, error) {
+	_, resp, err := s.cancelOwnershipTransfer.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.CancelOwnershipTransferResponse), nil
+}
+
+// ListOwnershipTransfers
+func (s *grpcServer) ListOwnershipTransfers(ctx context.Context, req *pb.ListOwnershipTransfersRequest) (*pb.ListOwnershipTransfersResponse, error) {
+	_, resp, err := s.listOwnershipTransfers.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.ListOwnershipTransfersResponse), nil
+}
+
+// decodeGrpcInitiateOwnershipTransferRequest decodes InitiateOwnershipTransfer requests
+func decodeGrpcInitiateOwnershipTransferRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.InitiateOwnershipTransferRequest)
+	return service.InitiateOwnershipTransferRequest{
+		AccountID:  req.AccountID,
+		FromUserID: req.FromUserID,
+		ToUserID:   req.ToUserID,
+	}, nil
+}
+
+// encodeGrpcInitiateOwnershipTransferResponse encodes InitiateOwnershipTransfer responses
+func encodeGrpcInitiateOwnershipTransferResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.InitiateOwnershipTransferResponse{
+		Transfer: *encodeOwnershipTransfer(r.(service.OwnershipTransfer)),
+	}, nil
+}
+
+// decodeGrpcAcceptOwnershipTransferRequest decodes AcceptOwnershipTransfer requests
+func decodeGrpcAcceptOwnershipTransferRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.AcceptOwnershipTransferRequest)
+	return service.AcceptOwnershipTransferRequest{ID: req.ID}, nil
+}
+
+// encodeGrpcAcceptOwnershipTransferResponse encodes AcceptOwnershipTransfer responses
+func encodeGrpcAcceptOwnershipTransferResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.AcceptOwnershipTransferResponse{
+		Transfer: *encodeOwnershipTransfer(r.(service.OwnershipTransfer)),
+	}, nil
+}
+
+// decodeGrpcCancelOwnershipTransferRequest decodes CancelOwnershipTransfer requests
+func decodeGrpcCancelOwnershipTransferRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.CancelOwnershipTransferRequest)
+	return service.CancelOwnershipTransferRequest{AccountID: req.AccountID, ID: req.ID}, nil
+}
+
+// encodeGrpcCancelOwnershipTransferResponse encodes CancelOwnershipTransfer responses
+func encodeGrpcCancelOwnershipTransferResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.CancelOwnershipTransferResponse{
+		Transfer: *encodeOwnershipTransfer(r.(service.OwnershipTransfer)),
+	}, nil
+}
+
+// decodeGrpcListOwnershipTransfersRequest decodes ListOwnershipTransfers requests
+func decodeGrpcListOwnershipTransfersRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ListOwnershipTransfersRequest)
+
+	var status service.OwnershipTransferStatus
+	switch req.Status {
+	case pb.OwnershipTransfer_PENDING:
+		status = service.OwnershipTransferPending
+	case pb.OwnershipTransfer_ACCEPTED:
+		status = service.OwnershipTransferAccepted
+	case pb.OwnershipTransfer_CANCELLED:
+		status = service.OwnershipTransferCancelled
+	case pb.OwnershipTransfer_EXPIRED:
+		status = service.OwnershipTransferExpired
+	}
+
+	return service.ListOwnershipTransfersRequest{AccountID: req.AccountID, Status: status}, nil
+}
+
+// encodeGrpcListOwnershipTransfersResponse encodes ListOwnershipTransfers responses
+func encodeGrpcListOwnershipTransfersResponse(_ context.Context, r interface{}) (interface{}, error) {
+	transfers := []pb.OwnershipTransfer{}
+	for _, transfer := range r.([]service.OwnershipTransfer) {
+		transfers = append(transfers, *encodeOwnershipTransfer(transfer))
+	}
+
+	return &pb.ListOwnershipTransfersResponse{
+		Transfers: transfers,
+	}, nil
+}
+
+// encodeOwnershipTransfer serializes an ownership transfer into its protobuf message
+func encodeOwnershipTransfer(t service.OwnershipTransfer) *pb.OwnershipTransfer {
+	encoded := &pb.OwnershipTransfer{
+		ID:          t.ID,
+		AccountID:   t.AccountID,
+		FromUserID:  t.FromUserID,
+		ToUserID:    t.ToUserID,
+		InitiatedBy: t.InitiatedBy,
+		Override:    t.Override,
+		CancelledBy: t.CancelledBy,
+		ExpiresAt:   t.ExpiresAt,
+		AcceptedAt:  t.AcceptedAt,
+		CancelledAt: t.CancelledAt,
+		UpdatedAt:   t.UpdatedAt,
+		CreatedAt:   t.CreatedAt,
+	}
+
+	switch t.Status {
+	case service.OwnershipTransferPending:
+		encoded.Status = pb.OwnershipTransfer_PENDING
+	case service.OwnershipTransferAccepted:
+		encoded.Status = pb.OwnershipTransfer_ACCEPTED
+	case service.OwnershipTransferCancelled:
+		encoded.Status = pb.OwnershipTransfer_CANCELLED
+	case service.OwnershipTransferExpired:
+		encoded.Status = pb.OwnershipTransfer_EXPIRED
+	}
+
+	return encoded
+}
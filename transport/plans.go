@@ -0,0 +1,464 @@
+package transport
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/gogo/protobuf/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/symptomatichq/customers/protos/customers"
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeGRPCCreatePlanEndpoint creates CreatePlan Endpoint for GRPC
+func MakeGRPCCreatePlanEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.CreatePlanRequest)
+		plan, err := svc.CreatePlan(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return plan, nil
+	}
+}
+
+// MakeGRPCListPlansEndpoint creates ListPlans Endpoint for GRPC
+func MakeGRPCListPlansEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListPlansRequest)
+		plans, err := svc.ListPlans(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return plans, nil
+	}
+}
+
+// MakeGRPCUpdatePlanEndpoint creates UpdatePlan Endpoint for GRPC
+func MakeGRPCUpdatePlanEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.UpdatePlanRequest)
+		plan, err := svc.UpdatePlan(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return plan, nil
+	}
+}
+
+// MakeGRPCAssignPlanEndpoint creates AssignPlan Endpoint for GRPC
+func MakeGRPCAssignPlanEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.AssignPlanRequest)
+		assignment, err := svc.AssignPlan(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return assignment, nil
+	}
+}
+
+// MakeGRPCListAccountPlansEndpoint creates ListAccountPlans Endpoint for GRPC
+func MakeGRPCListAccountPlansEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListAccountPlansRequest)
+		assignments, err := svc.ListAccountPlans(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return assignments, nil
+	}
+}
+
+// MakeGRPCSetEntitlementOverrideEndpoint creates SetEntitlementOverride Endpoint for GRPC
+func MakeGRPCSetEntitlementOverrideEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.SetEntitlementOverrideRequest)
+		override, err := svc.SetEntitlementOverride(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return override, nil
+	}
+}
+
+// MakeGRPCRemoveEntitlementOverrideEndpoint creates RemoveEntitlementOverride Endpoint for GRPC
+func MakeGRPCRemoveEntitlementOverrideEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.RemoveEntitlementOverrideRequest)
+		if err := svc.RemoveEntitlementOverride(ctx, req); err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return req, nil
+	}
+}
+
+// MakeGRPCListAccountEntitlementsEndpoint creates ListAccountEntitlements Endpoint for GRPC
+func MakeGRPCListAccountEntitlementsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListAccountEntitlementsRequest)
+		entitlements, err := svc.ListAccountEntitlements(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return entitlements, nil
+	}
+}
+
+// MakeGRPCCheckEntitlementEndpoint creates CheckEntitlement Endpoint for GRPC
+func MakeGRPCCheckEntitlementEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.CheckEntitlementRequest)
+		check, err := svc.CheckEntitlement(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return check, nil
+	}
+}
+
+// CreatePlan
+func (s *grpcServer) CreatePlan(ctx context.Context, req *pb.CreatePlanRequest) (*pb.CreatePlanResponse, error) {
+	_, resp, err := s.createPlan.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.CreatePlanResponse), nil
+}
+
+// ListPlans
+func (s *grpcServer) ListPlans(ctx context.Context, req *pb.ListPlansRequest) (*pb.ListPlansResponse, error) {
+	_, resp, err := s.listPlans.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.ListPlansResponse), nil
+}
+
+// UpdatePlan
+func (s *grpcServer) UpdatePlan(ctx context.Context, req *pb.UpdatePlanRequest) (*pb.UpdatePlanResponse, error) {
+	_, resp, err := s.updatePlan.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.UpdatePlanResponse), nil
+}
+
+// AssignPlan
+func (s *grpcServer) AssignPlan(ctx context.Context, req *pb.AssignPlanRequest) (*pb.AssignPlanResponse, error) {
+	_, resp, err := s.assignPlan.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.AssignPlanResponse), nil
+}
+
+// ListAccountPlans
+func (s *grpcServer) ListAccountPlans(ctx context.Context, req *pb.ListAccountPlansRequest) (*pb.ListAccountPlansResponse, error) {
+	_, resp, err := s.listAccountPlans.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.ListAccountPlansResponse), nil
+}
+
+// SetEntitlementOverride
+func (s *grpcServer) SetEntitlementOverride(ctx context.Context, req *pb.SetEntitlementOverrideRequest) (*pb.SetEntitlementOverrideResponse, error) {
+	_, resp, err := s.setEntitlementOverride.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.SetEntitlementOverrideResponse), nil
+}
+
+// RemoveEntitlementOverride
+func (s *grpcServer) RemoveEntitlementOverride(ctx context.Context, req *pb.RemoveEntitlementOverrideRequest) (*pb.RemoveEntitlementOverrideResponse, error) {
+	_, resp, err := s.removeEntitlementOverride.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.RemoveEntitlementOverrideResponse), nil
+}
+
+// ListAccountEntitlements
+func (s *grpcServer) ListAccountEntitlements(ctx context.Context, req *pb.ListAccountEntitlementsRequest) (*pb.ListAccountEntitlementsResponse, error) {
+	_, resp, err := s.listAccountEntitlements.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.ListAccountEntitlementsResponse), nil
+}
+
+// CheckEntitlement
+func (s *grpcServer) CheckEntitlement(ctx context.Context, req *pb.CheckEntitlementRequest) (*pb.CheckEntitlementResponse, error) {
+	_, resp, err := s.checkEntitlement.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.CheckEntitlementResponse), nil
+}
+
+// decodeGrpcCreatePlanRequest decodes CreatePlan requests
+func decodeGrpcCreatePlanRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.CreatePlanRequest)
+	return service.CreatePlanRequest{
+		Key:          req.Key,
+		Name:         req.Name,
+		Entitlements: decodeEntitlements(req.Entitlements),
+	}, nil
+}
+
+// encodeGrpcCreatePlanResponse encodes CreatePlan responses
+func encodeGrpcCreatePlanResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.CreatePlanResponse{
+		Plan: *encodePlan(r.(service.Plan)),
+	}, nil
+}
+
+// decodeGrpcListPlansRequest decodes ListPlans requests
+func decodeGrpcListPlansRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ListPlansRequest)
+	return service.ListPlansRequest{IncludeArchived: req.IncludeArchived}, nil
+}
+
+// encodeGrpcListPlansResponse encodes ListPlans responses
+func encodeGrpcListPlansResponse(_ context.Context, r interface{}) (interface{}, error) {
+	plans := []pb.Plan{}
+	for _, plan := range r.([]service.Plan) {
+		plans = append(plans, *encodePlan(plan))
+	}
+
+	return &pb.ListPlansResponse{
+		Plans: plans,
+	}, nil
+}
+
+// decodeGrpcUpdatePlanRequest decodes UpdatePlan requests
+func decodeGrpcUpdatePlanRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.UpdatePlanRequest)
+
+	update := service.UpdatePlanRequest{ID: req.ID}
+	if req.Name != nil {
+		update.Name = &req.Name.Value
+	}
+	if req.Archived != nil {
+		update.Archived = &req.Archived.Value
+	}
+	if req.ReplaceEntitlements {
+		update.Entitlements = decodeEntitlements(req.Entitlements)
+	}
+
+	return update, nil
+}
+
+// encodeGrpcUpdatePlanResponse encodes UpdatePlan responses
+func encodeGrpcUpdatePlanResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.UpdatePlanResponse{
+		Plan: *encodePlan(r.(service.Plan)),
+	}, nil
+}
+
+// decodeGrpcAssignPlanRequest decodes AssignPlan requests
+func decodeGrpcAssignPlanRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.AssignPlanRequest)
+	return service.AssignPlanRequest{
+		AccountID: req.AccountID,
+		PlanID:    req.PlanID,
+		StartsAt:  req.StartsAt,
+		EndsAt:    req.EndsAt,
+	}, nil
+}
+
+// encodeGrpcAssignPlanResponse encodes AssignPlan responses
+func encodeGrpcAssignPlanResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.AssignPlanResponse{
+		Assignment: *encodeAccountPlan(r.(service.AccountPlan)),
+	}, nil
+}
+
+// decodeGrpcListAccountPlansRequest decodes ListAccountPlans requests
+func decodeGrpcListAccountPlansRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ListAccountPlansRequest)
+	return service.ListAccountPlansRequest{AccountID: req.AccountID}, nil
+}
+
+// encodeGrpcListAccountPlansResponse encodes ListAccountPlans responses
+func encodeGrpcListAccountPlansResponse(_ context.Context, r interface{}) (interface{}, error) {
+	assignments := []pb.AccountPlan{}
+	for _, assignment := range r.([]service.AccountPlan) {
+		assignments = append(assignments, *encodeAccountPlan(assignment))
+	}
+
+	return &pb.ListAccountPlansResponse{
+		Assignments: assignments,
+	}, nil
+}
+
+// decodeGrpcSetEntitlementOverrideRequest decodes SetEntitlementOverride requests
+func decodeGrpcSetEntitlementOverrideRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.SetEntitlementOverrideRequest)
+	return service.SetEntitlementOverrideRequest{
+		AccountID: req.AccountID,
+		Key:       req.Key,
+		Enabled:   req.Enabled,
+		Limit:     decodeLimit(req.Limit),
+		Reason:    req.Reason,
+		ExpiresAt: req.ExpiresAt,
+	}, nil
+}
+
+// encodeGrpcSetEntitlementOverrideResponse encodes SetEntitlementOverride responses
+func encodeGrpcSetEntitlementOverrideResponse(_ context.Context, r interface{}) (interface{}, error) {
+	o := r.(service.EntitlementOverride)
+	return &pb.SetEntitlementOverrideResponse{
+		Override: pb.EntitlementOverride{
+			AccountID: o.AccountID,
+			Key:       o.Key,
+			Enabled:   o.Enabled,
+			Limit:     encodeLimit(o.Limit),
+			Reason:    o.Reason,
+			ExpiresAt: o.ExpiresAt,
+			CreatedBy: o.CreatedBy,
+			UpdatedAt: o.UpdatedAt,
+			CreatedAt: o.CreatedAt,
+		},
+	}, nil
+}
+
+// decodeGrpcRemoveEntitlementOverrideRequest decodes RemoveEntitlementOverride requests
+func decodeGrpcRemoveEntitlementOverrideRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.RemoveEntitlementOverrideRequest)
+	return service.RemoveEntitlementOverrideRequest{AccountID: req.AccountID, Key: req.Key}, nil
+}
+
+// encodeGrpcRemoveEntitlementOverrideResponse encodes RemoveEntitlementOverride responses
+func encodeGrpcRemoveEntitlementOverrideResponse(_ context.Context, _ interface{}) (interface{}, error) {
+	return &pb.RemoveEntitlementOverrideResponse{}, nil
+}
+
+// decodeGrpcListAccountEntitlementsRequest decodes ListAccountEntitlements requests
+func decodeGrpcListAccountEntitlementsRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ListAccountEntitlementsRequest)
+	return service.ListAccountEntitlementsRequest{AccountID: req.AccountID}, nil
+}
+
+// encodeGrpcListAccountEntitlementsResponse encodes ListAccountEntitlements responses
+func encodeGrpcListAccountEntitlementsResponse(_ context.Context, r interface{}) (interface{}, error) {
+	entitlements := []pb.EntitlementCheck{}
+	for _, check := range r.([]service.EntitlementCheck) {
+		entitlements = append(entitlements, *encodeEntitlementCheck(check))
+	}
+
+	return &pb.ListAccountEntitlementsResponse{
+		Entitlements: entitlements,
+	}, nil
+}
+
+// decodeGrpcCheckEntitlementRequest decodes CheckEntitlement requests
+func decodeGrpcCheckEntitlementRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.CheckEntitlementRequest)
+	return service.CheckEntitlementRequest{AccountID: req.AccountID, Key: req.Key}, nil
+}
+
+// encodeGrpcCheckEntitlementResponse encodes CheckEntitlement responses
+func encodeGrpcCheckEntitlementResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.CheckEntitlementResponse{
+		Entitlement: *encodeEntitlementCheck(r.(service.EntitlementCheck)),
+	}, nil
+}
+
+func encodePlan(p service.Plan) *pb.Plan {
+	entitlements := []pb.Entitlement{}
+	for _, e := range p.Entitlements {
+		entitlements = append(entitlements, pb.Entitlement{Key: e.Key, Enabled: e.Enabled, Limit: encodeLimit(e.Limit)})
+	}
+
+	return &pb.Plan{
+		ID:           p.ID,
+		Key:          p.Key,
+		Name:         p.Name,
+		Entitlements: entitlements,
+		Archived:     p.Archived,
+		UpdatedAt:    p.UpdatedAt,
+		CreatedAt:    p.CreatedAt,
+	}
+}
+
+func decodeEntitlements(entitlements []pb.Entitlement) []service.Entitlement {
+	decoded := make([]service.Entitlement, 0, len(entitlements))
+	for _, e := range entitlements {
+		decoded = append(decoded, service.Entitlement{Key: e.Key, Enabled: e.Enabled, Limit: decodeLimit(e.Limit)})
+	}
+
+	return decoded
+}
+
+func encodeAccountPlan(a service.AccountPlan) *pb.AccountPlan {
+	return &pb.AccountPlan{
+		ID:         a.ID,
+		AccountID:  a.AccountID,
+		PlanID:     a.PlanID,
+		StartsAt:   a.StartsAt,
+		EndsAt:     a.EndsAt,
+		AssignedBy: a.AssignedBy,
+		CreatedAt:  a.CreatedAt,
+	}
+}
+
+func encodeEntitlementCheck(c service.EntitlementCheck) *pb.EntitlementCheck {
+	encoded := &pb.EntitlementCheck{
+		AccountID: c.AccountID,
+		Key:       c.Key,
+		Allowed:   c.Allowed,
+		Limit:     encodeLimit(c.Limit),
+		PlanID:    c.PlanID,
+	}
+
+	switch c.Source {
+	case service.EntitlementFromPlan:
+		encoded.Source = pb.EntitlementCheck_PLAN
+	case service.EntitlementFromOverride:
+		encoded.Source = pb.EntitlementCheck_OVERRIDE
+	case service.EntitlementUndefined:
+		encoded.Source = pb.EntitlementCheck_UNDEFINED
+	}
+
+	return encoded
+}
+
+// encodeLimit leaves the limit unset for entitlements without one
+func encodeLimit(limit *int64) *types.Int64Value {
+	if limit == nil {
+		return nil
+	}
+
+	return &types.Int64Value{Value: *limit}
+}
+
+func decodeLimit(limit *types.Int64Value) *int64 {
+	if limit == nil {
+		return nil
+	}
+
+	return &limit.Value
+}
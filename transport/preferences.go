@@ -0,0 +1,235 @@
+package transport
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/symptomatichq/customers/protos/customers"
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeGRPCGetUserPreferencesEndpoint creates GetUserPreferences Endpoint for GRPC
+func MakeGRPCGetUserPreferencesEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.GetUserPreferencesRequest)
+		preferences, err := svc.GetUserPreferences(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return preferences, nil
+	}
+}
+
+// MakeGRPCUpdateUserPreferencesEndpoint creates UpdateUserPreferences Endpoint for GRPC
+func MakeGRPCUpdateUserPreferencesEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.UpdateUserPreferencesRequest)
+		preferences, err := svc.UpdateUserPreferences(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return preferences, nil
+	}
+}
+
+// MakeGRPCGetAccountPreferencesEndpoint creates GetAccountPreferences Endpoint for GRPC
+func MakeGRPCGetAccountPreferencesEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.GetAccountPreferencesRequest)
+		preferences, err := svc.GetAccountPreferences(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return preferences, nil
+	}
+}
+
+// MakeGRPCUpdateAccountPreferencesEndpoint creates UpdateAccountPreferences Endpoint for GRPC
+func MakeGRPCUpdateAccountPreferencesEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.UpdateAccountPreferencesRequest)
+		preferences, err := svc.UpdateAccountPreferences(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return preferences, nil
+	}
+}
+
+// GetUserPreferences
+func (s *grpcServer) GetUserPreferences(ctx context.Context, req *pb.GetUserPreferencesRequest) (*pb.GetUserPreferencesResponse, error) {
+	_, resp, err := s.getUserPreferences.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.GetUserPreferencesResponse), nil
+}
+
+// UpdateUserPreferences
+func (s *grpcServer) UpdateUserPreferences(ctx context.Context, req *pb.UpdateUserPreferencesRequest) (*pb.UpdateUserPreferencesResponse, error) {
+	_, resp, err := s.updateUserPreferences.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.UpdateUserPreferencesResponse), nil
+}
+
+// GetAccountPreferences
+func (s *grpcServer) GetAccountPreferences(ctx context.Context, req *pb.GetAccountPreferencesRequest) (*pb.GetAccountPreferencesResponse, error) {
+	_, resp, err := s.getAccountPreferences.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.GetAccountPreferencesResponse), nil
+}
+
+// UpdateAccountPreferences
+func (s *grpcServer) UpdateAccountPreferences(ctx context.Context, req *pb.UpdateAccountPreferencesRequest) (*pb.UpdateAccountPreferencesResponse, error) {
+	_, resp, err := s.updateAccountPreferences.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.UpdateAccountPreferencesResponse), nil
+}
+
+// decodeGrpcGetUserPreferencesRequest decodes GetUserPreferences requests
+func decodeGrpcGetUserPreferencesRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.GetUserPreferencesRequest)
+	return service.GetUserPreferencesRequest{UserID: req.UserID, AccountID: req.AccountID}, nil
+}
+
+// encodeGrpcGetUserPreferencesResponse encodes GetUserPreferences responses
+func encodeGrpcGetUserPreferencesResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.GetUserPreferencesResponse{
+		Preferences: *encodeUserPreferences(r.(service.UserPreferences)),
+	}, nil
+}
+
+// decodeGrpcUpdateUserPreferencesRequest decodes UpdateUserPreferences requests
+func decodeGrpcUpdateUserPreferencesRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.UpdateUserPreferencesRequest)
+	update := service.UpdateUserPreferencesRequest{
+		UserID:             req.UserID,
+		AccountID:          req.AccountID,
+		Notifications:      decodeNotifications(req.Notifications),
+		ClearNotifications: decodeNotificationChannels(req.ClearNotifications),
+	}
+	if req.Locale != nil {
+		update.Locale = &req.Locale.Value
+	}
+	if req.TimeZone != nil {
+		update.TimeZone = &req.TimeZone.Value
+	}
+
+	return update, nil
+}
+
+// encodeGrpcUpdateUserPreferencesResponse encodes UpdateUserPreferences responses
+func encodeGrpcUpdateUserPreferencesResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.UpdateUserPreferencesResponse{
+		Preferences: *encodeUserPreferences(r.(service.UserPreferences)),
+	}, nil
+}
+
+// decodeGrpcGetAccountPreferencesRequest decodes GetAccountPreferences requests
+func decodeGrpcGetAccountPreferencesRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.GetAccountPreferencesRequest)
+	return service.GetAccountPreferencesRequest{AccountID: req.AccountID}, nil
+}
+
+// encodeGrpcGetAccountPreferencesResponse encodes GetAccountPreferences responses
+func encodeGrpcGetAccountPreferencesResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.GetAccountPreferencesResponse{
+		Preferences: *encodeAccountPreferences(r.(service.AccountPreferences)),
+	}, nil
+}
+
+// decodeGrpcUpdateAccountPreferencesRequest decodes UpdateAccountPreferences requests
+func decodeGrpcUpdateAccountPreferencesRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.UpdateAccountPreferencesRequest)
+	update := service.UpdateAccountPreferencesRequest{
+		AccountID:          req.AccountID,
+		Notifications:      decodeNotifications(req.Notifications),
+		ClearNotifications: decodeNotificationChannels(req.ClearNotifications),
+	}
+	if req.Locale != nil {
+		update.Locale = &req.Locale.Value
+	}
+	if req.TimeZone != nil {
+		update.TimeZone = &req.TimeZone.Value
+	}
+
+	return update, nil
+}
+
+// encodeGrpcUpdateAccountPreferencesResponse encodes UpdateAccountPreferences responses
+func encodeGrpcUpdateAccountPreferencesResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.UpdateAccountPreferencesResponse{
+		Preferences: *encodeAccountPreferences(r.(service.AccountPreferences)),
+	}, nil
+}
+
+func encodeUserPreferences(p service.UserPreferences) *pb.UserPreferences {
+	return &pb.UserPreferences{
+		UserID:      p.UserID,
+		AccountID:   p.AccountID,
+		Preferences: *encodePreferences(p.Preferences),
+		Effective:   *encodePreferences(p.Effective),
+	}
+}
+
+func encodeAccountPreferences(p service.AccountPreferences) *pb.AccountPreferences {
+	return &pb.AccountPreferences{
+		AccountID:   p.AccountID,
+		Preferences: *encodePreferences(p.Preferences),
+		Effective:   *encodePreferences(p.Effective),
+	}
+}
+
+func encodePreferences(p service.Preferences) *pb.Preferences {
+	encoded := &pb.Preferences{
+		Locale:        p.Locale,
+		TimeZone:      p.TimeZone,
+		Notifications: map[string]bool{},
+	}
+	for channel, enabled := range p.Notifications {
+		encoded.Notifications[string(channel)] = enabled
+	}
+
+	return encoded
+}
+
+// decodeNotifications leaves the notifications nil when there are none, so
+// that updates leave them unchanged.
+func decodeNotifications(notifications map[string]bool) map[service.NotificationChannel]bool {
+	if len(notifications) == 0 {
+		return nil
+	}
+
+	decoded := map[service.NotificationChannel]bool{}
+	for channel, enabled := range notifications {
+		decoded[service.NotificationChannel(channel)] = enabled
+	}
+
+	return decoded
+}
+
+func decodeNotificationChannels(channels []string) []service.NotificationChannel {
+	decoded := []service.NotificationChannel{}
+	for _, channel := range channels {
+		decoded = append(decoded, service.NotificationChannel(channel))
+	}
+
+	return decoded
+}
@@ -0,0 +1,193 @@
+package transport
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/symptomatichq/customers/protos/customers"
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeGRPCScheduleStatusChangeEndpoint creates ScheduleStatusChange Endpoint for GRPC
+func MakeGRPCScheduleStatusChangeEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ScheduleStatusChangeRequest)
+		action, err := svc.ScheduleStatusChange(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return action, nil
+	}
+}
+
+// MakeGRPCListScheduledActionsEndpoint creates ListScheduledActions Endpoint for GRPC
+func MakeGRPCListScheduledActionsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListScheduledActionsRequest)
+		actions, err := svc.ListScheduledActions(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return actions, nil
+	}
+}
+
+// MakeGRPCCancelScheduledActionEndpoint creates CancelScheduledAction Endpoint for GRPC
+func MakeGRPCCancelScheduledActionEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.CancelScheduledActionRequest)
+		action, err := svc.CancelScheduledAction(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return action, nil
+	}
+}
+
+// ScheduleStatusChange
+func (s *grpcServer) ScheduleStatusChange(ctx context.Context, req *pb.ScheduleStatusChangeRequest) (*pb.ScheduleStatusChangeResponse, error) {
+	_, resp, err := s.scheduleStatusChange.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.ScheduleStatusChangeResponse), nil
+}
+
+// ListScheduledActions
+func (s *grpcServer) ListScheduledActions(ctx context.Context, req *pb.ListScheduledActionsRequest) (*pb.ListScheduledActionsResponse, error) {
+	_, resp, err := s.listScheduledActions.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.ListScheduledActionsResponse), nil
+}
+
+// CancelScheduledAction
+func (s *grpcServer) CancelScheduledAction(ctx context.Context, req *pb.CancelScheduledActionRequest) (*pb.CancelScheduledActionResponse, error) {
+	_, resp, err := s.cancelScheduledAction.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.CancelScheduledActionResponse), nil
+}
+
+// decodeGrpcScheduleStatusChangeRequest decodes ScheduleStatusChange requests
+func decodeGrpcScheduleStatusChangeRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ScheduleStatusChangeRequest)
+
+	status := string(decodeAccountStatus(req.AccountStatus))
+	if req.UserID != "" {
+		status = string(decodeUserStatus(req.UserStatus))
+	}
+
+	return service.ScheduleStatusChangeRequest{
+		AccountID: req.AccountID,
+		UserID:    req.UserID,
+		Status:    status,
+		RunAt:     req.RunAt,
+	}, nil
+}
+
+// encodeGrpcScheduleStatusChangeResponse encodes ScheduleStatusChange responses
+func encodeGrpcScheduleStatusChangeResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.ScheduleStatusChangeResponse{
+		Action: *encodeScheduledAction(r.(service.ScheduledAction)),
+	}, nil
+}
+
+// decodeGrpcListScheduledActionsRequest decodes ListScheduledActions requests
+func decodeGrpcListScheduledActionsRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ListScheduledActionsRequest)
+	return service.ListScheduledActionsRequest{
+		AccountID: req.AccountID,
+		UserID:    req.UserID,
+		Status:    decodeScheduledActionStatus(req.Status),
+	}, nil
+}
+
+// encodeGrpcListScheduledActionsResponse encodes ListScheduledActions responses
+func encodeGrpcListScheduledActionsResponse(_ context.Context, r interface{}) (interface{}, error) {
+	actions := []pb.ScheduledAction{}
+	for _, action := range r.([]service.ScheduledAction) {
+		actions = append(actions, *encodeScheduledAction(action))
+	}
+
+	return &pb.ListScheduledActionsResponse{
+		Actions: actions,
+	}, nil
+}
+
+// decodeGrpcCancelScheduledActionRequest decodes CancelScheduledAction requests
+func decodeGrpcCancelScheduledActionRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.CancelScheduledActionRequest)
+	return service.CancelScheduledActionRequest{AccountID: req.AccountID, ID: req.ID}, nil
+}
+
+// encodeGrpcCancelScheduledActionResponse encodes CancelScheduledAction responses
+func encodeGrpcCancelScheduledActionResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.CancelScheduledActionResponse{
+		Action: *encodeScheduledAction(r.(service.ScheduledAction)),
+	}, nil
+}
+
+func encodeScheduledAction(a service.ScheduledAction) *pb.ScheduledAction {
+	encoded := &pb.ScheduledAction{
+		ID:          a.ID,
+		AccountID:   a.AccountID,
+		UserID:      a.UserID,
+		RunAt:       a.RunAt,
+		Attempts:    int32(a.Attempts),
+		LastError:   a.LastError,
+		ScheduledBy: a.ScheduledBy,
+		CancelledBy: a.CancelledBy,
+		CompletedAt: a.CompletedAt,
+		CancelledAt: a.CancelledAt,
+		UpdatedAt:   a.UpdatedAt,
+		CreatedAt:   a.CreatedAt,
+	}
+
+	if a.UserID == "" {
+		encoded.AccountStatus = encodeAccountStatus(service.AccountStatus(a.ToStatus))
+	} else {
+		encoded.UserStatus = encodeUserStatus(service.UserStatus(a.ToStatus))
+	}
+
+	switch a.Status {
+	case service.ScheduledActionPending:
+		encoded.Status = pb.ScheduledAction_PENDING
+	case service.ScheduledActionCompleted:
+		encoded.Status = pb.ScheduledAction_COMPLETED
+	case service.ScheduledActionFailed:
+		encoded.Status = pb.ScheduledAction_FAILED
+	case service.ScheduledActionCancelled:
+		encoded.Status = pb.ScheduledAction_CANCELLED
+	}
+
+	return encoded
+}
+
+// decodeScheduledActionStatus leaves the status empty when unspecified, so
+// that actions of every status are listed.
+func decodeScheduledActionStatus(status pb.ScheduledAction_Status) service.ScheduledActionStatus {
+	switch status {
+	case pb.ScheduledAction_PENDING:
+		return service.ScheduledActionPending
+	case pb.ScheduledAction_COMPLETED:
+		return service.ScheduledActionCompleted
+	case pb.ScheduledAction_FAILED:
+		return service.ScheduledActionFailed
+	case pb.ScheduledAction_CANCELLED:
+		return service.ScheduledActionCancelled
+	}
+
+	return ""
+}
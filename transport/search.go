@@ -0,0 +1,95 @@
+package transport
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/symptomatichq/customers/protos/customers"
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeGRPCSearchCustomersEndpoint creates SearchCustomers Endpoint for GRPC
+func MakeGRPCSearchCustomersEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.SearchCustomersRequest)
+		results, err := svc.SearchCustomers(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return results, nil
+	}
+}
+
+// SearchCustomers
+func (s *grpcServer) SearchCustomers(ctx context.Context, req *pb.SearchCustomersRequest) (*pb.SearchCustomersResponse, error) {
+	_, resp, err := s.searchCustomers.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.SearchCustomersResponse), nil
+}
+
+// decodeGrpcSearchCustomersRequest decodes SearchCustomers requests
+func decodeGrpcSearchCustomersRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.SearchCustomersRequest)
+
+	var kinds []service.SearchKind
+	for _, kind := range req.Kinds {
+		switch kind {
+		case pb.SearchResult_ACCOUNT:
+			kinds = append(kinds, service.SearchAccounts)
+		case pb.SearchResult_USER:
+			kinds = append(kinds, service.SearchUsers)
+		}
+	}
+
+	return service.SearchCustomersRequest{
+		Query:      req.Query,
+		Kinds:      kinds,
+		AccountIDs: req.AccountIDs,
+		Limit:      int(req.Limit),
+	}, nil
+}
+
+// encodeGrpcSearchCustomersResponse encodes SearchCustomers responses
+func encodeGrpcSearchCustomersResponse(_ context.Context, r interface{}) (interface{}, error) {
+	results := []pb.SearchResult{}
+	for _, result := range r.([]service.SearchResult) {
+		encoded := pb.SearchResult{Score: result.Score}
+		switch result.Kind {
+		case service.SearchAccounts:
+			encoded.Kind = pb.SearchResult_ACCOUNT
+		case service.SearchUsers:
+			encoded.Kind = pb.SearchResult_USER
+		}
+		if result.Account != nil {
+			encoded.Account = encodeAccount(*result.Account)
+		}
+		if result.User != nil {
+			encoded.User = encodeUser(*result.User)
+		}
+
+		for _, highlight := range result.Highlights {
+			matches := []pb.SearchResult_Match{}
+			for _, match := range highlight.Matches {
+				matches = append(matches, pb.SearchResult_Match{Start: int32(match.Start), End: int32(match.End)})
+			}
+			encoded.Highlights = append(encoded.Highlights, pb.SearchResult_Highlight{
+				Field:   highlight.Field,
+				Value:   highlight.Value,
+				Matches: matches,
+			})
+		}
+
+		results = append(results, encoded)
+	}
+
+	return &pb.SearchCustomersResponse{
+		Results: results,
+	}, nil
+}
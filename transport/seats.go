@@ -0,0 +1,94 @@
+package transport
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-kit/kit/endpoint"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/symptomatichq/customers/protos/customers"
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeGRPCGetAccountUsageEndpoint creates GetAccountUsage Endpoint for GRPC
+func MakeGRPCGetAccountUsageEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.GetAccountUsageRequest)
+		usage, err := svc.GetAccountUsage(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return usage, nil
+	}
+}
+
+// GetAccountUsage
+func (s *grpcServer) GetAccountUsage(ctx context.Context, req *pb.GetAccountUsageRequest) (*pb.GetAccountUsageResponse, error) {
+	_, resp, err := s.getAccountUsage.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.GetAccountUsageResponse), nil
+}
+
+// decodeGrpcGetAccountUsageRequest decodes GetAccountUsage requests
+func decodeGrpcGetAccountUsageRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.GetAccountUsageRequest)
+	return service.GetAccountUsageRequest{AccountID: req.AccountID}, nil
+}
+
+// encodeGrpcGetAccountUsageResponse encodes GetAccountUsage responses
+func encodeGrpcGetAccountUsageResponse(_ context.Context, r interface{}) (interface{}, error) {
+	usage := r.(service.AccountUsage)
+	return &pb.GetAccountUsageResponse{
+		AccountID: usage.AccountID,
+		SeatsUsed: usage.SeatsUsed,
+		SeatLimit: encodeLimit(usage.SeatLimit),
+	}, nil
+}
+
+// encodeResourceExhausted describes the exhausted quota of seat limit errors
+// in a QuotaFailure, so clients can tell which limit was hit.
+func encodeResourceExhausted(err error) error {
+	st := status.New(codes.ResourceExhausted, err.Error())
+
+	limit, ok := seatLimitError(err)
+	if !ok {
+		return st.Err()
+	}
+
+	detailed, detailsErr := st.WithDetails(&errdetails.QuotaFailure{
+		Violations: []*errdetails.QuotaFailure_Violation{{
+			Subject:     fmt.Sprintf("account:%s", limit.AccountID),
+			Description: fmt.Sprintf("%d of %d seats are in use", limit.Used, limit.Limit),
+		}},
+	})
+	if detailsErr != nil {
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
+
+// seatLimitError finds a SeatLimitError among the causes of err. errors.Cause
+// cannot be used, as it unwraps the SeatLimitError itself.
+func seatLimitError(err error) (*service.SeatLimitError, bool) {
+	for err != nil {
+		if limit, ok := err.(*service.SeatLimitError); ok {
+			return limit, true
+		}
+
+		causer, ok := err.(interface{ Cause() error })
+		if !ok {
+			break
+		}
+		err = causer.Cause()
+	}
+
+	return nil, false
+}
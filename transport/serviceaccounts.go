@@ -0,0 +1,172 @@
+package transport
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/symptomatichq/customers/protos/customers"
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeGRPCCreateServiceAccountEndpoint creates CreateServiceAccount Endpoint for GRPC
+func MakeGRPCCreateServiceAccountEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.CreateServiceAccountRequest)
+		user, err := svc.CreateServiceAccount(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return user, nil
+	}
+}
+
+// MakeGRPCListServiceAccountsEndpoint creates ListServiceAccounts Endpoint for GRPC
+func MakeGRPCListServiceAccountsEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ListServiceAccountsRequest)
+		users, err := svc.ListServiceAccounts(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return users, nil
+	}
+}
+
+// MakeGRPCUpdateServiceAccountEndpoint creates UpdateServiceAccount Endpoint for GRPC
+func MakeGRPCUpdateServiceAccountEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.UpdateServiceAccountRequest)
+		user, err := svc.UpdateServiceAccount(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return user, nil
+	}
+}
+
+// MakeGRPCDeleteServiceAccountEndpoint creates DeleteServiceAccount Endpoint for GRPC
+func MakeGRPCDeleteServiceAccountEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.DeleteServiceAccountRequest)
+		if err := svc.DeleteServiceAccount(ctx, req); err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return req, nil
+	}
+}
+
+// CreateServiceAccount
+func (s *grpcServer) CreateServiceAccount(ctx context.Context, req *pb.CreateServiceAccountRequest) (*pb.CreateServiceAccountResponse, error) {
+	_, resp, err := s.createServiceAccount.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.CreateServiceAccountResponse), nil
+}
+
+// ListServiceAccounts
+func (s *grpcServer) ListServiceAccounts(ctx context.Context, req *pb.ListServiceAccountsRequest) (*pb.ListServiceAccountsResponse, error) {
+	_, resp, err := s.listServiceAccounts.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.ListServiceAccountsResponse), nil
+}
+
+// UpdateServiceAccount
+func (s *grpcServer) UpdateServiceAccount(ctx context.Context, req *pb.UpdateServiceAccountRequest) (*pb.UpdateServiceAccountResponse, error) {
+	_, resp, err := s.updateServiceAccount.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.UpdateServiceAccountResponse), nil
+}
+
+// DeleteServiceAccount
+func (s *grpcServer) DeleteServiceAccount(ctx context.Context, req *pb.DeleteServiceAccountRequest) (*pb.DeleteServiceAccountResponse, error) {
+	_, resp, err := s.deleteServiceAccount.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.DeleteServiceAccountResponse), nil
+}
+
+// decodeGrpcCreateServiceAccountRequest decodes CreateServiceAccount requests
+func decodeGrpcCreateServiceAccountRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.CreateServiceAccountRequest)
+	return service.CreateServiceAccountRequest{
+		AccountID: req.AccountID,
+		Name:      req.Name,
+		Role:      decodeRole(req.Role),
+	}, nil
+}
+
+// encodeGrpcCreateServiceAccountResponse encodes CreateServiceAccount responses
+func encodeGrpcCreateServiceAccountResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.CreateServiceAccountResponse{
+		User: *encodeUser(r.(service.User)),
+	}, nil
+}
+
+// decodeGrpcListServiceAccountsRequest decodes ListServiceAccounts requests
+func decodeGrpcListServiceAccountsRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ListServiceAccountsRequest)
+	return service.ListServiceAccountsRequest{AccountID: req.AccountID}, nil
+}
+
+// encodeGrpcListServiceAccountsResponse encodes ListServiceAccounts responses
+func encodeGrpcListServiceAccountsResponse(_ context.Context, r interface{}) (interface{}, error) {
+	users := []pb.User{}
+	for _, user := range r.([]service.User) {
+		users = append(users, *encodeUser(user))
+	}
+
+	return &pb.ListServiceAccountsResponse{
+		Users: users,
+	}, nil
+}
+
+// decodeGrpcUpdateServiceAccountRequest decodes UpdateServiceAccount requests
+func decodeGrpcUpdateServiceAccountRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.UpdateServiceAccountRequest)
+
+	update := service.UpdateServiceAccountRequest{AccountID: req.AccountID, ID: req.ID}
+	if req.Name != nil {
+		update.Name = &req.Name.Value
+	}
+	if req.Status != pb.User_INACTIVE {
+		status := decodeUserStatus(req.Status)
+		update.Status = &status
+	}
+
+	return update, nil
+}
+
+// encodeGrpcUpdateServiceAccountResponse encodes UpdateServiceAccount responses
+func encodeGrpcUpdateServiceAccountResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.UpdateServiceAccountResponse{
+		User: *encodeUser(r.(service.User)),
+	}, nil
+}
+
+// decodeGrpcDeleteServiceAccountRequest decodes DeleteServiceAccount requests
+func decodeGrpcDeleteServiceAccountRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.DeleteServiceAccountRequest)
+	return service.DeleteServiceAccountRequest{AccountID: req.AccountID, ID: req.ID}, nil
+}
+
+// encodeGrpcDeleteServiceAccountResponse encodes DeleteServiceAccount responses
+func encodeGrpcDeleteServiceAccountResponse(_ context.Context, _ interface{}) (interface{}, error) {
+	return &pb.DeleteServiceAccountResponse{}, nil
+}
@@ -0,0 +1,84 @@
+package transport
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/symptomatichq/customers/protos/customers"
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeGRPCExtendTrialEndpoint creates ExtendTrial Endpoint for GRPC
+func MakeGRPCExtendTrialEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ExtendTrialRequest)
+		account, err := svc.ExtendTrial(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return account, nil
+	}
+}
+
+// MakeGRPCConvertTrialEndpoint creates ConvertTrial Endpoint for GRPC
+func MakeGRPCConvertTrialEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ConvertTrialRequest)
+		account, err := svc.ConvertTrial(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return account, nil
+	}
+}
+
+// ExtendTrial
+func (s *grpcServer) ExtendTrial(ctx context.Context, req *pb.ExtendTrialRequest) (*pb.ExtendTrialResponse, error) {
+	_, resp, err := s.extendTrial.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.ExtendTrialResponse), nil
+}
+
+// ConvertTrial
+func (s *grpcServer) ConvertTrial(ctx context.Context, req *pb.ConvertTrialRequest) (*pb.ConvertTrialResponse, error) {
+	_, resp, err := s.convertTrial.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.ConvertTrialResponse), nil
+}
+
+// decodeGrpcExtendTrialRequest decodes ExtendTrial requests
+func decodeGrpcExtendTrialRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ExtendTrialRequest)
+	return service.ExtendTrialRequest{AccountID: req.AccountID, EndsAt: req.EndsAt}, nil
+}
+
+// encodeGrpcExtendTrialResponse encodes ExtendTrial responses
+func encodeGrpcExtendTrialResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.ExtendTrialResponse{
+		Account: *encodeAccount(r.(service.Account)),
+	}, nil
+}
+
+// decodeGrpcConvertTrialRequest decodes ConvertTrial requests
+func decodeGrpcConvertTrialRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ConvertTrialRequest)
+	return service.ConvertTrialRequest{AccountID: req.AccountID, PlanID: req.PlanID}, nil
+}
+
+// encodeGrpcConvertTrialResponse encodes ConvertTrial responses
+func encodeGrpcConvertTrialResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.ConvertTrialResponse{
+		Account: *encodeAccount(r.(service.Account)),
+	}, nil
+}
@@ -0,0 +1,93 @@
+package transport
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/symptomatichq/customers/protos/customers"
+	"github.com/symptomatichq/customers/service"
+)
+
+// MakeGRPCRequestEmailVerificationEndpoint creates RequestEmailVerification Endpoint for GRPC
+func MakeGRPCRequestEmailVerificationEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.RequestEmailVerificationRequest)
+		verification, err := svc.RequestEmailVerification(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return verification, nil
+	}
+}
+
+// MakeGRPCConfirmEmailVerificationEndpoint creates ConfirmEmailVerification Endpoint for GRPC
+func MakeGRPCConfirmEmailVerificationEndpoint(svc service.Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(service.ConfirmEmailVerificationRequest)
+		user, err := svc.ConfirmEmailVerification(ctx, req)
+		if err != nil {
+			return nil, encodeError(err, status.Errorf(codes.InvalidArgument, "invalid request"))
+		}
+
+		return user, nil
+	}
+}
+
+// RequestEmailVerification
+func (s *grpcServer) RequestEmailVerification(ctx context.Context, req *pb.RequestEmailVerificationRequest) (*pb.RequestEmailVerificationResponse, error) {
+	_, resp, err := s.requestEmailVerification.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.RequestEmailVerificationResponse), nil
+}
+
+// ConfirmEmailVerification
+func (s *grpcServer) ConfirmEmailVerification(ctx context.Context, req *pb.ConfirmEmailVerificationRequest) (*pb.ConfirmEmailVerificationResponse, error) {
+	_, resp, err := s.confirmEmailVerification.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*pb.ConfirmEmailVerificationResponse), nil
+}
+
+// decodeGrpcRequestEmailVerificationRequest decodes RequestEmailVerification requests
+func decodeGrpcRequestEmailVerificationRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.RequestEmailVerificationRequest)
+	return service.RequestEmailVerificationRequest{UserID: req.UserID}, nil
+}
+
+// encodeGrpcRequestEmailVerificationResponse encodes RequestEmailVerification
+// responses. The token is only ever sent to the email address.
+func encodeGrpcRequestEmailVerificationResponse(_ context.Context, r interface{}) (interface{}, error) {
+	verification := r.(service.EmailVerification)
+	return &pb.RequestEmailVerificationResponse{
+		Verification: pb.EmailVerification{
+			ID:         verification.ID,
+			UserID:     verification.UserID,
+			Email:      verification.Email,
+			ExpiresAt:  verification.ExpiresAt,
+			ConsumedAt: verification.ConsumedAt,
+			CreatedAt:  verification.CreatedAt,
+		},
+	}, nil
+}
+
+// decodeGrpcConfirmEmailVerificationRequest decodes ConfirmEmailVerification requests
+func decodeGrpcConfirmEmailVerificationRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ConfirmEmailVerificationRequest)
+	return service.ConfirmEmailVerificationRequest{Token: req.Token}, nil
+}
+
+// encodeGrpcConfirmEmailVerificationResponse encodes ConfirmEmailVerification responses
+func encodeGrpcConfirmEmailVerificationResponse(_ context.Context, r interface{}) (interface{}, error) {
+	return &pb.ConfirmEmailVerificationResponse{
+		User: *encodeUser(r.(service.User)),
+	}, nil
+}